@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
 	"math/big"
 	"math/bits"
 	"runtime"
@@ -103,11 +104,14 @@ func TestBFV(t *testing.T) {
 			testParameters,
 			testScaler,
 			testEncoder,
+			testIntegerEncoders,
 			testEncryptor,
 			testEvaluator,
 			testPolyEval,
 			testEvaluatorRotate,
+			testLinearTransform,
 			testEvaluatorKeySwitch,
+			testManagedEvaluator,
 			testMarshaller,
 		} {
 			testSet(tc, t)
@@ -333,6 +337,158 @@ func testEncoder(tc *testContext, t *testing.T) {
 	}
 }
 
+func testIntegerEncoders(tc *testContext, t *testing.T) {
+
+	// randomInt returns a random signed integer of at most bits bits
+	randomInt := func(bits int) *big.Int {
+		buff := make([]byte, (bits+7)/8)
+		tc.prng.Read(buff)
+		x := new(big.Int).Rsh(new(big.Int).SetBytes(buff), uint(8*len(buff)-bits))
+		if buff[0]&1 == 1 {
+			x.Neg(x)
+		}
+		return x
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("IntegerEncoder/Mul&Add", tc.params, lvl), func(t *testing.T) {
+
+			encoder := NewIntegerEncoder(tc.params, 16)
+
+			a, b := randomInt(200), randomInt(200)
+
+			ptA, err := encoder.EncodeNew(a, lvl)
+			require.NoError(t, err)
+			require.Zero(t, a.Cmp(encoder.Decode(ptA)))
+
+			ptB, err := encoder.EncodeNew(b, lvl)
+			require.NoError(t, err)
+
+			ctA := tc.encryptorSk.EncryptNew(ptA)
+			ctB := tc.encryptorSk.EncryptNew(ptB)
+
+			// a * b + a
+			ct := tc.evaluator.RelinearizeNew(tc.evaluator.MulNew(ctA, ctB))
+			tc.evaluator.Add(ct, ctA, ct)
+
+			want := new(big.Int).Mul(a, b)
+			want.Add(want, a)
+
+			require.Zero(t, want.Cmp(encoder.Decode(tc.decryptor.DecryptNew(ct))))
+
+			_, err = encoder.EncodeNew(new(big.Int).Lsh(big.NewInt(1), uint(4*tc.params.N())), lvl)
+			require.Error(t, err)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("FractionalEncoder/Mul&Add", tc.params, lvl), func(t *testing.T) {
+
+			encoder := NewFractionalEncoder(tc.params, 10, 2)
+
+			a, _ := new(big.Rat).SetString("1234.56")
+			b, _ := new(big.Rat).SetString("-78.9")
+
+			ptA, err := encoder.EncodeNew(a, lvl)
+			require.NoError(t, err)
+			require.Zero(t, a.Cmp(encoder.Decode(ptA)))
+
+			ptB, err := encoder.EncodeNew(b, lvl)
+			require.NoError(t, err)
+			require.Zero(t, b.Cmp(encoder.Decode(ptB)))
+
+			ctA := tc.encryptorSk.EncryptNew(ptA)
+			ctB := tc.encryptorSk.EncryptNew(ptB)
+
+			// a * b + a
+			ct := tc.evaluator.RelinearizeNew(tc.evaluator.MulNew(ctA, ctB))
+			tc.evaluator.Add(ct, ctA, ct)
+
+			want := new(big.Rat).Mul(a, b)
+			want.Add(want, a)
+
+			require.Zero(t, want.Cmp(encoder.Decode(tc.decryptor.DecryptNew(ct))))
+
+			// Truncation to the fractional digits
+			c, _ := new(big.Rat).SetString("-3.14159")
+			ptC, err := encoder.EncodeNew(c, lvl)
+			require.NoError(t, err)
+			want, _ = new(big.Rat).SetString("-3.14")
+			require.Zero(t, want.Cmp(encoder.Decode(ptC)))
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("WideIntegerEncoder/Add&MulScalar", tc.params, lvl), func(t *testing.T) {
+
+			encoder := NewWideIntegerEncoder(tc.params, 256, 16)
+
+			a := make([]*big.Int, encoder.Slots())
+			b := make([]*big.Int, encoder.Slots())
+			for i := range a {
+				a[i], b[i] = randomInt(120), randomInt(120)
+			}
+
+			ptA, err := encoder.EncodeNew(a, lvl)
+			require.NoError(t, err)
+
+			ptB, err := encoder.EncodeNew(b, lvl)
+			require.NoError(t, err)
+
+			ctA := tc.encryptorSk.EncryptNew(ptA)
+			ctB := tc.encryptorSk.EncryptNew(ptB)
+
+			// 3 * (a + b) - b, whose digits exceed the base and are propagated as carries at the decoding
+			ct := tc.evaluator.AddNew(ctA, ctB)
+			tc.evaluator.MulScalar(ct, 3, ct)
+			tc.evaluator.Sub(ct, ctB, ct)
+
+			have := encoder.Decode(tc.decryptor.DecryptNew(ct))
+
+			for i := range a {
+				want := new(big.Int).Add(a[i], b[i])
+				want.Mul(want, big.NewInt(3))
+				want.Sub(want, b[i])
+				require.Zero(t, want.Cmp(have[i]))
+			}
+
+			_, err = encoder.EncodeNew([]*big.Int{new(big.Int).Lsh(big.NewInt(1), 128)}, lvl)
+			require.Error(t, err)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("WideIntegerEncoder/Carries", tc.params, lvl), func(t *testing.T) {
+
+			base, digits := uint64(256), 16
+
+			encoder := NewWideIntegerEncoder(tc.params, base, digits)
+
+			// a = B^15 - 1 has its 15 least significant digits equal to B-1
+			a := new(big.Int).Exp(new(big.Int).SetUint64(base), big.NewInt(int64(digits-1)), nil)
+			a.Sub(a, big.NewInt(1))
+
+			ptA, err := encoder.EncodeNew([]*big.Int{a}, lvl)
+			require.NoError(t, err)
+
+			ctA := tc.encryptorSk.EncryptNew(ptA)
+
+			// The digits of a + a overflow the base in every slot
+			ct := tc.evaluator.AddNew(ctA, ctA)
+			pt := tc.decryptor.DecryptNew(ct)
+
+			slots := make([]uint64, tc.params.N())
+			tc.encoder.DecodeUint(pt, slots)
+			for i := 0; i < digits-1; i++ {
+				require.Equal(t, 2*(base-1), slots[i])
+			}
+
+			want := new(big.Int).Add(a, a)
+			require.Zero(t, want.Cmp(encoder.Decode(pt)[0]))
+		})
+	}
+}
+
 func testEncryptor(tc *testContext, t *testing.T) {
 	for _, lvl := range tc.testLevel {
 		t.Run(testString("Encryptor/Encrypt/key=pk", tc.params, lvl), func(t *testing.T) {
@@ -951,6 +1107,53 @@ func testEvaluatorRotate(tc *testContext, t *testing.T) {
 		})
 	}
 
+	// Batched inner sums and replications, with n not a power of two
+	batch, n := 3, 5
+
+	// want[r][i] = sum_{0 <= l < n} x[r][i + sign * l * batch], where the rotations are cyclic within each row
+	partialSum := func(values []uint64, sign int) (want []uint64) {
+		want = make([]uint64, len(values))
+		for l := 0; l < n; l++ {
+			rot := utils.RotateUint64Slots(values, sign*l*batch)
+			for i := range want {
+				want[i] = (want[i] + rot[i]) % tc.params.T()
+			}
+		}
+		return
+	}
+
+	for _, testSet := range []struct {
+		name      string
+		rotations []int
+		eval      func(eval Evaluator, ctIn *Ciphertext, ctOut *Ciphertext)
+		sign      int
+	}{
+		{"InnerSumLog", tc.params.RotationsForInnerSumLog(batch, n), func(eval Evaluator, ctIn, ctOut *Ciphertext) { eval.InnerSumLog(ctIn, batch, n, ctOut) }, 1},
+		{"InnerSumBatch", tc.params.RotationsForInnerSum(batch, n), func(eval Evaluator, ctIn, ctOut *Ciphertext) { eval.InnerSumBatch(ctIn, batch, n, ctOut) }, 1},
+		{"ReplicateLog", tc.params.RotationsForReplicateLog(batch, n), func(eval Evaluator, ctIn, ctOut *Ciphertext) { eval.ReplicateLog(ctIn, batch, n, ctOut) }, -1},
+		{"Replicate", tc.params.RotationsForReplicate(batch, n), func(eval Evaluator, ctIn, ctOut *Ciphertext) { eval.Replicate(ctIn, batch, n, ctOut) }, -1},
+	} {
+
+		rotkey = tc.kgen.GenRotationKeysForRotations(testSet.rotations, false, tc.sk)
+		evaluator = tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rotkey})
+
+		for _, lvl := range tc.testLevel {
+			t.Run(testString("Evaluator/Rotate/"+testSet.name, tc.params, lvl), func(t *testing.T) {
+				values, _, ciphertext := newTestVectorsRingQLvl(lvl, tc, tc.encryptorPk, t)
+
+				ctOut := NewCiphertextLvl(tc.params, 1, lvl)
+				testSet.eval(evaluator, ciphertext, ctOut)
+
+				want := partialSum(values.Coeffs[0], testSet.sign)
+				verifyTestVectors(tc, tc.decryptor, &ring.Poly{Coeffs: [][]uint64{want}}, ctOut, t)
+
+				// In place
+				testSet.eval(evaluator, ciphertext, ciphertext)
+				verifyTestVectors(tc, tc.decryptor, &ring.Poly{Coeffs: [][]uint64{want}}, ciphertext, t)
+			})
+		}
+	}
+
 	t.Run(testString("Evaluator/RescaleTo/Rotate", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
 		values1, _, ciphertext1 := newTestVectorsRingQLvl(tc.params.MaxLevel(), tc, tc.encryptorPk, t)
 		rotkey := tc.kgen.GenRotationKeysForRotations(nil, true, tc.sk)
@@ -963,6 +1166,269 @@ func testEvaluatorRotate(tc *testContext, t *testing.T) {
 	})
 }
 
+func testLinearTransform(tc *testContext, t *testing.T) {
+
+	if tc.params.PCount() == 0 {
+		return
+	}
+
+	params := tc.params
+	T := params.T()
+	slots := params.N() >> 1
+
+	nonZeroDiags := []int{-15, -4, -1, 0, 1, 2, 3, 4, 15}
+
+	diagMatrix := make(map[int][]uint64)
+	for _, k := range nonZeroDiags {
+		diagMatrix[k] = tc.uSampler.ReadNew().Coeffs[0]
+	}
+
+	// want[r][i] = sum_k d_k[r][i] * x[r][i+k]
+	linearTransform := func(values []uint64) (want []uint64) {
+		want = make([]uint64, len(values))
+		for k, diag := range diagMatrix {
+			rot := utils.RotateUint64Slots(values, k)
+			for i := range want {
+				want[i] = ring.CRed(want[i]+ring.BRed(diag[i], rot[i], T, tc.ringT.BredParams[0]), T)
+			}
+		}
+		return
+	}
+
+	for _, BSGSRatio := range []float64{0, 2} {
+
+		name := "Naive"
+		if BSGSRatio != 0 {
+			name = "BSGS"
+		}
+
+		rotations := params.RotationsForLinearTransform(nonZeroDiags, BSGSRatio)
+		rotkey := tc.kgen.GenRotationKeysForRotations(rotations, false, tc.sk)
+		evaluator := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rotkey})
+
+		for _, lvl := range tc.testLevel {
+			t.Run(testString("LinearTransform/"+name, params, lvl), func(t *testing.T) {
+
+				var LT LinearTransform
+				if BSGSRatio == 0 {
+					LT = GenLinearTransform(tc.encoder, diagMatrix, lvl)
+				} else {
+					LT = GenLinearTransformBSGS(tc.encoder, diagMatrix, lvl, BSGSRatio)
+					require.NotZero(t, LT.N1)
+				}
+
+				require.Subset(t, rotations, LT.Rotations())
+
+				values, _, ciphertext := newTestVectorsRingQLvl(lvl, tc, tc.encryptorPk, t)
+
+				ctOut := evaluator.LinearTransformNew(ciphertext, LT)[0]
+				require.Equal(t, lvl, ctOut.Level())
+
+				verifyTestVectors(tc, tc.decryptor, &ring.Poly{Coeffs: [][]uint64{linearTransform(values.Coeffs[0])}}, ctOut, t)
+
+				// In-place evaluation on the input ciphertext
+				evaluator.LinearTransform(ciphertext, LT, []*Ciphertext{ciphertext})
+
+				verifyTestVectors(tc, tc.decryptor, &ring.Poly{Coeffs: [][]uint64{linearTransform(values.Coeffs[0])}}, ciphertext, t)
+			})
+		}
+	}
+
+	t.Run(testString("LinearTransform/Encode", params, params.MaxLevel()), func(t *testing.T) {
+
+		// Diagonals of size N/2 given as int64 are used on both rows
+		diagMatrixInt := map[int][]int64{-1: make([]int64, slots), 1: make([]int64, slots)}
+		for i := 0; i < slots; i++ {
+			diagMatrixInt[-1][i] = -1
+			diagMatrixInt[1][i] = int64(i)
+		}
+
+		LT := NewLinearTransform(params, []int{-1, 1}, params.MaxLevel(), 0)
+		LT.Encode(tc.encoder, diagMatrixInt)
+
+		rotkey := tc.kgen.GenRotationKeysForRotations(LT.Rotations(), false, tc.sk)
+		evaluator := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rotkey})
+
+		values, _, ciphertext := newTestVectorsRingQLvl(params.MaxLevel(), tc, tc.encryptorPk, t)
+
+		ctOut := evaluator.LinearTransformNew(ciphertext, []LinearTransform{LT})[0]
+
+		x := values.Coeffs[0]
+		want := make([]uint64, len(x))
+		for r := 0; r < 2; r++ {
+			for i := 0; i < slots; i++ {
+				left := x[r*slots+(i+slots-1)%slots]
+				right := x[r*slots+(i+1)%slots]
+				want[r*slots+i] = ring.CRed(T-left+ring.BRed(uint64(i), right, T, tc.ringT.BredParams[0]), T)
+			}
+		}
+
+		verifyTestVectors(tc, tc.decryptor, &ring.Poly{Coeffs: [][]uint64{want}}, ctOut, t)
+	})
+}
+
+func testManagedEvaluator(tc *testContext, t *testing.T) {
+
+	rtks := tc.kgen.GenRotationKeysForRotations([]int{1}, true, tc.sk)
+	eval := NewManagedEvaluator(tc.params, rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rtks})
+
+	// measureNoise returns the log2 of the infinity norm of the invariant noise of ct, which is the
+	// centered residue of t * (c0 + c1 * s) modulo Q, divided by Q
+	measureNoise := func(ct *Ciphertext) float64 {
+
+		level := ct.Level()
+		Q := tc.ringQ.ModulusAtLevel[level]
+		halfQ := new(big.Int).Rsh(Q, 1)
+		T := new(big.Int).SetUint64(tc.params.T())
+
+		coeffs := make([]*big.Int, tc.params.N())
+		for i := range coeffs {
+			coeffs[i] = new(big.Int)
+		}
+		tc.ringQ.PolyToBigintCenteredLvl(level, tc.decryptor.DecryptNew(ct).Value, 1, coeffs)
+
+		max, v := new(big.Int), new(big.Int)
+		for _, c := range coeffs {
+			v.Mod(v.Mul(c, T), Q)
+			if v.Cmp(halfQ) > 0 {
+				v.Sub(Q, v)
+			}
+			if v.Cmp(max) > 0 {
+				max.Set(v)
+			}
+		}
+
+		mant := new(big.Float)
+		exp := new(big.Float).Quo(new(big.Float).SetInt(max), new(big.Float).SetInt(Q)).MantExp(mant)
+		f, _ := mant.Float64()
+		return float64(exp) + math.Log2(f)
+	}
+
+	verify := func(values *ring.Poly, ct *ManagedCiphertext, t *testing.T) {
+		verifyTestVectors(tc, tc.decryptor, values, ct.Ciphertext, t)
+		require.GreaterOrEqual(t, ct.Noise, measureNoise(ct.Ciphertext))
+		require.Greater(t, ct.NoiseBudget(), 0.0)
+	}
+
+	newTestVectors := func(t *testing.T) (values *ring.Poly, ct *ManagedCiphertext) {
+		values, _, ciphertext := newTestVectorsRingQLvl(tc.params.MaxLevel(), tc, tc.encryptorPk, t)
+		return values, NewManagedCiphertext(tc.params, ciphertext)
+	}
+
+	t.Run(testString("ManagedEvaluator/Circuit", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		if (tc.params.LogQ()-tc.params.LogT())/(tc.params.LogT()+tc.params.LogN()) < 3 {
+			t.Skip("Homomorphic Capacity Too Low")
+		}
+
+		values0, ct0 := newTestVectors(t)
+		values1, ct1 := newTestVectors(t)
+		verify(values0, ct0, t)
+
+		values := values0.CopyNew()
+
+		ct, err := eval.MulNew(ct0, ct1)
+		require.NoError(t, err)
+		tc.ringT.MulCoeffs(values, values1, values)
+		verify(values, ct, t)
+
+		require.NoError(t, eval.Mul(ct, ct0, ct))
+		tc.ringT.MulCoeffs(values, values0, values)
+		verify(values, ct, t)
+
+		// The noise of the product of depth two is larger than the noise of the modulus switching
+		require.Less(t, ct.Level(), tc.params.MaxLevel())
+
+		// The operands at a higher level are switched to the level of ct
+		require.NoError(t, eval.Add(ct, ct1, ct))
+		tc.ringT.Add(values, values1, values)
+		verify(values, ct, t)
+
+		values2, pt, _ := newTestVectorsRingQLvl(tc.params.MaxLevel(), tc, nil, t)
+		require.NoError(t, eval.Sub(ct, pt, ct))
+		tc.ringT.Sub(values, values2, values)
+		verify(values, ct, t)
+
+		values3, ptRt := newTestVectorsRingT(tc, t)
+		require.NoError(t, eval.Mul(ct, ptRt, ct))
+		tc.ringT.MulCoeffs(values, values3, values)
+		verify(values, ct, t)
+
+		values4, ptMul := newTestVectorsMulLvl(tc.params.MaxLevel(), tc, t)
+		require.NoError(t, eval.Mul(ct, ptMul, ct))
+		tc.ringT.MulCoeffs(values, values4, values)
+		verify(values, ct, t)
+
+		require.NoError(t, eval.MulScalar(ct, 7, ct))
+		require.NoError(t, eval.AddScalar(ct, 3, ct))
+		tc.ringT.MulScalar(values, 7, values)
+		tc.ringT.AddScalar(values, 3, values)
+		verify(values, ct, t)
+
+		rotated, err := eval.RotateColumnsNew(ct, 1)
+		require.NoError(t, err)
+		require.NoError(t, eval.RotateRows(rotated, rotated))
+
+		slots := tc.params.N() >> 1
+		want := tc.ringT.NewPoly()
+		for i := range want.Coeffs[0] {
+			row, col := i/slots, i%slots
+			want.Coeffs[0][i] = values.Coeffs[0][(1-row)*slots+(col+1)%slots]
+		}
+		verify(want, rotated, t)
+	})
+
+	t.Run(testString("ManagedEvaluator/Errors", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		values, ct := newTestVectors(t)
+
+		// Squares until the noise budget is exhausted, the results that are returned being correct
+		for {
+			square, err := eval.MulNew(ct, ct)
+			if err != nil {
+				break
+			}
+			ct = square
+			tc.ringT.MulCoeffs(values, values, values)
+			verify(values, ct, t)
+		}
+
+		require.Error(t, eval.Add(ct, ct.Ciphertext, ct))
+		require.Error(t, eval.Mul(ct, ct, ct))
+	})
+
+	t.Run(testString("ManagedEvaluator/ToBytes", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		if (tc.params.LogQ()-tc.params.LogT())/(tc.params.LogT()+tc.params.LogN()) < 2 {
+			t.Skip("Homomorphic Capacity Too Low")
+		}
+
+		values0, ct0 := newTestVectors(t)
+		values1, ct1 := newTestVectors(t)
+
+		ct, err := eval.MulNew(ct0, ct1)
+		require.NoError(t, err)
+		tc.ringT.MulCoeffs(values0, values1, values0)
+
+		_, err = eval.ToBytes(ct, ct.NoiseBudget()+1)
+		require.Error(t, err)
+
+		uncompressed := ct.ToBytes(&tc.params.Parameters, 0, 0)
+
+		for _, budget := range []float64{0, ct.NoiseBudget() / 2} {
+
+			data, err := eval.ToBytes(ct, budget)
+			require.NoError(t, err)
+			require.Less(t, len(data), len(uncompressed))
+
+			ctNew := new(Ciphertext)
+			ctNew.FromBytes(data)
+			verifyTestVectors(tc, tc.decryptor, values0, ctNew, t)
+			require.GreaterOrEqual(t, -1-budget, measureNoise(ctNew))
+		}
+	})
+}
+
 func testMarshaller(tc *testContext, t *testing.T) {
 
 	t.Run(testString("Marshaller/Parameters/Binary", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
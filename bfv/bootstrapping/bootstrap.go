@@ -0,0 +1,77 @@
+// Package bootstrapping implements the bootstrapping for the BFV scheme with a plaintext modulus t = p^r.
+//
+// The bootstrapping switches the input ciphertext to the plaintext modulus p^(r+1), which gives an encryption of p*m + v, where
+// v is a small error whose magnitude depends on the Hamming weight of the secret. The homomorphic decoding (CoeffsToSlots) moves
+// the coefficients p*m_i + v_i to the slots, where the digit removal polynomial removes v_i, and the homomorphic encoding
+// (SlotsToCoeffs) moves the values p*m_i back to the coefficients. The result is an encryption of p*m under p^(r+1), which is an
+// encryption of m under p^r with a fresh noise.
+package bootstrapping
+
+import (
+	"github.com/cipherflow-fhe/lattigo/bfv"
+)
+
+// Bootstrapp re-encrypts a ciphertext of degree one at any level to a ciphertext at MaxLevel.
+// The noise of the input ciphertext must leave at least log2(p) + log2(NoiseBound) bits of noise budget, where t = p^r,
+// so that the lowest base-p digit after the switch to the plaintext modulus p^(r+1) stays within the NoiseBound.
+// The output ciphertext is an encryption of the same plaintext with the noise of the bootstrapping circuit, whose depth
+// is CoeffsToSlotsDepth + DigitRemovalDepth + SlotsToCoeffsDepth.
+func (btp *Bootstrapper) Bootstrapp(ctIn *bfv.Ciphertext) (ctOut *bfv.Ciphertext) {
+
+	if ctIn.Degree() != 1 {
+		panic("cannot Bootstrapp: input ciphertext must be of degree 1")
+	}
+
+	// Steps 1 to 3: switch to p^(r+1), CoeffsToSlots and digit removal
+	ctOut = btp.halfBootstrapp(ctIn)
+
+	// Step 4: SlotsToCoeffs (homomorphic encoding)
+	return btp.SlotsToCoeffs(btp.Evaluator, ctOut)
+}
+
+// HalfBootstrapp evaluates the bootstrapping without its last step, the homomorphic encoding, and returns a ciphertext
+// at MaxLevel of the same parameters as ctIn, whose plaintext has the coefficient j + k*N/2 of the plaintext of ctIn in the slot i
+// of the row k, with j the bit-reversal of i on log2(N/2) bits. It has the same requirements on the noise of ctIn as Bootstrapp,
+// which is the noise of the coefficients that are moved to the slots.
+func (btp *Bootstrapper) HalfBootstrapp(ctIn *bfv.Ciphertext) (ctOut *bfv.Ciphertext) {
+
+	if ctIn.Degree() != 1 {
+		panic("cannot HalfBootstrapp: input ciphertext must be of degree 1")
+	}
+
+	// The slots of the output are p*m modulo p^(r+1), which are m modulo p^r.
+	return btp.halfBootstrapp(ctIn)
+}
+
+func (btp *Bootstrapper) halfBootstrapp(ctIn *bfv.Ciphertext) (ctOut *bfv.Ciphertext) {
+
+	// Step 1: switch from t = p^r to p^(r+1) and from the current level to the maximum level
+	ctOut = btp.modSwitch(ctIn)
+
+	// Step 2: CoeffsToSlots (homomorphic decoding)
+	ctOut = btp.CoeffsToSlots(btp.Evaluator, ctOut)
+
+	// Step 3: removal of the lowest base-p digit of each slot
+	var err error
+	if ctOut, err = btp.EvaluatePoly(ctOut, btp.digitRemovalPoly); err != nil {
+		panic(err)
+	}
+
+	return
+}
+
+// modSwitch returns an encryption at the maximum level of p*m + v modulo p^(r+1), where m is the plaintext
+// of ctIn modulo p^r, by rounding the coefficients of ctIn from Q_level to p^(r+1) and scaling them up to Q.
+func (btp *Bootstrapper) modSwitch(ctIn *bfv.Ciphertext) (ctOut *bfv.Ciphertext) {
+
+	ctOut = bfv.NewCiphertext(btp.paramsExt, 1)
+
+	ptRt := bfv.NewPlaintextRingT(btp.paramsExt)
+
+	for i := range ctIn.Value {
+		btp.encoder.ScaleDown(bfv.NewPlaintextAtLevelFromPoly(ctIn.Level(), ctIn.Value[i]), ptRt)
+		btp.encoder.ScaleUp(ptRt, bfv.NewPlaintextAtLevelFromPoly(ctOut.Level(), ctOut.Value[i]))
+	}
+
+	return
+}
@@ -0,0 +1,102 @@
+package bootstrapping
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ckks/advanced"
+	"github.com/cipherflow-fhe/lattigo/ring"
+)
+
+// Parameters is a struct for the BFV bootstrapping parameters.
+type Parameters struct {
+	CoeffsToSlotsDepth int     // Number of levels of the factorization of the homomorphic decoding.
+	SlotsToCoeffsDepth int     // Number of levels of the factorization of the homomorphic encoding.
+	NoiseBound         int     // Bound on the lowest digit after the modulus switch. If 0, it is derived from the Hamming weight of the secret.
+	BSGSRatio          float64 // Maximum ratio between the inner and outer loop of the baby-step giant-step algorithm.
+}
+
+// DefaultParameters are the default BFV bootstrapping parameters.
+var DefaultParameters = Parameters{
+	CoeffsToSlotsDepth: 3,
+	SlotsToCoeffsDepth: 3,
+	BSGSRatio:          2.0,
+}
+
+func (p *Parameters) validate(params bfv.Parameters) (err error) {
+
+	if p.CoeffsToSlotsDepth < 1 || p.CoeffsToSlotsDepth > params.LogN()-1 {
+		return fmt.Errorf("invalid CoeffsToSlotsDepth: must be between 1 and %d", params.LogN()-1)
+	}
+
+	if p.SlotsToCoeffsDepth < 1 || p.SlotsToCoeffsDepth > params.LogN()-1 {
+		return fmt.Errorf("invalid SlotsToCoeffsDepth: must be between 1 and %d", params.LogN()-1)
+	}
+
+	if p.BSGSRatio <= 0 {
+		return fmt.Errorf("invalid BSGSRatio: must be positive")
+	}
+
+	prime, _, ok := ring.IsPrimePower(params.T())
+	if !ok || prime&uint64(2*params.N()-1) != 1 {
+		return fmt.Errorf("invalid plaintext modulus: must be a power of a prime congruent to 1 mod 2N")
+	}
+
+	if bits := math.Log2(float64(params.T())) + math.Log2(float64(prime)); bits > 61 {
+		return fmt.Errorf("invalid plaintext modulus: T*p must be smaller than 2^61 but is 2^%.2f", bits)
+	}
+
+	if B := p.noiseBound(params); uint64(2*B) >= prime {
+		return fmt.Errorf("invalid NoiseBound: 2*NoiseBound must be smaller than the prime of the plaintext modulus")
+	}
+
+	return
+}
+
+// noiseBound returns the bound on the lowest digit v = T'/Q * e + e0 + e1 * s, where e0 and e1 are the uniform errors in [-1/2, 1/2]
+// of the rounding of the modulus switch and s the secret. If not set, it is six standard deviations of e0 + e1 * s.
+func (p *Parameters) noiseBound(params bfv.Parameters) int {
+
+	if p.NoiseBound != 0 {
+		return p.NoiseBound
+	}
+
+	return int(math.Ceil(6 * math.Sqrt(float64(params.HammingWeight()+1)/12)))
+}
+
+// DigitRemovalDepth returns the depth of the evaluation of the digit removal polynomial.
+func (p *Parameters) DigitRemovalDepth(params bfv.Parameters) int {
+	_, r, _ := ring.IsPrimePower(params.T())
+	return int(math.Ceil(math.Log2(float64((2*p.noiseBound(params) + 1) * (r + 1)))))
+}
+
+// RotationsForBootstrapping returns the list of column rotations required for the bootstrapping.
+// The bootstrapping also requires the row rotation key.
+func (p *Parameters) RotationsForBootstrapping(params bfv.Parameters) (rotations []int) {
+
+	rotKeys := make(map[int]bool)
+
+	for _, ltType := range []advanced.LinearTransformType{advanced.CoeffsToSlots, advanced.SlotsToCoeffs} {
+
+		depth := p.CoeffsToSlotsDepth
+		if ltType == advanced.SlotsToCoeffs {
+			depth = p.SlotsToCoeffsDepth
+		}
+
+		for _, matrix := range encodingMatricesIndex(params.LogN()-1, ltType, depth) {
+			for _, i := range params.RotationsForLinearTransform(matrix, p.BSGSRatio) {
+				if i != 0 {
+					rotKeys[i] = true
+				}
+			}
+		}
+	}
+
+	rotations = make([]int, 0, len(rotKeys))
+	for i := range rotKeys {
+		rotations = append(rotations, i)
+	}
+
+	return
+}
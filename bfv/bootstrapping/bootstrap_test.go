@@ -0,0 +1,171 @@
+package bootstrapping
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ckks/advanced"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams are insecure parameters for fast testing only, with plaintext moduli p and p^2.
+var testParams = []bfv.ParametersLiteral{
+	{
+		LogN: 10,
+		LogQ: []int{60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60},
+		LogP: []int{61, 61},
+		H:    64,
+		T:    65537,
+	},
+	{
+		LogN: 10,
+		LogQ: []int{60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60},
+		LogP: []int{61, 61},
+		H:    64,
+		T:    12289 * 12289,
+	},
+}
+
+func TestBootstrap(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping bootstrapping tests for GOARCH=wasm")
+	}
+
+	for _, paramsLiteral := range testParams {
+
+		params, err := bfv.NewParametersFromLiteral(paramsLiteral)
+		require.NoError(t, err)
+
+		btpParams := DefaultParameters
+
+		kgen := bfv.NewKeyGenerator(params)
+		sk, pk := kgen.GenKeyPair()
+		encoder := bfv.NewEncoder(params)
+		encryptor := bfv.NewEncryptor(params, pk)
+		decryptor := bfv.NewDecryptor(params, sk)
+		evk := GenEvaluationKeys(btpParams, params, sk)
+		eval := bfv.NewEvaluator(params, evk)
+
+		btp, err := NewBootstrapper(params, btpParams, evk)
+		require.NoError(t, err)
+
+		T := params.T()
+		paramsExt := btp.paramsExt
+		TExt := paramsExt.T()
+		bredParams := paramsExt.RingT().BredParams[0]
+
+		name := fmt.Sprintf("logN=%d/logQP=%d/T=%d", params.LogN(), params.LogQP(), T)
+
+		t.Run(name+"/EncodingMatrices", func(t *testing.T) {
+
+			applyMatrix := func(matrix map[int][]uint64, values []uint64) (res []uint64) {
+				res = make([]uint64, len(values))
+				for k, diag := range matrix {
+					for i, v := range mul(diag, utils.RotateUint64Slots(values, k), TExt, bredParams) {
+						res[i] = ring.CRed(res[i]+v, TExt)
+					}
+				}
+				return
+			}
+
+			applySplit := func(d0, d1, values []uint64) (res []uint64) {
+				slots := len(values) >> 1
+				res = applyMatrix(map[int][]uint64{0: d0}, values)
+				swapped := append(append([]uint64{}, values[slots:]...), values[:slots]...)
+				for i, v := range mul(d1, swapped, TExt, bredParams) {
+					res[i] = ring.CRed(res[i]+v, TExt)
+				}
+				return
+			}
+
+			// Decodes random coefficients to the slots
+			coeffs := randomValues(params.N(), TExt)
+			ptRt := bfv.NewPlaintextRingT(paramsExt)
+			copy(ptRt.Value.Coeffs[0], coeffs)
+			slots := bfv.NewEncoder(paramsExt).DecodeUintNew(ptRt)
+
+			// The homomorphic decoding maps the slots to the coefficients in bit-reversed order on each half
+			want := make([]uint64, params.N())
+			half := params.N() >> 1
+			for i := 0; i < half; i++ {
+				j := int(utils.BitReverse64(uint64(i), uint64(params.LogN()-1)))
+				want[i], want[i+half] = coeffs[j], coeffs[j+half]
+			}
+
+			for depth := 1; depth < params.LogN(); depth++ {
+
+				values := slots
+				for _, matrix := range encodingMatrices(paramsExt, advanced.CoeffsToSlots, depth) {
+					values = applyMatrix(matrix, values)
+				}
+
+				d0, d1 := rowSplittingMatrices(paramsExt, advanced.CoeffsToSlots)
+				values = applySplit(d0, d1, values)
+				require.Equal(t, want, values)
+
+				d0, d1 = rowSplittingMatrices(paramsExt, advanced.SlotsToCoeffs)
+				values = applySplit(d0, d1, values)
+				for _, matrix := range encodingMatrices(paramsExt, advanced.SlotsToCoeffs, depth) {
+					values = applyMatrix(matrix, values)
+				}
+				require.Equal(t, slots, values)
+			}
+		})
+
+		t.Run(name+"/DigitRemovalPolynomial", func(t *testing.T) {
+
+			prime := TExt / T
+			B := btpParams.noiseBound(params)
+			poly := btp.digitRemovalPoly
+
+			require.Equal(t, btpParams.DigitRemovalDepth(params), poly.Depth())
+
+			for _, m := range randomValues(16, T) {
+				for v := -B; v <= B; v++ {
+
+					x := ring.CRed(ring.BRed(prime, m, TExt, bredParams)+centeredToMod(v, TExt), TExt)
+
+					var y uint64
+					for i := len(poly.Coeffs) - 1; i >= 0; i-- {
+						y = ring.CRed(ring.BRed(y, x, TExt, bredParams)+poly.Coeffs[i], TExt)
+					}
+
+					require.Equal(t, ring.BRed(prime, m, TExt, bredParams), y)
+				}
+			}
+		})
+
+		t.Run(name+"/Bootstrapp", func(t *testing.T) {
+
+			values := randomValues(params.N(), T)
+			ciphertext := encryptor.EncryptNew(encoder.EncodeNew(values, params.MaxLevel()))
+
+			// Leaves only the noise budget of the first modulus
+			eval.RescaleTo(0, ciphertext, ciphertext)
+
+			ciphertext = btp.Bootstrapp(ciphertext)
+			require.Equal(t, values, encoder.DecodeUintNew(decryptor.DecryptNew(ciphertext)))
+
+			// The output can be used for further computation
+			eval.Relinearize(eval.MulNew(ciphertext, ciphertext), ciphertext)
+			for j := range values {
+				values[j] = ring.BRed(values[j], values[j], T, params.RingT().BredParams[0])
+			}
+
+			require.Equal(t, values, encoder.DecodeUintNew(decryptor.DecryptNew(ciphertext)))
+		})
+	}
+}
+
+func randomValues(n int, T uint64) (values []uint64) {
+	values = make([]uint64, n)
+	for i := range values {
+		values[i] = utils.RandUint64() % T
+	}
+	return
+}
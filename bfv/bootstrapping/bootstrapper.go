@@ -0,0 +1,110 @@
+package bootstrapping
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Bootstrapper is a struct to store a memory buffer with the plaintext matrices,
+// the digit removal polynomial, and the keys for the bootstrapping.
+// The embedded bfv.Evaluator operates with the plaintext modulus p^(r+1) of the bootstrapping circuit.
+type Bootstrapper struct {
+	bfv.Evaluator
+	*bootstrapperBase
+	encoder bfv.Encoder
+}
+
+type bootstrapperBase struct {
+	Parameters
+	params    bfv.Parameters
+	paramsExt bfv.Parameters // Parameters with plaintext modulus p^(r+1) for the plaintext modulus p^r of params
+
+	*HomomorphicEncoding // Homomorphic decoding and encoding modulo p^(r+1)
+
+	digitRemovalPoly *bfv.Polynomial
+}
+
+// NewBootstrapper creates a new Bootstrapper for the parameters params, whose plaintext modulus must be a power p^r of a prime p
+// congruent to 1 mod 2N. The bootstrapping evaluates the homomorphic decoding, the digit removal and the homomorphic encoding
+// modulo p^(r+1), which must satisfy p^(r+2) < 2^61.
+func NewBootstrapper(params bfv.Parameters, btpParams Parameters, evk rlwe.EvaluationKey) (btp *Bootstrapper, err error) {
+
+	if err = btpParams.validate(params); err != nil {
+		return nil, fmt.Errorf("cannot NewBootstrapper: %w", err)
+	}
+
+	bb := &bootstrapperBase{Parameters: btpParams, params: params}
+
+	if err = bb.checkKeys(evk); err != nil {
+		return nil, fmt.Errorf("invalid bootstrapping key: %w", err)
+	}
+
+	prime, r, _ := ring.IsPrimePower(params.T())
+
+	if bb.paramsExt, err = bfv.NewParameters(params.Parameters, params.T()*prime); err != nil {
+		return nil, fmt.Errorf("cannot NewBootstrapper: %w", err)
+	}
+
+	bb.HomomorphicEncoding = NewHomomorphicEncoding(bb.paramsExt, btpParams)
+
+	bb.digitRemovalPoly = digitRemovalPolynomial(bb.paramsExt.T(), r, btpParams.noiseBound(params))
+
+	return &Bootstrapper{
+		Evaluator:        bfv.NewEvaluator(bb.paramsExt, evk),
+		bootstrapperBase: bb,
+		encoder:          bfv.NewEncoder(bb.paramsExt),
+	}, nil
+}
+
+// GenEvaluationKeys generates the bootstrapping evaluation keys, which contain the relinearization key,
+// the column rotation keys of RotationsForBootstrapping and the row rotation key.
+func GenEvaluationKeys(btpParams Parameters, params bfv.Parameters, sk *rlwe.SecretKey) rlwe.EvaluationKey {
+	kgen := bfv.NewKeyGenerator(params)
+	return rlwe.EvaluationKey{
+		Rlk:  kgen.GenRelinearizationKey(sk, 1),
+		Rtks: kgen.GenRotationKeysForRotations(btpParams.RotationsForBootstrapping(params), true, sk),
+	}
+}
+
+// ShallowCopy creates a shallow copy of this Bootstrapper in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Bootstrapper can be used concurrently.
+func (btp *Bootstrapper) ShallowCopy() *Bootstrapper {
+	return &Bootstrapper{
+		Evaluator:        btp.Evaluator.ShallowCopy(),
+		bootstrapperBase: btp.bootstrapperBase,
+		encoder:          btp.encoder.ShallowCopy(),
+	}
+}
+
+// checkKeys checks if all the necessary keys are present in evk.
+func (bb *bootstrapperBase) checkKeys(evk rlwe.EvaluationKey) (err error) {
+
+	if evk.Rlk == nil {
+		return fmt.Errorf("relinearization key is nil")
+	}
+
+	if evk.Rtks == nil {
+		return fmt.Errorf("rotation key is nil")
+	}
+
+	rotMissing := []int{}
+	for _, i := range bb.RotationsForBootstrapping(bb.params) {
+		if _, generated := evk.Rtks.Keys[bb.params.GaloisElementForColumnRotationBy(i)]; !generated {
+			rotMissing = append(rotMissing, i)
+		}
+	}
+
+	if len(rotMissing) != 0 {
+		return fmt.Errorf("rotation key(s) missing: %d", rotMissing)
+	}
+
+	if _, generated := evk.Rtks.Keys[bb.params.GaloisElementForRowRotation()]; !generated {
+		return fmt.Errorf("row rotation key missing")
+	}
+
+	return nil
+}
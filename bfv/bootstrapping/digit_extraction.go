@@ -0,0 +1,70 @@
+package bootstrapping
+
+import (
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+)
+
+// digitRemovalPolynomial returns the polynomial F(x) = x - L(x) modulo T = p^(r+1) that removes the lowest base-p digit
+// of the values of the form p*m + v with -B <= v <= B, that is, F(p*m + v) = p*m mod T.
+//
+// L is the Hermite interpolant of the identity at the nodes v in [-B, B] with all its derivatives of order 1 to r set to zero,
+// so that its Taylor expansion L(v + p*m) = sum_{j<=r} L^(j)(v)/j! * (p*m)^j = v mod p^(r+1). The nodes are distinct modulo p
+// if 2B < p, hence their differences are invertible modulo T and L has degree (2B+1)(r+1)-1, independently of p.
+func digitRemovalPolynomial(T uint64, r, B int) (poly *bfv.Polynomial) {
+
+	bredParams := ring.BRedParams(T)
+
+	// Nodes with multiplicity r+1, equal nodes must be consecutive
+	nodes := make([]uint64, 0, (2*B+1)*(r+1))
+	for v := -B; v <= B; v++ {
+		for j := 0; j <= r; j++ {
+			nodes = append(nodes, centeredToMod(v, T))
+		}
+	}
+
+	n := len(nodes)
+
+	// Confluent divided differences: f[z_i, ..., z_i] = f^(j)(z_i)/j! is z_i for j = 0 and zero for 1 <= j <= r.
+	dd := make([]uint64, n)
+	copy(dd, nodes)
+
+	for j := 1; j < n; j++ {
+		for i := n - 1; i >= j; i-- {
+			if nodes[i] == nodes[i-j] {
+				dd[i] = 0
+			} else {
+				diff := ring.CRed(nodes[i]+T-nodes[i-j], T)
+				dd[i] = ring.BRed(ring.CRed(dd[i]+T-dd[i-1], T), modInverse(diff, T), T, bredParams)
+			}
+		}
+	}
+
+	// Newton form to monomial form: L(x) = dd[0] + (x - z_0)(dd[1] + (x - z_1)(dd[2] + ...))
+	coeffs := make([]uint64, n)
+	coeffs[0] = dd[n-1]
+	for i := n - 2; i >= 0; i-- {
+		for k := n - 1 - i; k > 0; k-- {
+			coeffs[k] = ring.CRed(coeffs[k-1]+T-ring.BRed(coeffs[k], nodes[i], T, bredParams), T)
+		}
+		coeffs[0] = ring.CRed(dd[i]+T-ring.BRed(coeffs[0], nodes[i], T, bredParams), T)
+	}
+
+	// F(x) = x - L(x)
+	for i := range coeffs {
+		if coeffs[i] != 0 {
+			coeffs[i] = T - coeffs[i]
+		}
+	}
+
+	coeffs[1] = ring.CRed(coeffs[1]+1, T)
+
+	return bfv.NewPoly(coeffs)
+}
+
+func centeredToMod(v int, T uint64) uint64 {
+	if v < 0 {
+		return T - uint64(-v)
+	}
+	return uint64(v)
+}
@@ -0,0 +1,411 @@
+package bootstrapping
+
+import (
+	"math"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ckks/advanced"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// HomomorphicEncoding stores the plaintext matrices of the homomorphic decoding (CoeffsToSlots) and encoding (SlotsToCoeffs)
+// for the plaintext modulus of a set of bfv.Parameters, which must be a power of a prime congruent to 1 mod 2N.
+type HomomorphicEncoding struct {
+	params      bfv.Parameters
+	ctsMatrices []bfv.LinearTransform
+	ctsSplit    [2]*bfv.PlaintextMul
+	stcSplit    [2]*bfv.PlaintextMul
+	stcMatrices []bfv.LinearTransform
+}
+
+// NewHomomorphicEncoding encodes the matrices of the homomorphic decoding and encoding modulo the plaintext modulus of params,
+// factorized in btpParams.CoeffsToSlotsDepth and btpParams.SlotsToCoeffsDepth matrices. Their evaluation requires the rotation
+// keys of btpParams.RotationsForBootstrapping and the row rotation key.
+func NewHomomorphicEncoding(params bfv.Parameters, btpParams Parameters) (he *HomomorphicEncoding) {
+
+	he = &HomomorphicEncoding{params: params}
+
+	encoder := bfv.NewEncoder(params)
+
+	for _, ltType := range []advanced.LinearTransformType{advanced.CoeffsToSlots, advanced.SlotsToCoeffs} {
+
+		depth := btpParams.CoeffsToSlotsDepth
+		if ltType == advanced.SlotsToCoeffs {
+			depth = btpParams.SlotsToCoeffsDepth
+		}
+
+		matrices := encodingMatrices(params, ltType, depth)
+		lts := make([]bfv.LinearTransform, len(matrices))
+		for i := range matrices {
+			lts[i] = bfv.GenLinearTransformBSGS(encoder, matrices[i], params.MaxLevel(), btpParams.BSGSRatio)
+		}
+
+		d0, d1 := rowSplittingMatrices(params, ltType)
+		split := [2]*bfv.PlaintextMul{
+			encoder.EncodeMulNew(d0, params.MaxLevel()),
+			encoder.EncodeMulNew(d1, params.MaxLevel()),
+		}
+
+		if ltType == advanced.CoeffsToSlots {
+			he.ctsMatrices, he.ctsSplit = lts, split
+		} else {
+			he.stcMatrices, he.stcSplit = lts, split
+		}
+	}
+
+	return
+}
+
+// CoeffsToSlots evaluates the homomorphic decoding on ctIn with eval, which must be an evaluator for the parameters of he.
+// The coefficient j + k*N/2 of the plaintext of ctIn is moved to the slot i of the row k, with j the bit-reversal of i on log2(N/2) bits.
+func (he *HomomorphicEncoding) CoeffsToSlots(eval bfv.Evaluator, ctIn *bfv.Ciphertext) (ctOut *bfv.Ciphertext) {
+
+	ctOut = ctIn
+	for _, lt := range he.ctsMatrices {
+		ctOut = eval.LinearTransformNew(ctOut, lt)[0]
+	}
+
+	return he.splitRows(eval, ctOut, he.ctsSplit)
+}
+
+// SlotsToCoeffs evaluates the homomorphic encoding on ctIn with eval, which must be an evaluator for the parameters of he.
+// It is the inverse of CoeffsToSlots.
+func (he *HomomorphicEncoding) SlotsToCoeffs(eval bfv.Evaluator, ctIn *bfv.Ciphertext) (ctOut *bfv.Ciphertext) {
+
+	ctOut = he.splitRows(eval, ctIn, he.stcSplit)
+	for _, lt := range he.stcMatrices {
+		ctOut = eval.LinearTransformNew(ctOut, lt)[0]
+	}
+
+	return
+}
+
+// splitRows returns split[0] * ctIn + split[1] * RotateRows(ctIn).
+func (he *HomomorphicEncoding) splitRows(eval bfv.Evaluator, ctIn *bfv.Ciphertext, split [2]*bfv.PlaintextMul) (ctOut *bfv.Ciphertext) {
+	ctOut = bfv.NewCiphertextLvl(he.params, 1, ctIn.Level())
+	tmp := eval.RotateRowsNew(ctIn)
+	eval.Mul(ctIn, split[0], ctOut)
+	eval.MulAndAdd(tmp, split[1], ctOut)
+	return
+}
+
+// encodingMatrices returns the diagonals of the factorized homomorphic encoding (SlotsToCoeffs) or decoding (CoeffsToSlots)
+// modulo the plaintext modulus of params, as a list of depth matrices to be applied in order.
+//
+// The DFT of the slots is the special FFT of the ckks/advanced package with the complex 2N-th root of unity replaced by the
+// 2N-th root of unity psi of the NTT modulo T, and the complex conjugation replaced by the row rotation: the first row of
+// the slots of a plaintext m(X) are the evaluations of w(Y) = sum_{k<N/2} (m_k + i*m_{k+N/2}) Y^k at psi^{5^j} with i = psi^{N/2},
+// and the second row the evaluations of sum_{k<N/2} (m_k - i*m_{k+N/2}) Y^k at psi^{-5^j}.
+//
+// The CoeffsToSlots matrices map the slots to the bit-reversed coefficients (m_k + i*m_{k+N/2}) and (m_k - i*m_{k+N/2}) on the
+// first and second row, which the row splitting matrix of rowSplittingMatrices maps to m_k and m_{k+N/2}. The SlotsToCoeffs
+// matrices are the inverse.
+func encodingMatrices(params bfv.Parameters, ltType advanced.LinearTransformType, depth int) (matrices []map[int][]uint64) {
+
+	ringT := params.RingT()
+	T := ringT.Modulus[0]
+	bredParams := ringT.BredParams[0]
+
+	logSlots := params.LogN() - 1
+	slots := 1 << logSlots
+
+	// 2N-th roots of unity of the two rows
+	psi := ring.InvMForm(ringT.PsiMont[0], T, ringT.MredParams[0])
+	psiInv := ring.InvMForm(ringT.PsiInvMont[0], T, ringT.MredParams[0])
+	roots := [2][]uint64{computeRoots(psi, slots<<2, T, bredParams), computeRoots(psiInv, slots<<2, T, bredParams)}
+
+	pow5 := make([]int, (slots<<1)+1)
+	pow5[0] = 1
+	for i := 1; i < (slots<<1)+1; i++ {
+		pow5[i] = pow5[i-1] * 5
+		pow5[i] &= (slots << 2) - 1
+	}
+
+	// Butterflies of each layer of the FFT, one row after the other
+	var a, b, c [][]uint64
+	for row := 0; row < 2; row++ {
+
+		var aRow, bRow, cRow [][]uint64
+		if ltType == advanced.CoeffsToSlots {
+			aRow, bRow, cRow = fftInvPlainVec(logSlots, roots[row], pow5, T)
+		} else {
+			aRow, bRow, cRow = fftPlainVec(logSlots, roots[row], pow5, T)
+		}
+
+		if row == 0 {
+			a, b, c = aRow, bRow, cRow
+		} else {
+			for i := range a {
+				a[i] = append(a[i], aRow[i]...)
+				b[i] = append(b[i], bRow[i]...)
+				c[i] = append(c[i], cRow[i]...)
+			}
+		}
+	}
+
+	merge := mergeLevels(logSlots, ltType, depth)
+
+	matrices = make([]map[int][]uint64, depth)
+
+	fftLevel := logSlots
+	for i := 0; i < depth; i++ {
+
+		matrices[i] = make(map[int][]uint64)
+		matrices[i][0] = make([]uint64, slots<<1)
+		for j := range matrices[i][0] {
+			matrices[i][0][j] = 1
+		}
+
+		for j := 0; j < merge[i]; j++ {
+			rot := layerRotation(logSlots, fftLevel-j, ltType)
+			level := logSlots - (fftLevel - j)
+			matrices[i] = multiplyWithLayer(matrices[i], slots, rot, a[level], b[level], c[level], T, bredParams)
+		}
+
+		fftLevel -= merge[i]
+	}
+
+	return
+}
+
+// rowSplittingMatrices returns the diagonals of the matrix applied on a ciphertext and of the matrix applied on its row rotation,
+// whose sum maps the first and second row (m_k + i*m_{k+N/2}) and (m_k - i*m_{k+N/2}) to m_k and m_{k+N/2} (CoeffsToSlots),
+// or the inverse (SlotsToCoeffs). The CoeffsToSlots matrices also cancel the factor N/2 of the inverse DFT.
+func rowSplittingMatrices(params bfv.Parameters, ltType advanced.LinearTransformType) (d0, d1 []uint64) {
+
+	ringT := params.RingT()
+	T := ringT.Modulus[0]
+	bredParams := ringT.BredParams[0]
+
+	slots := params.N() >> 1
+
+	// i = psi^{N/2} is a primitive 4-th root of unity
+	psi := ring.InvMForm(ringT.PsiMont[0], T, ringT.MredParams[0])
+	i := ring.ModExp(psi, uint64(slots), T)
+
+	d0 = make([]uint64, slots<<1)
+	d1 = make([]uint64, slots<<1)
+
+	if ltType == advanced.CoeffsToSlots {
+
+		// 1/(2*N/2) and 1/(2*i*N/2)
+		half := modInverse(uint64(slots<<1), T)
+		halfOverI := ring.BRed(half, T-i, T, bredParams)
+
+		for j := 0; j < slots; j++ {
+			d0[j], d1[j] = half, half
+			d0[j+slots], d1[j+slots] = T-halfOverI, halfOverI
+		}
+
+	} else {
+
+		for j := 0; j < slots; j++ {
+			d0[j], d1[j] = 1, i
+			d0[j+slots], d1[j+slots] = T-i, 1
+		}
+	}
+
+	return
+}
+
+func computeRoots(psi uint64, m int, T uint64, bredParams []uint64) (roots []uint64) {
+
+	roots = make([]uint64, m)
+	roots[0] = 1
+
+	for i := 1; i < m; i++ {
+		roots[i] = ring.BRed(roots[i-1], psi, T, bredParams)
+	}
+
+	return
+}
+
+func fftPlainVec(logN int, roots []uint64, pow5 []int, T uint64) (a, b, c [][]uint64) {
+
+	N := 1 << logN
+
+	a = make([][]uint64, logN)
+	b = make([][]uint64, logN)
+	c = make([][]uint64, logN)
+
+	index := 0
+	for m := 2; m <= N; m <<= 1 {
+
+		a[index] = make([]uint64, N)
+		b[index] = make([]uint64, N)
+		c[index] = make([]uint64, N)
+
+		tt := m >> 1
+
+		for i := 0; i < N; i += m {
+
+			gap := N / m
+			mask := (m << 2) - 1
+
+			for j := 0; j < m>>1; j++ {
+
+				k := (pow5[j] & mask) * gap
+
+				idx1 := i + j
+				idx2 := i + j + tt
+
+				a[index][idx1] = 1
+				a[index][idx2] = T - roots[k]
+				b[index][idx1] = roots[k]
+				c[index][idx2] = 1
+			}
+		}
+
+		index++
+	}
+
+	return
+}
+
+func fftInvPlainVec(logN int, roots []uint64, pow5 []int, T uint64) (a, b, c [][]uint64) {
+
+	N := 1 << logN
+
+	a = make([][]uint64, logN)
+	b = make([][]uint64, logN)
+	c = make([][]uint64, logN)
+
+	index := 0
+	for m := N; m >= 2; m >>= 1 {
+
+		a[index] = make([]uint64, N)
+		b[index] = make([]uint64, N)
+		c[index] = make([]uint64, N)
+
+		tt := m >> 1
+
+		for i := 0; i < N; i += m {
+
+			gap := N / m
+			mask := (m << 2) - 1
+
+			for j := 0; j < m>>1; j++ {
+
+				k := ((m << 2) - (pow5[j] & mask)) * gap
+
+				idx1 := i + j
+				idx2 := i + j + tt
+
+				a[index][idx1] = 1
+				a[index][idx2] = T - roots[k]
+				b[index][idx1] = 1
+				c[index][idx2] = roots[k]
+			}
+		}
+
+		index++
+	}
+
+	return
+}
+
+func layerRotation(logSlots, fftLevel int, ltType advanced.LinearTransformType) int {
+	if ltType == advanced.CoeffsToSlots {
+		return 1 << (fftLevel - 1)
+	}
+	return 1 << (logSlots - fftLevel)
+}
+
+// multiplyWithLayer returns the diagonals of the product of the layer a*x + b*rot(x, rot) + c*rot(x, -rot) with the matrix vec.
+func multiplyWithLayer(vec map[int][]uint64, slots, rot int, a, b, c []uint64, T uint64, bredParams []uint64) (newVec map[int][]uint64) {
+
+	newVec = make(map[int][]uint64)
+
+	for i := range vec {
+		addToDiagMatrix(newVec, i, mul(vec[i], a, T, bredParams), T)
+		addToDiagMatrix(newVec, (i+rot)&(slots-1), mul(utils.RotateUint64Slots(vec[i], rot), b, T, bredParams), T)
+		addToDiagMatrix(newVec, (i-rot)&(slots-1), mul(utils.RotateUint64Slots(vec[i], -rot), c, T, bredParams), T)
+	}
+
+	return
+}
+
+func addToDiagMatrix(diagMat map[int][]uint64, index int, vec []uint64, T uint64) {
+	if diagMat[index] == nil {
+		diagMat[index] = vec
+	} else {
+		for i := range vec {
+			diagMat[index][i] = ring.CRed(diagMat[index][i]+vec[i], T)
+		}
+	}
+}
+
+func mul(a, b []uint64, T uint64, bredParams []uint64) (res []uint64) {
+
+	res = make([]uint64, len(a))
+
+	for i := range a {
+		res[i] = ring.BRed(a[i], b[i], T, bredParams)
+	}
+
+	return
+}
+
+// modInverse returns x^-1 mod T for T a prime power.
+func modInverse(x, T uint64) uint64 {
+	p, _, _ := ring.IsPrimePower(T)
+	return ring.ModExp(x, T/p*(p-1)-1, T)
+}
+
+// encodingMatricesIndex returns the indexes of the non-zero diagonals of the matrices of encodingMatrices.
+func encodingMatricesIndex(logSlots int, ltType advanced.LinearTransformType, depth int) (index []map[int]bool) {
+
+	slots := 1 << logSlots
+
+	merge := mergeLevels(logSlots, ltType, depth)
+
+	index = make([]map[int]bool, depth)
+
+	fftLevel := logSlots
+	for i := 0; i < depth; i++ {
+
+		index[i] = map[int]bool{0: true}
+
+		for j := 0; j < merge[i]; j++ {
+
+			rot := layerRotation(logSlots, fftLevel-j, ltType)
+
+			next := make(map[int]bool)
+			for k := range index[i] {
+				next[k] = true
+				next[(k+rot)&(slots-1)] = true
+				next[(k-rot)&(slots-1)] = true
+			}
+
+			index[i] = next
+		}
+
+		fftLevel -= merge[i]
+	}
+
+	return
+}
+
+// mergeLevels returns the number of layers of the FFT merged in each of the depth matrices, with the same
+// merging as ckks/advanced.
+func mergeLevels(logSlots int, ltType advanced.LinearTransformType, depth int) (merge []int) {
+
+	merge = make([]int, depth)
+
+	fftLevel := logSlots
+	for i := 0; i < depth; i++ {
+
+		d := int(math.Ceil(float64(fftLevel) / float64(depth-i)))
+
+		if ltType == advanced.CoeffsToSlots {
+			merge[i] = d
+		} else {
+			merge[len(merge)-i-1] = d
+		}
+
+		fftLevel -= d
+	}
+
+	return
+}
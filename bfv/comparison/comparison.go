@@ -0,0 +1,164 @@
+// Package comparison implements exact equality, comparison and range tests on the slots of BFV ciphertexts
+// with a prime plaintext modulus t.
+//
+// Each test is the evaluation of the indicator function of a subset of Z_t on a value z derived from the inputs.
+// Over Z_t, the indicator of a set S is the polynomial sum_{a in S} 1 - (z - a)^(t-1), which is evaluated as
+// P(z) + c * z^(t-1) with P of degree at most t-2: P is evaluated with the baby-step giant-step (Paterson–Stockmeyer)
+// algorithm of bfv.Evaluator.EvaluatePoly, which only computes the odd (resp. even) baby-step powers of odd (resp. even)
+// polynomials, and z^(t-1) is taken from the same power basis.
+//
+// All the tests evaluate a polynomial of degree t-1, with a multiplicative depth of ceil(log2(t-1)) and O(sqrt(t))
+// ciphertext multiplications, and the coefficients of the LessThan polynomial are precomputed with O(t^2) operations
+// modulo t. The package is therefore only practical for small plaintext moduli, of about 14 bits (e.g. t = 12289) at most.
+package comparison
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+)
+
+// Evaluator is a struct to evaluate exact equality, comparison and range tests on BFV ciphertexts.
+// The outputs encrypt 1 in the slots where the test is true and 0 elsewhere.
+// It requires the relinearization key.
+type Evaluator struct {
+	bfv.Evaluator
+	*evaluatorBase
+}
+
+type evaluatorBase struct {
+	params bfv.Parameters
+
+	lessThanOnce sync.Once
+	lessThanPoly *bfv.Polynomial // Odd part of the indicator of [(t+1)/2, t-1], computed on the first call to LessThan
+}
+
+// NewEvaluator creates a new Evaluator from the evaluator eval. It returns an error if the plaintext modulus is not prime.
+func NewEvaluator(params bfv.Parameters, eval bfv.Evaluator) (*Evaluator, error) {
+
+	if T := params.T(); T < 3 || !ring.IsPrime(T) {
+		return nil, fmt.Errorf("cannot NewEvaluator: the plaintext modulus t=%d must be an odd prime", T)
+	}
+
+	return &Evaluator{Evaluator: eval, evaluatorBase: &evaluatorBase{params: params}}, nil
+}
+
+// ShallowCopy creates a shallow copy of this Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluator can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	return &Evaluator{Evaluator: eval.Evaluator.ShallowCopy(), evaluatorBase: eval.evaluatorBase}
+}
+
+// IsZero evaluates z == 0 on the slots of ct as 1 - z^(t-1) and returns the result on a new ciphertext.
+func (eval *Evaluator) IsZero(ct *bfv.Ciphertext) (ctOut *bfv.Ciphertext, err error) {
+
+	T := eval.params.T()
+
+	pb := bfv.NewPowerBasis(ct)
+	pb.GenPower(int(T-1), eval.Evaluator)
+
+	ctOut = eval.NegNew(pb.Value[int(T-1)])
+	eval.AddScalar(ctOut, 1, ctOut)
+
+	return
+}
+
+// Equal evaluates x == y on the slots x of ct0 and y of op1 and returns the result on a new ciphertext.
+// op1 can be a *bfv.Ciphertext or any bfv plaintext.
+func (eval *Evaluator) Equal(ct0 *bfv.Ciphertext, op1 bfv.Operand) (ctOut *bfv.Ciphertext, err error) {
+	return eval.IsZero(eval.SubNew(ct0, op1))
+}
+
+// LessThan evaluates x < y on the slots x of ct0 and y of op1 and returns the result on a new ciphertext.
+// op1 can be a *bfv.Ciphertext or any bfv plaintext.
+// The result is exact if x and y are in [0, (t-1)/2], in which case x < y if and only if x - y mod t is in
+// [(t+1)/2, t-1]. The coefficients of the indicator of this set are computed once, on the first call, with
+// O(t^2) operations modulo t.
+func (eval *Evaluator) LessThan(ct0 *bfv.Ciphertext, op1 bfv.Operand) (ctOut *bfv.Ciphertext, err error) {
+
+	eval.lessThanOnce.Do(func() {
+		eval.lessThanPoly = lessThanPolynomial(eval.params.T())
+	})
+
+	// The indicator of the negative values is (t+1)/2 * z^(t-1) plus an odd polynomial
+	if ctOut, err = eval.evaluate(eval.SubNew(ct0, op1), eval.lessThanPoly, (eval.params.T()+1)>>1); err != nil {
+		return nil, fmt.Errorf("cannot LessThan: %w", err)
+	}
+
+	return
+}
+
+// InRange evaluates lo <= x <= hi on the slots x of ct and returns the result on a new ciphertext.
+// The bounds must satisfy lo <= hi < t. The test is the evaluation of the indicator of the set
+// {-(hi-lo), -(hi-lo)+2, ..., hi-lo} on 2x - lo - hi, which is an even polynomial plus -(hi-lo+1) * z^(t-1).
+// Its coefficients are computed with O((hi-lo) * t) operations modulo t.
+func (eval *Evaluator) InRange(ct *bfv.Ciphertext, lo, hi uint64) (ctOut *bfv.Ciphertext, err error) {
+
+	T := eval.params.T()
+
+	if lo > hi || hi >= T {
+		return nil, fmt.Errorf("cannot InRange: the bounds must satisfy lo <= hi < t")
+	}
+
+	// z = 2x - lo - hi
+	z := eval.MulScalarNew(ct, 2)
+	eval.AddScalar(z, (2*T-lo-hi)%T, z)
+
+	span := hi - lo
+
+	if ctOut, err = eval.evaluate(z, inRangePolynomial(T, span), (T-(span+1)%T)%T); err != nil {
+		return nil, fmt.Errorf("cannot InRange: %w", err)
+	}
+
+	return
+}
+
+// evaluate evaluates pol(z) + lead * z^(t-1) and returns the result on a new ciphertext.
+func (eval *Evaluator) evaluate(z *bfv.Ciphertext, pol *bfv.Polynomial, lead uint64) (ctOut *bfv.Ciphertext, err error) {
+
+	pb := bfv.NewPowerBasis(z)
+
+	if ctOut, err = eval.EvaluatePoly(pb, pol); err != nil {
+		return nil, err
+	}
+
+	if lead != 0 {
+		T := int(eval.params.T())
+		pb.GenPower(T-1, eval.Evaluator)
+		eval.MulScalarAndAdd(pb.Value[T-1], lead, ctOut)
+	}
+
+	return
+}
+
+// OperationReport is the cost of an operation of the Evaluator.
+type OperationReport struct {
+	Degree int // Degree of the evaluated polynomial
+	Depth  int // Multiplicative depth
+}
+
+// DepthReport reports the cost of each operation of the Evaluator.
+type DepthReport struct {
+	IsZero   OperationReport
+	Equal    OperationReport
+	LessThan OperationReport
+	InRange  OperationReport
+}
+
+// String returns a string representation of the DepthReport.
+func (r DepthReport) String() string {
+	return fmt.Sprintf("IsZero: degree=%d depth=%d, Equal: degree=%d depth=%d, LessThan: degree=%d depth=%d, InRange: degree=%d depth=%d",
+		r.IsZero.Degree, r.IsZero.Depth, r.Equal.Degree, r.Equal.Depth, r.LessThan.Degree, r.LessThan.Depth, r.InRange.Degree, r.InRange.Depth)
+}
+
+// DepthReport returns the degree and the multiplicative depth of each operation of the Evaluator.
+// The inputs of a test must be able to support this depth for its output to be decrypted correctly.
+func (eval *Evaluator) DepthReport() DepthReport {
+	T := eval.params.T()
+	op := OperationReport{Degree: int(T - 1), Depth: bits.Len64(T - 2)} // ceil(log2(t-1))
+	return DepthReport{IsZero: op, Equal: op, LessThan: op, InRange: op}
+}
@@ -0,0 +1,155 @@
+package comparison
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams are insecure parameters for fast testing only, with a prime plaintext modulus t = 1 mod 2N.
+var testParams = bfv.ParametersLiteral{
+	LogN: 10,
+	LogQ: []int{60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60},
+	LogP: []int{61, 61},
+	H:    64,
+	T:    12289,
+}
+
+func TestComparison(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping comparison tests for GOARCH=wasm")
+	}
+
+	params, err := bfv.NewParametersFromLiteral(testParams)
+	require.NoError(t, err)
+
+	kgen := bfv.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPair()
+	encoder := bfv.NewEncoder(params)
+	encryptor := bfv.NewEncryptor(params, pk)
+	decryptor := bfv.NewDecryptor(params, sk)
+
+	eval, err := NewEvaluator(params, bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1)}))
+	require.NoError(t, err)
+
+	T := params.T()
+	half := (T - 1) >> 1
+	N := params.N()
+
+	name := fmt.Sprintf("logN=%d/logQP=%d/T=%d", params.LogN(), params.LogQP(), T)
+
+	prng, err := utils.NewPRNG()
+	require.NoError(t, err)
+	sampler := ring.NewUniformSampler(prng, params.RingT())
+
+	// Returns uniform values in [0, bound]
+	sample := func(bound uint64) (values []uint64) {
+		values = sampler.ReadNew().Coeffs[0]
+		for i := range values {
+			values[i] %= bound + 1
+		}
+		return
+	}
+
+	encrypt := func(values []uint64) *bfv.Ciphertext {
+		pt := bfv.NewPlaintext(params)
+		encoder.Encode(values, pt)
+		return encryptor.EncryptNew(pt)
+	}
+
+	verify := func(t *testing.T, want func(i int) bool, ct *bfv.Ciphertext) {
+		have := encoder.DecodeUintNew(decryptor.DecryptNew(ct))
+		for i := range have {
+			if want(i) {
+				require.Equal(t, uint64(1), have[i], "slot %d", i)
+			} else {
+				require.Equal(t, uint64(0), have[i], "slot %d", i)
+			}
+		}
+	}
+
+	t.Run(name+"/DepthReport", func(t *testing.T) {
+		report := eval.DepthReport()
+		require.Equal(t, 14, report.LessThan.Depth)
+		require.Equal(t, int(T-1), report.InRange.Degree)
+		require.NotEmpty(t, report.String())
+	})
+
+	t.Run(name+"/IsZero", func(t *testing.T) {
+		x := sample(T - 1)
+		for i := 0; i < N; i += 3 {
+			x[i] = 0
+		}
+		ctOut, err := eval.IsZero(encrypt(x))
+		require.NoError(t, err)
+		verify(t, func(i int) bool { return x[i] == 0 }, ctOut)
+	})
+
+	t.Run(name+"/Equal", func(t *testing.T) {
+		x, y := sample(T-1), sample(T-1)
+		for i := 0; i < N; i += 2 {
+			y[i] = x[i]
+		}
+
+		ctOut, err := eval.Equal(encrypt(x), encrypt(y))
+		require.NoError(t, err)
+		verify(t, func(i int) bool { return x[i] == y[i] }, ctOut)
+
+		// With a plaintext operand
+		pt := bfv.NewPlaintext(params)
+		encoder.Encode(y, pt)
+		ctOut, err = eval.Equal(encrypt(x), pt)
+		require.NoError(t, err)
+		verify(t, func(i int) bool { return x[i] == y[i] }, ctOut)
+	})
+
+	t.Run(name+"/LessThan", func(t *testing.T) {
+		x, y := sample(half), sample(half)
+		for i := 0; i < N; i += 4 {
+			y[i] = x[i]
+		}
+		// Extreme values of the domain
+		x[1], y[1] = 0, half
+		x[2], y[2] = half, 0
+
+		ctOut, err := eval.LessThan(encrypt(x), encrypt(y))
+		require.NoError(t, err)
+		verify(t, func(i int) bool { return x[i] < y[i] }, ctOut)
+	})
+
+	t.Run(name+"/InRange", func(t *testing.T) {
+		for _, bounds := range [][2]uint64{{100, 5000}, {7, 7}, {0, 1}, {T - 40, T - 1}} {
+
+			lo, hi := bounds[0], bounds[1]
+
+			x := sample(T - 1)
+			// Values at and around the bounds
+			for i, v := range []uint64{lo, hi, lo - 1, hi + 1} {
+				x[i] = v % T
+			}
+
+			ctOut, err := eval.InRange(encrypt(x), lo, hi)
+			require.NoError(t, err)
+			verify(t, func(i int) bool { return lo <= x[i] && x[i] <= hi }, ctOut)
+		}
+
+		_, err := eval.InRange(encrypt(sample(T-1)), 10, 9)
+		require.Error(t, err)
+	})
+
+	t.Run(name+"/InvalidPlaintextModulus", func(t *testing.T) {
+		paramsLiteral := testParams
+		paramsLiteral.T = 12289 * 12289
+		params, err := bfv.NewParametersFromLiteral(paramsLiteral)
+		require.NoError(t, err)
+		_, err = NewEvaluator(params, nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,76 @@
+package comparison
+
+import (
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+)
+
+// The indicator of a set S of Z_t is sum_{a in S} 1 - (z - a)^(t-1). Since binomial(t-1, i) = (-1)^i mod t,
+// its coefficient of degree 0 is 1 if 0 is in S and 0 otherwise, and its coefficient of degree 0 < i <= t-1 is
+// -sum_{a in S} a^(t-1-i), with 0^0 = 1.
+
+// lessThanPolynomial returns the polynomial of degree t-2 such that its sum with (t+1)/2 * z^(t-1) is the
+// indicator of S = [(t+1)/2, t-1] = {-1, ..., -(t-1)/2}. As S and -S partition the non-zero elements of Z_t,
+// their indicators sum to z^(t-1), so the indicator of S is 1/2 * z^(t-1) = (t+1)/2 * z^(t-1) plus an odd polynomial,
+// whose coefficient of odd degree i is sum_{b=1}^{(t-1)/2} b^(t-1-i). It is computed with O(t^2) operations modulo t.
+func lessThanPolynomial(T uint64) (poly *bfv.Polynomial) {
+
+	bredParams := ring.BRedParams(T)
+
+	sums := make([]uint64, T-1)
+	for b := uint64(1); b <= (T-1)>>1; b++ {
+		addOddPowers(b, T, bredParams, sums)
+	}
+
+	coeffs := make([]uint64, T-1)
+	for i := 1; i < len(coeffs); i += 2 {
+		coeffs[i] = sums[int(T)-1-i]
+	}
+
+	return bfv.NewPoly(coeffs)
+}
+
+// inRangePolynomial returns the polynomial of degree t-3 such that its sum with -(span+1) * z^(t-1) is the
+// indicator of S = {-span, -span+2, ..., span}. As S = -S, this polynomial is even and its coefficient of even
+// degree i > 0 is -2 * sum_{a in S, a > 0} a^(t-1-i).
+func inRangePolynomial(T, span uint64) (poly *bfv.Polynomial) {
+
+	bredParams := ring.BRedParams(T)
+
+	sums := make([]uint64, T-1)
+	for a := span; a > 0 && a <= span; a -= 2 {
+		addEvenPowers(a%T, T, bredParams, sums)
+	}
+
+	coeffs := make([]uint64, T-2)
+
+	if span&1 == 0 {
+		coeffs[0] = 1
+	}
+
+	for i := 2; i < len(coeffs); i += 2 {
+		coeffs[i] = (T - ring.BRed(2, sums[int(T)-1-i], T, bredParams)) % T
+	}
+
+	return bfv.NewPoly(coeffs)
+}
+
+// addOddPowers adds a^k mod T on sums[k] for all odd k < len(sums).
+func addOddPowers(a, T uint64, bredParams []uint64, sums []uint64) {
+	a2 := ring.BRed(a, a, T, bredParams)
+	pow := a
+	for k := 1; k < len(sums); k += 2 {
+		sums[k] = ring.CRed(sums[k]+pow, T)
+		pow = ring.BRed(pow, a2, T, bredParams)
+	}
+}
+
+// addEvenPowers adds a^k mod T on sums[k] for all even 0 < k < len(sums).
+func addEvenPowers(a, T uint64, bredParams []uint64, sums []uint64) {
+	a2 := ring.BRed(a, a, T, bredParams)
+	pow := a2
+	for k := 2; k < len(sums); k += 2 {
+		sums[k] = ring.CRed(sums[k]+pow, T)
+		pow = ring.BRed(pow, a2, T, bredParams)
+	}
+}
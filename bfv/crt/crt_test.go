@@ -0,0 +1,145 @@
+package crt
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams are insecure parameters for fast testing only.
+var testParams = ParametersLiteral{
+	LogN: 12,
+	LogQ: []int{56, 56, 56},
+	LogP: []int{61},
+}
+
+func testString(opname string, params Parameters) string {
+	return fmt.Sprintf("%s/LogN=%d/logQP=%d/LogT=%d/Moduli=%d", opname, params.LogN(), params.LogQP(), params.LogT(), len(params.PlaintextModuli()))
+}
+
+func randomValues(t *testing.T, n int, bound *big.Int) (values []*big.Int) {
+	values = make([]*big.Int, n)
+	for i := range values {
+		var err error
+		values[i], err = rand.Int(rand.Reader, bound)
+		require.NoError(t, err)
+	}
+	return
+}
+
+func TestCRT(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping CRT tests for GOARCH=wasm")
+	}
+
+	pl := testParams
+	pl.T = ring.GenerateNTTPrimes(20, 2<<pl.LogN, 3)
+
+	params, err := NewParametersFromLiteral(pl)
+	require.NoError(t, err)
+
+	T := params.T()
+
+	kgen := NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPair()
+	rlk := kgen.GenRelinearizationKey(sk, 1)
+	rtks := kgen.GenRotationKeysForRotations([]int{1, 5}, true, sk)
+
+	encoder := NewEncoder(params)
+	encryptor := NewEncryptor(params, pk)
+	decryptor := NewDecryptor(params, sk)
+	eval := NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk, Rtks: rtks})
+
+	N := params.N()
+
+	values0 := randomValues(t, N, T)
+	values1 := randomValues(t, N, T)
+	scalar := randomValues(t, 1, T)[0]
+
+	ct0 := encryptor.EncryptNew(encoder.EncodeNew(values0))
+	ct1 := encryptor.EncryptNew(encoder.EncodeNew(values1))
+
+	verify := func(t *testing.T, want []*big.Int, ct *Ciphertext) {
+		have := encoder.DecodeNew(decryptor.DecryptNew(ct))
+		for i := range want {
+			require.Zero(t, want[i].Cmp(have[i]), "slot %d", i)
+		}
+	}
+
+	apply := func(f func(x, y *big.Int) *big.Int) (want []*big.Int) {
+		want = make([]*big.Int, N)
+		for i := range want {
+			want[i] = f(values0[i], values1[i])
+			want[i].Mod(want[i], T)
+		}
+		return
+	}
+
+	t.Run(testString("Encoder/Signed", params), func(t *testing.T) {
+		halfT := new(big.Int).Rsh(T, 1)
+		values := []*big.Int{big.NewInt(-1), new(big.Int).Neg(halfT), halfT, big.NewInt(0)}
+		have := encoder.DecodeIntNew(encoder.EncodeNew(values))
+		for i := range values {
+			require.Zero(t, values[i].Cmp(have[i]))
+		}
+	})
+
+	t.Run(testString("Evaluator/Add", params), func(t *testing.T) {
+		verify(t, apply(func(x, y *big.Int) *big.Int { return new(big.Int).Add(x, y) }), eval.AddNew(ct0, ct1))
+	})
+
+	t.Run(testString("Evaluator/Sub/Plaintext", params), func(t *testing.T) {
+		verify(t, apply(func(x, y *big.Int) *big.Int { return new(big.Int).Sub(x, y) }), eval.SubNew(ct0, encoder.EncodeNew(values1)))
+	})
+
+	t.Run(testString("Evaluator/Neg", params), func(t *testing.T) {
+		verify(t, apply(func(x, y *big.Int) *big.Int { return new(big.Int).Neg(x) }), eval.NegNew(ct0))
+	})
+
+	t.Run(testString("Evaluator/Scalar", params), func(t *testing.T) {
+		ct := eval.MulScalarNew(ct0, scalar)
+		eval.AddScalar(ct, scalar, ct)
+		verify(t, apply(func(x, y *big.Int) *big.Int { return new(big.Int).Add(new(big.Int).Mul(x, scalar), scalar) }), ct)
+	})
+
+	t.Run(testString("Evaluator/Mul/Relinearize", params), func(t *testing.T) {
+		ct := eval.RelinearizeNew(eval.MulNew(ct0, ct1))
+		require.Equal(t, 1, ct.Degree())
+		verify(t, apply(func(x, y *big.Int) *big.Int { return new(big.Int).Mul(x, y) }), ct)
+
+		eval.MulAndAdd(ct0, encoder.EncodeNew(values1), ct)
+		verify(t, apply(func(x, y *big.Int) *big.Int { return new(big.Int).Mul(new(big.Int).Mul(x, y), big.NewInt(2)) }), ct)
+	})
+
+	t.Run(testString("Evaluator/Rotate", params), func(t *testing.T) {
+
+		ct := eval.RotateColumnsNew(ct0, 5)
+		eval.RotateRows(ct, ct)
+
+		want := make([]*big.Int, N)
+		for i := range want {
+			// Slot i of the row r receives the slot (i+5) mod N/2 of the row 1-r
+			row, col := i/(N>>1), i%(N>>1)
+			want[i] = values0[(1-row)*(N>>1)+(col+5)%(N>>1)]
+		}
+		verify(t, want, ct)
+	})
+
+	t.Run(testString("Errors", params), func(t *testing.T) {
+		pl := testParams
+		pl.T = []uint64{65537, 65537}
+		_, err := NewParametersFromLiteral(pl)
+		require.Error(t, err)
+
+		pl.T = nil
+		_, err = NewParametersFromLiteral(pl)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,65 @@
+package crt
+
+import (
+	"github.com/cipherflow-fhe/lattigo/bfv"
+)
+
+// Operand is a common interface for Ciphertext and Plaintext.
+type Operand interface {
+	residue(i int) bfv.Operand
+}
+
+// Plaintext is a plaintext modulo T, stored as one BFV plaintext per plaintext modulus t_i.
+type Plaintext struct {
+	Value []*bfv.Plaintext
+}
+
+// NewPlaintext creates a new Plaintext at the maximum level.
+func NewPlaintext(params Parameters) *Plaintext {
+	pt := &Plaintext{Value: make([]*bfv.Plaintext, len(params.bfvParams))}
+	for i := range pt.Value {
+		pt.Value[i] = bfv.NewPlaintext(params.bfvParams[i])
+	}
+	return pt
+}
+
+func (pt *Plaintext) residue(i int) bfv.Operand {
+	return pt.Value[i]
+}
+
+// Ciphertext is a ciphertext modulo T, stored as one BFV ciphertext per plaintext modulus t_i.
+type Ciphertext struct {
+	Value []*bfv.Ciphertext
+}
+
+// NewCiphertext creates a new Ciphertext of the given degree at the maximum level.
+func NewCiphertext(params Parameters, degree int) *Ciphertext {
+	ct := &Ciphertext{Value: make([]*bfv.Ciphertext, len(params.bfvParams))}
+	for i := range ct.Value {
+		ct.Value[i] = bfv.NewCiphertext(params.bfvParams[i], degree)
+	}
+	return ct
+}
+
+// Degree returns the degree of the Ciphertext.
+func (ct *Ciphertext) Degree() int {
+	return ct.Value[0].Degree()
+}
+
+// Level returns the level of the Ciphertext.
+func (ct *Ciphertext) Level() int {
+	return ct.Value[0].Level()
+}
+
+// CopyNew creates a deep copy of the Ciphertext.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	ctCopy := &Ciphertext{Value: make([]*bfv.Ciphertext, len(ct.Value))}
+	for i := range ct.Value {
+		ctCopy.Value[i] = ct.Value[i].CopyNew()
+	}
+	return ctCopy
+}
+
+func (ct *Ciphertext) residue(i int) bfv.Operand {
+	return ct.Value[i]
+}
@@ -0,0 +1,120 @@
+package crt
+
+import (
+	"math/big"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+)
+
+// Encoder is a struct to encode integers modulo T on the slots of a Plaintext and to decode them.
+// The encoding of the slots of each residue is the one of bfv.Encoder, which requires all the plaintext
+// moduli to be primes congruent to 1 modulo 2N.
+type Encoder struct {
+	params   Parameters
+	encoders []bfv.Encoder
+	values   []uint64
+}
+
+// NewEncoder creates a new Encoder.
+func NewEncoder(params Parameters) *Encoder {
+	encoders := make([]bfv.Encoder, len(params.bfvParams))
+	for i := range encoders {
+		encoders[i] = bfv.NewEncoder(params.bfvParams[i])
+	}
+	return &Encoder{params: params, encoders: encoders, values: make([]uint64, params.N())}
+}
+
+// ShallowCopy creates a shallow copy of the Encoder in which the read-only data-structures are shared with the
+// receiver and the temporary buffers are reallocated.
+func (ecd *Encoder) ShallowCopy() *Encoder {
+	encoders := make([]bfv.Encoder, len(ecd.encoders))
+	for i := range encoders {
+		encoders[i] = ecd.encoders[i].ShallowCopy()
+	}
+	return &Encoder{params: ecd.params, encoders: encoders, values: make([]uint64, len(ecd.values))}
+}
+
+// Encode encodes at most N integers, which are reduced modulo T, on the slots of pt.
+// Negative integers are encoded as their representative in [0, T).
+func (ecd *Encoder) Encode(values []*big.Int, pt *Plaintext) {
+
+	tmp := new(big.Int)
+
+	for i, params := range ecd.params.bfvParams {
+
+		ti := new(big.Int).SetUint64(params.T())
+
+		for j := range ecd.values {
+			ecd.values[j] = 0
+		}
+
+		for j := range values {
+			ecd.values[j] = tmp.Mod(values[j], ti).Uint64()
+		}
+
+		ecd.encoders[i].Encode(ecd.values, pt.Value[i])
+	}
+}
+
+// EncodeNew encodes at most N integers, which are reduced modulo T, on the slots of a new Plaintext.
+func (ecd *Encoder) EncodeNew(values []*big.Int) (pt *Plaintext) {
+	pt = NewPlaintext(ecd.params)
+	ecd.Encode(values, pt)
+	return
+}
+
+// Decode decodes the N slots of pt on values, as integers in [0, T).
+func (ecd *Encoder) Decode(pt *Plaintext, values []*big.Int) {
+	ecd.decode(pt, values, false)
+}
+
+// DecodeNew decodes the N slots of pt on a new slice, as integers in [0, T).
+func (ecd *Encoder) DecodeNew(pt *Plaintext) (values []*big.Int) {
+	values = make([]*big.Int, ecd.params.N())
+	ecd.Decode(pt, values)
+	return
+}
+
+// DecodeInt decodes the N slots of pt on values, as signed integers in (-T/2, T/2].
+func (ecd *Encoder) DecodeInt(pt *Plaintext, values []*big.Int) {
+	ecd.decode(pt, values, true)
+}
+
+// DecodeIntNew decodes the N slots of pt on a new slice, as signed integers in (-T/2, T/2].
+func (ecd *Encoder) DecodeIntNew(pt *Plaintext) (values []*big.Int) {
+	values = make([]*big.Int, ecd.params.N())
+	ecd.DecodeInt(pt, values)
+	return
+}
+
+// decode recombines the residues of the slots as sum_i residue_i * (T/t_i) * ((T/t_i)^-1 mod t_i) mod T.
+func (ecd *Encoder) decode(pt *Plaintext, values []*big.Int, signed bool) {
+
+	T := ecd.params.t
+	halfT := new(big.Int).Rsh(T, 1)
+
+	for j := range values {
+		if values[j] == nil {
+			values[j] = new(big.Int)
+		}
+		values[j].SetUint64(0)
+	}
+
+	tmp := new(big.Int)
+
+	for i := range ecd.params.bfvParams {
+
+		ecd.encoders[i].DecodeUint(pt.Value[i], ecd.values)
+
+		for j := range values {
+			values[j].Add(values[j], tmp.Mul(ecd.params.crt[i], tmp.SetUint64(ecd.values[j])))
+		}
+	}
+
+	for j := range values {
+		values[j].Mod(values[j], T)
+		if signed && values[j].Cmp(halfT) > 0 {
+			values[j].Sub(values[j], T)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package crt
+
+import (
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// NewKeyGenerator creates a rlwe.KeyGenerator from the parameters. The keys are shared by all the plaintext moduli.
+func NewKeyGenerator(params Parameters) rlwe.KeyGenerator {
+	return rlwe.NewKeyGenerator(params.Parameters)
+}
+
+// Encryptor is a struct to encrypt Plaintexts, residue by residue.
+type Encryptor struct {
+	encryptors []bfv.Encryptor
+}
+
+// NewEncryptor creates a new Encryptor from the key, which can be a *rlwe.SecretKey or a *rlwe.PublicKey.
+func NewEncryptor(params Parameters, key interface{}) *Encryptor {
+	encryptors := make([]bfv.Encryptor, len(params.bfvParams))
+	for i := range encryptors {
+		encryptors[i] = bfv.NewEncryptor(params.bfvParams[i], key)
+	}
+	return &Encryptor{encryptors: encryptors}
+}
+
+// Encrypt encrypts pt on ctOut.
+func (enc *Encryptor) Encrypt(pt *Plaintext, ctOut *Ciphertext) {
+	for i := range enc.encryptors {
+		enc.encryptors[i].Encrypt(pt.Value[i], ctOut.Value[i])
+	}
+}
+
+// EncryptNew encrypts pt on a new Ciphertext.
+func (enc *Encryptor) EncryptNew(pt *Plaintext) (ctOut *Ciphertext) {
+	ctOut = &Ciphertext{Value: make([]*bfv.Ciphertext, len(enc.encryptors))}
+	for i := range enc.encryptors {
+		ctOut.Value[i] = enc.encryptors[i].EncryptNew(pt.Value[i])
+	}
+	return
+}
+
+// ShallowCopy creates a shallow copy of the Encryptor in which the read-only data-structures are shared with the
+// receiver and the temporary buffers are reallocated.
+func (enc *Encryptor) ShallowCopy() *Encryptor {
+	encryptors := make([]bfv.Encryptor, len(enc.encryptors))
+	for i := range encryptors {
+		encryptors[i] = enc.encryptors[i].ShallowCopy()
+	}
+	return &Encryptor{encryptors: encryptors}
+}
+
+// Decryptor is a struct to decrypt Ciphertexts, residue by residue.
+type Decryptor struct {
+	decryptors []bfv.Decryptor
+}
+
+// NewDecryptor creates a new Decryptor from the secret key.
+func NewDecryptor(params Parameters, sk *rlwe.SecretKey) *Decryptor {
+	decryptors := make([]bfv.Decryptor, len(params.bfvParams))
+	for i := range decryptors {
+		decryptors[i] = bfv.NewDecryptor(params.bfvParams[i], sk)
+	}
+	return &Decryptor{decryptors: decryptors}
+}
+
+// Decrypt decrypts ct on ptOut.
+func (dec *Decryptor) Decrypt(ct *Ciphertext, ptOut *Plaintext) {
+	for i := range dec.decryptors {
+		dec.decryptors[i].Decrypt(ct.Value[i], ptOut.Value[i])
+	}
+}
+
+// DecryptNew decrypts ct on a new Plaintext.
+func (dec *Decryptor) DecryptNew(ct *Ciphertext) (ptOut *Plaintext) {
+	ptOut = &Plaintext{Value: make([]*bfv.Plaintext, len(dec.decryptors))}
+	for i := range dec.decryptors {
+		ptOut.Value[i] = dec.decryptors[i].DecryptNew(ct.Value[i])
+	}
+	return
+}
+
+// ShallowCopy creates a shallow copy of the Decryptor in which the read-only data-structures are shared with the
+// receiver and the temporary buffers are reallocated.
+func (dec *Decryptor) ShallowCopy() *Decryptor {
+	decryptors := make([]bfv.Decryptor, len(dec.decryptors))
+	for i := range decryptors {
+		decryptors[i] = dec.decryptors[i].ShallowCopy()
+	}
+	return &Decryptor{decryptors: decryptors}
+}
@@ -0,0 +1,217 @@
+package crt
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// Evaluator is a struct to evaluate operations modulo T on Ciphertexts, with one bfv.Evaluator per plaintext
+// modulus. Each operation is evaluated on all the residues in parallel. An Evaluator is not safe for concurrent use,
+// see ShallowCopy.
+type Evaluator struct {
+	params     Parameters
+	evaluators []bfv.Evaluator
+}
+
+// NewEvaluator creates a new Evaluator from the evaluation key, which is shared by all the plaintext moduli.
+func NewEvaluator(params Parameters, evk rlwe.EvaluationKey) *Evaluator {
+	evaluators := make([]bfv.Evaluator, len(params.bfvParams))
+	for i := range evaluators {
+		evaluators[i] = bfv.NewEvaluator(params.bfvParams[i], evk)
+	}
+	return &Evaluator{params: params, evaluators: evaluators}
+}
+
+// ShallowCopy creates a shallow copy of the Evaluator in which the read-only data-structures are shared with the
+// receiver and the temporary buffers are reallocated. The receiver and the returned Evaluator can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	evaluators := make([]bfv.Evaluator, len(eval.evaluators))
+	for i := range evaluators {
+		evaluators[i] = eval.evaluators[i].ShallowCopy()
+	}
+	return &Evaluator{params: eval.params, evaluators: evaluators}
+}
+
+// WithKey creates a shallow copy of the Evaluator with a new evaluation key.
+func (eval *Evaluator) WithKey(evk rlwe.EvaluationKey) *Evaluator {
+	evaluators := make([]bfv.Evaluator, len(eval.evaluators))
+	for i := range evaluators {
+		evaluators[i] = eval.evaluators[i].WithKey(evk)
+	}
+	return &Evaluator{params: eval.params, evaluators: evaluators}
+}
+
+// run evaluates f on each residue, in parallel.
+func (eval *Evaluator) run(f func(i int, eval bfv.Evaluator)) {
+	var wg sync.WaitGroup
+	wg.Add(len(eval.evaluators))
+	for i := range eval.evaluators {
+		go func(i int) {
+			defer wg.Done()
+			f(i, eval.evaluators[i])
+		}(i)
+	}
+	wg.Wait()
+}
+
+// newCiphertext returns a new Ciphertext of the given degree and level.
+func (eval *Evaluator) newCiphertext(degree, level int) *Ciphertext {
+	ct := &Ciphertext{Value: make([]*bfv.Ciphertext, len(eval.evaluators))}
+	for i := range ct.Value {
+		ct.Value[i] = bfv.NewCiphertextLvl(eval.params.bfvParams[i], degree, level)
+	}
+	return ct
+}
+
+// residues returns the residues of the scalar modulo each plaintext modulus.
+func (eval *Evaluator) residues(scalar *big.Int) (r []uint64) {
+	r = make([]uint64, len(eval.evaluators))
+	tmp := new(big.Int)
+	for i, params := range eval.params.bfvParams {
+		r[i] = tmp.Mod(scalar, new(big.Int).SetUint64(params.T())).Uint64()
+	}
+	return
+}
+
+// Add adds ctIn to op1 and returns the result on ctOut.
+func (eval *Evaluator) Add(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.Add(ctIn.Value[i], op1.residue(i), ctOut.Value[i])
+	})
+}
+
+// AddNew adds ctIn to op1 and returns the result on a new Ciphertext.
+func (eval *Evaluator) AddNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = eval.newCiphertext(utils.MaxInt(ctIn.Degree(), op1.residue(0).Degree()), ctIn.Level())
+	eval.Add(ctIn, op1, ctOut)
+	return
+}
+
+// Sub subtracts op1 from ctIn and returns the result on ctOut.
+func (eval *Evaluator) Sub(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.Sub(ctIn.Value[i], op1.residue(i), ctOut.Value[i])
+	})
+}
+
+// SubNew subtracts op1 from ctIn and returns the result on a new Ciphertext.
+func (eval *Evaluator) SubNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = eval.newCiphertext(utils.MaxInt(ctIn.Degree(), op1.residue(0).Degree()), ctIn.Level())
+	eval.Sub(ctIn, op1, ctOut)
+	return
+}
+
+// Neg negates ctIn and returns the result on ctOut.
+func (eval *Evaluator) Neg(ctIn *Ciphertext, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.Neg(ctIn.Value[i], ctOut.Value[i])
+	})
+}
+
+// NegNew negates ctIn and returns the result on a new Ciphertext.
+func (eval *Evaluator) NegNew(ctIn *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = eval.newCiphertext(ctIn.Degree(), ctIn.Level())
+	eval.Neg(ctIn, ctOut)
+	return
+}
+
+// AddScalar adds the scalar, reduced modulo T, to ctIn and returns the result on ctOut.
+func (eval *Evaluator) AddScalar(ctIn *Ciphertext, scalar *big.Int, ctOut *Ciphertext) {
+	r := eval.residues(scalar)
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.AddScalar(ctIn.Value[i], r[i], ctOut.Value[i])
+	})
+}
+
+// MulScalar multiplies ctIn by the scalar, reduced modulo T, and returns the result on ctOut.
+func (eval *Evaluator) MulScalar(ctIn *Ciphertext, scalar *big.Int, ctOut *Ciphertext) {
+	r := eval.residues(scalar)
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.MulScalar(ctIn.Value[i], r[i], ctOut.Value[i])
+	})
+}
+
+// MulScalarNew multiplies ctIn by the scalar, reduced modulo T, and returns the result on a new Ciphertext.
+func (eval *Evaluator) MulScalarNew(ctIn *Ciphertext, scalar *big.Int) (ctOut *Ciphertext) {
+	ctOut = eval.newCiphertext(ctIn.Degree(), ctIn.Level())
+	eval.MulScalar(ctIn, scalar, ctOut)
+	return
+}
+
+// Mul multiplies ctIn by op1 and returns the result on ctOut, whose degree must be at least the sum of their degrees.
+func (eval *Evaluator) Mul(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.Mul(ctIn.Value[i], op1.residue(i), ctOut.Value[i])
+	})
+}
+
+// MulNew multiplies ctIn by op1 and returns the result on a new Ciphertext.
+func (eval *Evaluator) MulNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = eval.newCiphertext(ctIn.Degree()+op1.residue(0).Degree(), ctIn.Level())
+	eval.Mul(ctIn, op1, ctOut)
+	return
+}
+
+// MulAndAdd multiplies ctIn by op1 and adds the result on ctOut.
+func (eval *Evaluator) MulAndAdd(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.MulAndAdd(ctIn.Value[i], op1.residue(i), ctOut.Value[i])
+	})
+}
+
+// Relinearize relinearizes ctIn and returns the result on ctOut. It requires the relinearization key.
+func (eval *Evaluator) Relinearize(ctIn *Ciphertext, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.Relinearize(ctIn.Value[i], ctOut.Value[i])
+	})
+}
+
+// RelinearizeNew relinearizes ctIn and returns the result on a new Ciphertext. It requires the relinearization key.
+func (eval *Evaluator) RelinearizeNew(ctIn *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = eval.newCiphertext(1, ctIn.Level())
+	eval.Relinearize(ctIn, ctOut)
+	return
+}
+
+// RotateColumns rotates the columns of ctIn by k positions to the left and returns the result on ctOut.
+// It requires the rotation key of the column rotation by k.
+func (eval *Evaluator) RotateColumns(ctIn *Ciphertext, k int, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.RotateColumns(ctIn.Value[i], k, ctOut.Value[i])
+	})
+}
+
+// RotateColumnsNew rotates the columns of ctIn by k positions to the left and returns the result on a new Ciphertext.
+// It requires the rotation key of the column rotation by k.
+func (eval *Evaluator) RotateColumnsNew(ctIn *Ciphertext, k int) (ctOut *Ciphertext) {
+	ctOut = eval.newCiphertext(ctIn.Degree(), ctIn.Level())
+	eval.RotateColumns(ctIn, k, ctOut)
+	return
+}
+
+// RotateRows swaps the rows of ctIn and returns the result on ctOut. It requires the rotation key of the row rotation.
+func (eval *Evaluator) RotateRows(ctIn *Ciphertext, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.RotateRows(ctIn.Value[i], ctOut.Value[i])
+	})
+}
+
+// RotateRowsNew swaps the rows of ctIn and returns the result on a new Ciphertext. It requires the rotation key of
+// the row rotation.
+func (eval *Evaluator) RotateRowsNew(ctIn *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = eval.newCiphertext(ctIn.Degree(), ctIn.Level())
+	eval.RotateRows(ctIn, ctOut)
+	return
+}
+
+// InnerSum sums the slots of ctIn and returns the result, replicated in all the slots, on ctOut.
+// It requires the rotation keys of the column rotations by powers of two and of the row rotation.
+func (eval *Evaluator) InnerSum(ctIn *Ciphertext, ctOut *Ciphertext) {
+	eval.run(func(i int, eval bfv.Evaluator) {
+		eval.InnerSum(ctIn.Value[i], ctOut.Value[i])
+	})
+}
@@ -0,0 +1,152 @@
+// Package crt implements BFV over a composite plaintext modulus T = t_0 * t_1 * ... * t_{k-1}, given as the product
+// of pairwise coprime moduli, to compute exactly over integers larger than a single plaintext modulus.
+//
+// By the Chinese remainder theorem, Z_T is isomorphic to Z_{t_0} x ... x Z_{t_{k-1}}: an integer modulo T is encoded
+// as its residues modulo each t_i, each residue being encoded and encrypted with the BFV parameters of plaintext modulus
+// t_i, and the residues are recombined into an integer modulo T at decoding. All the BFV parameters share the ring
+// degree and the moduli Q and P, so that the residues are encrypted under the same secret key and evaluated with the
+// same relinearization and rotation keys: a Ciphertext is a vector of k BFV ciphertexts, on which the Evaluator applies
+// each operation residue by residue, in parallel.
+package crt
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// ParametersLiteral is a literal representation of CRT-composed BFV parameters. It has the fields of
+// bfv.ParametersLiteral, except that the plaintext modulus is replaced by a list of pairwise coprime
+// plaintext moduli.
+type ParametersLiteral struct {
+	LogN     int
+	Q        []uint64
+	P        []uint64
+	LogQ     []int `json:",omitempty"`
+	LogP     []int `json:",omitempty"`
+	Pow2Base int
+	Sigma    float64
+	H        int
+	T        []uint64 // Pairwise coprime plaintext moduli
+}
+
+// RLWEParameters returns the rlwe.ParametersLiteral from the target crt.ParametersLiteral.
+func (p ParametersLiteral) RLWEParameters() rlwe.ParametersLiteral {
+	return rlwe.ParametersLiteral{
+		LogN:     p.LogN,
+		Q:        p.Q,
+		P:        p.P,
+		LogQ:     p.LogQ,
+		LogP:     p.LogP,
+		Pow2Base: p.Pow2Base,
+		Sigma:    p.Sigma,
+		H:        p.H,
+		RingType: ring.Standard,
+	}
+}
+
+// Parameters represents a set of BFV parameters sharing the same rlwe.Parameters, one per plaintext modulus t_i.
+// Its fields are private and immutable.
+type Parameters struct {
+	rlwe.Parameters
+	bfvParams []bfv.Parameters
+	t         *big.Int
+	crt       []*big.Int // (T/t_i) * ((T/t_i)^-1 mod t_i)
+}
+
+// NewParameters instantiates CRT-composed BFV parameters from the generic RLWE parameters and the plaintext moduli T,
+// which must be pairwise coprime. It returns the empty parameters Parameters{} and a non-nil error if the specified
+// parameters are invalid.
+func NewParameters(rlweParams rlwe.Parameters, T []uint64) (p Parameters, err error) {
+
+	if len(T) == 0 {
+		return Parameters{}, fmt.Errorf("cannot NewParameters: at least one plaintext modulus is required")
+	}
+
+	p = Parameters{Parameters: rlweParams, bfvParams: make([]bfv.Parameters, len(T)), t: big.NewInt(1), crt: make([]*big.Int, len(T))}
+
+	for i := range T {
+
+		for j := 0; j < i; j++ {
+			if gcd(T[i], T[j]) != 1 {
+				return Parameters{}, fmt.Errorf("cannot NewParameters: the plaintext moduli %d and %d are not coprime", T[j], T[i])
+			}
+		}
+
+		if p.bfvParams[i], err = bfv.NewParameters(rlweParams, T[i]); err != nil {
+			return Parameters{}, fmt.Errorf("cannot NewParameters: %w", err)
+		}
+
+		p.t.Mul(p.t, new(big.Int).SetUint64(T[i]))
+	}
+
+	for i := range T {
+		ti := new(big.Int).SetUint64(T[i])
+		p.crt[i] = new(big.Int).Quo(p.t, ti)
+		p.crt[i].Mul(p.crt[i], new(big.Int).ModInverse(p.crt[i], ti))
+	}
+
+	return
+}
+
+// NewParametersFromLiteral instantiates CRT-composed BFV parameters from a ParametersLiteral specification.
+// It returns the empty parameters Parameters{} and a non-nil error if the specified parameters are invalid.
+//
+// See `rlwe.NewParametersFromLiteral` for default values of the optional fields.
+func NewParametersFromLiteral(pl ParametersLiteral) (Parameters, error) {
+	rlweParams, err := rlwe.NewParametersFromLiteral(pl.RLWEParameters())
+	if err != nil {
+		return Parameters{}, err
+	}
+	return NewParameters(rlweParams, pl.T)
+}
+
+// T returns the composite plaintext modulus, the product of the plaintext moduli.
+func (p Parameters) T() *big.Int {
+	return new(big.Int).Set(p.t)
+}
+
+// LogT returns the number of bits of the composite plaintext modulus.
+func (p Parameters) LogT() int {
+	return p.t.BitLen()
+}
+
+// PlaintextModuli returns the plaintext moduli.
+func (p Parameters) PlaintextModuli() (T []uint64) {
+	T = make([]uint64, len(p.bfvParams))
+	for i := range p.bfvParams {
+		T[i] = p.bfvParams[i].T()
+	}
+	return
+}
+
+// BFVParameters returns the BFV parameters of each plaintext modulus.
+func (p Parameters) BFVParameters() []bfv.Parameters {
+	return append([]bfv.Parameters{}, p.bfvParams...)
+}
+
+// Equals compares two sets of parameters for equality.
+func (p Parameters) Equals(other Parameters) bool {
+
+	if !p.Parameters.Equals(other.Parameters) || len(p.bfvParams) != len(other.bfvParams) {
+		return false
+	}
+
+	for i := range p.bfvParams {
+		if p.bfvParams[i].T() != other.bfvParams[i].T() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,293 @@
+package bfv
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// IntegerEncoder encodes a signed integer of arbitrary size on the coefficients of a plaintext, as the integer
+// encoder of the FV paper (https://eprint.iacr.org/2012/144): the integer a = sign * sum_i a_i * B^i, with the digits
+// a_i in [0, B), is encoded as the polynomial sign * sum_i a_i * X^i, so that the additions and multiplications of
+// the plaintexts are the ones of the integers. The decoding evaluates the polynomial at X = B with its coefficients
+// centered modulo t, and therefore propagates the carries of the digits that grew beyond B. It is exact as long as the
+// coefficients stay in (-t/2, t/2] and the degree of the polynomial stays smaller than N.
+type IntegerEncoder struct {
+	digitEncoder
+}
+
+// digitEncoder stores the fields common to the encoders of integers as digits in a base B.
+type digitEncoder struct {
+	params  Parameters
+	encoder Encoder
+	base    *big.Int
+	values  []uint64
+}
+
+// newDigitEncoder creates a new digitEncoder in the base B.
+func newDigitEncoder(params Parameters, base uint64) digitEncoder {
+	return digitEncoder{params: params, encoder: NewEncoder(params), base: new(big.Int).SetUint64(base), values: make([]uint64, params.N())}
+}
+
+// NewIntegerEncoder creates a new IntegerEncoder in the base B, which must be in [2, t).
+func NewIntegerEncoder(params Parameters, base uint64) *IntegerEncoder {
+
+	if base < 2 || base >= params.T() {
+		panic(fmt.Errorf("cannot NewIntegerEncoder: the base must be in [2, t)"))
+	}
+
+	return &IntegerEncoder{newDigitEncoder(params, base)}
+}
+
+// Encode encodes the integer on pt. It returns an error if the integer has more than N digits in the base B.
+func (ecd *IntegerEncoder) Encode(value *big.Int, pt *Plaintext) (err error) {
+
+	for i := range ecd.values {
+		ecd.values[i] = 0
+	}
+
+	if err = encodeDigits(value, ecd.base, ecd.params.T(), ecd.values); err != nil {
+		return fmt.Errorf("cannot Encode: %w", err)
+	}
+
+	ecd.encoder.EncodeCoeffs(ecd.values, pt)
+
+	return
+}
+
+// EncodeNew encodes the integer on a new plaintext at the given level.
+func (ecd *IntegerEncoder) EncodeNew(value *big.Int, level int) (pt *Plaintext, err error) {
+	pt = NewPlaintextLvl(ecd.params, level)
+	return pt, ecd.Encode(value, pt)
+}
+
+// Decode decodes any plaintext type, evaluating its polynomial at X = B.
+// It panics if pt is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *IntegerEncoder) Decode(pt interface{}) (value *big.Int) {
+	ecd.encoder.DecodeCoeffsUint(pt, ecd.values)
+	return evaluateDigits(ecd.values, ecd.base, ecd.params.T())
+}
+
+// FractionalEncoder encodes a signed rational number on the coefficients of a plaintext, as the fractional encoder of
+// the FV paper (https://eprint.iacr.org/2012/144): the integer part is encoded as with the IntegerEncoder on the
+// coefficients of degree [0, N/2) and the fractional part sign * sum_{j>0} f_j * B^-j, with the digits f_j in [0, B),
+// is encoded on the coefficients of degree [N/2, N) as -sign * sum_{j>0} f_j * X^(N-j), since X^-j = -X^(N-j) modulo
+// X^N + 1. The additions and multiplications of the plaintexts are the ones of the numbers, as long as the coefficients
+// stay in (-t/2, t/2] and the digits of the integer and fractional parts do not overlap. A number of FractionalDigits
+// digits in the base B, such as a money amount in cents with B = 10 and two fractional digits, is encoded exactly.
+type FractionalEncoder struct {
+	digitEncoder
+	FractionalDigits int
+}
+
+// NewFractionalEncoder creates a new FractionalEncoder in the base B, which must be in [2, t), that truncates the
+// fractional part of the numbers to fractionalDigits digits, with 0 <= fractionalDigits <= N/2.
+func NewFractionalEncoder(params Parameters, base uint64, fractionalDigits int) *FractionalEncoder {
+
+	if base < 2 || base >= params.T() {
+		panic(fmt.Errorf("cannot NewFractionalEncoder: the base must be in [2, t)"))
+	}
+
+	if fractionalDigits < 0 || fractionalDigits > params.N()>>1 {
+		panic(fmt.Errorf("cannot NewFractionalEncoder: the number of fractional digits must be in [0, N/2]"))
+	}
+
+	return &FractionalEncoder{digitEncoder: newDigitEncoder(params, base), FractionalDigits: fractionalDigits}
+}
+
+// Encode encodes the number on pt, truncated to FractionalDigits fractional digits in the base B. It returns an error
+// if its integer part has more than N/2 digits in the base B.
+func (ecd *FractionalEncoder) Encode(value *big.Rat, pt *Plaintext) (err error) {
+
+	N := ecd.params.N()
+	T := ecd.params.T()
+
+	for i := range ecd.values {
+		ecd.values[i] = 0
+	}
+
+	integer, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	if err = encodeDigits(integer, ecd.base, T, ecd.values[:N>>1]); err != nil {
+		return fmt.Errorf("cannot Encode: %w", err)
+	}
+
+	// |remainder| / denom = sum_{j>0} f_j * B^-j, whose digits are the successive quotients of B * remainder by denom
+	negative := remainder.Sign() < 0
+	remainder.Abs(remainder)
+
+	digit := new(big.Int)
+	for j := 1; j <= ecd.FractionalDigits && remainder.Sign() != 0; j++ {
+
+		digit.QuoRem(remainder.Mul(remainder, ecd.base), value.Denom(), remainder)
+
+		if f := digit.Uint64(); f != 0 && negative {
+			ecd.values[N-j] = f
+		} else if f != 0 {
+			ecd.values[N-j] = T - f
+		}
+	}
+
+	ecd.encoder.EncodeCoeffs(ecd.values, pt)
+
+	return
+}
+
+// EncodeNew encodes the number on a new plaintext at the given level.
+func (ecd *FractionalEncoder) EncodeNew(value *big.Rat, level int) (pt *Plaintext, err error) {
+	pt = NewPlaintextLvl(ecd.params, level)
+	return pt, ecd.Encode(value, pt)
+}
+
+// Decode decodes any plaintext type, evaluating its coefficients of degree [0, N/2) at X = B and its coefficients
+// of degree [N/2, N) at X = -B^-(N-i). It panics if pt is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *FractionalEncoder) Decode(pt interface{}) (value *big.Rat) {
+
+	N := ecd.params.N()
+	T := ecd.params.T()
+
+	ecd.encoder.DecodeCoeffsUint(pt, ecd.values)
+
+	value = new(big.Rat).SetInt(evaluateDigits(ecd.values[:N>>1], ecd.base, T))
+
+	// sum_{j=1}^{N/2} -c_{N-j} * B^-j = -(sum_{j=1}^{N/2} c_{N-j} * B^(N/2-j)) / B^(N/2)
+	fractional := evaluateDigits(ecd.values[N>>1:], ecd.base, T)
+	fractional.Neg(fractional)
+
+	return value.Add(value, new(big.Rat).SetFrac(fractional, new(big.Int).Exp(ecd.base, big.NewInt(int64(N>>1)), nil)))
+}
+
+// WideIntegerEncoder encodes signed integers wider than log2(t) bits on the slots of a plaintext, by decomposing each
+// integer into Digits() digits in the base B stored in Digits() consecutive slots, from the least significant digit. The
+// slot-wise additions, subtractions and multiplications by small scalars of the plaintexts are the ones of the
+// integers, the digits growing beyond B being propagated as carries at the decoding. The decoding is exact as long as
+// the slots stay in (-t/2, t/2], for example for up to (t-1)/(2(B-1)) additions of encoded integers.
+// Only these linear operations are supported: the slot-wise product of two plaintexts or ciphertexts multiplies the
+// digits independently and is not the encoding of the product of the integers.
+// It requires t to be a prime congruent to 1 modulo 2N.
+type WideIntegerEncoder struct {
+	digitEncoder
+	digits int
+	bound  *big.Int
+}
+
+// NewWideIntegerEncoder creates a new WideIntegerEncoder of integers of digits digits in the base B, which must be in
+// [2, t). It encodes N / digits integers per plaintext, of absolute value smaller than B^digits.
+func NewWideIntegerEncoder(params Parameters, base uint64, digits int) *WideIntegerEncoder {
+
+	if base < 2 || base >= params.T() {
+		panic(fmt.Errorf("cannot NewWideIntegerEncoder: the base must be in [2, t)"))
+	}
+
+	if digits < 1 || digits > params.N() {
+		panic(fmt.Errorf("cannot NewWideIntegerEncoder: the number of digits must be in [1, N]"))
+	}
+
+	ecd := newDigitEncoder(params, base)
+
+	return &WideIntegerEncoder{digitEncoder: ecd, digits: digits, bound: new(big.Int).Exp(ecd.base, big.NewInt(int64(digits)), nil)}
+}
+
+// Digits returns the number of digits, i.e. of slots, of an integer.
+func (ecd *WideIntegerEncoder) Digits() int {
+	return ecd.digits
+}
+
+// Slots returns the number of integers encoded per plaintext.
+func (ecd *WideIntegerEncoder) Slots() int {
+	return ecd.params.N() / ecd.digits
+}
+
+// Encode encodes at most Slots() integers on pt, the integer i on the slots [i * Digits(), (i+1) * Digits()).
+// It returns an error if an integer has an absolute value larger than or equal to B^Digits().
+func (ecd *WideIntegerEncoder) Encode(values []*big.Int, pt *Plaintext) (err error) {
+
+	if len(values) > ecd.Slots() {
+		return fmt.Errorf("cannot Encode: %d integers of %d digits do not fit in %d slots", len(values), ecd.digits, ecd.params.N())
+	}
+
+	for i := range ecd.values {
+		ecd.values[i] = 0
+	}
+
+	for i := range values {
+
+		if new(big.Int).Abs(values[i]).Cmp(ecd.bound) >= 0 {
+			return fmt.Errorf("cannot Encode: the integer %d has more than %d digits in the base %d", i, ecd.digits, ecd.base)
+		}
+
+		if err = encodeDigits(values[i], ecd.base, ecd.params.T(), ecd.values[i*ecd.digits:(i+1)*ecd.digits]); err != nil {
+			return fmt.Errorf("cannot Encode: %w", err)
+		}
+	}
+
+	ecd.encoder.Encode(ecd.values, pt)
+
+	return
+}
+
+// EncodeNew encodes at most Slots() integers on a new plaintext at the given level.
+func (ecd *WideIntegerEncoder) EncodeNew(values []*big.Int, level int) (pt *Plaintext, err error) {
+	pt = NewPlaintextLvl(ecd.params, level)
+	return pt, ecd.Encode(values, pt)
+}
+
+// Decode decodes the Slots() integers of any plaintext type, propagating the carries of their digits.
+// It panics if pt is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *WideIntegerEncoder) Decode(pt interface{}) (values []*big.Int) {
+
+	ecd.encoder.DecodeUint(pt, ecd.values)
+
+	values = make([]*big.Int, ecd.Slots())
+	for i := range values {
+		values[i] = evaluateDigits(ecd.values[i*ecd.digits:(i+1)*ecd.digits], ecd.base, ecd.params.T())
+	}
+
+	return
+}
+
+// encodeDigits writes the digits of |value| in the base B on coeffs, from the least significant digit, multiplied by
+// the sign of value modulo T. It returns an error if the value has more than len(coeffs) digits.
+func encodeDigits(value, base *big.Int, T uint64, coeffs []uint64) error {
+
+	negative := value.Sign() < 0
+
+	abs := new(big.Int).Abs(value)
+	digit := new(big.Int)
+
+	for i := 0; abs.Sign() != 0; i++ {
+
+		if i == len(coeffs) {
+			return fmt.Errorf("the integer has more than %d digits in the base %d", len(coeffs), base)
+		}
+
+		abs.QuoRem(abs, base, digit)
+
+		if d := digit.Uint64(); d != 0 && negative {
+			coeffs[i] = T - d
+		} else {
+			coeffs[i] = d
+		}
+	}
+
+	return nil
+}
+
+// evaluateDigits returns sum_i c_i * B^i, where the c_i are the coefficients centered modulo T.
+func evaluateDigits(coeffs []uint64, base *big.Int, T uint64) (value *big.Int) {
+
+	value = new(big.Int)
+	digit := new(big.Int)
+
+	for i := len(coeffs) - 1; i >= 0; i-- {
+
+		value.Mul(value, base)
+
+		if c := coeffs[i]; c > T>>1 {
+			value.Sub(value, digit.SetUint64(T-c))
+		} else {
+			value.Add(value, digit.SetUint64(c))
+		}
+	}
+
+	return
+}
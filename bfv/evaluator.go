@@ -54,6 +54,14 @@ type Evaluator interface {
 	RotateRows(ctIn *Ciphertext, ctOut *Ciphertext)
 	RotateRowsNew(ctIn *Ciphertext) (ctOut *Ciphertext)
 	InnerSum(ctIn *Ciphertext, ctOut *Ciphertext)
+	InnerSumLog(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext)
+	InnerSumBatch(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext)
+	ReplicateLog(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext)
+	Replicate(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext)
+	LinearTransformNew(ctIn *Ciphertext, linearTransform interface{}) (ctOut []*Ciphertext)
+	LinearTransform(ctIn *Ciphertext, linearTransform interface{}, ctOut []*Ciphertext)
+	MultiplyByDiagMatrix(ctIn *Ciphertext, matrix LinearTransform, BuffDecompQP []ringqp.Poly, ctOut *Ciphertext)
+	MultiplyByDiagMatrixBSGS(ctIn *Ciphertext, matrix LinearTransform, BuffDecompQP []ringqp.Poly, ctOut *Ciphertext)
 	ShallowCopy() Evaluator
 	WithKey(rlwe.EvaluationKey) Evaluator
 
@@ -119,9 +127,11 @@ func newEvaluatorPrecomp(params Parameters) *evaluatorBase {
 }
 
 type evaluatorBuffers struct {
-	buffQ    [][]*ring.Poly
-	buffQMul [][]*ring.Poly
-	buffPt   *Plaintext
+	buffQ      [][]*ring.Poly
+	buffQMul   [][]*ring.Poly
+	buffPt     *Plaintext
+	buffCt     *Ciphertext     // Memory buffer for the rotated ciphertexts of the linear transforms
+	buffRotNTT [][2]*ring.Poly // Memory buffer for the baby-steps of MultiplyByDiagMatrixBSGS, grown on demand
 }
 
 func newEvaluatorBuffer(eval *evaluatorBase) *evaluatorBuffers {
@@ -138,6 +148,7 @@ func newEvaluatorBuffer(eval *evaluatorBase) *evaluatorBuffers {
 	}
 
 	evb.buffPt = NewPlaintext(eval.params)
+	evb.buffCt = NewCiphertext(eval.params, 1)
 
 	return evb
 }
@@ -718,6 +729,129 @@ func (eval *evaluator) InnerSum(ctIn *Ciphertext, ctOut *Ciphertext) {
 	eval.Add(ctOut, cTmp, ctOut)
 }
 
+// InnerSumLog applies an optimized inner sum on the ciphertext (log2(n) + HW(n) rotations).
+// The operation assumes that each row of `ctIn` encrypts N/(2*`batch`) sub-vectors of size `batch` which it adds together (in parallel) by groups of `n`.
+// It outputs in ctOut a ciphertext for which the "leftmost" sub-vector of each group is equal to the sum of the group.
+// The rotations are cyclic within each row of N/2 slots.
+// This method is faster than InnerSumBatch when the number of rotations is large and uses log2(n) + HW(n) instead of 'n' keys.
+// Required rotation keys can be generated with 'RotationsForInnerSumLog(batch, n)'.
+func (eval *evaluator) InnerSumLog(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext) {
+
+	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot InnerSumLog: input and output must be of degree 1")
+	}
+
+	if n < 1 {
+		panic("cannot InnerSumLog: n must be at least 1")
+	}
+
+	level := ctIn.Level()
+
+	// tmp = sum_{0 <= l < 2^i} Rotate(ctIn, l * batch)
+	tmp := NewCiphertextLvl(eval.params, 1, level)
+	ctRot := NewCiphertextLvl(eval.params, 1, level)
+	acc := NewCiphertextLvl(eval.params, 1, level)
+
+	tmp.Copy(ctIn.El())
+
+	state := false
+	// Binary reading of the input n
+	for i, j := 0, n; j > 0; i, j = i+1, j>>1 {
+
+		// If the binary reading scans a 1, adds the sum of the sub-vectors [k, k + 2^i) of the group
+		if j&1 == 1 {
+
+			k := (n - (n & ((2 << i) - 1))) * batch
+
+			if k != 0 {
+				eval.RotateColumns(tmp, k, ctRot)
+			} else {
+				ctRot.Copy(tmp.El())
+			}
+
+			if !state {
+				acc.Copy(ctRot.El())
+				state = true
+			} else {
+				eval.Add(acc, ctRot, acc)
+			}
+		}
+
+		if j > 1 {
+			eval.RotateColumns(tmp, (1<<i)*batch, ctRot)
+			eval.Add(tmp, ctRot, tmp)
+		}
+	}
+
+	ctOut.Resize(ctOut.Degree(), level)
+	ctOut.Copy(acc.El())
+}
+
+// InnerSumBatch applies a naive inner sum on the ciphertext (n-1 rotations with single hoisting).
+// The operation assumes that each row of `ctIn` encrypts N/(2*`batch`) sub-vectors of size `batch` which it adds together (in parallel) by groups of `n`.
+// It outputs in ctOut a ciphertext for which the "leftmost" sub-vector of each group is equal to the sum of the group.
+// The rotations are cyclic within each row of N/2 slots.
+// This method is faster than InnerSumLog when the number of rotations is small but uses 'n' keys instead of log2(n) + HW(n).
+// Required rotation keys can be generated with 'RotationsForInnerSum(batch, n)'.
+func (eval *evaluator) InnerSumBatch(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext) {
+
+	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot InnerSumBatch: input and output must be of degree 1")
+	}
+
+	if n < 1 {
+		panic("cannot InnerSumBatch: n must be at least 1")
+	}
+
+	level := ctIn.Level()
+
+	rotations := eval.params.RotationsForInnerSum(batch, n)
+
+	ctRot := make(map[int]*Ciphertext, len(rotations))
+	for _, k := range rotations {
+		ctRot[k] = NewCiphertextLvl(eval.params, 1, level)
+	}
+
+	// The rotations are hoisted if the parameters allow it
+	if eval.params.PCount() > 0 {
+		eval.RotateHoisted(ctIn, rotations, ctRot)
+	} else {
+		for _, k := range rotations {
+			eval.RotateColumns(ctIn, k, ctRot[k])
+		}
+	}
+
+	ctOut.Resize(ctOut.Degree(), level)
+	ctOut.Copy(ctIn.El())
+	for _, k := range rotations {
+		eval.Add(ctOut, ctRot[k], ctOut)
+	}
+}
+
+// ReplicateLog applies an optimized replication on the ciphertext (log2(n) + HW(n) rotations).
+// It acts as the inverse of a inner sum (summing elements from left to right).
+// The replication is parameterized by the size of the sub-vectors to replicate "batch" and
+// the number of time "n" they need to be replicated.
+// To ensure correctness, a gap of zero values of size batch * (n-1) must exist between
+// two consecutive sub-vectors to replicate.
+// This method is faster than Replicate when the number of rotations is large and uses log2(n) + HW(n) instead of 'n'.
+// Required rotation keys can be generated with 'RotationsForReplicateLog(batch, n)'.
+func (eval *evaluator) ReplicateLog(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext) {
+	eval.InnerSumLog(ctIn, -batch, n, ctOut)
+}
+
+// Replicate applies naive replication on the ciphertext (n-1 rotations with single hoisting).
+// It acts as the inverse of a inner sum (summing elements from left to right).
+// The replication is parameterized by the size of the sub-vectors to replicate "batch" and
+// the number of time "n" they need to be replicated.
+// To ensure correctness, a gap of zero values of size batch * (n-1) must exist between
+// two consecutive sub-vectors to replicate.
+// This method is faster than ReplicateLog when the number of rotations is small but uses 'n' keys instead of log2(n) + HW(n).
+// Required rotation keys can be generated with 'RotationsForReplicate(batch, n)'.
+func (eval *evaluator) Replicate(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext) {
+	eval.InnerSumBatch(ctIn, -batch, n, ctOut)
+}
+
 // ShallowCopy creates a shallow copy of this evaluator in which the read-only data-structures are
 // shared with the receiver.
 func (eval *evaluator) ShallowCopy() Evaluator {
@@ -0,0 +1,425 @@
+package bfv
+
+import (
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/rlwe/ringqp"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// LinearTransform is a type for linear transformations over Z_t on the slots of ciphertexts.
+// The slots are arranged in a 2 x N/2 matrix and the transform is given in diagonal form: the diagonal
+// of index k is a vector d_k of N values and the transform maps the slots x to sum_k d_k * RotateColumns(x, k),
+// where the product is slot-wise. Each row of the slots is thus multiplied by its own N/2 x N/2 matrix.
+// It can be evaluated on a ciphertext by using the evaluator.LinearTransform method.
+type LinearTransform struct {
+	N1    int                   // N1 is the number of inner loops of the baby-step giant-step algorithm used in the evaluation (if N1 == 0, BSGS is not used).
+	Level int                   // Level is the level at which the matrix is encoded (can be circuit dependent)
+	Vec   map[int]*PlaintextMul // Vec is the matrix, in diagonal form, where each entry of vec is an indexed non-zero diagonal.
+}
+
+// NewLinearTransform allocates a new LinearTransform with zero plaintexts at the specified level.
+// If BSGSRatio == 0, the LinearTransform is set to not use the BSGS approach.
+// Method will panic if BSGSRatio < 0.
+func NewLinearTransform(params Parameters, nonZeroDiags []int, level int, BSGSRatio float64) LinearTransform {
+	vec := make(map[int]*PlaintextMul)
+	slots := params.N() >> 1
+	var N1 int
+	if BSGSRatio == 0 {
+		N1 = 0
+		for _, i := range nonZeroDiags {
+			vec[i&(slots-1)] = NewPlaintextMulLvl(params, level)
+		}
+	} else if BSGSRatio > 0 {
+		N1 = rlwe.FindBestBSGSSplit(nonZeroDiags, slots, BSGSRatio)
+		index, _, _ := rlwe.BsgsIndex(nonZeroDiags, slots, N1)
+		for j := range index {
+			for _, i := range index[j] {
+				vec[j+i] = NewPlaintextMulLvl(params, level)
+			}
+		}
+	} else {
+		panic("BSGS ratio cannot be negative")
+	}
+
+	return LinearTransform{N1: N1, Level: level, Vec: vec}
+}
+
+// Rotations returns the list of rotations needed for the evaluation
+// of the linear transform.
+func (LT *LinearTransform) Rotations() (rotations []int) {
+
+	rotIndex := make(map[int]bool)
+
+	N1 := LT.N1
+
+	if N1 == 0 {
+		for j := range LT.Vec {
+			rotIndex[j] = true
+		}
+	} else {
+		for j := range LT.Vec {
+			rotIndex[(j/N1)*N1] = true
+			rotIndex[j&(N1-1)] = true
+		}
+	}
+
+	rotations = make([]int, 0, len(rotIndex))
+	for j := range rotIndex {
+		rotations = append(rotations, j)
+	}
+
+	return rotations
+}
+
+// Encode encodes on a pre-allocated LinearTransform the linear transforms' matrix in diagonal form `value`.
+// value.(type) can be either map[int][]uint64 or map[int][]int64, and the diagonals must be of size N/2,
+// in which case they are used on both rows of the slots, or of size N.
+// The diagonals are indexed by their rotation, which is taken modulo N/2.
+func (LT *LinearTransform) Encode(ecd Encoder, value interface{}) {
+
+	params := encoderParameters(ecd, "Encode")
+	dMat := diagonalsToSlots(params, value)
+	slots := params.N() >> 1
+	N1 := LT.N1
+
+	if N1 == 0 {
+		for i := range dMat {
+			if _, ok := LT.Vec[i]; !ok {
+				panic("cannot Encode: error encoding on LinearTransform: input does not match the same non-zero diagonals")
+			}
+
+			ecd.EncodeMul(dMat[i], LT.Vec[i])
+		}
+	} else {
+		index, _, _ := rlwe.BsgsIndex(nonZeroDiagonals(dMat), slots, N1)
+
+		for j := range index {
+			for _, i := range index[j] {
+
+				if _, ok := LT.Vec[j+i]; !ok {
+					panic("cannot Encode: error encoding on LinearTransform BSGS: input does not match the same non-zero diagonals")
+				}
+
+				ecd.EncodeMul(utils.RotateUint64Slots(dMat[j+i], -j), LT.Vec[j+i])
+			}
+		}
+	}
+}
+
+// GenLinearTransform allocates and encode a new LinearTransform struct from the linear transforms' matrix in diagonal form `value`.
+// value.(type) can be either map[int][]uint64 or map[int][]int64, and the diagonals must be of size N/2,
+// in which case they are used on both rows of the slots, or of size N.
+// It can then be evaluated on a ciphertext using evaluator.LinearTransform.
+// Evaluation will use the naive approach (single hoisting and no baby-step giant-step).
+// Faster if there is only a few non-zero diagonals but uses more keys.
+func GenLinearTransform(ecd Encoder, value interface{}, level int) LinearTransform {
+
+	params := encoderParameters(ecd, "GenLinearTransform")
+	dMat := diagonalsToSlots(params, value)
+
+	vec := make(map[int]*PlaintextMul)
+	for i := range dMat {
+		vec[i] = ecd.EncodeMulNew(dMat[i], level)
+	}
+
+	return LinearTransform{N1: 0, Vec: vec, Level: level}
+}
+
+// GenLinearTransformBSGS allocates and encodes a new LinearTransform struct from the linear transforms' matrix in diagonal form `value` for evaluation with a baby-step giant-step approach.
+// value.(type) can be either map[int][]uint64 or map[int][]int64, and the diagonals must be of size N/2,
+// in which case they are used on both rows of the slots, or of size N.
+// LinearTransform types can be be evaluated on a ciphertext using evaluator.LinearTransform.
+// Evaluation will use the optimized approach (single hoisting and baby-step giant-step).
+// Faster if there is more than a few non-zero diagonals.
+// BSGSRatio is the maximum ratio between the inner and outer loop of the baby-step giant-step algorithm used in evaluator.LinearTransform.
+// Optimal BSGSRatio value is between 4 and 16 depending on the sparsity of the matrix.
+func GenLinearTransformBSGS(ecd Encoder, value interface{}, level int, BSGSRatio float64) (LT LinearTransform) {
+
+	params := encoderParameters(ecd, "GenLinearTransformBSGS")
+	dMat := diagonalsToSlots(params, value)
+	slots := params.N() >> 1
+
+	nonZeroDiags := nonZeroDiagonals(dMat)
+
+	// N1*N2 = N/2
+	N1 := rlwe.FindBestBSGSSplit(nonZeroDiags, slots, BSGSRatio)
+
+	index, _, _ := rlwe.BsgsIndex(nonZeroDiags, slots, N1)
+
+	vec := make(map[int]*PlaintextMul)
+	for j := range index {
+		for _, i := range index[j] {
+			vec[j+i] = ecd.EncodeMulNew(utils.RotateUint64Slots(dMat[j+i], -j), level)
+		}
+	}
+
+	return LinearTransform{N1: N1, Vec: vec, Level: level}
+}
+
+// encoderParameters returns the parameters of the Encoder ecd.
+func encoderParameters(ecd Encoder, method string) Parameters {
+	enc, ok := ecd.(*encoder)
+	if !ok {
+		panic("cannot " + method + ": encoder should be a bfv.Encoder returned by NewEncoder")
+	}
+	return enc.params
+}
+
+// diagonalsToSlots returns the diagonals of value, indexed between 0 and N/2-1, as vectors of N values mod t.
+func diagonalsToSlots(params Parameters, value interface{}) (dMat map[int][]uint64) {
+
+	N := params.N()
+	slots := N >> 1
+	T := params.T()
+
+	dMat = make(map[int][]uint64)
+
+	add := func(i, size int, get func(j int) uint64) {
+
+		if size != slots && size != N {
+			panic("cannot encode LinearTransform: diagonals must be of size N/2 or N")
+		}
+
+		idx := i & (slots - 1)
+		if _, ok := dMat[idx]; ok {
+			panic("cannot encode LinearTransform: two diagonals have the same index modulo N/2")
+		}
+
+		v := make([]uint64, N)
+		for j := range v {
+			v[j] = get(j % size)
+		}
+		dMat[idx] = v
+	}
+
+	switch el := value.(type) {
+	case map[int][]uint64:
+		for i, d := range el {
+			add(i, len(d), func(j int) uint64 { return d[j] % T })
+		}
+	case map[int][]int64:
+		for i, d := range el {
+			add(i, len(d), func(j int) uint64 {
+				if d[j] < 0 {
+					return (T - uint64(-d[j])%T) % T
+				}
+				return uint64(d[j]) % T
+			})
+		}
+	default:
+		panic("cannot encode LinearTransform: invalid input, must be map[int][]uint64 or map[int][]int64")
+	}
+
+	return
+}
+
+// nonZeroDiagonals returns the indexes of the diagonals of el.
+func nonZeroDiagonals(el interface{}) (nonZeroDiags []int) {
+	switch element := el.(type) {
+	case map[int][]uint64:
+		for key := range element {
+			nonZeroDiags = append(nonZeroDiags, key)
+		}
+	case map[int][]int64:
+		for key := range element {
+			nonZeroDiags = append(nonZeroDiags, key)
+		}
+	case map[int]bool:
+		for key := range element {
+			nonZeroDiags = append(nonZeroDiags, key)
+		}
+	case map[int]*PlaintextMul:
+		for key := range element {
+			nonZeroDiags = append(nonZeroDiags, key)
+		}
+	case []int:
+		nonZeroDiags = element
+	default:
+		panic("cannot nonZeroDiagonals: invalid input, must be map[int][]uint64, map[int][]int64, map[int]bool, map[int]*PlaintextMul or []int")
+	}
+	return
+}
+
+// LinearTransformNew evaluates a linear transform on the ciphertext and returns the result on a new ciphertext.
+// The linearTransform can either be an (ordered) list of LinearTransform or a single LinearTransform.
+// In either case a list of ciphertext is returned (the second case returning a list
+// containing a single ciphertext).
+func (eval *evaluator) LinearTransformNew(ctIn *Ciphertext, linearTransform interface{}) (ctOut []*Ciphertext) {
+
+	switch LTs := linearTransform.(type) {
+	case []LinearTransform:
+		var maxLevel int
+		for _, LT := range LTs {
+			maxLevel = utils.MaxInt(maxLevel, LT.Level)
+		}
+
+		ctOut = make([]*Ciphertext, len(LTs))
+		for i := range LTs {
+			ctOut[i] = NewCiphertextLvl(eval.params, 1, utils.MinInt(maxLevel, ctIn.Level()))
+		}
+
+	case LinearTransform:
+		ctOut = []*Ciphertext{NewCiphertextLvl(eval.params, 1, utils.MinInt(LTs.Level, ctIn.Level()))}
+
+	default:
+		panic("cannot LinearTransformNew: invalid input, must be []LinearTransform or LinearTransform")
+	}
+
+	eval.LinearTransform(ctIn, linearTransform, ctOut)
+
+	return
+}
+
+// LinearTransform evaluates a linear transform on the pre-allocated ciphertexts.
+// The linearTransform can either be an (ordered) list of LinearTransform or a single LinearTransform.
+// In either case the results are written on the first len(linearTransform) ciphertexts of ctOut.
+// The decomposition of the input ciphertext is shared among all the linear transforms.
+// The hoisting of the rotations requires parameters with an auxiliary modulus P.
+func (eval *evaluator) LinearTransform(ctIn *Ciphertext, linearTransform interface{}, ctOut []*Ciphertext) {
+
+	if eval.params.PCount() == 0 {
+		panic("cannot LinearTransform: hoisted rotations require parameters with a modulus P")
+	}
+
+	switch LTs := linearTransform.(type) {
+	case []LinearTransform:
+		var maxLevel int
+		for _, LT := range LTs {
+			maxLevel = utils.MaxInt(maxLevel, LT.Level)
+		}
+
+		minLevel := utils.MinInt(maxLevel, ctIn.Level())
+
+		eval.DecomposeNTT(minLevel, eval.params.PCount()-1, eval.params.PCount(), ctIn.Value[1], eval.BuffDecompQP)
+
+		for i, LT := range LTs {
+			if LT.N1 == 0 {
+				eval.MultiplyByDiagMatrix(ctIn, LT, eval.BuffDecompQP, ctOut[i])
+			} else {
+				eval.MultiplyByDiagMatrixBSGS(ctIn, LT, eval.BuffDecompQP, ctOut[i])
+			}
+		}
+
+	case LinearTransform:
+		minLevel := utils.MinInt(LTs.Level, ctIn.Level())
+		eval.DecomposeNTT(minLevel, eval.params.PCount()-1, eval.params.PCount(), ctIn.Value[1], eval.BuffDecompQP)
+		if LTs.N1 == 0 {
+			eval.MultiplyByDiagMatrix(ctIn, LTs, eval.BuffDecompQP, ctOut[0])
+		} else {
+			eval.MultiplyByDiagMatrixBSGS(ctIn, LTs, eval.BuffDecompQP, ctOut[0])
+		}
+
+	default:
+		panic("cannot LinearTransform: invalid input, must be []LinearTransform or LinearTransform")
+	}
+}
+
+// MultiplyByDiagMatrix multiplies the ciphertext "ctIn" by the plaintext matrix "matrix" and returns the result on the ciphertext
+// "ctOut". BuffDecompQP must store the decomposition of ctIn.Value[1], as given by DecomposeNTT, at a level at least
+// equal to the minimum level of ctIn, ctOut and matrix.
+// The naive approach is used (single hoisting and no baby-step giant-step), which is faster than MultiplyByDiagMatrixBSGS
+// for matrix of only a few non-zero diagonals but uses more keys.
+func (eval *evaluator) MultiplyByDiagMatrix(ctIn *Ciphertext, matrix LinearTransform, BuffDecompQP []ringqp.Poly, ctOut *Ciphertext) {
+
+	ringQ := eval.ringQ
+
+	levelQ := utils.MinInt(ctOut.Level(), utils.MinInt(ctIn.Level(), matrix.Level))
+
+	// Accumulators in the NTT domain
+	acc0, acc1 := eval.buffQ[0][0], eval.buffQ[0][1]
+	tmp0, tmp1 := eval.buffQ[0][2], eval.buffQ[0][3]
+
+	ctRot := eval.buffCt
+	ctRot.Resize(1, levelQ)
+
+	state := false
+	for k, pt := range matrix.Vec {
+
+		eval.AutomorphismHoisted(levelQ, ctIn.Ciphertext, BuffDecompQP, eval.params.GaloisElementForColumnRotationBy(k), ctRot.Ciphertext)
+
+		ringQ.NTTLvl(levelQ, ctRot.Value[0], tmp0)
+		ringQ.NTTLvl(levelQ, ctRot.Value[1], tmp1)
+
+		if !state {
+			ringQ.MulCoeffsMontgomeryLvl(levelQ, tmp0, pt.Value, acc0)
+			ringQ.MulCoeffsMontgomeryLvl(levelQ, tmp1, pt.Value, acc1)
+			state = true
+		} else {
+			ringQ.MulCoeffsMontgomeryAndAddLvl(levelQ, tmp0, pt.Value, acc0)
+			ringQ.MulCoeffsMontgomeryAndAddLvl(levelQ, tmp1, pt.Value, acc1)
+		}
+	}
+
+	ctOut.Resize(ctOut.Degree(), levelQ)
+
+	if !state {
+		ctOut.Value[0].Zero()
+		ctOut.Value[1].Zero()
+		return
+	}
+
+	ringQ.InvNTTLvl(levelQ, acc0, ctOut.Value[0])
+	ringQ.InvNTTLvl(levelQ, acc1, ctOut.Value[1])
+}
+
+// MultiplyByDiagMatrixBSGS multiplies the ciphertext "ctIn" by the plaintext matrix "matrix" and returns the result on the ciphertext
+// "ctOut". BuffDecompQP must store the decomposition of ctIn.Value[1], as given by DecomposeNTT, at a level at least
+// equal to the minimum level of ctIn, ctOut and matrix.
+// The BSGS approach is used (single hoisting of the baby-steps and one rotation per giant-step), which is faster than
+// MultiplyByDiagMatrix for matrix with more than a few non-zero diagonals and uses much less keys.
+func (eval *evaluator) MultiplyByDiagMatrixBSGS(ctIn *Ciphertext, matrix LinearTransform, BuffDecompQP []ringqp.Poly, ctOut *Ciphertext) {
+
+	ringQ := eval.ringQ
+
+	levelQ := utils.MinInt(ctOut.Level(), utils.MinInt(ctIn.Level(), matrix.Level))
+
+	index, _, rotN2 := rlwe.BsgsIndex(nonZeroDiagonals(matrix.Vec), eval.params.N()>>1, matrix.N1)
+
+	// The baby-step rotations are in [0, N1)
+	for len(eval.buffRotNTT) < matrix.N1 {
+		eval.buffRotNTT = append(eval.buffRotNTT, [2]*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly()})
+	}
+
+	// Pre-rotates the ciphertext for the baby-steps and switches them in the NTT domain
+	ctInRotNTT := eval.buffRotNTT
+	ctRot := eval.buffCt
+	ctRot.Resize(1, levelQ)
+	for _, i := range rotN2 {
+		eval.AutomorphismHoisted(levelQ, ctIn.Ciphertext, BuffDecompQP, eval.params.GaloisElementForColumnRotationBy(i), ctRot.Ciphertext)
+		ringQ.NTTLvl(levelQ, ctRot.Value[0], ctInRotNTT[i][0])
+		ringQ.NTTLvl(levelQ, ctRot.Value[1], ctInRotNTT[i][1])
+	}
+
+	// Accumulators of the inner loop in the NTT domain
+	acc0, acc1 := eval.buffQ[0][0], eval.buffQ[0][1]
+
+	ctOut.Resize(ctOut.Degree(), levelQ)
+	ctOut.Value[0].Zero()
+	ctOut.Value[1].Zero()
+
+	for j := range index {
+
+		for n, i := range index[j] {
+			pt := matrix.Vec[j+i]
+			if n == 0 {
+				ringQ.MulCoeffsMontgomeryLvl(levelQ, ctInRotNTT[i][0], pt.Value, acc0)
+				ringQ.MulCoeffsMontgomeryLvl(levelQ, ctInRotNTT[i][1], pt.Value, acc1)
+			} else {
+				ringQ.MulCoeffsMontgomeryAndAddLvl(levelQ, ctInRotNTT[i][0], pt.Value, acc0)
+				ringQ.MulCoeffsMontgomeryAndAddLvl(levelQ, ctInRotNTT[i][1], pt.Value, acc1)
+			}
+		}
+
+		ringQ.InvNTTLvl(levelQ, acc0, ctRot.Value[0])
+		ringQ.InvNTTLvl(levelQ, acc1, ctRot.Value[1])
+
+		// Giant-step: the diagonals have been pre-rotated by -j so that the rotation by j can be applied on the sum
+		if j != 0 {
+			eval.RotateColumns(ctRot, j, ctRot)
+		}
+
+		ringQ.AddLvl(levelQ, ctOut.Value[0], ctRot.Value[0], ctOut.Value[0])
+		ringQ.AddLvl(levelQ, ctOut.Value[1], ctRot.Value[1], ctOut.Value[1])
+	}
+}
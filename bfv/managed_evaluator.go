@@ -0,0 +1,599 @@
+package bfv
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// ManagedCiphertext is a Ciphertext along with an estimate of its noise, which is updated by the ManagedEvaluator.
+type ManagedCiphertext struct {
+	*Ciphertext
+
+	// Noise is the log2 of the estimated infinity norm of the invariant noise v of the ciphertext, defined by
+	// t/Q * (c0 + c1 * s) = m + v mod t at the level of the ciphertext. The decryption is correct as long as ||v|| < 1/2.
+	Noise float64
+}
+
+// NewManagedCiphertext wraps ct, which must be a fresh encryption, in a ManagedCiphertext with the noise estimate
+// of a fresh encryption at its level.
+func NewManagedCiphertext(params Parameters, ct *Ciphertext) *ManagedCiphertext {
+	return &ManagedCiphertext{Ciphertext: ct, Noise: newNoiseEstimator(params).fresh(ct.Level())}
+}
+
+// NoiseBudget returns the estimated number of bits by which the noise of the ciphertext can still grow
+// before its decryption fails.
+func (ct *ManagedCiphertext) NoiseBudget() float64 {
+	return -1 - ct.Noise
+}
+
+// CopyNew creates a deep copy of the receiver ManagedCiphertext and returns it.
+func (ct *ManagedCiphertext) CopyNew() *ManagedCiphertext {
+	return &ManagedCiphertext{Ciphertext: ct.Ciphertext.CopyNew(), Noise: ct.Noise}
+}
+
+// ManagedEvaluator is an evaluator that tracks an estimate of the noise of the ManagedCiphertexts and automatically
+// manages their levels. After each operation, the result is switched to the smallest level at which the modulus
+// switching at most doubles its estimated noise: since the noise of BFV is relative to the modulus, this does not
+// consume noise budget, while the following operations are faster and the ciphertext is smaller. Before binary
+// operations, the operands are switched to the smallest of their levels.
+// The noise estimates are average-case heuristics, in which the random variables are bounded by six standard
+// deviations, and not worst-case bounds: a decryption failure remains possible, although unlikely, when the estimated
+// noise budget is close to zero. The operations return an error if the estimated noise of the result would exceed
+// the decryption bound, and ToBytes serializes the ciphertexts at the smallest level and with the largest number of
+// dropped bits that keep the requested estimated noise budget.
+// The ManagedEvaluator only provides the managed operations: the underlying Evaluator is not exposed, so that the
+// noise estimates cannot be bypassed.
+type ManagedEvaluator struct {
+	evaluator Evaluator
+	params    Parameters
+	noise     *noiseEstimator
+}
+
+// NewManagedEvaluator creates a new ManagedEvaluator from the given parameters and evaluation keys.
+func NewManagedEvaluator(params Parameters, evaluationKey rlwe.EvaluationKey) *ManagedEvaluator {
+	return &ManagedEvaluator{
+		evaluator: NewEvaluator(params, evaluationKey),
+		params:    params,
+		noise:     newNoiseEstimator(params),
+	}
+}
+
+// ShallowCopy creates a shallow copy of this ManagedEvaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// ManagedEvaluators can be used concurrently.
+func (eval *ManagedEvaluator) ShallowCopy() *ManagedEvaluator {
+	return &ManagedEvaluator{
+		evaluator: eval.evaluator.ShallowCopy(),
+		params:    eval.params,
+		noise:     eval.noise,
+	}
+}
+
+// WithKey creates a shallow copy of the receiver ManagedEvaluator for which the new EvaluationKey is evaluationKey
+// and where the temporary buffers are shared. The receiver and the returned ManagedEvaluators cannot be used concurrently.
+func (eval *ManagedEvaluator) WithKey(evaluationKey rlwe.EvaluationKey) *ManagedEvaluator {
+	return &ManagedEvaluator{
+		evaluator: eval.evaluator.WithKey(evaluationKey),
+		params:    eval.params,
+		noise:     eval.noise,
+	}
+}
+
+// Add adds op1 to ctIn and returns the result in ctOut, after switching the operands to the same level.
+// The ciphertext operands must be of type *ManagedCiphertext.
+func (eval *ManagedEvaluator) Add(ctIn *ManagedCiphertext, op1 Operand, ctOut *ManagedCiphertext) (err error) {
+	if err = eval.evaluateBinary(ctIn, op1, ctOut, eval.evaluator.Add); err != nil {
+		return fmt.Errorf("cannot Add: %w", err)
+	}
+	return
+}
+
+// AddNew adds op1 to ctIn and returns the result in a newly created element, after switching the operands to the same level.
+// The ciphertext operands must be of type *ManagedCiphertext.
+func (eval *ManagedEvaluator) AddNew(ctIn *ManagedCiphertext, op1 Operand) (ctOut *ManagedCiphertext, err error) {
+	ctOut = eval.newCiphertext(ctIn.Level())
+	if err = eval.evaluateBinary(ctIn, op1, ctOut, eval.evaluator.Add); err != nil {
+		return nil, fmt.Errorf("cannot AddNew: %w", err)
+	}
+	return
+}
+
+// Sub subtracts op1 from ctIn and returns the result in ctOut, after switching the operands to the same level.
+// The ciphertext operands must be of type *ManagedCiphertext.
+func (eval *ManagedEvaluator) Sub(ctIn *ManagedCiphertext, op1 Operand, ctOut *ManagedCiphertext) (err error) {
+	if err = eval.evaluateBinary(ctIn, op1, ctOut, eval.evaluator.Sub); err != nil {
+		return fmt.Errorf("cannot Sub: %w", err)
+	}
+	return
+}
+
+// SubNew subtracts op1 from ctIn and returns the result in a newly created element, after switching the operands to the same level.
+// The ciphertext operands must be of type *ManagedCiphertext.
+func (eval *ManagedEvaluator) SubNew(ctIn *ManagedCiphertext, op1 Operand) (ctOut *ManagedCiphertext, err error) {
+	ctOut = eval.newCiphertext(ctIn.Level())
+	if err = eval.evaluateBinary(ctIn, op1, ctOut, eval.evaluator.Sub); err != nil {
+		return nil, fmt.Errorf("cannot SubNew: %w", err)
+	}
+	return
+}
+
+// Neg negates ctIn and returns the result in ctOut.
+func (eval *ManagedEvaluator) Neg(ctIn *ManagedCiphertext, ctOut *ManagedCiphertext) {
+	ctOut.Resize(1, ctIn.Level())
+	eval.evaluator.Neg(ctIn.Ciphertext, ctOut.Ciphertext)
+	ctOut.Noise = ctIn.Noise
+}
+
+// AddScalar adds the scalar to ctIn and returns the result in ctOut.
+func (eval *ManagedEvaluator) AddScalar(ctIn *ManagedCiphertext, scalar uint64, ctOut *ManagedCiphertext) (err error) {
+
+	noise := eval.noise.add(ctIn.Noise, eval.noise.plaintext(ctIn.Level()))
+
+	if err = eval.noise.check(noise); err != nil {
+		return fmt.Errorf("cannot AddScalar: %w", err)
+	}
+
+	if ctOut != ctIn {
+		ctOut.Resize(1, ctIn.Level())
+		ctOut.Copy(ctIn.El())
+	}
+
+	eval.evaluator.AddScalar(ctOut.Ciphertext, scalar, ctOut.Ciphertext)
+	ctOut.Noise = noise
+	eval.switchLevel(ctOut)
+
+	return
+}
+
+// MulScalar multiplies ctIn by the scalar and returns the result in ctOut.
+func (eval *ManagedEvaluator) MulScalar(ctIn *ManagedCiphertext, scalar uint64, ctOut *ManagedCiphertext) (err error) {
+
+	noise := eval.noise.mulScalar(ctIn.Noise, scalar)
+
+	if err = eval.noise.check(noise); err != nil {
+		return fmt.Errorf("cannot MulScalar: %w", err)
+	}
+
+	ctOut.Resize(1, ctIn.Level())
+	eval.evaluator.MulScalar(ctIn.Ciphertext, scalar, ctOut.Ciphertext)
+	ctOut.Noise = noise
+	eval.switchLevel(ctOut)
+
+	return
+}
+
+// MulScalarNew multiplies ctIn by the scalar and returns the result in a newly created element.
+func (eval *ManagedEvaluator) MulScalarNew(ctIn *ManagedCiphertext, scalar uint64) (ctOut *ManagedCiphertext, err error) {
+	ctOut = eval.newCiphertext(ctIn.Level())
+	if err = eval.MulScalar(ctIn, scalar, ctOut); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Mul multiplies ctIn with op1 and returns the result in ctOut, after switching the operands to the same level.
+// The product of two ciphertexts is relinearized, which requires the relinearization key.
+// The ciphertext operands must be of type *ManagedCiphertext.
+func (eval *ManagedEvaluator) Mul(ctIn *ManagedCiphertext, op1 Operand, ctOut *ManagedCiphertext) (err error) {
+	if err = eval.mul(ctIn, op1, ctOut); err != nil {
+		return fmt.Errorf("cannot Mul: %w", err)
+	}
+	return
+}
+
+// MulNew multiplies ctIn with op1 and returns the result in a newly created element, after switching the
+// operands to the same level. The product of two ciphertexts is relinearized, which requires the relinearization key.
+// The ciphertext operands must be of type *ManagedCiphertext.
+func (eval *ManagedEvaluator) MulNew(ctIn *ManagedCiphertext, op1 Operand) (ctOut *ManagedCiphertext, err error) {
+	ctOut = eval.newCiphertext(ctIn.Level())
+	if err = eval.mul(ctIn, op1, ctOut); err != nil {
+		return nil, fmt.Errorf("cannot MulNew: %w", err)
+	}
+	return
+}
+
+// RotateColumns rotates the columns of ctIn by k positions to the left and returns the result in ctOut.
+// It requires the rotation key of the column rotation by k.
+func (eval *ManagedEvaluator) RotateColumns(ctIn *ManagedCiphertext, k int, ctOut *ManagedCiphertext) (err error) {
+	if err = eval.evaluateKeySwitch(ctIn, ctOut, false, func(ctIn, ctOut *Ciphertext) { eval.evaluator.RotateColumns(ctIn, k, ctOut) }); err != nil {
+		return fmt.Errorf("cannot RotateColumns: %w", err)
+	}
+	return
+}
+
+// RotateColumnsNew rotates the columns of ctIn by k positions to the left and returns the result in a newly created element.
+// It requires the rotation key of the column rotation by k.
+func (eval *ManagedEvaluator) RotateColumnsNew(ctIn *ManagedCiphertext, k int) (ctOut *ManagedCiphertext, err error) {
+	ctOut = eval.newCiphertext(ctIn.Level())
+	if err = eval.RotateColumns(ctIn, k, ctOut); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// RotateRows swaps the rows of ctIn and returns the result in ctOut. It requires the rotation key of the row rotation.
+func (eval *ManagedEvaluator) RotateRows(ctIn *ManagedCiphertext, ctOut *ManagedCiphertext) (err error) {
+	if err = eval.evaluateKeySwitch(ctIn, ctOut, false, eval.evaluator.RotateRows); err != nil {
+		return fmt.Errorf("cannot RotateRows: %w", err)
+	}
+	return
+}
+
+// RotateRowsNew swaps the rows of ctIn and returns the result in a newly created element. It requires the rotation
+// key of the row rotation.
+func (eval *ManagedEvaluator) RotateRowsNew(ctIn *ManagedCiphertext) (ctOut *ManagedCiphertext, err error) {
+	ctOut = eval.newCiphertext(ctIn.Level())
+	if err = eval.RotateRows(ctIn, ctOut); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// InnerSum sums the slots of ctIn and returns the result, replicated in all the slots, in ctOut.
+// It requires the rotation keys of the column rotations by powers of two and of the row rotation.
+func (eval *ManagedEvaluator) InnerSum(ctIn *ManagedCiphertext, ctOut *ManagedCiphertext) (err error) {
+	if err = eval.evaluateKeySwitch(ctIn, ctOut, true, eval.evaluator.InnerSum); err != nil {
+		return fmt.Errorf("cannot InnerSum: %w", err)
+	}
+	return
+}
+
+// ToBytes serializes ctIn with Ciphertext.ToBytes after switching a copy of it to the smallest level, and at level
+// zero dropping the largest number of least significant bits of its coefficients, that keep an estimated noise
+// budget of at least budget bits. ctIn is not modified. The ciphertext can be deserialized with Ciphertext.FromBytes.
+func (eval *ManagedEvaluator) ToBytes(ctIn *ManagedCiphertext, budget float64) (data []byte, err error) {
+
+	bound := -1 - budget
+
+	if ctIn.Noise > bound {
+		return nil, fmt.Errorf("cannot ToBytes: the noise budget %.2f is smaller than %.2f", ctIn.NoiseBudget(), budget)
+	}
+
+	ct, noise := ctIn.Ciphertext, ctIn.Noise
+
+	if level := eval.lowestLevel(ctIn.Level(), noise, bound); level < ctIn.Level() {
+		ct = NewCiphertextLvl(eval.params, 1, level)
+		eval.evaluator.RescaleTo(level, ctIn.Ciphertext, ct)
+		noise = eval.noise.modSwitch(level, noise)
+	}
+
+	var dropBit0, dropBit1 int
+	if ct.Level() == 0 {
+		dropBit0, dropBit1 = eval.noise.dropBits(noise, bound)
+	}
+
+	return ct.ToBytes(&eval.params.Parameters, dropBit0, dropBit1), nil
+}
+
+// newCiphertext returns a new ManagedCiphertext of degree one at the given level.
+func (eval *ManagedEvaluator) newCiphertext(level int) *ManagedCiphertext {
+	return &ManagedCiphertext{Ciphertext: NewCiphertextLvl(eval.params, 1, level)}
+}
+
+// evaluateBinary evaluates the addition or subtraction f of ctIn and op1 at the smallest of their levels.
+func (eval *ManagedEvaluator) evaluateBinary(ctIn *ManagedCiphertext, op1 Operand, ctOut *ManagedCiphertext, f func(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext)) (err error) {
+
+	op0, op1Out, noise0, noise1, err := eval.matchLevels(ctIn, op1)
+	if err != nil {
+		return
+	}
+
+	noise := eval.noise.add(noise0, noise1)
+
+	if err = eval.noise.check(noise); err != nil {
+		return
+	}
+
+	ctOut.Resize(1, op0.Level())
+	f(op0, op1Out, ctOut.Ciphertext)
+	ctOut.Noise = noise
+	eval.switchLevel(ctOut)
+
+	return
+}
+
+// mul evaluates the product of ctIn with op1 at the smallest of their levels, and relinearizes it if op1 is a ciphertext.
+func (eval *ManagedEvaluator) mul(ctIn *ManagedCiphertext, op1 Operand, ctOut *ManagedCiphertext) (err error) {
+
+	op0, op1Out, noise0, noise1, err := eval.matchLevels(ctIn, op1)
+	if err != nil {
+		return
+	}
+
+	level := op0.Level()
+
+	var noise float64
+	switch op1.(type) {
+	case *ManagedCiphertext, *Plaintext:
+		noise = eval.noise.mul(level, noise0, noise1)
+	default:
+		noise = eval.noise.mulPlaintext(noise0)
+	}
+
+	if err = eval.noise.check(noise); err != nil {
+		return
+	}
+
+	if _, isCt := op1.(*ManagedCiphertext); isCt {
+		tmp := NewCiphertextLvl(eval.params, 2, level)
+		eval.evaluator.Mul(op0, op1Out, tmp)
+		ctOut.Resize(1, level)
+		eval.evaluator.Relinearize(tmp, ctOut.Ciphertext)
+	} else {
+		ctOut.Resize(1, level)
+		eval.evaluator.Mul(op0, op1Out, ctOut.Ciphertext)
+	}
+
+	ctOut.Noise = noise
+	eval.switchLevel(ctOut)
+
+	return
+}
+
+// evaluateKeySwitch evaluates the key-switching operation f on ctIn, which sums all the slots if innerSum is true.
+func (eval *ManagedEvaluator) evaluateKeySwitch(ctIn, ctOut *ManagedCiphertext, innerSum bool, f func(ctIn, ctOut *Ciphertext)) (err error) {
+
+	level := ctIn.Level()
+
+	noise := eval.noise.keySwitch(level, ctIn.Noise)
+	if innerSum {
+		noise += float64(eval.params.LogN())
+	}
+
+	if err = eval.noise.check(noise); err != nil {
+		return
+	}
+
+	ctOut.Resize(1, level)
+	f(ctIn.Ciphertext, ctOut.Ciphertext)
+	ctOut.Noise = noise
+	eval.switchLevel(ctOut)
+
+	return
+}
+
+// matchLevels returns the operands at the smallest of their levels, along with their noise estimates.
+// The input operands are not modified.
+func (eval *ManagedEvaluator) matchLevels(ctIn *ManagedCiphertext, op1 Operand) (op0 *Ciphertext, op1Out Operand, noise0, noise1 float64, err error) {
+
+	level := ctIn.Level()
+	if _, isPtRingT := op1.(*PlaintextRingT); !isPtRingT {
+		level = utils.MinInt(level, op1.Level())
+	}
+
+	op0, noise0 = eval.ciphertextAtLevel(ctIn, level)
+
+	switch op1 := op1.(type) {
+	case *ManagedCiphertext:
+		op1Out, noise1 = eval.ciphertextAtLevel(op1, level)
+	case *Plaintext:
+		op1Out, noise1 = eval.plaintextAtLevel(op1, level), eval.noise.plaintext(level)
+	case *PlaintextMul:
+		pt := rlwe.NewPlaintextAtLevelFromPoly(level, op1.Value)
+		pt.Value.IsNTT, pt.Value.IsMForm = op1.Value.IsNTT, op1.Value.IsMForm
+		op1Out, noise1 = &PlaintextMul{pt}, math.Inf(-1)
+	case *PlaintextRingT:
+		op1Out, noise1 = op1, eval.noise.plaintext(level)
+	default:
+		err = fmt.Errorf("invalid operand type %T: the ciphertexts must be of type *bfv.ManagedCiphertext", op1)
+	}
+
+	return
+}
+
+// ciphertextAtLevel returns ct switched to the given level, along with its noise estimate.
+// The input ciphertext is not modified.
+func (eval *ManagedEvaluator) ciphertextAtLevel(ct *ManagedCiphertext, level int) (ctOut *Ciphertext, noise float64) {
+
+	if ct.Level() == level {
+		return ct.Ciphertext, ct.Noise
+	}
+
+	ctOut = NewCiphertextLvl(eval.params, 1, level)
+	eval.evaluator.RescaleTo(level, ct.Ciphertext, ctOut)
+
+	return ctOut, eval.noise.modSwitch(level, ct.Noise)
+}
+
+// plaintextAtLevel returns the plaintext pt, which is scaled by Q/t, scaled at the given level.
+// The input plaintext is not modified.
+func (eval *ManagedEvaluator) plaintextAtLevel(pt *Plaintext, level int) (ptOut *Plaintext) {
+
+	if pt.Level() == level {
+		return pt
+	}
+
+	ringQ := eval.params.RingQ()
+	ptOut = NewPlaintextLvl(eval.params, level)
+	ringQ.DivRoundByLastModulusManyLvl(pt.Level(), pt.Level()-level, pt.Value, ringQ.NewPolyLvl(pt.Level()), ptOut.Value)
+
+	return
+}
+
+// switchLevel switches ct to the smallest level at which the modulus switching at most doubles its noise.
+func (eval *ManagedEvaluator) switchLevel(ct *ManagedCiphertext) {
+	if level := eval.lowestLevel(ct.Level(), ct.Noise, ct.Noise+1); level < ct.Level() {
+		eval.evaluator.RescaleTo(level, ct.Ciphertext, ct.Ciphertext)
+		ct.Noise = eval.noise.modSwitch(level, ct.Noise)
+	}
+}
+
+// lowestLevel returns the smallest level, at most the given level, at which the noise of a ciphertext is at
+// most bound after the modulus switching.
+func (eval *ManagedEvaluator) lowestLevel(level int, noise, bound float64) int {
+	for level > 0 && eval.noise.modSwitch(level-1, noise) <= bound {
+		level--
+	}
+	return level
+}
+
+// noiseEstimator estimates the log2 of the infinity norm of the invariant noise of the ciphertexts after each
+// operation. The estimates are average-case heuristics, in which the infinity norm of the product of two random
+// polynomials of infinity norms a and b is about 2 * sqrt(N) * a * b, and in which the rounding errors are
+// uniform and bounded by six standard deviations.
+type noiseEstimator struct {
+	t         uint64
+	logN      float64
+	logT      float64
+	logQ      []float64 // log2(Q) at each level
+	logQ0     int       // bit-length of Q[0]
+	expansion float64   // log2(2 * sqrt(N)), the expansion factor of the products of polynomials
+	logS      float64   // log2(sqrt(3h)), the infinity norm of e * s for e uniform in [-1/2, 1/2]
+
+	fresh0    float64   // log2 of the noise of a fresh encryption, times Q/t
+	rounding  float64   // log2 of the noise added by the rounding of a ciphertext, times Q/t
+	tensor    float64   // log2 of the noise added by the rounding of a tensor product, times Q/t
+	switching []float64 // log2 of the noise added by a key-switching at each level, times Q/t
+}
+
+// newNoiseEstimator creates a new noiseEstimator for the given parameters.
+func newNoiseEstimator(params Parameters) (ne *noiseEstimator) {
+
+	ne = new(noiseEstimator)
+
+	N := float64(params.N())
+	h := float64(params.HammingWeight())
+	sigma := params.Sigma()
+
+	ne.t = params.T()
+	ne.logN = math.Log2(N)
+	ne.logT = math.Log2(float64(ne.t))
+	ne.logQ0 = bits.Len64(params.Q()[0])
+	ne.expansion = 1 + ne.logN/2
+	ne.logS = math.Log2(math.Sqrt(3 * h))
+
+	ne.logQ = make([]float64, params.QCount())
+	for i, qi := range params.Q() {
+		ne.logQ[i] = math.Log2(float64(qi))
+		if i > 0 {
+			ne.logQ[i] += ne.logQ[i-1]
+		}
+	}
+
+	// e0 + e1 * s + u * e, with the ternary secrets s and u, and the rounding of Q/t * m
+	ne.fresh0 = math.Log2(6*sigma*math.Sqrt(4*N/3+1) + 0.5)
+
+	// round(c0) + round(c1) * s
+	ne.rounding = math.Log2(math.Sqrt(3) * (1 + math.Sqrt(h)))
+
+	// t * (floor(c0) + floor(c1) * s + floor(c2) * s^2), see tensorAndRescale
+	ne.tensor = ne.logT + math.Log2(math.Sqrt(3)*(1+math.Sqrt(h)+h))
+
+	// sum_i d_i * e_i / P, with the digits d_i uniform modulo q_i (or 2^w), and the rounding of the division by P
+	var logP float64
+	for _, pj := range params.P() {
+		logP += math.Log2(float64(pj))
+	}
+
+	ne.switching = make([]float64, params.QCount())
+	for level := range ne.switching {
+
+		digits := float64(params.DecompRNS(level, params.PCount()-1) * params.DecompPw2(level, params.PCount()-1))
+
+		logDigit := float64(params.MaxBit(level, params.PCount()-1))
+		if params.Pow2Base() != 0 {
+			logDigit = float64(params.Pow2Base())
+		}
+
+		ne.switching[level] = logSum(math.Log2(6*sigma*math.Sqrt(digits*N/12))+logDigit-logP, ne.rounding)
+	}
+
+	return
+}
+
+// invariant returns the log2 of the invariant noise of the noise 2^logNoise, times Q/t, at the given level.
+func (ne *noiseEstimator) invariant(level int, logNoise float64) float64 {
+	return ne.logT + logNoise - ne.logQ[level]
+}
+
+// check returns an error if the noise exceeds the decryption bound.
+func (ne *noiseEstimator) check(noise float64) (err error) {
+	if noise >= -1 {
+		return fmt.Errorf("the estimated noise 2^%.2f exceeds the decryption bound 2^-1", noise)
+	}
+	return
+}
+
+// fresh returns the estimated noise of a fresh encryption at the given level.
+func (ne *noiseEstimator) fresh(level int) float64 {
+	return ne.invariant(level, ne.fresh0)
+}
+
+// plaintext returns the estimated noise of a plaintext scaled by Q/t at the given level.
+func (ne *noiseEstimator) plaintext(level int) float64 {
+	return ne.invariant(level, 0)
+}
+
+// add returns the estimated noise of the sum of two ciphertexts.
+func (ne *noiseEstimator) add(noise0, noise1 float64) float64 {
+	return logSum(noise0, noise1)
+}
+
+// mulScalar returns the estimated noise of the product of a ciphertext with a scalar modulo t.
+func (ne *noiseEstimator) mulScalar(noise float64, scalar uint64) float64 {
+
+	if scalar %= ne.t; scalar > ne.t>>1 {
+		scalar = ne.t - scalar
+	}
+
+	return noise + math.Log2(math.Max(1, float64(scalar)))
+}
+
+// mulPlaintext returns the estimated noise of the product of a ciphertext with a plaintext that is not scaled by Q/t, whose
+// coefficients are in [0, t).
+func (ne *noiseEstimator) mulPlaintext(noise float64) float64 {
+	return noise + ne.logT + ne.expansion
+}
+
+// mul returns the estimated noise of the relinearized product of two ciphertexts at the given level: t/Q * ct_i(s) is equal
+// to m_i + v_i + t * k_i with ||m_i|| <= t/2 and ||k_i|| <= (1 + sqrt(3h))/2, so that the product has the noise
+// m0 * v1 + m1 * v0 + t * (k0 * v1 + k1 * v0) + v0 * v1, plus the rounding of the tensor product and the relinearization.
+func (ne *noiseEstimator) mul(level int, noise0, noise1 float64) (noise float64) {
+	noise = ne.logT + ne.expansion + logSum(0, ne.logS) + logSum(noise0, noise1)
+	noise = logSum(noise, noise0+noise1)
+	noise = logSum(noise, ne.invariant(level, ne.tensor))
+	return ne.keySwitch(level, noise)
+}
+
+// keySwitch returns the estimated noise of a ciphertext after a key-switching at the given level.
+func (ne *noiseEstimator) keySwitch(level int, noise float64) float64 {
+	return logSum(noise, ne.invariant(level, ne.switching[level]))
+}
+
+// modSwitch returns the estimated noise of a ciphertext after the switching of its modulus to the given level.
+func (ne *noiseEstimator) modSwitch(level int, noise float64) float64 {
+	return logSum(noise, ne.invariant(level, ne.rounding))
+}
+
+// dropBits returns the largest numbers of least significant bits of the coefficients of c0 and c1 at level zero
+// that can be dropped while keeping the noise at most bound, the remaining noise being split evenly between them.
+// Dropping n bits of c0 adds a noise of 2^(n-1) and dropping n bits of c1 adds a noise of 2^n * sqrt(3h), times Q/t.
+func (ne *noiseEstimator) dropBits(noise, bound float64) (dropBit0, dropBit1 int) {
+
+	if noise >= bound {
+		return
+	}
+
+	// log2 of half the remaining noise, times Q/t
+	margin := bound + math.Log2(1-math.Exp2(noise-bound)) - 1 + ne.logQ[0] - ne.logT
+
+	dropBit0 = utils.MinInt(utils.MaxInt(int(math.Floor(margin+1)), 0), ne.logQ0-1)
+	dropBit1 = utils.MinInt(utils.MaxInt(int(math.Floor(margin-ne.logS)), 0), ne.logQ0-1)
+
+	return
+}
+
+// logSum returns log2(2^a + 2^b).
+func logSum(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	return a + math.Log2(1+math.Exp2(b-a))
+}
@@ -210,6 +210,71 @@ func (p Parameters) RingT() *ring.Ring {
 	return p.ringT
 }
 
+// RotationsForInnerSum generates the rotations that will be performed by the
+// `Evaluator.InnerSumBatch` operation when performed with parameters `batch` and `n`.
+func (p Parameters) RotationsForInnerSum(batch, n int) (rotations []int) {
+	rotations = []int{}
+	for i := 1; i < n; i++ {
+		rotations = append(rotations, i*batch)
+	}
+	return
+}
+
+// RotationsForInnerSumLog generates the rotations that will be performed by the
+// `Evaluator.InnerSumLog` operation when performed with parameters `batch` and `n`.
+func (p Parameters) RotationsForInnerSumLog(batch, n int) (rotations []int) {
+
+	rotIndex := make(map[int]bool)
+
+	var k int
+	for i := 1; i < n; i <<= 1 {
+
+		k = i
+		k *= batch
+		rotIndex[k] = true
+
+		k = n - (n & ((i << 1) - 1))
+		k *= batch
+		rotIndex[k] = true
+	}
+
+	rotations = make([]int, 0, len(rotIndex))
+	for j := range rotIndex {
+		rotations = append(rotations, j)
+	}
+
+	return
+}
+
+// RotationsForReplicate generates the rotations that will be performed by the
+// `Evaluator.Replicate` operation when performed with parameters `batch` and `n`.
+func (p Parameters) RotationsForReplicate(batch, n int) (rotations []int) {
+	return p.RotationsForInnerSum(-batch, n)
+}
+
+// RotationsForReplicateLog generates the rotations that will be performed by the
+// `Evaluator.ReplicateLog` operation when performed with parameters `batch` and `n`.
+func (p Parameters) RotationsForReplicateLog(batch, n int) (rotations []int) {
+	return p.RotationsForInnerSumLog(-batch, n)
+}
+
+// RotationsForLinearTransform generates the list of column rotations needed for the evaluation of a linear transform
+// with the provided list of non-zero diagonals and BSGSratio.
+// nonZeroDiags.(type) can be either []int, map[int]bool, map[int][]uint64 or map[int][]int64.
+// If BSGSratio == 0, then provides the rotations needed for an evaluation without the BSGS approach.
+func (p Parameters) RotationsForLinearTransform(nonZeroDiags interface{}, BSGSratio float64) (rotations []int) {
+	slots := p.N() >> 1
+	diags := nonZeroDiagonals(nonZeroDiags)
+	if BSGSratio == 0 {
+		_, _, rotN2 := rlwe.BsgsIndex(diags, slots, slots)
+		return rotN2
+	}
+
+	N1 := rlwe.FindBestBSGSSplit(diags, slots, BSGSratio)
+	_, rotN1, rotN2 := rlwe.BsgsIndex(diags, slots, N1)
+	return append(rotN1, rotN2...)
+}
+
 // Equals compares two sets of parameters for equality.
 func (p Parameters) Equals(other Parameters) bool {
 	res := p.Parameters.Equals(other.Parameters)
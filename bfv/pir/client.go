@@ -0,0 +1,110 @@
+package pir
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Client is a struct to generate the queries of a PIR database and to decode the responses of the Server.
+type Client struct {
+	params Parameters
+	sk     *rlwe.SecretKey
+
+	encoder   bfv.Encoder
+	encryptor bfv.Encryptor
+	decryptor bfv.Decryptor
+}
+
+// NewClient creates a new Client with the secret key sk, under which the queries are encrypted.
+func NewClient(params Parameters, sk *rlwe.SecretKey) *Client {
+	return &Client{
+		params:    params,
+		sk:        sk,
+		encoder:   bfv.NewEncoder(params.Parameters),
+		encryptor: bfv.NewEncryptor(params.Parameters, sk),
+		decryptor: bfv.NewDecryptor(params.Parameters, sk),
+	}
+}
+
+// GenEvaluationKey generates the evaluation key that the Client gives to the Server: the rotation keys for
+// the query expansion and, if the database has more than one dimension, a relinearization key.
+func (c *Client) GenEvaluationKey() (evk rlwe.EvaluationKey) {
+
+	kgen := bfv.NewKeyGenerator(c.params.Parameters)
+
+	evk.Rtks = kgen.GenRotationKeys(c.params.GaloisElements(), c.sk)
+
+	if len(c.params.Dimensions) > 1 {
+		evk.Rlk = kgen.GenRelinearizationKey(c.sk, 1)
+	}
+
+	return
+}
+
+// GenQuery returns the query for the element of the database at the given index. The query is a seeded
+// ciphertext, half the size of a ciphertext, holding the selection bits of the plaintext of the element.
+func (c *Client) GenQuery(index int) (query *bfv.CompressedCiphertext, err error) {
+
+	if index < 0 || index >= c.params.Elements {
+		return nil, fmt.Errorf("cannot GenQuery: index %d is out of the database of %d elements", index, c.params.Elements)
+	}
+
+	rows, _ := c.params.position(index)
+
+	// The selection bits of the dimension i are in the coefficients [sum_{j<i} Dimensions[j], sum_{j<=i} Dimensions[j])
+	coeffs := make([]uint64, c.params.N())
+	var offset int
+	for i, n := range c.params.Dimensions {
+		coeffs[offset+rows[i]] = 1
+		offset += n
+	}
+
+	pt := bfv.NewPlaintext(c.params.Parameters)
+	c.encoder.EncodeCoeffs(coeffs, pt)
+
+	query = bfv.NewCompressedCiphertext(c.params.Parameters, 1, c.params.MaxLevel())
+	c.encryptor.EncryptCompressed(pt, query)
+
+	return
+}
+
+// DecodeResponse decrypts the response of the Server to the query for the element at the given index and
+// returns the element, of params.ElementSize bytes.
+func (c *Client) DecodeResponse(index int, response *bfv.Ciphertext) (element []byte) {
+
+	_, offset := c.params.position(index)
+
+	coeffs := c.encoder.DecodeCoeffsUintNew(c.decryptor.DecryptNew(response))
+
+	element = make([]byte, c.params.ElementSize)
+	c.params.unpackElement(coeffs[offset*c.params.coeffsPerElement:(offset+1)*c.params.coeffsPerElement], element)
+
+	return
+}
+
+// Retrieve retrieves the element at the given index from the Server behind the transport.
+func (c *Client) Retrieve(index int, transport Transport) (element []byte, err error) {
+
+	query, err := c.GenQuery(index)
+	if err != nil {
+		return nil, fmt.Errorf("cannot Retrieve: %w", err)
+	}
+
+	data, err := query.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("cannot Retrieve: %w", err)
+	}
+
+	if data, err = transport.RoundTrip(data); err != nil {
+		return nil, fmt.Errorf("cannot Retrieve: %w", err)
+	}
+
+	response := new(bfv.Ciphertext)
+	if err = response.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("cannot Retrieve: %w", err)
+	}
+
+	return c.DecodeResponse(index, response), nil
+}
@@ -0,0 +1,61 @@
+package pir
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+)
+
+// Database is a database of elements preprocessed by the Server, packed in the coefficients of plaintexts
+// in the NTT and Montgomery domain.
+type Database struct {
+	Parameters
+
+	// plaintexts[i] stores the elements [i * ElementsPerPlaintext(), (i+1) * ElementsPerPlaintext()), it is
+	// nil for the padding plaintexts of the hypercube of dimensions
+	plaintexts []*bfv.PlaintextMul
+}
+
+// NewDatabase encodes the elements on a new Database. Each element must have at most params.ElementSize bytes,
+// and shorter elements are padded with zeroes.
+func NewDatabase(params Parameters, encoder bfv.Encoder, elements [][]byte) (db *Database, err error) {
+
+	if len(elements) != params.Elements {
+		return nil, fmt.Errorf("cannot NewDatabase: the number of elements (%d) does not match the parameters (%d)", len(elements), params.Elements)
+	}
+
+	for i := range elements {
+		if len(elements[i]) > params.ElementSize {
+			return nil, fmt.Errorf("cannot NewDatabase: the element %d has %d bytes, more than the element size (%d)", i, len(elements[i]), params.ElementSize)
+		}
+	}
+
+	size := 1
+	for _, n := range params.Dimensions {
+		size *= n
+	}
+
+	db = &Database{Parameters: params, plaintexts: make([]*bfv.PlaintextMul, size)}
+
+	coeffs := make([]uint64, params.N())
+	ptRt := bfv.NewPlaintextRingT(params.Parameters)
+
+	for i := 0; i < params.Plaintexts(); i++ {
+
+		for j := range coeffs {
+			coeffs[j] = 0
+		}
+
+		for j := 0; j < params.ElementsPerPlaintext(); j++ {
+			if index := i*params.ElementsPerPlaintext() + j; index < params.Elements {
+				params.packElement(elements[index], coeffs[j*params.coeffsPerElement:(j+1)*params.coeffsPerElement])
+			}
+		}
+
+		encoder.EncodeCoeffsRingT(coeffs, ptRt)
+		db.plaintexts[i] = bfv.NewPlaintextMul(params.Parameters)
+		encoder.RingTToMul(ptRt, db.plaintexts[i])
+	}
+
+	return
+}
@@ -0,0 +1,142 @@
+// Package pir implements single-server private information retrieval (PIR) on top of the BFV scheme.
+//
+// A database of fixed-size elements is packed in the coefficients of plaintexts, which are preprocessed in the NTT
+// and Montgomery domain (bfv.PlaintextMul) and arranged in a hypercube of Dimensions. To retrieve an element, a Client
+// encrypts with its secret key a single seeded query (bfv.CompressedCiphertext) holding one selection index per
+// dimension in its coefficients. The Server expands it into one encrypted selection bit per row of each dimension
+// with rlwe.Evaluator.ExpandRLWE, folds the first dimension with ciphertext-plaintext products and each next dimension
+// with ciphertext-ciphertext products, and returns a single ciphertext which holds the plaintext of the element.
+//
+// The Client needs to give the Server an evaluation key with the rotation keys for Parameters.GaloisElements and,
+// if there is more than one dimension, a relinearization key (see Client.GenEvaluationKey).
+package pir
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+)
+
+// Parameters are the parameters of a PIR database: the BFV parameters, the number of elements of the database,
+// their size in bytes and the sizes of the dimensions of the hypercube of plaintexts.
+//
+// Each coefficient of a plaintext stores floor(log2(t)) bits of an element, and each plaintext stores
+// ElementsPerPlaintext() consecutive elements.
+type Parameters struct {
+	bfv.Parameters
+
+	Elements    int
+	ElementSize int   // Size of an element in bytes
+	Dimensions  []int // Number of rows of each dimension, whose product is at least Plaintexts()
+
+	bitsPerCoeff     int
+	coeffsPerElement int
+	logExpand        int
+}
+
+// NewParameters creates new Parameters for a database of elements of elementSize bytes each, arranged in the given
+// number of dimensions. The dimensions are chosen as balanced as possible; each additional dimension reduces the
+// size of the query expansion and the number of ciphertext-plaintext products, but increases the multiplicative
+// depth of the Server by one ciphertext-ciphertext product.
+func NewParameters(params bfv.Parameters, elements, elementSize, dimensions int) (p Parameters, err error) {
+
+	if elements < 1 || elementSize < 1 || dimensions < 1 {
+		return Parameters{}, fmt.Errorf("cannot NewParameters: the number of elements, their size and the number of dimensions must be positive")
+	}
+
+	p = Parameters{Parameters: params, Elements: elements, ElementSize: elementSize}
+
+	p.bitsPerCoeff = bits.Len64(params.T()) - 1
+	p.coeffsPerElement = (8*elementSize + p.bitsPerCoeff - 1) / p.bitsPerCoeff
+
+	if p.coeffsPerElement > params.N() {
+		return Parameters{}, fmt.Errorf("cannot NewParameters: an element of %d bytes does not fit in a plaintext of %d coefficients of %d bits", elementSize, params.N(), p.bitsPerCoeff)
+	}
+
+	p.Dimensions = make([]int, dimensions)
+
+	remaining := p.Plaintexts()
+	var sum int
+	for i := range p.Dimensions {
+		p.Dimensions[i] = root(remaining, dimensions-i)
+		remaining = (remaining + p.Dimensions[i] - 1) / p.Dimensions[i]
+		sum += p.Dimensions[i]
+	}
+
+	// The selection bits of all the dimensions are packed in the coefficients of a single query
+	if sum > params.N() {
+		return Parameters{}, fmt.Errorf("cannot NewParameters: the query of %d selection bits does not fit in a plaintext of %d coefficients, the number of dimensions must be increased", sum, params.N())
+	}
+
+	p.logExpand = bits.Len64(uint64(sum - 1))
+
+	return
+}
+
+// ElementsPerPlaintext returns the number of elements stored in each plaintext.
+func (p Parameters) ElementsPerPlaintext() int {
+	return p.N() / p.coeffsPerElement
+}
+
+// Plaintexts returns the number of plaintexts of the database.
+func (p Parameters) Plaintexts() int {
+	return (p.Elements + p.ElementsPerPlaintext() - 1) / p.ElementsPerPlaintext()
+}
+
+// GaloisElements returns the Galois elements of the rotation keys required by the Server to expand the queries.
+func (p Parameters) GaloisElements() []uint64 {
+	return p.GaloisElementForExpandRLWE(p.logExpand)
+}
+
+// position returns the index of the plaintext of the element in each dimension and the index of the element
+// in its plaintext.
+func (p Parameters) position(index int) (rows []int, offset int) {
+
+	plaintext := index / p.ElementsPerPlaintext()
+
+	rows = make([]int, len(p.Dimensions))
+	for i := len(p.Dimensions) - 1; i >= 0; i-- {
+		rows[i] = plaintext % p.Dimensions[i]
+		plaintext /= p.Dimensions[i]
+	}
+
+	return rows, index % p.ElementsPerPlaintext()
+}
+
+// root returns the smallest integer r such that r^k >= n.
+func root(n, k int) (r int) {
+
+	pow := func(r int) (x int) {
+		x = 1
+		for i := 0; i < k; i++ {
+			x *= r
+		}
+		return
+	}
+
+	r = int(math.Pow(float64(n), 1/float64(k)))
+	for pow(r) < n {
+		r++
+	}
+	for r > 1 && pow(r-1) >= n {
+		r--
+	}
+
+	return
+}
+
+// packElement writes the bits of the element on the coefficients, bitsPerCoeff bits per coefficient.
+func (p Parameters) packElement(element []byte, coeffs []uint64) {
+	for k := 0; k < 8*len(element); k++ {
+		coeffs[k/p.bitsPerCoeff] |= uint64(element[k>>3]>>(k&7)&1) << (k % p.bitsPerCoeff)
+	}
+}
+
+// unpackElement reads the bits of the element from the coefficients, bitsPerCoeff bits per coefficient.
+func (p Parameters) unpackElement(coeffs []uint64, element []byte) {
+	for k := 0; k < 8*len(element); k++ {
+		element[k>>3] |= byte(coeffs[k/p.bitsPerCoeff]>>(k%p.bitsPerCoeff)&1) << (k & 7)
+	}
+}
@@ -0,0 +1,101 @@
+package pir
+
+import (
+	"crypto/rand"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams are insecure parameters for fast testing only.
+var testParams = bfv.ParametersLiteral{
+	LogN: 12,
+	LogQ: []int{60, 60, 60},
+	LogP: []int{61},
+	T:    65537,
+}
+
+func TestPIR(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping PIR tests for GOARCH=wasm")
+	}
+
+	params, err := bfv.NewParametersFromLiteral(testParams)
+	require.NoError(t, err)
+
+	sk := bfv.NewKeyGenerator(params).GenSecretKey()
+	encoder := bfv.NewEncoder(params)
+
+	nbElements, elementSize := 1000, 100
+
+	elements := make([][]byte, nbElements)
+	for i := range elements {
+		elements[i] = make([]byte, elementSize)
+		_, err = rand.Read(elements[i])
+		require.NoError(t, err)
+	}
+
+	// Shorter elements are padded with zeroes
+	elements[1] = elements[1][:10]
+
+	for _, dimensions := range []int{1, 2, 3} {
+
+		pirParams, err := NewParameters(params, nbElements, elementSize, dimensions)
+		require.NoError(t, err)
+
+		size := 1
+		for _, n := range pirParams.Dimensions {
+			size *= n
+		}
+		require.GreaterOrEqual(t, size, pirParams.Plaintexts())
+
+		t.Run(fmt.Sprintf("Retrieve/logN=%d/logQP=%d/Dimensions=%v", params.LogN(), params.LogQP(), pirParams.Dimensions), func(t *testing.T) {
+
+			db, err := NewDatabase(pirParams, encoder, elements)
+			require.NoError(t, err)
+
+			client := NewClient(pirParams, sk)
+			transport := &InMemoryTransport{Server: NewServer(db, client.GenEvaluationKey())}
+
+			for _, index := range []int{0, 1, pirParams.ElementsPerPlaintext(), 517, nbElements - 1} {
+
+				element, err := client.Retrieve(index, transport)
+				require.NoError(t, err)
+
+				want := make([]byte, elementSize)
+				copy(want, elements[index])
+				require.Equal(t, want, element)
+			}
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+
+		_, err := NewParameters(params, 0, elementSize, 1)
+		require.Error(t, err)
+
+		// An element larger than a plaintext
+		_, err = NewParameters(params, nbElements, 2*params.N()*params.LogT()/8, 1)
+		require.Error(t, err)
+
+		// A query of more selection bits than coefficients
+		_, err = NewParameters(params, 1<<20, 512, 1)
+		require.Error(t, err)
+
+		pirParams, err := NewParameters(params, nbElements, elementSize, 2)
+		require.NoError(t, err)
+
+		_, err = NewDatabase(pirParams, encoder, elements[1:])
+		require.Error(t, err)
+
+		_, err = NewDatabase(pirParams, encoder, append(elements[1:], make([]byte, elementSize+1)))
+		require.Error(t, err)
+
+		_, err = NewClient(pirParams, sk).GenQuery(nbElements)
+		require.Error(t, err)
+	})
+}
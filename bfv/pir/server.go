@@ -0,0 +1,139 @@
+package pir
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Server is a struct to answer the queries of the Clients on a Database.
+// A Server is not safe for concurrent use.
+type Server struct {
+	db *Database
+
+	eval     bfv.Evaluator
+	evalRLWE *rlwe.Evaluator
+}
+
+// NewServer creates a new Server answering the queries on the database with the evaluation key of the Client
+// (see Client.GenEvaluationKey).
+func NewServer(db *Database, evk rlwe.EvaluationKey) *Server {
+	return &Server{
+		db:       db,
+		eval:     bfv.NewEvaluator(db.Parameters.Parameters, evk),
+		evalRLWE: rlwe.NewEvaluator(db.Parameters.Parameters.Parameters, &evk),
+	}
+}
+
+// Answer returns the response to the query, a ciphertext encrypting the plaintext of the queried element.
+// Its multiplicative depth is len(Dimensions)-1 ciphertext-ciphertext products.
+func (s *Server) Answer(query *bfv.CompressedCiphertext) (response *bfv.Ciphertext, err error) {
+
+	params := s.db.Parameters
+
+	if query.Value.N() != params.N() || query.Level() != params.MaxLevel() {
+		return nil, fmt.Errorf("cannot Answer: the query does not match the parameters of the database")
+	}
+
+	ringQ := params.RingQ()
+	level := query.Level()
+
+	// Expands the query into one encrypted selection bit per row of each dimension, in the NTT domain
+	ct := query.ToCiphertext(params.Parameters)
+	for i := range ct.Value {
+		ringQ.NTTLvl(level, ct.Value[i], ct.Value[i])
+		ct.Value[i].IsNTT = true
+	}
+
+	selection := s.evalRLWE.ExpandRLWE(ct.Ciphertext, params.logExpand)
+
+	// First dimension: ciphertext-plaintext products, accumulated in the NTT domain
+	rows := params.Dimensions[0]
+	rest := len(s.db.plaintexts) / rows
+
+	results := make([]*bfv.Ciphertext, rest)
+	for j := range results {
+
+		acc := rlwe.NewCiphertextNTT(params.Parameters.Parameters, 1, level)
+
+		for i := 0; i < rows; i++ {
+			if pt := s.db.plaintexts[i*rest+j]; pt != nil {
+				for k := range acc.Value {
+					ringQ.MulCoeffsMontgomeryAndAddLvl(level, selection[i].Value[k], pt.Value, acc.Value[k])
+				}
+			}
+		}
+
+		for k := range acc.Value {
+			ringQ.InvNTTLvl(level, acc.Value[k], acc.Value[k])
+			acc.Value[k].IsNTT = false
+		}
+
+		results[j] = &bfv.Ciphertext{Ciphertext: acc}
+	}
+
+	// Next dimensions: ciphertext-ciphertext products with the selection bits of the dimension
+	offset := rows
+	for _, n := range params.Dimensions[1:] {
+
+		sel := make([]*bfv.Ciphertext, n)
+		for i := range sel {
+			sel[i] = &bfv.Ciphertext{Ciphertext: selection[offset+i]}
+			for k := range sel[i].Value {
+				ringQ.InvNTTLvl(level, sel[i].Value[k], sel[i].Value[k])
+				sel[i].Value[k].IsNTT = false
+			}
+		}
+
+		rest /= n
+
+		next := make([]*bfv.Ciphertext, rest)
+		for j := range next {
+
+			acc := bfv.NewCiphertextLvl(params.Parameters, 2, level)
+			for i := 0; i < n; i++ {
+				s.eval.MulAndAdd(sel[i], results[i*rest+j], acc)
+			}
+
+			next[j] = s.eval.RelinearizeNew(acc)
+		}
+
+		results = next
+		offset += n
+	}
+
+	return results[0], nil
+}
+
+// AnswerBinary answers the query given in its binary form (see bfv.CompressedCiphertext.MarshalBinary) and returns
+// the response in its binary form.
+func (s *Server) AnswerBinary(data []byte) ([]byte, error) {
+
+	query := new(bfv.CompressedCiphertext)
+	if err := query.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("cannot AnswerBinary: %w", err)
+	}
+
+	response, err := s.Answer(query)
+	if err != nil {
+		return nil, fmt.Errorf("cannot AnswerBinary: %w", err)
+	}
+
+	return response.MarshalBinary()
+}
+
+// Transport carries the binary queries of a Client to a Server and their binary responses back.
+type Transport interface {
+	RoundTrip(query []byte) (response []byte, err error)
+}
+
+// InMemoryTransport is a Transport calling a Server in the same process, for example for testing.
+type InMemoryTransport struct {
+	Server *Server
+}
+
+// RoundTrip answers the query with the Server.
+func (t *InMemoryTransport) RoundTrip(query []byte) (response []byte, err error) {
+	return t.Server.AnswerBinary(query)
+}
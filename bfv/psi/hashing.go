@@ -0,0 +1,96 @@
+package psi
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// maxEvictions is the maximum number of evictions of the insertion of an item in a CuckooTable.
+const maxEvictions = 512
+
+// hashedItem is an item hashed to its field elements and its candidate bins.
+type hashedItem struct {
+	felts []uint64
+	bins  [HashFunctions]int
+}
+
+// hashItem hashes the item with blake2b-512: the first 8 * HashFunctions bytes of the digest give the candidate bins
+// of the item and its last 32 bytes give its ItemBits bits.
+func (p Parameters) hashItem(item []byte) (h hashedItem) {
+
+	digest := blake2b.Sum512(item)
+
+	for i := range h.bins {
+		h.bins[i] = int(binary.LittleEndian.Uint64(digest[8*i:]) % uint64(p.Bins()))
+	}
+
+	h.felts = make([]uint64, p.feltsPerItem)
+	p.packBits(digest[32:], p.ItemBits, h.felts)
+
+	return
+}
+
+// CuckooTable is the assignment of the items of a Receiver to the bins, at most one item per bin, computed with cuckoo
+// hashing. It is returned by Receiver.GenQuery and is needed to decode the response of the Sender.
+type CuckooTable struct {
+	items []hashedItem
+	table []int // Index of the item in each bin, -1 for an empty bin
+}
+
+// newCuckooTable inserts the items in a new CuckooTable. Each item is inserted in its first candidate bin, evicting
+// the item that occupies it, which is then inserted in its next candidate bin, and so on.
+func (p Parameters) newCuckooTable(items [][]byte) (ct *CuckooTable, err error) {
+
+	if len(items) > p.Bins() {
+		return nil, fmt.Errorf("cannot insert %d items in a cuckoo table of %d bins", len(items), p.Bins())
+	}
+
+	ct = &CuckooTable{items: make([]hashedItem, len(items)), table: make([]int, p.Bins())}
+
+	for i := range ct.table {
+		ct.table[i] = -1
+	}
+
+	for i := range items {
+
+		ct.items[i] = p.hashItem(items[i])
+
+		current, h := i, 0
+
+		var evictions int
+		for ; evictions < maxEvictions; evictions++ {
+
+			bin := ct.items[current].bins[h]
+
+			evicted := ct.table[bin]
+			ct.table[bin] = current
+
+			if evicted == -1 {
+				break
+			}
+
+			// The evicted item is inserted in the candidate bin following the one it occupied
+			for h = 0; ct.items[evicted].bins[h] != bin; h++ {
+			}
+			current, h = evicted, (h+1)%HashFunctions
+		}
+
+		if evictions == maxEvictions {
+			return nil, fmt.Errorf("cannot insert %d items in a cuckoo table of %d bins: the maximum number of evictions is reached", len(items), p.Bins())
+		}
+	}
+
+	return
+}
+
+// bin returns the bin of the i-th item.
+func (ct *CuckooTable) bin(i int) int {
+	for _, bin := range ct.items[i].bins {
+		if ct.table[bin] == i {
+			return bin
+		}
+	}
+	return -1
+}
@@ -0,0 +1,122 @@
+// Package psi implements unbalanced and labeled private set intersection (PSI) on top of the BFV scheme, following
+// the protocol of Chen, Laine and Rindal (https://eprint.iacr.org/2017/299) and its labeled extension
+// (https://eprint.iacr.org/2018/787).
+//
+// The items are hashed to ItemBits bits, which are split into FeltsPerItem() field elements modulo t, each stored in
+// one slot. The slots are grouped in Bins() bins of FeltsPerItem() consecutive slots. The Receiver inserts its items
+// in the bins with cuckoo hashing, one item per bin, and the Sender inserts each of its items in all of its candidate
+// bins with simple hashing. As the Sender can have many more items than the Receiver, the items of each bin of the
+// Sender are split in partitions of at most MaxDegree items. For each partition, the Sender interpolates in each slot
+// the polynomial whose roots are the field elements of its items in the slot, and, for labeled PSI, the polynomials
+// mapping these field elements to the field elements of their labels.
+//
+// The Receiver encrypts the powers x^k, for k in SourcePowers(), of the field elements x of its items (windowing), as
+// seeded ciphertexts (bfv.CompressedCiphertext) under its secret key. The Sender completes the power basis and
+// evaluates the polynomials of each partition on all the slots at once with bfv.Evaluator.EvaluatePolyVector. A
+// Receiver's item is in the intersection if, for a partition, all the slots of its bin decrypt to zero. The results
+// are multiplied by random plaintexts so that the Receiver learns nothing but zero or a uniform value per slot,
+// although they are not noise-flooded.
+//
+// A field element of an item may collide with the one of another item of the same partition, so that the probability
+// of a false positive for a Receiver's item is at most about (MaxDegree / 2^bitsPerFelt)^FeltsPerItem() per
+// partition, where bitsPerFelt = floor(log2(t)).
+package psi
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+)
+
+// HashFunctions is the number of hash functions, i.e. of candidate bins of an item.
+const HashFunctions = 3
+
+// Parameters are the parameters of a PSI protocol: the BFV parameters, the size of the hash of the items, the size of
+// the labels, the maximum degree of the polynomials of the Sender and the window size of the powers of the Receiver.
+type Parameters struct {
+	bfv.Parameters
+
+	ItemBits   int // Number of bits of the hash of an item, at most 256
+	LabelSize  int // Size of a label in bytes, 0 for unlabeled PSI
+	MaxDegree  int // Maximum number of items of a bin in a partition of the Sender
+	WindowSize int // The Receiver sends the powers i * 2^(j*WindowSize) for 1 <= i < 2^WindowSize
+
+	bitsPerFelt  int
+	feltsPerItem int
+	labelFelts   int
+}
+
+// NewParameters creates new Parameters. The plaintext modulus t must be a prime congruent to 1 modulo 2N, so that
+// the plaintexts are batched in N slots.
+//
+// A larger MaxDegree reduces the number of partitions of the Sender, but increases the multiplicative depth of the
+// evaluation, and a larger WindowSize reduces this depth at the cost of more ciphertexts in the queries.
+func NewParameters(params bfv.Parameters, itemBits, labelSize, maxDegree, windowSize int) (p Parameters, err error) {
+
+	if T := params.T(); !ring.IsPrime(T) || (T-1)%uint64(2*params.N()) != 0 {
+		return Parameters{}, fmt.Errorf("cannot NewParameters: the plaintext modulus t=%d must be a prime congruent to 1 modulo 2N", T)
+	}
+
+	if itemBits < 1 || itemBits > 256 {
+		return Parameters{}, fmt.Errorf("cannot NewParameters: the number of bits of the items must be in [1, 256]")
+	}
+
+	if labelSize < 0 || maxDegree < 1 || windowSize < 1 {
+		return Parameters{}, fmt.Errorf("cannot NewParameters: the label size must be non-negative, the maximum degree and the window size must be positive")
+	}
+
+	p = Parameters{Parameters: params, ItemBits: itemBits, LabelSize: labelSize, MaxDegree: maxDegree, WindowSize: windowSize}
+
+	p.bitsPerFelt = bits.Len64(params.T()) - 1
+	p.feltsPerItem = (itemBits + p.bitsPerFelt - 1) / p.bitsPerFelt
+	p.labelFelts = (8*labelSize + p.bitsPerFelt - 1) / p.bitsPerFelt
+
+	if p.feltsPerItem > params.N() {
+		return Parameters{}, fmt.Errorf("cannot NewParameters: an item of %d bits does not fit in %d slots of %d bits", itemBits, params.N(), p.bitsPerFelt)
+	}
+
+	return
+}
+
+// FeltsPerItem returns the number of field elements, i.e. of slots, of an item.
+func (p Parameters) FeltsPerItem() int {
+	return p.feltsPerItem
+}
+
+// Bins returns the number of bins, which is the maximum number of items of the Receiver.
+func (p Parameters) Bins() int {
+	return p.N() / p.feltsPerItem
+}
+
+// LabelParts returns the number of label polynomials per partition: the field elements of a label are split in
+// parts of FeltsPerItem() elements, one part per ciphertext of the response.
+func (p Parameters) LabelParts() int {
+	return (p.labelFelts + p.feltsPerItem - 1) / p.feltsPerItem
+}
+
+// SourcePowers returns the exponents of the powers of the items encrypted in the queries, in increasing order.
+func (p Parameters) SourcePowers() (powers []int) {
+	window := 1 << p.WindowSize
+	for base := 1; base <= p.MaxDegree; base *= window {
+		for i := 1; i < window && i*base <= p.MaxDegree; i++ {
+			powers = append(powers, i*base)
+		}
+	}
+	return
+}
+
+// packBits writes the first nbBits bits of src on dst, bitsPerFelt bits per element.
+func (p Parameters) packBits(src []byte, nbBits int, dst []uint64) {
+	for k := 0; k < nbBits; k++ {
+		dst[k/p.bitsPerFelt] |= uint64(src[k>>3]>>(k&7)&1) << (k % p.bitsPerFelt)
+	}
+}
+
+// unpackBits reads 8*len(dst) bits from src, bitsPerFelt bits per element.
+func (p Parameters) unpackBits(src []uint64, dst []byte) {
+	for k := 0; k < 8*len(dst); k++ {
+		dst[k>>3] |= byte(src[k/p.bitsPerFelt]>>(k%p.bitsPerFelt)&1) << (k & 7)
+	}
+}
@@ -0,0 +1,63 @@
+package psi
+
+import (
+	"github.com/cipherflow-fhe/lattigo/ring"
+)
+
+// rootsPolynomial returns the coefficients of prod_i (x - roots[i]) mod T, in increasing degree, on a slice of
+// length at least size.
+func rootsPolynomial(roots []uint64, size int, T uint64, bredParams []uint64) (coeffs []uint64) {
+
+	coeffs = make([]uint64, size)
+	coeffs[0] = 1
+
+	for i, r := range roots {
+		// coeffs <- coeffs * (x - r)
+		for j := i + 1; j > 0; j-- {
+			coeffs[j] = ring.CRed(coeffs[j-1]+T-ring.BRed(coeffs[j], r, T, bredParams), T)
+		}
+		coeffs[0] = (T - ring.BRed(coeffs[0], r, T, bredParams)) % T
+	}
+
+	return
+}
+
+// interpolate returns the coefficients of the polynomial of degree less than len(xs) mapping the distinct xs[i] to
+// ys[i] mod T, in increasing degree, on a slice of length at least size. It computes the Lagrange interpolation
+// sum_i ys[i] / Q_i(xs[i]) * Q_i(x), where Q_i(x) = prod_{j != i} (x - xs[j]) = prod_j (x - xs[j]) / (x - xs[i]).
+func interpolate(xs, ys []uint64, size int, T uint64, bredParams []uint64) (coeffs []uint64) {
+
+	coeffs = make([]uint64, size)
+
+	n := len(xs)
+	if n == 0 {
+		return
+	}
+
+	prod := rootsPolynomial(xs, n+1, T, bredParams)
+	q := make([]uint64, n)
+
+	for i := range xs {
+
+		// q <- prod / (x - xs[i]) by synthetic division
+		q[n-1] = prod[n]
+		for j := n - 1; j > 0; j-- {
+			q[j-1] = ring.CRed(prod[j]+ring.BRed(q[j], xs[i], T, bredParams), T)
+		}
+
+		if ys[i] == 0 {
+			continue
+		}
+
+		// w = q(xs[i])
+		w := ring.EvalPolyModP(xs[i], q, T)
+
+		scale := ring.BRed(ys[i], ring.ModExp(w, T-2, T), T, bredParams)
+
+		for j := range q {
+			coeffs[j] = ring.CRed(coeffs[j]+ring.BRed(q[j], scale, T, bredParams), T)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,130 @@
+package psi
+
+import (
+	"crypto/rand"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams are insecure parameters for fast testing only.
+var testParams = bfv.ParametersLiteral{
+	LogN: 12,
+	LogQ: []int{55, 55, 55, 55},
+	LogP: []int{61},
+	T:    65537,
+}
+
+func randomItems(t *testing.T, n, size int) (items [][]byte) {
+	items = make([][]byte, n)
+	for i := range items {
+		items[i] = make([]byte, size)
+		_, err := rand.Read(items[i])
+		require.NoError(t, err)
+	}
+	return
+}
+
+func TestPSI(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping PSI tests for GOARCH=wasm")
+	}
+
+	params, err := bfv.NewParametersFromLiteral(testParams)
+	require.NoError(t, err)
+
+	sk := bfv.NewKeyGenerator(params).GenSecretKey()
+
+	// Unbalanced sets: the Receiver has 200 items, the Sender 3000, of which 50 are shared
+	receiverItems := randomItems(t, 200, 16)
+	senderItems := append(randomItems(t, 2950, 16), receiverItems[100:150]...)
+	senderLabels := randomItems(t, len(senderItems), 6)
+
+	// Shorter labels are padded with zeroes
+	senderLabels[len(senderItems)-1] = senderLabels[len(senderItems)-1][:2]
+
+	for _, tc := range []struct {
+		labelSize, maxDegree, windowSize int
+	}{
+		{0, 16, 1},
+		{6, 16, 2},
+		{6, 1, 1},
+	} {
+
+		psiParams, err := NewParameters(params, 64, tc.labelSize, tc.maxDegree, tc.windowSize)
+		require.NoError(t, err)
+
+		var labels [][]byte
+		if tc.labelSize > 0 {
+			labels = senderLabels
+		}
+
+		t.Run(fmt.Sprintf("Intersect/logN=%d/logQP=%d/LabelSize=%d/MaxDegree=%d/WindowSize=%d", params.LogN(), params.LogQP(), tc.labelSize, tc.maxDegree, tc.windowSize), func(t *testing.T) {
+
+			db, err := NewDatabase(psiParams, senderItems, labels)
+			require.NoError(t, err)
+
+			receiver := NewReceiver(psiParams, sk)
+			sender := NewSender(db, receiver.GenEvaluationKey())
+
+			query, table, err := receiver.GenQuery(receiverItems)
+			require.NoError(t, err)
+			require.Len(t, query.Powers, len(psiParams.SourcePowers()))
+
+			response, err := sender.Answer(query)
+			require.NoError(t, err)
+			require.Len(t, response.Matches, db.Partitions())
+
+			intersection, matchedLabels := receiver.Intersect(table, response)
+
+			wantIntersection, wantLabels := psiParams.PlainIntersection(receiverItems, senderItems, labels)
+			require.Equal(t, wantIntersection, intersection)
+			require.Equal(t, wantLabels, matchedLabels)
+		})
+	}
+
+	t.Run("SourcePowers", func(t *testing.T) {
+
+		psiParams, err := NewParameters(params, 64, 0, 16, 1)
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2, 4, 8, 16}, psiParams.SourcePowers())
+
+		psiParams, err = NewParameters(params, 64, 0, 16, 2)
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2, 3, 4, 8, 12, 16}, psiParams.SourcePowers())
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+
+		// Plaintext modulus not congruent to 1 modulo 2N
+		paramsT, err := bfv.NewParametersFromLiteral(bfv.ParametersLiteral{LogN: 12, LogQ: []int{55}, LogP: []int{61}, T: 65521})
+		require.NoError(t, err)
+		_, err = NewParameters(paramsT, 64, 0, 16, 1)
+		require.Error(t, err)
+
+		_, err = NewParameters(params, 0, 0, 16, 1)
+		require.Error(t, err)
+
+		_, err = NewParameters(params, 64, 0, 0, 1)
+		require.Error(t, err)
+
+		psiParams, err := NewParameters(params, 64, 6, 16, 1)
+		require.NoError(t, err)
+
+		_, err = NewDatabase(psiParams, senderItems, senderLabels[1:])
+		require.Error(t, err)
+
+		_, err = NewDatabase(psiParams, senderItems, append(senderLabels[1:], make([]byte, 7)))
+		require.Error(t, err)
+
+		_, err = NewDatabase(psiParams, append(senderItems, senderItems[0]), append(senderLabels, senderLabels[0]))
+		require.Error(t, err)
+
+		_, _, err = NewReceiver(psiParams, sk).GenQuery(randomItems(t, psiParams.Bins()+1, 16))
+		require.Error(t, err)
+	})
+}
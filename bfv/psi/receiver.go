@@ -0,0 +1,147 @@
+package psi
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Receiver is a struct to generate the queries of a set of items and to decode the intersection from the responses
+// of the Sender.
+type Receiver struct {
+	params Parameters
+	sk     *rlwe.SecretKey
+
+	encoder   bfv.Encoder
+	encryptor bfv.Encryptor
+	decryptor bfv.Decryptor
+}
+
+// NewReceiver creates a new Receiver with the secret key sk, under which the queries are encrypted.
+func NewReceiver(params Parameters, sk *rlwe.SecretKey) *Receiver {
+	return &Receiver{
+		params:    params,
+		sk:        sk,
+		encoder:   bfv.NewEncoder(params.Parameters),
+		encryptor: bfv.NewEncryptor(params.Parameters, sk),
+		decryptor: bfv.NewDecryptor(params.Parameters, sk),
+	}
+}
+
+// GenEvaluationKey generates the evaluation key that the Receiver gives to the Sender, i.e. a relinearization key.
+func (r *Receiver) GenEvaluationKey() rlwe.EvaluationKey {
+	return rlwe.EvaluationKey{Rlk: bfv.NewKeyGenerator(r.params.Parameters).GenRelinearizationKey(r.sk, 1)}
+}
+
+// GenQuery inserts the items in a new CuckooTable and returns the query for these items along with the table.
+// It returns an error if there are more items than Bins(), or if the cuckoo hashing fails, which is unlikely
+// for less than about 0.9 * Bins() items.
+func (r *Receiver) GenQuery(items [][]byte) (query *Query, table *CuckooTable, err error) {
+
+	if table, err = r.params.newCuckooTable(items); err != nil {
+		return nil, nil, fmt.Errorf("cannot GenQuery: %w", err)
+	}
+
+	F := r.params.feltsPerItem
+	T := r.params.T()
+
+	x := make([]uint64, r.params.N())
+	for bin, i := range table.table {
+		if i != -1 {
+			copy(x[bin*F:(bin+1)*F], table.items[i].felts)
+		}
+	}
+
+	powers := r.params.SourcePowers()
+
+	query = &Query{Powers: make([]*bfv.CompressedCiphertext, len(powers))}
+
+	values := make([]uint64, len(x))
+	pt := bfv.NewPlaintext(r.params.Parameters)
+
+	for j, k := range powers {
+
+		for i := range x {
+			values[i] = ring.ModExp(x[i], uint64(k), T)
+		}
+
+		r.encoder.Encode(values, pt)
+
+		query.Powers[j] = bfv.NewCompressedCiphertext(r.params.Parameters, 1, r.params.MaxLevel())
+		r.encryptor.EncryptCompressed(pt, query.Powers[j])
+	}
+
+	return
+}
+
+// Intersect decrypts the response of the Sender to the query of the items of the table and returns the indexes of
+// the items in the intersection, in increasing order, and, for labeled PSI, their labels of params.LabelSize bytes.
+func (r *Receiver) Intersect(table *CuckooTable, response *Response) (intersection []int, labels [][]byte) {
+
+	F := r.params.feltsPerItem
+
+	// [item], the partition where the item matches, -1 otherwise
+	matches := make([]int, len(table.items))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	for p := range response.Matches {
+
+		values := r.encoder.DecodeUintNew(r.decryptor.DecryptNew(response.Matches[p]))
+
+		for bin, i := range table.table {
+			if i != -1 && matches[i] == -1 && isZero(values[bin*F:(bin+1)*F]) {
+				matches[i] = p
+			}
+		}
+	}
+
+	// Decoded label ciphertexts, [partition][part]
+	decoded := make([][][]uint64, len(response.Labels))
+
+	felts := make([]uint64, r.params.LabelParts()*F)
+
+	for i, p := range matches {
+
+		if p == -1 {
+			continue
+		}
+
+		intersection = append(intersection, i)
+
+		if r.params.LabelSize == 0 {
+			continue
+		}
+
+		if decoded[p] == nil {
+			decoded[p] = make([][]uint64, len(response.Labels[p]))
+			for j := range decoded[p] {
+				decoded[p][j] = r.encoder.DecodeUintNew(r.decryptor.DecryptNew(response.Labels[p][j]))
+			}
+		}
+
+		bin := table.bin(i)
+		for j := range decoded[p] {
+			copy(felts[j*F:(j+1)*F], decoded[p][j][bin*F:(bin+1)*F])
+		}
+
+		label := make([]byte, r.params.LabelSize)
+		r.params.unpackBits(felts, label)
+		labels = append(labels, label)
+	}
+
+	return
+}
+
+// isZero returns true if all the values are zero.
+func isZero(values []uint64) bool {
+	for _, v := range values {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package psi
+
+// PlainIntersection is the reference implementation of the protocol: it computes in the clear the intersection of the
+// items of a Receiver with the items of a Sender and returns the indexes of the Receiver's items in the intersection,
+// in increasing order, and, for labeled PSI, their labels padded with zeroes to LabelSize bytes. Up to the false
+// positives of the protocol, it returns the same values as Receiver.Intersect.
+func (p Parameters) PlainIntersection(receiverItems, senderItems, senderLabels [][]byte) (intersection []int, labels [][]byte) {
+
+	index := make(map[string]int, len(senderItems))
+	for i := range senderItems {
+		index[string(senderItems[i])] = i
+	}
+
+	for i := range receiverItems {
+
+		j, ok := index[string(receiverItems[i])]
+		if !ok {
+			continue
+		}
+
+		intersection = append(intersection, i)
+
+		if p.LabelSize > 0 {
+			label := make([]byte, p.LabelSize)
+			copy(label, senderLabels[j])
+			labels = append(labels, label)
+		}
+	}
+
+	return
+}
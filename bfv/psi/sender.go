@@ -0,0 +1,269 @@
+package psi
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// Database is the set of items of a Sender, and their labels, preprocessed into the polynomials of its partitions.
+type Database struct {
+	Parameters
+
+	partitions []*partition
+	slotsIndex map[int][]int // Maps the polynomial i to the slot i
+}
+
+// partition stores the polynomials of a partition, one per slot of the bins.
+type partition struct {
+	match  []*bfv.Polynomial   // [slot], of degree MaxDegree
+	labels [][]*bfv.Polynomial // [part][slot], of degree MaxDegree-1
+}
+
+// NewDatabase hashes the items, and their labels for labeled PSI, on a new Database. The labels must be nil if
+// params.LabelSize is zero, and otherwise each label must have at most params.LabelSize bytes, shorter labels
+// being padded with zeroes. The items must be distinct.
+func NewDatabase(params Parameters, items, labels [][]byte) (db *Database, err error) {
+
+	labeled := params.LabelSize > 0
+
+	if labeled && len(labels) != len(items) {
+		return nil, fmt.Errorf("cannot NewDatabase: the number of labels (%d) does not match the number of items (%d)", len(labels), len(items))
+	}
+
+	if !labeled && labels != nil {
+		return nil, fmt.Errorf("cannot NewDatabase: labels are given but the label size is zero")
+	}
+
+	F := params.feltsPerItem
+
+	hashed := make([]hashedItem, len(items))
+	labelFelts := make([][]uint64, len(labels))
+
+	distinct := make(map[string]bool, len(items))
+
+	for i := range items {
+
+		if distinct[string(items[i])] {
+			return nil, fmt.Errorf("cannot NewDatabase: the item %d is a duplicate", i)
+		}
+		distinct[string(items[i])] = true
+
+		hashed[i] = params.hashItem(items[i])
+
+		if labeled {
+
+			if len(labels[i]) > params.LabelSize {
+				return nil, fmt.Errorf("cannot NewDatabase: the label %d has %d bytes, more than the label size (%d)", i, len(labels[i]), params.LabelSize)
+			}
+
+			labelFelts[i] = make([]uint64, params.LabelParts()*F)
+			params.packBits(labels[i], 8*len(labels[i]), labelFelts[i])
+		}
+	}
+
+	// Simple hashing: each item is inserted in each of its candidate bins, in the first partition where the bin has less
+	// than MaxDegree items and, for labeled PSI, where its field elements differ from the ones of the items of the bin.
+	var bins [][][]int // [partition][bin][item]
+
+	for i := range hashed {
+
+		for h, bin := range hashed[i].bins {
+
+			// Candidate bins of the item that are equal are filled once
+			if utils.IsInSliceInt(bin, hashed[i].bins[:h]) {
+				continue
+			}
+
+			p := 0
+			for ; p < len(bins); p++ {
+				if len(bins[p][bin]) < params.MaxDegree && !(labeled && collides(hashed, bins[p][bin], hashed[i])) {
+					break
+				}
+			}
+
+			if p == len(bins) {
+				bins = append(bins, make([][]int, params.Bins()))
+			}
+
+			bins[p][bin] = append(bins[p][bin], i)
+		}
+	}
+
+	db = &Database{Parameters: params, partitions: make([]*partition, len(bins)), slotsIndex: make(map[int][]int, params.Bins()*F)}
+
+	for i := 0; i < params.Bins()*F; i++ {
+		db.slotsIndex[i] = []int{i}
+	}
+
+	T := params.T()
+	bredParams := ring.BRedParams(T)
+
+	xs := make([]uint64, params.MaxDegree)
+	ys := make([]uint64, params.MaxDegree)
+
+	for p := range bins {
+
+		part := &partition{match: make([]*bfv.Polynomial, params.Bins()*F), labels: make([][]*bfv.Polynomial, params.LabelParts())}
+
+		for j := range part.labels {
+			part.labels[j] = make([]*bfv.Polynomial, params.Bins()*F)
+		}
+
+		for bin := range bins[p] {
+			for c := 0; c < F; c++ {
+
+				slot := bin*F + c
+
+				xs = xs[:len(bins[p][bin])]
+				for k, i := range bins[p][bin] {
+					xs[k] = hashed[i].felts[c]
+				}
+
+				part.match[slot] = bfv.NewPoly(rootsPolynomial(xs, params.MaxDegree+1, T, bredParams))
+
+				for j := range part.labels {
+
+					ys = ys[:len(xs)]
+					for k, i := range bins[p][bin] {
+						ys[k] = labelFelts[i][j*F+c]
+					}
+
+					part.labels[j][slot] = bfv.NewPoly(interpolate(xs, ys, params.MaxDegree, T, bredParams))
+				}
+			}
+		}
+
+		db.partitions[p] = part
+	}
+
+	return
+}
+
+// collides returns true if a field element of the item is equal to the one at the same position of an item of the bin.
+func collides(hashed []hashedItem, bin []int, item hashedItem) bool {
+	for _, i := range bin {
+		for c := range item.felts {
+			if hashed[i].felts[c] == item.felts[c] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Partitions returns the number of partitions of the Database, i.e. the number of match ciphertexts of a response.
+func (db *Database) Partitions() int {
+	return len(db.partitions)
+}
+
+// Query is the query of a Receiver: the encryptions of the powers x^k of the field elements of its items,
+// for k in Parameters.SourcePowers().
+type Query struct {
+	Powers []*bfv.CompressedCiphertext
+}
+
+// Response is the response of the Sender to a Query: for each partition, a ciphertext whose slots are zero at the
+// field elements of the Receiver's items that match an item of the partition and, for labeled PSI, LabelParts()
+// ciphertexts whose slots hold the field elements of the labels of the matching items.
+// All the other slots are uniformly random.
+type Response struct {
+	Matches []*bfv.Ciphertext   // [partition]
+	Labels  [][]*bfv.Ciphertext // [partition][part]
+}
+
+// Sender is a struct to answer the queries of the Receivers on a Database.
+// A Sender is not safe for concurrent use.
+type Sender struct {
+	db *Database
+
+	eval    bfv.Evaluator
+	encoder bfv.Encoder
+
+	prng utils.PRNG
+	mask uint64
+}
+
+// NewSender creates a new Sender answering the queries on the database with the evaluation key of the Receiver
+// (see Receiver.GenEvaluationKey).
+func NewSender(db *Database, evk rlwe.EvaluationKey) *Sender {
+
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+
+	return &Sender{
+		db:      db,
+		eval:    bfv.NewEvaluator(db.Parameters.Parameters, evk),
+		encoder: bfv.NewEncoder(db.Parameters.Parameters),
+		prng:    prng,
+		mask:    (1 << bits.Len64(db.T()-1)) - 1,
+	}
+}
+
+// Answer evaluates the polynomials of each partition on the query and returns the response.
+func (s *Sender) Answer(query *Query) (response *Response, err error) {
+
+	params := s.db.Parameters
+	powers := params.SourcePowers()
+
+	if len(query.Powers) != len(powers) {
+		return nil, fmt.Errorf("cannot Answer: the query has %d powers but the parameters require %d", len(query.Powers), len(powers))
+	}
+
+	pb := &bfv.PowerBasis{Value: make(map[int]*bfv.Ciphertext, len(powers))}
+	for i, k := range powers {
+		if query.Powers[i].Value.N() != params.N() || query.Powers[i].Level() != params.MaxLevel() {
+			return nil, fmt.Errorf("cannot Answer: the query does not match the parameters of the database")
+		}
+		pb.Value[k] = query.Powers[i].ToCiphertext(params.Parameters)
+	}
+
+	response = &Response{Matches: make([]*bfv.Ciphertext, len(s.db.partitions)), Labels: make([][]*bfv.Ciphertext, len(s.db.partitions))}
+
+	for p, part := range s.db.partitions {
+
+		var match *bfv.Ciphertext
+		if match, err = s.eval.EvaluatePolyVector(pb, part.match, s.encoder, s.db.slotsIndex); err != nil {
+			return nil, fmt.Errorf("cannot Answer: %w", err)
+		}
+
+		response.Labels[p] = make([]*bfv.Ciphertext, len(part.labels))
+
+		// label + r * match, with r uniform, is the label where match is zero and uniform elsewhere
+		for j := range part.labels {
+
+			if response.Labels[p][j], err = s.eval.EvaluatePolyVector(pb, part.labels[j], s.encoder, s.db.slotsIndex); err != nil {
+				return nil, fmt.Errorf("cannot Answer: %w", err)
+			}
+
+			s.eval.MulAndAdd(match, s.randomPlaintext(false), response.Labels[p][j])
+		}
+
+		// r * match, with r uniform and non-zero, is zero where match is zero and uniform elsewhere
+		response.Matches[p] = s.eval.MulNew(match, s.randomPlaintext(true))
+	}
+
+	return
+}
+
+// randomPlaintext returns a new plaintext of uniform slots modulo t, which are non-zero if nonZero is true.
+func (s *Sender) randomPlaintext(nonZero bool) *bfv.PlaintextMul {
+
+	T := s.db.T()
+
+	values := make([]uint64, s.db.N())
+	for i := range values {
+		values[i] = ring.RandUniform(s.prng, T, s.mask)
+		for nonZero && values[i] == 0 {
+			values[i] = ring.RandUniform(s.prng, T, s.mask)
+		}
+	}
+
+	return s.encoder.EncodeMulNew(values, s.db.MaxLevel())
+}
@@ -0,0 +1,64 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+)
+
+// Client is a struct to encrypt the queries on a table packed according to a Layout and to decode their results.
+// The results are decrypted by the caller, with a bfv.Decryptor or after their re-encryption by a KeySwitcher.
+type Client struct {
+	params    bfv.Parameters
+	layout    Layout
+	encoder   bfv.Encoder
+	encryptor bfv.Encryptor
+}
+
+// NewClient creates a new Client.
+func NewClient(params bfv.Parameters, layout Layout, encoder bfv.Encoder, encryptor bfv.Encryptor) *Client {
+	return &Client{params: params, layout: layout, encoder: encoder, encryptor: encryptor}
+}
+
+// EncryptEqualityQuery encrypts the value, replicated in all the slots, as the operand of Server.Filter.
+func (c *Client) EncryptEqualityQuery(value uint64) *bfv.Ciphertext {
+	values := make([]uint64, c.params.N())
+	for i := range values {
+		values[i] = value
+	}
+	return c.encryptor.EncryptNew(c.encoder.EncodeNew(values, c.params.MaxLevel()))
+}
+
+// EncryptRowQuery encrypts the selection vector of the row, which is 1 at its position and 0 elsewhere, block by block,
+// as the operand of Server.FetchRow.
+func (c *Client) EncryptRowQuery(row int) (selection []*bfv.Ciphertext, err error) {
+
+	if row < 0 || row >= c.layout.Rows {
+		return nil, fmt.Errorf("cannot EncryptRowQuery: row %d is out of the table of %d rows", row, c.layout.Rows)
+	}
+
+	block, slot := c.layout.Position(row)
+
+	values := make([]uint64, c.params.N())
+
+	selection = make([]*bfv.Ciphertext, c.layout.Blocks())
+	for i := range selection {
+		values[slot] = 0
+		if i == block {
+			values[slot] = 1
+		}
+		selection[i] = c.encryptor.EncryptNew(c.encoder.EncodeNew(values, c.params.MaxLevel()))
+	}
+
+	return
+}
+
+// DecodeScalar decodes the decrypted result of Server.Count or Server.Sum.
+func (c *Client) DecodeScalar(pt *bfv.Plaintext) uint64 {
+	return c.encoder.DecodeUintNew(pt)[0]
+}
+
+// DecodeRow decodes the decrypted result of Server.FetchRow.
+func (c *Client) DecodeRow(pt *bfv.Plaintext) []uint64 {
+	return c.encoder.DecodeUintNew(pt)[:c.layout.Columns]
+}
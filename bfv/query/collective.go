@@ -0,0 +1,73 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/dbfv"
+	"github.com/cipherflow-fhe/lattigo/drlwe"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// KeySwitcher re-encrypts the results of the queries on a table encrypted under a collective key of the dbfv package
+// for the public key of a client, with the collective public key-switching protocol of dbfv. Each party holding a share
+// of the collective secret key generates its share of the re-encryption with GenShare, and the shares of all the parties
+// are combined by KeySwitchNew.
+type KeySwitcher struct {
+	params bfv.Parameters
+	pcks   *dbfv.PCKSProtocol
+}
+
+// NewKeySwitcher creates a new KeySwitcher, where sigmaSmudging is the standard deviation of the smudging noise
+// added by each party to its share.
+func NewKeySwitcher(params bfv.Parameters, sigmaSmudging float64) *KeySwitcher {
+	return &KeySwitcher{params: params, pcks: dbfv.NewPCKSProtocol(params, sigmaSmudging)}
+}
+
+// ShallowCopy creates a shallow copy of this KeySwitcher in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// KeySwitcher can be used concurrently.
+func (ks *KeySwitcher) ShallowCopy() *KeySwitcher {
+	return &KeySwitcher{params: ks.params, pcks: ks.pcks.ShallowCopy()}
+}
+
+// GenShare returns the share of the party holding skShare to re-encrypt the result ct under the public key pkClient.
+func (ks *KeySwitcher) GenShare(skShare *rlwe.SecretKey, pkClient *rlwe.PublicKey, ct *bfv.Ciphertext) (share *drlwe.PCKSShare, err error) {
+
+	if ct.Degree() != 1 {
+		return nil, fmt.Errorf("cannot GenShare: the result must be a ciphertext of degree 1")
+	}
+
+	share = ks.pcks.PCKSProtocol.AllocateShare(ct.Level())
+	ks.pcks.GenShare(skShare, pkClient, ct.Value[1], share)
+
+	return
+}
+
+// KeySwitchNew combines the shares of all the parties for the result ct and returns ct re-encrypted under the
+// public key of the client on a new ciphertext, which the client decrypts with its secret key.
+func (ks *KeySwitcher) KeySwitchNew(ct *bfv.Ciphertext, shares []*drlwe.PCKSShare) (ctOut *bfv.Ciphertext, err error) {
+
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("cannot KeySwitchNew: there must be at least one share")
+	}
+
+	if ct.Degree() != 1 {
+		return nil, fmt.Errorf("cannot KeySwitchNew: the result must be a ciphertext of degree 1")
+	}
+
+	combined := ks.pcks.PCKSProtocol.AllocateShare(ct.Level())
+	for i, share := range shares {
+
+		if share.Value[0].Level() != ct.Level() || share.Value[1].Level() != ct.Level() {
+			return nil, fmt.Errorf("cannot KeySwitchNew: the level of the share %d does not match the level of the result (%d)", i, ct.Level())
+		}
+
+		ks.pcks.AggregateShare(combined, share, combined)
+	}
+
+	ctOut = bfv.NewCiphertextLvl(ks.params, 1, ct.Level())
+	ks.pcks.KeySwitch(ct, combined, ctOut)
+
+	return
+}
@@ -0,0 +1,204 @@
+package query
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/drlwe"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams are insecure parameters for fast testing only, with a prime plaintext modulus t = 1 mod 2N.
+var testParams = bfv.ParametersLiteral{
+	LogN: 10,
+	LogQ: []int{60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60},
+	LogP: []int{61, 61},
+	H:    64,
+	T:    12289,
+}
+
+func TestQuery(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping query tests for GOARCH=wasm")
+	}
+
+	params, err := bfv.NewParametersFromLiteral(testParams)
+	require.NoError(t, err)
+
+	// The secret key of the table is shared among two parties, and the results are re-encrypted
+	// under the key of the client with the collective public key-switching protocol.
+	parties := 2
+	kgen := bfv.NewKeyGenerator(params)
+	skShares := make([]*rlwe.SecretKey, parties)
+	sk := bfv.NewSecretKey(params)
+	for i := range skShares {
+		skShares[i] = kgen.GenSecretKey()
+		params.RingQP().AddLvl(params.QCount()-1, params.PCount()-1, sk.Value, skShares[i].Value, sk.Value)
+	}
+	pk := kgen.GenPublicKey(sk)
+
+	skClient, pkClient := kgen.GenKeyPair()
+
+	// 1500 rows on 2 blocks of 1024 slots, the last one being partially filled
+	layout, err := NewLayout(params, 1500, 3)
+	require.NoError(t, err)
+
+	rtks := kgen.GenRotationKeysForRotations(layout.Rotations(), true, sk)
+	eval := bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1), Rtks: rtks})
+
+	encoder := bfv.NewEncoder(params)
+	encryptor := bfv.NewEncryptor(params, pk)
+	decryptor := bfv.NewDecryptor(params, sk)
+
+	client := NewClient(params, layout, encoder, encryptor)
+
+	server, err := NewServer(params, layout, encoder, eval)
+	require.NoError(t, err)
+
+	T := params.T()
+
+	prng, err := utils.NewPRNG()
+	require.NoError(t, err)
+	sampler := ring.NewUniformSampler(prng, params.RingT())
+
+	// Column 0: keys in [0, 16), column 1: uniform values, column 2: unique identifiers
+	values := make([][]uint64, layout.Rows)
+	for i := range values {
+		values[i] = []uint64{sampler.ReadNew().Coeffs[0][0] % 16, sampler.ReadNew().Coeffs[0][0], uint64(i) + 100}
+	}
+
+	encodedTable, err := EncodeTable(params, layout, encoder, values)
+	require.NoError(t, err)
+
+	encryptedTable, err := EncryptTable(params, layout, encoder, encryptor, values)
+	require.NoError(t, err)
+
+	decodeScalar := func(ct *bfv.Ciphertext) uint64 {
+		return client.DecodeScalar(decryptor.DecryptNew(ct))
+	}
+
+	for _, table := range []*Table{encodedTable, encryptedTable} {
+
+		name := fmt.Sprintf("logN=%d/logQP=%d/T=%d/encrypted=%t", params.LogN(), params.LogQP(), T, table.IsEncrypted())
+
+		t.Run("Filter&Count&Sum/"+name, func(t *testing.T) {
+
+			// 0 also matches the padding slots, which must be excluded
+			for _, key := range []uint64{0, 7} {
+
+				var count, sum uint64
+				for _, row := range values {
+					if row[0] == key {
+						count++
+						sum = (sum + row[1]) % T
+					}
+				}
+
+				mask, err := server.Filter(table, 0, client.EncryptEqualityQuery(key))
+				require.NoError(t, err)
+
+				ct, err := server.Count(mask)
+				require.NoError(t, err)
+				require.Equal(t, count, decodeScalar(ct))
+
+				ct, err = server.Sum(table, 1, mask)
+				require.NoError(t, err)
+				require.Equal(t, sum, decodeScalar(ct))
+			}
+		})
+
+		t.Run("FetchRow/"+name, func(t *testing.T) {
+
+			for _, row := range []int{3, 1024, 1499} {
+
+				selection, err := client.EncryptRowQuery(row)
+				require.NoError(t, err)
+
+				ct, err := server.FetchRow(table, selection)
+				require.NoError(t, err)
+				require.Equal(t, values[row], client.DecodeRow(decryptor.DecryptNew(ct)))
+			}
+		})
+
+		t.Run("FetchRowWhere/"+name, func(t *testing.T) {
+
+			row := 1234
+
+			mask, err := server.Filter(table, 2, client.EncryptEqualityQuery(values[row][2]))
+			require.NoError(t, err)
+
+			ct, err := server.FetchRow(table, mask)
+			require.NoError(t, err)
+			require.Equal(t, values[row], client.DecodeRow(decryptor.DecryptNew(ct)))
+		})
+	}
+
+	t.Run("Sum/NoMask", func(t *testing.T) {
+
+		var sum uint64
+		for _, row := range values {
+			sum = (sum + row[1]) % T
+		}
+
+		ct, err := server.Sum(encryptedTable, 1, nil)
+		require.NoError(t, err)
+		require.Equal(t, sum, decodeScalar(ct))
+
+		_, err = server.Sum(encodedTable, 1, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("CollectiveDecryption", func(t *testing.T) {
+
+		selection, err := client.EncryptRowQuery(42)
+		require.NoError(t, err)
+
+		ct, err := server.FetchRow(encryptedTable, selection)
+		require.NoError(t, err)
+
+		ks := NewKeySwitcher(params, 3.19)
+		shares := make([]*drlwe.PCKSShare, parties)
+		for i := range shares {
+			shares[i], err = ks.GenShare(skShares[i], pkClient, ct)
+			require.NoError(t, err)
+		}
+
+		ctClient, err := ks.KeySwitchNew(ct, shares)
+		require.NoError(t, err)
+
+		require.Equal(t, values[42], client.DecodeRow(bfv.NewDecryptor(params, skClient).DecryptNew(ctClient)))
+
+		_, err = ks.KeySwitchNew(ct, nil)
+		require.Error(t, err)
+
+		_, err = ks.KeySwitchNew(bfv.NewCiphertextLvl(params, 1, ct.Level()-1), shares)
+		require.Error(t, err)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+
+		_, err := NewLayout(params, 0, 1)
+		require.Error(t, err)
+
+		_, err = NewLayout(params, 1, params.N()+1)
+		require.Error(t, err)
+
+		_, err = server.Filter(encodedTable, 3, client.EncryptEqualityQuery(0))
+		require.Error(t, err)
+
+		_, err = client.EncryptRowQuery(layout.Rows)
+		require.Error(t, err)
+
+		_, err = EncodeTable(params, layout, encoder, values[1:])
+		require.Error(t, err)
+
+		_, err = NewEncryptedTable(layout, make([][]*bfv.Ciphertext, layout.Columns))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,207 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	"github.com/cipherflow-fhe/lattigo/bfv/comparison"
+)
+
+// Server is a struct to evaluate encrypted queries on tables packed according to a Layout.
+// It requires an evaluator with the relinearization key and the rotation keys for Layout.Rotations() and
+// the row rotation, and a prime plaintext modulus t for the equality filters.
+//
+// All the results are computed modulo t: the counts and the sums wrap around if they exceed t-1.
+type Server struct {
+	*comparison.Evaluator
+
+	params bfv.Parameters
+	layout Layout
+
+	validMask   *bfv.PlaintextMul   // 1 on the slots of the last block mapped to a row, nil if they all are
+	unitVectors []*bfv.PlaintextMul // unitVectors[c] is 1 on the slot c and 0 elsewhere
+}
+
+// NewServer creates a new Server for the tables packed according to the layout.
+func NewServer(params bfv.Parameters, layout Layout, encoder bfv.Encoder, eval bfv.Evaluator) (*Server, error) {
+
+	cmp, err := comparison.NewEvaluator(params, eval)
+	if err != nil {
+		return nil, fmt.Errorf("cannot NewServer: %w", err)
+	}
+
+	s := &Server{Evaluator: cmp, params: params, layout: layout}
+
+	values := make([]uint64, params.N())
+
+	if last := layout.Rows % layout.Slots; last != 0 {
+		for i := 0; i < last; i++ {
+			values[i] = 1
+		}
+		s.validMask = encoder.EncodeMulNew(values, params.MaxLevel())
+	}
+
+	s.unitVectors = make([]*bfv.PlaintextMul, layout.Columns)
+	for c := range s.unitVectors {
+		for i := range values {
+			values[i] = 0
+		}
+		values[c] = 1
+		s.unitVectors[c] = encoder.EncodeMulNew(values, params.MaxLevel())
+	}
+
+	return s, nil
+}
+
+// Filter evaluates the equality filter column == value on the rows of the table, where value is encrypted in
+// all the slots of query (see Client.EncryptEqualityQuery), and returns the encrypted mask of the rows that
+// satisfy it, block by block, with 1 on these rows and 0 elsewhere.
+// Its multiplicative depth is the one of comparison.Evaluator.Equal.
+func (s *Server) Filter(table *Table, column int, query *bfv.Ciphertext) (mask []*bfv.Ciphertext, err error) {
+
+	if err = s.checkTable(table, column); err != nil {
+		return nil, fmt.Errorf("cannot Filter: %w", err)
+	}
+
+	mask = make([]*bfv.Ciphertext, s.layout.Blocks())
+
+	for block := range mask {
+
+		var op bfv.Operand
+		if table.IsEncrypted() {
+			op = table.ciphertexts[column][block]
+		} else {
+			op = table.plaintexts[column][block]
+		}
+
+		if mask[block], err = s.Equal(query, op); err != nil {
+			return nil, fmt.Errorf("cannot Filter: %w", err)
+		}
+	}
+
+	// The padding slots of the last block are equal to 0 and must be removed from the mask
+	if s.validMask != nil {
+		last := mask[len(mask)-1]
+		s.Mul(last, s.validMask, last)
+	}
+
+	return
+}
+
+// Count returns the number of rows selected by the mask (see Filter), replicated in all the slots.
+func (s *Server) Count(mask []*bfv.Ciphertext) (ctOut *bfv.Ciphertext, err error) {
+
+	if len(mask) != s.layout.Blocks() {
+		return nil, fmt.Errorf("cannot Count: the number of blocks of the mask (%d) does not match the layout (%d)", len(mask), s.layout.Blocks())
+	}
+
+	ctOut = mask[0].CopyNew()
+	for _, ct := range mask[1:] {
+		s.Add(ctOut, ct, ctOut)
+	}
+
+	s.InnerSum(ctOut, ctOut)
+
+	return
+}
+
+// Sum returns the sum of the values of the column on the rows selected by the mask (see Filter), replicated
+// in all the slots. The mask can be nil to sum all the rows of an encrypted table.
+// With a mask, its multiplicative depth is one more than the depth of the mask for an encrypted table.
+func (s *Server) Sum(table *Table, column int, mask []*bfv.Ciphertext) (ctOut *bfv.Ciphertext, err error) {
+
+	if err = s.checkTable(table, column); err != nil {
+		return nil, fmt.Errorf("cannot Sum: %w", err)
+	}
+
+	if mask == nil {
+
+		if !table.IsEncrypted() {
+			return nil, fmt.Errorf("cannot Sum: a mask is required for a table in plaintext")
+		}
+
+		ctOut = table.ciphertexts[column][0].CopyNew()
+		for _, ct := range table.ciphertexts[column][1:] {
+			s.Add(ctOut, ct, ctOut)
+		}
+
+	} else if ctOut, err = s.dot(table, column, mask); err != nil {
+		return nil, fmt.Errorf("cannot Sum: %w", err)
+	}
+
+	s.InnerSum(ctOut, ctOut)
+
+	return
+}
+
+// FetchRow returns the row of the table selected by the selection vector (see Client.EncryptRowQuery), with the value
+// of the column c in the slot c and 0 in the slots larger than the number of columns. The selection can also be the
+// mask of an equality filter on a column of unique values, which fetches the row with the queried value.
+// Its multiplicative depth is one more than the depth of the selection for an encrypted table, and it evaluates one
+// InnerSum per column.
+func (s *Server) FetchRow(table *Table, selection []*bfv.Ciphertext) (ctOut *bfv.Ciphertext, err error) {
+
+	if err = s.checkTable(table, 0); err != nil {
+		return nil, fmt.Errorf("cannot FetchRow: %w", err)
+	}
+
+	var ct *bfv.Ciphertext
+	for column := 0; column < s.layout.Columns; column++ {
+
+		if ct, err = s.dot(table, column, selection); err != nil {
+			return nil, fmt.Errorf("cannot FetchRow: %w", err)
+		}
+
+		// The selected value is replicated in all the slots and then moved to the slot of the column
+		s.InnerSum(ct, ct)
+		s.Mul(ct, s.unitVectors[column], ct)
+
+		if ctOut == nil {
+			ctOut = ct
+		} else {
+			s.Add(ctOut, ct, ctOut)
+		}
+	}
+
+	return
+}
+
+// dot returns the slot-wise product of the column of the table with the selection, summed over the blocks.
+func (s *Server) dot(table *Table, column int, selection []*bfv.Ciphertext) (ctOut *bfv.Ciphertext, err error) {
+
+	if len(selection) != s.layout.Blocks() {
+		return nil, fmt.Errorf("the number of blocks of the selection (%d) does not match the layout (%d)", len(selection), s.layout.Blocks())
+	}
+
+	for block, sel := range selection {
+
+		var ct *bfv.Ciphertext
+		if table.IsEncrypted() {
+			ct = s.RelinearizeNew(s.MulNew(sel, table.ciphertexts[column][block]))
+		} else {
+			ct = s.MulNew(sel, table.plaintextsMul[column][block])
+		}
+
+		if ctOut == nil {
+			ctOut = ct
+		} else {
+			s.Add(ctOut, ct, ctOut)
+		}
+	}
+
+	return
+}
+
+// checkTable returns an error if the table does not match the layout of the Server or if the column is out of range.
+func (s *Server) checkTable(table *Table, column int) error {
+
+	if table.Layout != s.layout {
+		return fmt.Errorf("the layout of the table does not match the layout of the server")
+	}
+
+	if column < 0 || column >= s.layout.Columns {
+		return fmt.Errorf("column %d is out of the table of %d columns", column, s.layout.Columns)
+	}
+
+	return nil
+}
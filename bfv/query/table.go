@@ -0,0 +1,178 @@
+// Package query implements SELECT-style queries on tables of integers modulo t packed in the slots of BFV plaintexts
+// or ciphertexts: equality filters, counts, sums and PIR-style row fetches.
+//
+// A table of Rows x Columns values is stored column by column (see Layout): each column is split into blocks of N
+// consecutive rows, and each block is a plaintext (for a table held in clear by the server) or a ciphertext (for an
+// encrypted table). A Client encrypts the queries and decodes the results, and a Server evaluates the queries with a
+// bfv.Evaluator. The queries and the results are ordinary BFV ciphertexts, so that the table and the queries can as
+// well be encrypted under a collective public key of the dbfv package, the results being then re-encrypted under
+// the public key of the client by a KeySwitcher.
+//
+// The equality filters are evaluated with the comparison package, whose depth of ceil(log2(t-1)) and cost in O(sqrt(t))
+// ciphertext multiplications restrict the filters to small prime plaintext moduli t, and hence the values of the table
+// to a small range. The counts and the sums are computed modulo the same t.
+package query
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+)
+
+// Layout describes how a table of Rows x Columns values is packed in the slots of BFV plaintexts.
+//
+// Each column is stored in Blocks() = ceil(Rows/N) plaintexts: the row r is in the block r / N, at the slot r % N
+// of the encoding of bfv.Encoder, where the slots [0, N/2) and [N/2, N) are the two rows of the 2 x N/2 matrix of
+// the slots. The slots of the last block that are not mapped to a row are set to zero and are excluded from the
+// results of the queries.
+//
+// The results of the aggregations (Count and Sum) are replicated in all the slots, and the result of a row fetch
+// stores the value of the column c in the slot c, which requires Columns <= N.
+type Layout struct {
+	Rows    int
+	Columns int
+	Slots   int // Number of slots per plaintext, equal to N
+}
+
+// NewLayout creates a new Layout for a table of rows x columns values with the parameters params.
+func NewLayout(params bfv.Parameters, rows, columns int) (Layout, error) {
+
+	if rows < 1 || columns < 1 {
+		return Layout{}, fmt.Errorf("cannot NewLayout: the number of rows and columns must be positive")
+	}
+
+	if columns > params.N() {
+		return Layout{}, fmt.Errorf("cannot NewLayout: the number of columns (%d) cannot exceed the number of slots (%d)", columns, params.N())
+	}
+
+	return Layout{Rows: rows, Columns: columns, Slots: params.N()}, nil
+}
+
+// Blocks returns the number of plaintexts per column.
+func (l Layout) Blocks() int {
+	return (l.Rows + l.Slots - 1) / l.Slots
+}
+
+// Position returns the block and the slot of the row.
+func (l Layout) Position(row int) (block, slot int) {
+	return row / l.Slots, row % l.Slots
+}
+
+// Rotations returns the column rotations required by the Server, which sums the slots with bfv.Evaluator.InnerSum.
+// The row rotation is also required: the rotation keys can be generated with
+// KeyGenerator.GenRotationKeysForRotations(layout.Rotations(), true, sk).
+func (l Layout) Rotations() (rotations []int) {
+	for k := 1; k < l.Slots>>1; k <<= 1 {
+		rotations = append(rotations, k)
+	}
+	return
+}
+
+// Table is a table of integers modulo t packed according to a Layout, either in plaintexts or in ciphertexts.
+type Table struct {
+	Layout
+
+	// [column][block], for a table in plaintext
+	plaintexts    [][]*bfv.Plaintext
+	plaintextsMul [][]*bfv.PlaintextMul
+
+	// [column][block], for an encrypted table
+	ciphertexts [][]*bfv.Ciphertext
+}
+
+// EncodeTable encodes the values, given row by row and reduced modulo t, on a new Table in plaintext.
+// Each block is stored both as a bfv.Plaintext, for the equality tests, and as a bfv.PlaintextMul, for
+// the products with the encrypted queries.
+func EncodeTable(params bfv.Parameters, layout Layout, encoder bfv.Encoder, values [][]uint64) (table *Table, err error) {
+
+	table = &Table{Layout: layout}
+	table.plaintexts = make([][]*bfv.Plaintext, layout.Columns)
+	table.plaintextsMul = make([][]*bfv.PlaintextMul, layout.Columns)
+
+	err = layout.forEachBlock(values, func(column, block int, slots []uint64) {
+		if block == 0 {
+			table.plaintexts[column] = make([]*bfv.Plaintext, layout.Blocks())
+			table.plaintextsMul[column] = make([]*bfv.PlaintextMul, layout.Blocks())
+		}
+		table.plaintexts[column][block] = encoder.EncodeNew(slots, params.MaxLevel())
+		table.plaintextsMul[column][block] = encoder.EncodeMulNew(slots, params.MaxLevel())
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot EncodeTable: %w", err)
+	}
+
+	return
+}
+
+// EncryptTable encodes and encrypts the values, given row by row and reduced modulo t, on a new encrypted Table.
+func EncryptTable(params bfv.Parameters, layout Layout, encoder bfv.Encoder, encryptor bfv.Encryptor, values [][]uint64) (table *Table, err error) {
+
+	columns := make([][]*bfv.Ciphertext, layout.Columns)
+
+	err = layout.forEachBlock(values, func(column, block int, slots []uint64) {
+		if block == 0 {
+			columns[column] = make([]*bfv.Ciphertext, layout.Blocks())
+		}
+		columns[column][block] = encryptor.EncryptNew(encoder.EncodeNew(slots, params.MaxLevel()))
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot EncryptTable: %w", err)
+	}
+
+	return &Table{Layout: layout, ciphertexts: columns}, nil
+}
+
+// NewEncryptedTable creates a new encrypted Table from ciphertexts given column by column and block by block and
+// packed according to the layout, for example encrypted by several parties under a collective public key.
+func NewEncryptedTable(layout Layout, columns [][]*bfv.Ciphertext) (*Table, error) {
+
+	if len(columns) != layout.Columns {
+		return nil, fmt.Errorf("cannot NewEncryptedTable: the number of columns (%d) does not match the layout (%d)", len(columns), layout.Columns)
+	}
+
+	for i := range columns {
+		if len(columns[i]) != layout.Blocks() {
+			return nil, fmt.Errorf("cannot NewEncryptedTable: the number of blocks of the column %d (%d) does not match the layout (%d)", i, len(columns[i]), layout.Blocks())
+		}
+	}
+
+	return &Table{Layout: layout, ciphertexts: columns}, nil
+}
+
+// IsEncrypted returns true if the table is encrypted.
+func (t *Table) IsEncrypted() bool {
+	return t.ciphertexts != nil
+}
+
+// forEachBlock calls f on the slots of each block of each column of the values, given row by row.
+func (l Layout) forEachBlock(values [][]uint64, f func(column, block int, slots []uint64)) error {
+
+	if len(values) != l.Rows {
+		return fmt.Errorf("the number of rows (%d) does not match the layout (%d)", len(values), l.Rows)
+	}
+
+	for i := range values {
+		if len(values[i]) != l.Columns {
+			return fmt.Errorf("the number of columns of the row %d (%d) does not match the layout (%d)", i, len(values[i]), l.Columns)
+		}
+	}
+
+	slots := make([]uint64, l.Slots)
+
+	for column := 0; column < l.Columns; column++ {
+		for block := 0; block < l.Blocks(); block++ {
+			for slot := range slots {
+				if row := block*l.Slots + slot; row < l.Rows {
+					slots[slot] = values[row][column]
+				} else {
+					slots[slot] = 0
+				}
+			}
+			f(column, block, slots)
+		}
+	}
+
+	return nil
+}
@@ -76,8 +76,7 @@ func NewRNSScaler(ringQ *ring.Ring, T uint64) (rnss *RNSScaler) {
 
 			bigQ.Mul(bigQ, ring.NewUint(ringQ.Modulus[i]))
 
-			rnss.qInv[i] = tmp.Mod(bigQ, TBig).Uint64()
-			rnss.qInv[i] = ring.ModExp(rnss.qInv[i], T-2, T)
+			rnss.qInv[i] = tmp.ModInverse(tmp.Mod(bigQ, TBig), TBig).Uint64()
 			if !rnss.tPowOf2 {
 				rnss.qInv[i] = ring.MForm(rnss.qInv[i], T, bredParams)
 			}
@@ -0,0 +1,464 @@
+package bgv
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testString(opname string, p Parameters, lvl int) string {
+	return fmt.Sprintf("%s/LogN=%d/logQP=%d/logT=%d/#Q=%d/#P=%d/lvl=%d", opname, p.LogN(), p.LogQP(), p.LogT(), p.QCount(), p.PCount(), lvl)
+}
+
+type testContext struct {
+	params      Parameters
+	ringQ       *ring.Ring
+	ringT       *ring.Ring
+	prng        utils.PRNG
+	uSampler    *ring.UniformSampler
+	encoder     Encoder
+	kgen        rlwe.KeyGenerator
+	sk          *rlwe.SecretKey
+	pk          *rlwe.PublicKey
+	rlk         *rlwe.RelinearizationKey
+	encryptorPk Encryptor
+	encryptorSk Encryptor
+	decryptor   Decryptor
+	evaluator   Evaluator
+	testLevel   []int
+}
+
+var (
+	// TESTN12QP297 is a set of test parameters with a plaintext modulus t = 1 mod 2N.
+	TESTN12QP297 = ParametersLiteral{
+		LogN: 12,
+		LogQ: []int{55, 45, 45, 45, 45},
+		LogP: []int{61},
+		T:    0x10001,
+	}
+
+	// TESTN12QP190NoP is a set of test parameters without modulus P, for which the key-switching
+	// error must be kept below q_0/t with a power of two decomposition.
+	TESTN12QP190NoP = ParametersLiteral{
+		LogN:     12,
+		LogQ:     []int{55, 45, 45, 45},
+		Pow2Base: 8,
+		T:        0x10001,
+	}
+
+	// TestParams is a set of test parameters for BGV.
+	TestParams = []ParametersLiteral{TESTN12QP297, TESTN12QP190NoP}
+)
+
+func TestBGV(t *testing.T) {
+
+	for _, p := range TestParams {
+
+		params, err := NewParametersFromLiteral(p)
+		require.NoError(t, err)
+
+		tc, err := genTestParams(params)
+		require.NoError(t, err)
+
+		for _, testSet := range []func(tc *testContext, t *testing.T){
+			testParameters,
+			testEncoder,
+			testEncryptor,
+			testEvaluator,
+			testEvaluatorRotate,
+			testMarshaller,
+		} {
+			testSet(tc, t)
+			runtime.GC()
+		}
+	}
+}
+
+func genTestParams(params Parameters) (tc *testContext, err error) {
+
+	tc = new(testContext)
+	tc.params = params
+
+	if tc.prng, err = utils.NewPRNG(); err != nil {
+		return nil, err
+	}
+
+	tc.ringQ = params.RingQ()
+	tc.ringT = params.RingT()
+
+	tc.uSampler = ring.NewUniformSampler(tc.prng, tc.ringT)
+	tc.kgen = NewKeyGenerator(tc.params)
+	tc.sk, tc.pk = tc.kgen.GenKeyPair()
+
+	tc.rlk = tc.kgen.GenRelinearizationKey(tc.sk, 2)
+
+	tc.encoder = NewEncoder(tc.params)
+	tc.encryptorPk = NewEncryptor(tc.params, tc.pk)
+	tc.encryptorSk = NewEncryptor(tc.params, tc.sk)
+	tc.decryptor = NewDecryptor(tc.params, tc.sk)
+	tc.evaluator = NewEvaluator(tc.params, rlwe.EvaluationKey{Rlk: tc.rlk})
+
+	tc.testLevel = []int{params.MaxLevel(), 1, 0}
+
+	return
+}
+
+func newTestVectorsLvl(level int, scale uint64, tc *testContext, encryptor Encryptor) (values *ring.Poly, plaintext *Plaintext, ciphertext *Ciphertext) {
+	values = tc.uSampler.ReadNew()
+	plaintext = tc.encoder.EncodeNew(values.Coeffs[0], level, scale)
+	if encryptor != nil {
+		ciphertext = encryptor.EncryptNew(plaintext)
+	}
+	return values, plaintext, ciphertext
+}
+
+func verifyTestVectors(tc *testContext, decryptor Decryptor, values *ring.Poly, element Operand, t *testing.T) {
+
+	var valuesTest []uint64
+
+	switch el := element.(type) {
+	case *Plaintext:
+		valuesTest = tc.encoder.DecodeUintNew(el)
+	case *Ciphertext:
+		valuesTest = tc.encoder.DecodeUintNew(decryptor.DecryptNew(el))
+	default:
+		t.Error("invalid test object to verify")
+	}
+
+	require.True(t, utils.EqualSliceUint64(values.Coeffs[0], valuesTest))
+}
+
+func testParameters(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Parameters/NewParameters", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+		_, err := NewParameters(tc.params.Parameters, tc.params.Q()[0])
+		require.Error(t, err)
+		_, err = NewParameters(tc.params.Parameters, 1)
+		require.Error(t, err)
+	})
+
+	t.Run(testString("Parameters/QiInvModT", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+		for i, qi := range tc.params.Q() {
+			require.Equal(t, uint64(1), ring.BRed(qi%tc.params.T(), tc.params.QiInvModT(i), tc.params.T(), tc.ringT.BredParams[0]))
+		}
+	})
+}
+
+func testEncoder(tc *testContext, t *testing.T) {
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Encoder/Encode&DecodeUint", tc.params, lvl), func(t *testing.T) {
+			values, plaintext, _ := newTestVectorsLvl(lvl, 7, tc, nil)
+			verifyTestVectors(tc, nil, values, plaintext, t)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Encoder/Encode&DecodeInt", tc.params, lvl), func(t *testing.T) {
+
+			T := tc.params.T()
+			values := make([]int64, tc.params.N())
+			for i := range values {
+				values[i] = int64(utils.RandUint64()%(T-1)) - int64(T>>1)
+			}
+
+			plaintext := tc.encoder.EncodeNew(values, lvl, 3)
+			require.Equal(t, values, tc.encoder.DecodeIntNew(plaintext))
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Encoder/EncodeCoeffs&DecodeCoeffs", tc.params, lvl), func(t *testing.T) {
+			values := tc.uSampler.ReadNew()
+			plaintext := tc.encoder.EncodeCoeffsNew(values.Coeffs[0], lvl, 5)
+			require.Equal(t, values.Coeffs[0], tc.encoder.DecodeCoeffsUintNew(plaintext))
+		})
+	}
+}
+
+func testEncryptor(tc *testContext, t *testing.T) {
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Encryptor/EncryptFromPk", tc.params, lvl), func(t *testing.T) {
+			values, _, ciphertext := newTestVectorsLvl(lvl, 1, tc, tc.encryptorPk)
+			require.Equal(t, lvl, ciphertext.Level())
+			verifyTestVectors(tc, tc.decryptor, values, ciphertext, t)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Encryptor/EncryptFromSk", tc.params, lvl), func(t *testing.T) {
+			values, _, ciphertext := newTestVectorsLvl(lvl, 11, tc, tc.encryptorSk)
+			verifyTestVectors(tc, tc.decryptor, values, ciphertext, t)
+		})
+	}
+}
+
+func testEvaluator(tc *testContext, t *testing.T) {
+
+	ringT := tc.ringT
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Evaluator/Add/op1=Ciphertext", tc.params, lvl), func(t *testing.T) {
+
+			values0, _, ciphertext0 := newTestVectorsLvl(lvl, 3, tc, tc.encryptorPk)
+			values1, _, ciphertext1 := newTestVectorsLvl(lvl, 5, tc, tc.encryptorSk)
+
+			tc.evaluator.Add(ciphertext0, ciphertext1, ciphertext0)
+			ringT.Add(values0, values1, values0)
+
+			require.Equal(t, uint64(3), ciphertext0.Scale)
+			verifyTestVectors(tc, tc.decryptor, values0, ciphertext0, t)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Evaluator/AddNew/op1=Plaintext", tc.params, lvl), func(t *testing.T) {
+
+			values0, _, ciphertext0 := newTestVectorsLvl(lvl, 1, tc, tc.encryptorPk)
+			values1, plaintext1, _ := newTestVectorsLvl(tc.params.MaxLevel(), 9, tc, nil)
+
+			ciphertext2 := tc.evaluator.AddNew(ciphertext0, plaintext1)
+			ringT.Add(values0, values1, values0)
+
+			require.Equal(t, lvl, ciphertext2.Level())
+			verifyTestVectors(tc, tc.decryptor, values0, ciphertext2, t)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Evaluator/Sub", tc.params, lvl), func(t *testing.T) {
+
+			values0, _, ciphertext0 := newTestVectorsLvl(lvl, 2, tc, tc.encryptorPk)
+			values1, _, ciphertext1 := newTestVectorsLvl(lvl, 7, tc, tc.encryptorPk)
+
+			ciphertext2 := tc.evaluator.SubNew(ciphertext0, ciphertext1)
+			ringT.Sub(values0, values1, values0)
+			verifyTestVectors(tc, tc.decryptor, values0, ciphertext2, t)
+
+			tc.evaluator.Neg(ciphertext2, ciphertext2)
+			ringT.MulScalar(values0, tc.params.T()-1, values0)
+			verifyTestVectors(tc, tc.decryptor, values0, ciphertext2, t)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Evaluator/AddScalar&MulScalar", tc.params, lvl), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectorsLvl(lvl, 13, tc, tc.encryptorPk)
+
+			scalar := tc.params.T() - 5
+
+			tc.evaluator.AddScalar(ciphertext, scalar, ciphertext)
+			ringT.AddScalar(values, scalar, values)
+			verifyTestVectors(tc, tc.decryptor, values, ciphertext, t)
+
+			ciphertext = tc.evaluator.MulScalarNew(ciphertext, scalar)
+			ringT.MulScalar(values, scalar, values)
+			verifyTestVectors(tc, tc.decryptor, values, ciphertext, t)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Evaluator/MulScalarAndAdd", tc.params, lvl), func(t *testing.T) {
+
+			values0, _, ciphertext0 := newTestVectorsLvl(lvl, 3, tc, tc.encryptorPk)
+			values1, _, ciphertext1 := newTestVectorsLvl(lvl, 17, tc, tc.encryptorPk)
+
+			tc.evaluator.MulScalarAndAdd(ciphertext0, 42, ciphertext1)
+			ringT.MulScalarAndAdd(values0, 42, values1)
+
+			require.Equal(t, uint64(17), ciphertext1.Scale)
+			verifyTestVectors(tc, tc.decryptor, values1, ciphertext1, t)
+		})
+	}
+
+	for _, lvl := range tc.testLevel[:2] {
+		t.Run(testString("Evaluator/Mul&Relinearize", tc.params, lvl), func(t *testing.T) {
+
+			values0, _, ciphertext0 := newTestVectorsLvl(lvl, 1, tc, tc.encryptorPk)
+			values1, _, ciphertext1 := newTestVectorsLvl(lvl, 1, tc, tc.encryptorPk)
+
+			receiver := tc.evaluator.MulNew(ciphertext0, ciphertext1)
+			ringT.MulCoeffs(values0, values1, values0)
+
+			require.Equal(t, lvl-1, receiver.Level())
+			require.Equal(t, 2, receiver.Degree())
+			verifyTestVectors(tc, tc.decryptor, values0, receiver, t)
+
+			tc.evaluator.Relinearize(receiver, receiver)
+			require.Equal(t, 1, receiver.Degree())
+			verifyTestVectors(tc, tc.decryptor, values0, receiver, t)
+		})
+	}
+
+	t.Run(testString("Evaluator/Mul/Chain", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsLvl(tc.params.MaxLevel(), 1, tc, tc.encryptorPk)
+
+		valuesWant := values.CopyNew()
+		for ciphertext.Level() > 0 {
+			tc.evaluator.Relinearize(tc.evaluator.MulNew(ciphertext, ciphertext), ciphertext)
+			ringT.MulCoeffs(valuesWant, valuesWant, valuesWant)
+			verifyTestVectors(tc, tc.decryptor, valuesWant, ciphertext, t)
+		}
+	})
+
+	t.Run(testString("Evaluator/Mul/Degree2", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		values0, _, ciphertext0 := newTestVectorsLvl(tc.params.MaxLevel(), 1, tc, tc.encryptorPk)
+		values1, _, ciphertext1 := newTestVectorsLvl(tc.params.MaxLevel(), 1, tc, tc.encryptorPk)
+
+		tc.evaluator.Mul(ciphertext0, ciphertext1, ciphertext0)
+		tc.evaluator.Mul(ciphertext0, ciphertext1, ciphertext0)
+		ringT.MulCoeffs(values0, values1, values0)
+		ringT.MulCoeffs(values0, values1, values0)
+
+		require.Equal(t, 3, ciphertext0.Degree())
+		verifyTestVectors(tc, tc.decryptor, values0, ciphertext0, t)
+
+		tc.evaluator.Relinearize(ciphertext0, ciphertext0)
+		verifyTestVectors(tc, tc.decryptor, values0, ciphertext0, t)
+	})
+
+	for _, lvl := range tc.testLevel[:2] {
+		t.Run(testString("Evaluator/MulAndAdd", tc.params, lvl), func(t *testing.T) {
+
+			values0, _, ciphertext0 := newTestVectorsLvl(lvl, 1, tc, tc.encryptorPk)
+			values1, _, ciphertext1 := newTestVectorsLvl(lvl, 1, tc, tc.encryptorPk)
+			values2, _, ciphertext2 := newTestVectorsLvl(lvl, 5, tc, tc.encryptorPk)
+
+			tc.evaluator.MulAndAdd(ciphertext0, ciphertext1, ciphertext2)
+			ringT.MulCoeffsAndAdd(values0, values1, values2)
+
+			require.Equal(t, uint64(5), ciphertext2.Scale)
+			require.Equal(t, lvl-1, ciphertext2.Level())
+			verifyTestVectors(tc, tc.decryptor, values2, ciphertext2, t)
+		})
+	}
+
+	t.Run(testString("Evaluator/RescaleTo", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsLvl(tc.params.MaxLevel(), 1, tc, tc.encryptorPk)
+
+		receiver := NewCiphertextLvl(tc.params, 1, 0)
+		tc.evaluator.RescaleTo(0, ciphertext, receiver)
+
+		require.Equal(t, 0, receiver.Level())
+		verifyTestVectors(tc, tc.decryptor, values, receiver, t)
+
+		tc.evaluator.Rescale(ciphertext, ciphertext)
+		require.Equal(t, tc.params.MaxLevel()-1, ciphertext.Level())
+		verifyTestVectors(tc, tc.decryptor, values, ciphertext, t)
+	})
+}
+
+func testEvaluatorRotate(tc *testContext, t *testing.T) {
+
+	rots := []int{1, -1, 4, -4, 63, -63}
+	galEls := tc.params.GaloisElementsForRowInnerSum()
+	for _, n := range rots {
+		galEls = append(galEls, tc.params.GaloisElementForColumnRotationBy(n))
+	}
+	rotkey := tc.kgen.GenRotationKeys(galEls, tc.sk)
+	evaluator := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rotkey})
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Evaluator/RotateRows", tc.params, lvl), func(t *testing.T) {
+			values, _, ciphertext := newTestVectorsLvl(lvl, 3, tc, tc.encryptorPk)
+			ciphertext = evaluator.RotateRowsNew(ciphertext)
+			values.Coeffs[0] = append(values.Coeffs[0][tc.params.N()>>1:], values.Coeffs[0][:tc.params.N()>>1]...)
+			verifyTestVectors(tc, tc.decryptor, values, ciphertext, t)
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Evaluator/RotateColumns", tc.params, lvl), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectorsLvl(lvl, 3, tc, tc.encryptorPk)
+
+			receiver := NewCiphertextLvl(tc.params, 1, lvl)
+			for _, n := range rots {
+				evaluator.RotateColumns(ciphertext, n, receiver)
+				valuesWant := utils.RotateUint64Slots(values.Coeffs[0], n)
+				verifyTestVectors(tc, tc.decryptor, &ring.Poly{Coeffs: [][]uint64{valuesWant}}, receiver, t)
+			}
+		})
+	}
+
+	for _, lvl := range tc.testLevel {
+		t.Run(testString("Evaluator/RotateHoisted", tc.params, lvl), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectorsLvl(lvl, 3, tc, tc.encryptorPk)
+
+			ciphertexts := evaluator.RotateHoistedNew(ciphertext, rots)
+			for _, n := range rots {
+				valuesWant := utils.RotateUint64Slots(values.Coeffs[0], n)
+				verifyTestVectors(tc, tc.decryptor, &ring.Poly{Coeffs: [][]uint64{valuesWant}}, ciphertexts[n], t)
+			}
+		})
+	}
+
+	t.Run(testString("Evaluator/InnerSum", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsLvl(tc.params.MaxLevel(), 3, tc, tc.encryptorPk)
+
+		evaluator.InnerSum(ciphertext, ciphertext)
+
+		var sum uint64
+		for _, c := range values.Coeffs[0] {
+			sum += c
+		}
+		sum %= tc.params.T()
+
+		for i := range values.Coeffs[0] {
+			values.Coeffs[0][i] = sum
+		}
+
+		verifyTestVectors(tc, tc.decryptor, values, ciphertext, t)
+	})
+}
+
+func testMarshaller(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Marshaller/Parameters/Binary", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+		bytes, err := tc.params.MarshalBinary()
+		require.NoError(t, err)
+		var p Parameters
+		require.NoError(t, p.UnmarshalBinary(bytes))
+		require.True(t, tc.params.Equals(p))
+	})
+
+	t.Run(testString("Marshaller/Parameters/JSON", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+		data, err := json.Marshal(tc.params)
+		require.NoError(t, err)
+		var paramsRec Parameters
+		require.NoError(t, json.Unmarshal(data, &paramsRec))
+		require.True(t, tc.params.Equals(paramsRec))
+	})
+
+	t.Run(testString("Marshaller/Ciphertext", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		ciphertextWant := NewCiphertextRandom(tc.prng, tc.params, 2, tc.params.MaxLevel())
+		ciphertextWant.Scale = 12345
+
+		data, err := ciphertextWant.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, ciphertextWant.GetDataLen(true), len(data))
+
+		ciphertextTest := new(Ciphertext)
+		require.NoError(t, ciphertextTest.UnmarshalBinary(data))
+
+		require.Equal(t, ciphertextWant.Scale, ciphertextTest.Scale)
+		for i := range ciphertextWant.Value {
+			require.True(t, tc.ringQ.Equal(ciphertextWant.Value[i], ciphertextTest.Value[i]))
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package bgv
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// Ciphertext is a *ring.Poly array representing a polynomial of degree > 0 with coefficients in R_Q.
+// Its decryption is Scale * m + t * e, with m the message and e the error.
+type Ciphertext struct {
+	*rlwe.Ciphertext
+	Scale uint64
+}
+
+// NewCiphertext creates a new ciphertext parameterized by degree, at the max level and with scale 1.
+func NewCiphertext(params Parameters, degree int) (ciphertext *Ciphertext) {
+	return NewCiphertextLvl(params, degree, params.MaxLevel())
+}
+
+// NewCiphertextLvl creates a new ciphertext parameterized by degree and level, with scale 1.
+func NewCiphertextLvl(params Parameters, degree, level int) (ciphertext *Ciphertext) {
+	return &Ciphertext{Ciphertext: rlwe.NewCiphertext(params.Parameters, degree, level), Scale: 1}
+}
+
+// NewCiphertextRandom generates a new uniformly distributed ciphertext of given degree and level, with scale 1.
+func NewCiphertextRandom(prng utils.PRNG, params Parameters, degree, level int) (ciphertext *Ciphertext) {
+	return &Ciphertext{Ciphertext: rlwe.NewCiphertextRandom(prng, params.Parameters, degree, level), Scale: 1}
+}
+
+// ScalingFactor returns the scaling factor of the ciphertext.
+func (ct *Ciphertext) ScalingFactor() uint64 {
+	return ct.Scale
+}
+
+// SetScalingFactor sets the scaling factor of the ciphertext.
+func (ct *Ciphertext) SetScalingFactor(scale uint64) {
+	ct.Scale = scale
+}
+
+// Copy copies the given ciphertext ctp into the receiver ciphertext.
+func (ct *Ciphertext) Copy(ctp *Ciphertext) {
+	ct.Ciphertext.Copy(ctp.Ciphertext)
+	ct.Scale = ctp.Scale
+}
+
+// CopyNew creates a deep copy of the receiver ciphertext and returns it.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	return &Ciphertext{Ciphertext: ct.Ciphertext.CopyNew(), Scale: ct.Scale}
+}
+
+// GetDataLen returns the length in bytes of the target Ciphertext.
+func (ct *Ciphertext) GetDataLen(WithMetaData bool) (dataLen int) {
+	// MetaData is :
+	// 8 byte : Scale
+	if WithMetaData {
+		dataLen += 8
+	}
+
+	dataLen += ct.Ciphertext.GetDataLen(WithMetaData)
+
+	return dataLen
+}
+
+// MarshalBinary encodes a Ciphertext on a byte slice.
+func (ct *Ciphertext) MarshalBinary() (data []byte, err error) {
+
+	dataScale := make([]byte, 8)
+
+	binary.LittleEndian.PutUint64(dataScale, ct.Scale)
+
+	var dataCt []byte
+	if dataCt, err = ct.Ciphertext.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	return append(dataScale, dataCt...), nil
+}
+
+// UnmarshalBinary decodes a previously marshaled Ciphertext on the target Ciphertext.
+func (ct *Ciphertext) UnmarshalBinary(data []byte) (err error) {
+	if len(data) < 10 { // cf. ct.GetDataLen()
+		return errors.New("too small bytearray")
+	}
+
+	ct.Scale = binary.LittleEndian.Uint64(data[0:8])
+	ct.Ciphertext = new(rlwe.Ciphertext)
+	return ct.Ciphertext.UnmarshalBinary(data[8:])
+}
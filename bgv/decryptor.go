@@ -0,0 +1,50 @@
+package bgv
+
+import (
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Decryptor is an interface wrapping a rlwe.Decryptor.
+type Decryptor interface {
+	DecryptNew(ciphertext *Ciphertext) (plaintext *Plaintext)
+	Decrypt(ciphertext *Ciphertext, plaintext *Plaintext)
+	ShallowCopy() Decryptor
+	WithKey(sk *rlwe.SecretKey) Decryptor
+}
+
+type decryptor struct {
+	rlwe.Decryptor
+	params Parameters
+}
+
+// NewDecryptor instantiates a Decryptor for the BGV scheme.
+func NewDecryptor(params Parameters, sk *rlwe.SecretKey) Decryptor {
+	return &decryptor{rlwe.NewDecryptor(params.Parameters, sk), params}
+}
+
+// Decrypt decrypts the ciphertext and write the result in ptOut, which inherits the scale of the ciphertext.
+func (dec *decryptor) Decrypt(ct *Ciphertext, ptOut *Plaintext) {
+	dec.Decryptor.Decrypt(ct.Ciphertext, ptOut.Plaintext)
+	ptOut.Scale = ct.Scale
+}
+
+// DecryptNew decrypts the ciphertext and returns the result in a newly allocated Plaintext.
+func (dec *decryptor) DecryptNew(ct *Ciphertext) (ptOut *Plaintext) {
+	ptOut = NewPlaintextLvl(dec.params, ct.Level())
+	dec.Decrypt(ct, ptOut)
+	return
+}
+
+// ShallowCopy creates a shallow copy of Decryptor in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Decryptor can be used concurrently.
+func (dec *decryptor) ShallowCopy() Decryptor {
+	return &decryptor{dec.Decryptor.ShallowCopy(), dec.params}
+}
+
+// WithKey creates a shallow copy of Decryptor with a new decryption key, in which all the
+// read-only data-structures are shared with the receiver and the temporary buffers
+// are reallocated. The receiver and the returned Decryptor can be used concurrently.
+func (dec *decryptor) WithKey(sk *rlwe.SecretKey) Decryptor {
+	return &decryptor{dec.Decryptor.WithKey(sk), dec.params}
+}
@@ -0,0 +1,297 @@
+// Package bgv implements a RNS-accelerated version of the Brakerski-Gentry-Vaikuntanathan homomorphic encryption scheme.
+// It provides modular arithmetic over the integers.
+//
+// Unlike BFV, the message is stored in the least significant bits of the ciphertexts: a ciphertext decrypts to
+// Scale * m + t * e mod Q, where the scale is an element of Z_t tracked alongside the ciphertext. The multiplications
+// are tensored directly in R_Q and are followed by a modulus switching, which divides the ciphertext by the last modulus
+// of Q and its scale by this modulus modulo t. Hence the size of the error is stable along the circuit and each
+// multiplication consumes one modulus of Q.
+//
+// The plaintext slots follow the same layout as the slots of the bfv package: a plaintext is a 2 x N/2 matrix whose
+// columns are rotated by Evaluator.RotateColumns and rows are swapped by Evaluator.RotateRows.
+package bgv
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// GaloisGen is an integer of order N=2^d modulo M=2N and that spans Z_M with the integer -1.
+// The j-th ring automorphism takes the root zeta to zeta^(5j).
+const GaloisGen uint64 = ring.GaloisGen
+
+// Encoder is an interface for plaintext encoding and decoding operations. It provides methods to embed []uint64 and []int64 types into
+// plaintexts and the inverse operations. The values are multiplied by the scale of the plaintext at encoding and divided by it at decoding.
+type Encoder interface {
+	Encode(values interface{}, pt *Plaintext)
+	EncodeNew(values interface{}, level int, scale uint64) (pt *Plaintext)
+	EncodeCoeffs(values interface{}, pt *Plaintext)
+	EncodeCoeffsNew(values interface{}, level int, scale uint64) (pt *Plaintext)
+
+	DecodeUint(pt *Plaintext, values []uint64)
+	DecodeUintNew(pt *Plaintext) (values []uint64)
+	DecodeInt(pt *Plaintext, values []int64)
+	DecodeIntNew(pt *Plaintext) (values []int64)
+	DecodeCoeffsUint(pt *Plaintext, coeffs []uint64)
+	DecodeCoeffsUintNew(pt *Plaintext) (coeffs []uint64)
+
+	ShallowCopy() Encoder
+}
+
+// encoder is a structure that stores the parameters to encode values on a plaintext in a SIMD (Single-Instruction Multiple-Data) fashion.
+type encoder struct {
+	params Parameters
+
+	indexMatrix []uint64
+	switcher    *modulusSwitcher
+
+	buffT *ring.Poly
+	buffQ *ring.Poly
+}
+
+// NewEncoder creates a new encoder from the provided parameters.
+func NewEncoder(params Parameters) Encoder {
+
+	var N, logN, pow, pos uint64 = uint64(params.N()), uint64(params.LogN()), 1, 0
+
+	mask := 2*N - 1
+
+	indexMatrix := make([]uint64, N)
+
+	for i, j := 0, int(N>>1); i < int(N>>1); i, j = i+1, j+1 {
+
+		pos = utils.BitReverse64(pow>>1, logN)
+
+		indexMatrix[i] = pos
+		indexMatrix[j] = N - pos - 1
+
+		pow *= GaloisGen
+		pow &= mask
+	}
+
+	return &encoder{
+		params:      params,
+		indexMatrix: indexMatrix,
+		switcher:    newModulusSwitcher(params),
+		buffT:       params.RingT().NewPoly(),
+		buffQ:       params.RingQ().NewPoly(),
+	}
+}
+
+// EncodeNew encodes a slice of integers of type []uint64 or []int64 of size at most N on the slots of a newly allocated plaintext
+// with the given level and scale.
+func (ecd *encoder) EncodeNew(values interface{}, level int, scale uint64) (pt *Plaintext) {
+	pt = NewPlaintextLvl(ecd.params, level)
+	pt.Scale = scale
+	ecd.Encode(values, pt)
+	return
+}
+
+// Encode encodes a slice of integers of type []uint64 or []int64 of size at most N on the slots of a pre-allocated plaintext.
+// The values are reduced modulo t and multiplied by the scale of the plaintext.
+func (ecd *encoder) Encode(values interface{}, pt *Plaintext) {
+
+	ecd.setValues(values, ecd.indexMatrix, ecd.buffT.Coeffs[0])
+
+	ecd.params.RingT().InvNTT(ecd.buffT, ecd.buffT)
+
+	ecd.scaleUp(pt)
+}
+
+// EncodeCoeffsNew encodes a slice of integers of type []uint64 or []int64 of size at most N on the coefficients of a newly allocated
+// plaintext with the given level and scale.
+func (ecd *encoder) EncodeCoeffsNew(values interface{}, level int, scale uint64) (pt *Plaintext) {
+	pt = NewPlaintextLvl(ecd.params, level)
+	pt.Scale = scale
+	ecd.EncodeCoeffs(values, pt)
+	return
+}
+
+// EncodeCoeffs encodes a slice of integers of type []uint64 or []int64 of size at most N on the coefficients of a pre-allocated plaintext.
+// The values are reduced modulo t and multiplied by the scale of the plaintext.
+func (ecd *encoder) EncodeCoeffs(values interface{}, pt *Plaintext) {
+	ecd.setValues(values, nil, ecd.buffT.Coeffs[0])
+	ecd.scaleUp(pt)
+}
+
+// setValues reduces the values modulo t and writes them on coeffs, at the positions given by index if not nil.
+func (ecd *encoder) setValues(values interface{}, index []uint64, coeffs []uint64) {
+
+	T := ecd.params.T()
+	bredParamsT := ecd.params.RingT().BredParams[0]
+
+	pos := func(i int) int {
+		if index != nil {
+			return int(index[i])
+		}
+		return i
+	}
+
+	var valLen int
+	switch values := values.(type) {
+	case []uint64:
+		for i, c := range values {
+			coeffs[pos(i)] = ring.BRedAdd(c, T, bredParamsT)
+		}
+		valLen = len(values)
+	case []int64:
+		var sign, abs uint64
+		for i, c := range values {
+			sign = uint64(c) >> 63
+			abs = ring.BRedAdd(uint64(c*((int64(sign)^1)-int64(sign))), T, bredParamsT)
+			coeffs[pos(i)] = sign*(T-abs) | (sign^1)*abs
+		}
+		valLen = len(values)
+	default:
+		panic("cannot Encode: values must be either []uint64 or []int64")
+	}
+
+	for i := valLen; i < len(coeffs); i++ {
+		coeffs[pos(i)] = 0
+	}
+}
+
+// scaleUp multiplies buffT by the scale of pt and writes its centered lift modulo each q_i on pt.
+func (ecd *encoder) scaleUp(pt *Plaintext) {
+
+	ringT := ecd.params.RingT()
+	ringQ := ecd.params.RingQ()
+
+	T := ringT.Modulus[0]
+	tHalf := T >> 1
+
+	ringT.MulScalar(ecd.buffT, pt.Scale%T, ecd.buffT)
+
+	coeffsT := ecd.buffT.Coeffs[0]
+	for i, qi := range ringQ.Modulus[:pt.Level()+1] {
+		coeffsQi := pt.Value.Coeffs[i]
+		for j, c := range coeffsT {
+			if c > tHalf {
+				coeffsQi[j] = qi - (T - c)
+			} else {
+				coeffsQi[j] = c
+			}
+		}
+	}
+}
+
+// scaleDown switches the modulus of pt down to q_0, reduces its centered coefficients modulo t,
+// divides them by the resulting scale and writes the result on buffT.
+func (ecd *encoder) scaleDown(pt *Plaintext) {
+
+	ringQ := ecd.params.RingQ()
+	ringT := ecd.params.RingT()
+
+	level := pt.Level()
+	T := ringT.Modulus[0]
+
+	p := pt.Value
+	scale := pt.Scale % T
+	if level > 0 {
+		ecd.switcher.switchModulusLvl(level, level, pt.Value, ecd.buffQ)
+		scale = ring.BRed(scale, scaleDown(ringQ, level, level, T), T, ringT.BredParams[0])
+		p = ecd.buffQ
+	}
+
+	scaleInv, err := invModT(scale, T)
+	if err != nil {
+		panic(fmt.Errorf("cannot Decode: %w", err))
+	}
+
+	q0 := ringQ.Modulus[0]
+	q0Half := q0 >> 1
+
+	coeffsT := ecd.buffT.Coeffs[0]
+	for j, c := range p.Coeffs[0][:ringQ.N] {
+		if c > q0Half {
+			coeffsT[j] = (T - (q0-c)%T) % T
+		} else {
+			coeffsT[j] = c % T
+		}
+	}
+
+	ringT.MulScalar(ecd.buffT, scaleInv, ecd.buffT)
+}
+
+// DecodeUint decodes the slots of a plaintext and writes them on values.
+func (ecd *encoder) DecodeUint(pt *Plaintext, values []uint64) {
+
+	ecd.scaleDown(pt)
+
+	ecd.params.RingT().NTT(ecd.buffT, ecd.buffT)
+
+	for i := range values[:ecd.params.N()] {
+		values[i] = ecd.buffT.Coeffs[0][ecd.indexMatrix[i]]
+	}
+}
+
+// DecodeUintNew decodes the slots of a plaintext and returns them in a new []uint64.
+func (ecd *encoder) DecodeUintNew(pt *Plaintext) (values []uint64) {
+	values = make([]uint64, ecd.params.N())
+	ecd.DecodeUint(pt, values)
+	return
+}
+
+// DecodeInt decodes the slots of a plaintext and writes them on values, centered in [-t/2, t/2).
+func (ecd *encoder) DecodeInt(pt *Plaintext, values []int64) {
+
+	ecd.scaleDown(pt)
+
+	ecd.params.RingT().NTT(ecd.buffT, ecd.buffT)
+
+	modulus := int64(ecd.params.T())
+	modulusHalf := modulus >> 1
+	var value int64
+	for i := range values[:ecd.params.N()] {
+		value = int64(ecd.buffT.Coeffs[0][ecd.indexMatrix[i]])
+		values[i] = value
+		if value >= modulusHalf {
+			values[i] -= modulus
+		}
+	}
+}
+
+// DecodeIntNew decodes the slots of a plaintext and returns them in a new []int64, centered in [-t/2, t/2).
+func (ecd *encoder) DecodeIntNew(pt *Plaintext) (values []int64) {
+	values = make([]int64, ecd.params.N())
+	ecd.DecodeInt(pt, values)
+	return
+}
+
+// DecodeCoeffsUint decodes the coefficients of a plaintext and writes them on coeffs.
+func (ecd *encoder) DecodeCoeffsUint(pt *Plaintext, coeffs []uint64) {
+	ecd.scaleDown(pt)
+	copy(coeffs, ecd.buffT.Coeffs[0])
+}
+
+// DecodeCoeffsUintNew decodes the coefficients of a plaintext and returns them in a new []uint64.
+func (ecd *encoder) DecodeCoeffsUintNew(pt *Plaintext) (coeffs []uint64) {
+	coeffs = make([]uint64, ecd.params.N())
+	ecd.DecodeCoeffsUint(pt, coeffs)
+	return
+}
+
+// ShallowCopy creates a shallow copy of Encoder in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Encoder can be used concurrently.
+func (ecd *encoder) ShallowCopy() Encoder {
+	return &encoder{
+		params:      ecd.params,
+		indexMatrix: ecd.indexMatrix,
+		switcher:    ecd.switcher.shallowCopy(),
+		buffT:       ecd.params.RingT().NewPoly(),
+		buffQ:       ecd.params.RingQ().NewPoly(),
+	}
+}
+
+// invModT returns x^-1 mod t, or an error if x is not invertible modulo t.
+func invModT(x, t uint64) (uint64, error) {
+	T := new(big.Int).SetUint64(t)
+	if inv := new(big.Int).ModInverse(new(big.Int).SetUint64(x), T); inv != nil {
+		return inv.Uint64(), nil
+	}
+	return 0, fmt.Errorf("scale %d is not invertible modulo t=%d", x, t)
+}
@@ -0,0 +1,73 @@
+package bgv
+
+import (
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Encryptor an encryption interface for the BGV scheme.
+type Encryptor interface {
+	Encrypt(plaintext *Plaintext, ciphertext *Ciphertext)
+	EncryptNew(plaintext *Plaintext) *Ciphertext
+	EncryptZero(ciphertext *Ciphertext)
+	EncryptZeroNew() *Ciphertext
+	ShallowCopy() Encryptor
+	WithKey(key interface{}) Encryptor
+}
+
+type encryptor struct {
+	rlwe.Encryptor
+	params Parameters
+}
+
+// NewEncryptor instantiates a new Encryptor for the BGV scheme. The key argument can
+// be *rlwe.PublicKey or *rlwe.SecretKey.
+func NewEncryptor(params Parameters, key interface{}) Encryptor {
+	return &encryptor{rlwe.NewEncryptor(params.Parameters, key), params}
+}
+
+// Encrypt encrypts the input plaintext and writes the result on ctOut.
+// The ciphertext is an encryption of zero with error t * e, to which the plaintext is added,
+// and it inherits the level and the scale of the plaintext.
+func (enc *encryptor) Encrypt(plaintext *Plaintext, ctOut *Ciphertext) {
+	ctOut.Resize(1, plaintext.Level())
+	enc.EncryptZero(ctOut)
+	enc.params.RingQ().AddLvl(plaintext.Level(), ctOut.Value[0], plaintext.Value, ctOut.Value[0])
+	ctOut.Scale = plaintext.Scale
+}
+
+// EncryptNew encrypts the input plaintext returns the result as a newly allocated ciphertext.
+func (enc *encryptor) EncryptNew(plaintext *Plaintext) *Ciphertext {
+	ct := NewCiphertextLvl(enc.params, 1, plaintext.Level())
+	enc.Encrypt(plaintext, ct)
+	return ct
+}
+
+// EncryptZero generates an encryption of zero with error t * e and writes the result on ctOut.
+func (enc *encryptor) EncryptZero(ctOut *Ciphertext) {
+	enc.Encryptor.EncryptZero(ctOut.Ciphertext)
+	for i := range ctOut.Value {
+		enc.params.RingQ().MulScalarLvl(ctOut.Level(), ctOut.Value[i], enc.params.T(), ctOut.Value[i])
+	}
+}
+
+// EncryptZeroNew generates an encryption of zero and returns the result as a newly allocated ciphertext.
+func (enc *encryptor) EncryptZeroNew() *Ciphertext {
+	ct := NewCiphertext(enc.params, 1)
+	enc.EncryptZero(ct)
+	return ct
+}
+
+// ShallowCopy creates a shallow copy of this encryptor in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Encryptors can be used concurrently.
+func (enc *encryptor) ShallowCopy() Encryptor {
+	return &encryptor{enc.Encryptor.ShallowCopy(), enc.params}
+}
+
+// WithKey creates a shallow copy of this encryptor with a new key in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Encryptors can be used concurrently.
+// Key can be *rlwe.PublicKey or *rlwe.SecretKey.
+func (enc *encryptor) WithKey(key interface{}) Encryptor {
+	return &encryptor{enc.Encryptor.WithKey(key), enc.params}
+}
@@ -0,0 +1,593 @@
+package bgv
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// Operand is a common interface for Ciphertext and Plaintext.
+type Operand interface {
+	El() *rlwe.Ciphertext
+	Level() int
+	Degree() int
+	ScalingFactor() uint64
+}
+
+// Evaluator is an interface implementing the public methodes of the eval.
+// It provides the arithmetic, relinearization, key-switching and rotation methods of bfv.Evaluator.
+// Polynomial evaluation, linear transforms, the InnerSumLog/Replicate family and the AddNoMod/Reduce
+// variants of bfv.Evaluator are not provided.
+type Evaluator interface {
+	Add(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext)
+	AddNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext)
+	Sub(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext)
+	SubNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext)
+	Neg(ctIn *Ciphertext, ctOut *Ciphertext)
+	NegNew(ctIn *Ciphertext) (ctOut *Ciphertext)
+	AddScalar(ctIn *Ciphertext, scalar uint64, ctOut *Ciphertext)
+	MulScalar(ctIn *Ciphertext, scalar uint64, ctOut *Ciphertext)
+	MulScalarAndAdd(ctIn *Ciphertext, scalar uint64, ctOut *Ciphertext)
+	MulScalarNew(ctIn *Ciphertext, scalar uint64) (ctOut *Ciphertext)
+	Rescale(ctIn, ctOut *Ciphertext)
+	RescaleTo(level int, ctIn, ctOut *Ciphertext)
+	Mul(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext)
+	MulNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext)
+	MulAndAdd(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext)
+	Relinearize(ctIn *Ciphertext, ctOut *Ciphertext)
+	RelinearizeNew(ctIn *Ciphertext) (ctOut *Ciphertext)
+	SwitchKeys(ctIn *Ciphertext, switchKey *rlwe.SwitchingKey, ctOut *Ciphertext)
+	SwitchKeysNew(ctIn *Ciphertext, switchkey *rlwe.SwitchingKey) (ctOut *Ciphertext)
+	RotateColumnsNew(ctIn *Ciphertext, k int) (ctOut *Ciphertext)
+	RotateColumns(ctIn *Ciphertext, k int, ctOut *Ciphertext)
+	RotateHoistedNew(ctIn *Ciphertext, rotations []int) (ctOut map[int]*Ciphertext)
+	RotateHoisted(ctIn *Ciphertext, rotations []int, ctOut map[int]*Ciphertext)
+	RotateRows(ctIn *Ciphertext, ctOut *Ciphertext)
+	RotateRowsNew(ctIn *Ciphertext) (ctOut *Ciphertext)
+	InnerSum(ctIn *Ciphertext, ctOut *Ciphertext)
+	ShallowCopy() Evaluator
+	WithKey(rlwe.EvaluationKey) Evaluator
+}
+
+// evaluator is a struct that holds the necessary elements to perform the homomorphic operations between ciphertexts and/or plaintexts.
+// It also holds a memory buffer used to store intermediate computations.
+type evaluator struct {
+	*evaluatorBase
+	*evaluatorBuffers
+	*rlwe.Evaluator
+}
+
+type evaluatorBase struct {
+	params Parameters
+	ringQ  *ring.Ring
+
+	t        uint64
+	tInvModQ *big.Int // t^-1 mod Q
+}
+
+func newEvaluatorPrecomp(params Parameters) *evaluatorBase {
+	ev := new(evaluatorBase)
+	ev.params = params
+	ev.ringQ = params.RingQ()
+	ev.t = params.T()
+	ev.tInvModQ = new(big.Int).ModInverse(new(big.Int).SetUint64(ev.t), ev.ringQ.ModulusAtLevel[params.MaxLevel()])
+	return ev
+}
+
+type evaluatorBuffers struct {
+	// buffQ[0-1]: tensoring inputs, key-switching input, scale matching
+	// buffQ[2]: tensoring output
+	// buffQ[3]: MulAndAdd product
+	buffQ    [4][]*ring.Poly
+	switcher *modulusSwitcher
+}
+
+func newEvaluatorBuffer(params Parameters) *evaluatorBuffers {
+	evb := new(evaluatorBuffers)
+	for i := range evb.buffQ {
+		evb.buffQ[i] = make([]*ring.Poly, 6)
+		for j := range evb.buffQ[i] {
+			evb.buffQ[i][j] = params.RingQ().NewPoly()
+		}
+	}
+	evb.switcher = newModulusSwitcher(params)
+	return evb
+}
+
+// NewEvaluator creates a new Evaluator, that can be used to do homomorphic
+// operations on ciphertexts and/or plaintexts. It stores a memory buffer
+// and ciphertexts that will be used for intermediate values.
+func NewEvaluator(params Parameters, evaluationKey rlwe.EvaluationKey) Evaluator {
+	ev := new(evaluator)
+	ev.evaluatorBase = newEvaluatorPrecomp(params)
+	ev.evaluatorBuffers = newEvaluatorBuffer(params)
+	ev.Evaluator = rlwe.NewEvaluator(params.Parameters, &evaluationKey)
+	return ev
+}
+
+// NewEvaluators creates n evaluators sharing the same read-only data-structures.
+func NewEvaluators(params Parameters, evaluationKey rlwe.EvaluationKey, n int) []Evaluator {
+	if n <= 0 {
+		return []Evaluator{}
+	}
+	evas := make([]Evaluator, n)
+	for i := range evas {
+		if i == 0 {
+			evas[0] = NewEvaluator(params, evaluationKey)
+		} else {
+			evas[i] = evas[i-1].ShallowCopy()
+		}
+	}
+	return evas
+}
+
+// Add adds ctIn to op1 and returns the result in ctOut.
+// The result is at the minimum level of the operands and at the scale of ctIn: if the scales differ,
+// op1 is first multiplied by ctIn.Scale/op1.Scale mod t, which multiplies its error by at most t/2.
+func (eval *evaluator) Add(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	eval.evaluateBinary(ctIn, op1, ctOut, eval.ringQ.AddLvl, false)
+}
+
+// AddNew adds ctIn to op1 and creates a new element ctOut to store the result.
+func (eval *evaluator) AddNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, utils.MaxInt(ctIn.Degree(), op1.Degree()), utils.MinInt(ctIn.Level(), op1.Level()))
+	eval.Add(ctIn, op1, ctOut)
+	return
+}
+
+// Sub subtracts op1 from ctIn and returns the result in ctOut.
+// The result is at the minimum level of the operands and at the scale of ctIn (see Add).
+func (eval *evaluator) Sub(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	eval.evaluateBinary(ctIn, op1, ctOut, eval.ringQ.SubLvl, true)
+}
+
+// SubNew subtracts op1 from ctIn and creates a new element ctOut to store the result.
+func (eval *evaluator) SubNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, utils.MaxInt(ctIn.Degree(), op1.Degree()), utils.MinInt(ctIn.Level(), op1.Level()))
+	eval.Sub(ctIn, op1, ctOut)
+	return
+}
+
+// evaluateBinary applies evaluate on ctIn and op1, brought to the scale of ctIn, and returns the result in ctOut.
+// If neg is true, the elements of op1 of degree larger than ctIn are negated.
+func (eval *evaluator) evaluateBinary(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext, evaluate func(int, *ring.Poly, *ring.Poly, *ring.Poly), neg bool) {
+
+	if ctIn == nil || op1 == nil || ctOut == nil {
+		panic("cannot evaluateBinary: ctIn, op1 or ctOut cannot be nil")
+	}
+
+	level := utils.MinInt(ctIn.Level(), op1.Level())
+
+	el0 := ctIn.El()
+	el1 := eval.matchScale(level, ctIn.Scale, op1)
+
+	degree := utils.MaxInt(el0.Degree(), el1.Degree())
+	minDegree := utils.MinInt(el0.Degree(), el1.Degree())
+
+	// ctOut may alias the inputs, whose polynomials are only read up to the given level.
+	ctOut.Resize(degree, level)
+
+	for i := 0; i < minDegree+1; i++ {
+		evaluate(level, el0.Value[i], el1.Value[i], ctOut.Value[i])
+	}
+
+	// If the inputs degrees differ, it copies the remaining degree on the receiver.
+	for i := minDegree + 1; i < degree+1; i++ {
+		if el0.Degree() > el1.Degree() {
+			if el0 != ctOut.El() {
+				ring.CopyValuesLvl(level, el0.Value[i], ctOut.Value[i])
+			}
+		} else if neg {
+			eval.ringQ.NegLvl(level, el1.Value[i], ctOut.Value[i])
+		} else {
+			ring.CopyValuesLvl(level, el1.Value[i], ctOut.Value[i])
+		}
+	}
+
+	ctOut.Scale = ctIn.Scale
+}
+
+// matchScale returns op1 if its scale is equal to scale, else it returns op1 * scale/op1.Scale mod t
+// on the buffer buffQ[1].
+func (eval *evaluator) matchScale(level int, scale uint64, op1 Operand) *rlwe.Ciphertext {
+
+	if op1.ScalingFactor() == scale {
+		return op1.El()
+	}
+
+	scaleInv, err := invModT(op1.ScalingFactor(), eval.t)
+	if err != nil {
+		panic(fmt.Errorf("cannot match the scales: %w", err))
+	}
+
+	r := ring.BRed(scale%eval.t, scaleInv, eval.t, eval.params.RingT().BredParams[0])
+
+	el1 := op1.El()
+	elOut := buffCiphertextLvl(level, el1.Degree(), eval.buffQ[1])
+	for i := range el1.Value {
+		eval.mulScalarLvl(level, el1.Value[i], r, elOut.Value[i])
+	}
+
+	return elOut
+}
+
+// mulScalarLvl multiplies p0 by the centered representative of the scalar modulo t and writes the result on p1.
+func (eval *evaluator) mulScalarLvl(level int, p0 *ring.Poly, scalar uint64, p1 *ring.Poly) {
+	if scalar > eval.t>>1 {
+		eval.ringQ.MulScalarLvl(level, p0, eval.t-scalar, p1)
+		eval.ringQ.NegLvl(level, p1, p1)
+	} else {
+		eval.ringQ.MulScalarLvl(level, p0, scalar, p1)
+	}
+}
+
+// Neg negates ctIn and returns the result in ctOut.
+func (eval *evaluator) Neg(ctIn, ctOut *Ciphertext) {
+	level := ctIn.Level()
+	ctOut.Resize(ctIn.Degree(), level)
+	for i := range ctIn.Value {
+		eval.ringQ.NegLvl(level, ctIn.Value[i], ctOut.Value[i])
+	}
+	ctOut.Scale = ctIn.Scale
+}
+
+// NegNew negates ctIn and creates a new element to store the result.
+func (eval *evaluator) NegNew(ctIn *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, ctIn.Degree(), ctIn.Level())
+	eval.Neg(ctIn, ctOut)
+	return ctOut
+}
+
+// AddScalar adds the scalar on each slot of ctIn and returns the result on ctOut.
+func (eval *evaluator) AddScalar(ctIn *Ciphertext, scalar uint64, ctOut *Ciphertext) {
+
+	level := ctIn.Level()
+
+	if ctIn != ctOut {
+		ctOut.Resize(ctIn.Degree(), level)
+		for i := range ctIn.Value {
+			ring.CopyValuesLvl(level, ctIn.Value[i], ctOut.Value[i])
+		}
+		ctOut.Scale = ctIn.Scale
+	}
+
+	// The constant polynomial scalar * Scale, with its coefficient centered modulo t
+	c := ring.BRed(scalar%eval.t, ctIn.Scale%eval.t, eval.t, eval.params.RingT().BredParams[0])
+
+	for i, qi := range eval.ringQ.Modulus[:level+1] {
+		ci := c
+		if c > eval.t>>1 {
+			ci = qi - (eval.t - c)
+		}
+		ctOut.Value[0].Coeffs[i][0] = ring.CRed(ctOut.Value[0].Coeffs[i][0]+ci, qi)
+	}
+}
+
+// MulScalar multiplies each slot of ctIn by a uint64 scalar and returns the result in ctOut.
+// The error is multiplied by the centered representative of the scalar modulo t.
+func (eval *evaluator) MulScalar(ctIn *Ciphertext, scalar uint64, ctOut *Ciphertext) {
+	level := ctIn.Level()
+	ctOut.Resize(ctIn.Degree(), level)
+	for i := range ctIn.Value {
+		eval.mulScalarLvl(level, ctIn.Value[i], scalar%eval.t, ctOut.Value[i])
+	}
+	ctOut.Scale = ctIn.Scale
+}
+
+// MulScalarAndAdd multiplies each slot of ctIn by a uint64 scalar and adds the result on ctOut.
+// The scale of ctOut is preserved: the scalar is multiplied by ctOut.Scale/ctIn.Scale mod t.
+func (eval *evaluator) MulScalarAndAdd(ctIn *Ciphertext, scalar uint64, ctOut *Ciphertext) {
+
+	scaleInv, err := invModT(ctIn.Scale, eval.t)
+	if err != nil {
+		panic(fmt.Errorf("cannot MulScalarAndAdd: %w", err))
+	}
+
+	bredParams := eval.params.RingT().BredParams[0]
+	scalar = ring.BRed(ring.BRed(scalar%eval.t, ctOut.Scale%eval.t, eval.t, bredParams), scaleInv, eval.t, bredParams)
+
+	level := utils.MinInt(ctIn.Level(), ctOut.Level())
+	ctOut.Resize(utils.MaxInt(ctIn.Degree(), ctOut.Degree()), level)
+
+	for i := range ctIn.Value {
+		if scalar > eval.t>>1 {
+			eval.ringQ.MulScalarAndSubLvl(level, ctIn.Value[i], eval.t-scalar, ctOut.Value[i])
+		} else {
+			eval.ringQ.MulScalarAndAddLvl(level, ctIn.Value[i], scalar, ctOut.Value[i])
+		}
+	}
+}
+
+// MulScalarNew multiplies ctIn by a uint64 scalar and creates a new element ctOut to store the result.
+func (eval *evaluator) MulScalarNew(ctIn *Ciphertext, scalar uint64) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, ctIn.Degree(), ctIn.Level())
+	eval.MulScalar(ctIn, scalar, ctOut)
+	return
+}
+
+// Rescale switches ctIn to the modulus Q/q_l, with q_l its last modulus, and returns the result in ctOut.
+// The error is divided by q_l and the scale is multiplied by q_l^-1 mod t.
+func (eval *evaluator) Rescale(ctIn, ctOut *Ciphertext) {
+	eval.RescaleTo(ctIn.Level()-1, ctIn, ctOut)
+}
+
+// RescaleTo switches ctIn to the modulus given by its first `level+1` moduli and returns the result in ctOut.
+// The scale is multiplied by the inverse modulo t of the dropped moduli.
+func (eval *evaluator) RescaleTo(level int, ctIn, ctOut *Ciphertext) {
+
+	if level < 0 || level > ctIn.Level() {
+		panic("cannot RescaleTo: level must be in [0, ctIn.Level()]")
+	}
+
+	// Only resizes the degree of ctOut, as it may alias ctIn
+	ctOut.Resize(ctIn.Degree(), utils.MaxInt(ctOut.Level(), level))
+
+	for i := range ctIn.Value {
+		eval.switcher.switchModulusLvl(ctIn.Level(), ctIn.Level()-level, ctIn.Value[i], ctOut.Value[i])
+	}
+
+	ctOut.Scale = ring.BRed(ctIn.Scale%eval.t, scaleDown(eval.ringQ, ctIn.Level(), ctIn.Level()-level, eval.t), eval.t, eval.params.RingT().BredParams[0])
+
+	ctOut.Resize(ctIn.Degree(), level)
+}
+
+// Mul multiplies ctIn by op1 and returns the result in ctOut.
+// The tensoring is done in R_Q in the NTT domain, at the minimum level of the operands, and is followed by a modulus
+// switching to the next level (if the operands are not at level 0): the output is one level below the operands and
+// its scale is ctIn.Scale * op1.Scale * q_l^-1 mod t.
+func (eval *evaluator) Mul(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+
+	if ctIn == nil || op1 == nil || ctOut == nil {
+		panic("cannot Mul: ctIn, op1 or ctOut cannot be nil")
+	}
+
+	degree := ctIn.Degree() + op1.Degree()
+
+	if degree+1 > len(eval.buffQ[2]) {
+		panic(fmt.Errorf("cannot Mul: the output degree cannot exceed %d", len(eval.buffQ[2])-1))
+	}
+
+	level := utils.MinInt(ctIn.Level(), op1.Level())
+
+	el1 := op1.El()
+
+	c0, c1, c2 := eval.buffQ[0], eval.buffQ[1], eval.buffQ[2]
+
+	for i := range ctIn.Value {
+		eval.ringQ.NTTLazyLvl(level, ctIn.Value[i], c0[i])
+		eval.ringQ.MFormLvl(level, c0[i], c0[i])
+	}
+
+	for i := range el1.Value {
+		eval.ringQ.NTTLazyLvl(level, el1.Value[i], c1[i])
+	}
+
+	for i := 0; i < degree+1; i++ {
+		c2[i].Zero()
+	}
+
+	for i := range ctIn.Value {
+		for j := range el1.Value {
+			eval.ringQ.MulCoeffsMontgomeryAndAddLvl(level, c0[i], c1[j], c2[i+j])
+		}
+	}
+
+	scale := ring.BRed(ctIn.Scale%eval.t, op1.ScalingFactor()%eval.t, eval.t, eval.params.RingT().BredParams[0])
+
+	ctOut.Resize(degree, level)
+
+	for i := range ctOut.Value {
+		eval.ringQ.InvNTTLvl(level, c2[i], ctOut.Value[i])
+	}
+
+	ctOut.Scale = scale
+
+	if level > 0 {
+		eval.Rescale(ctOut, ctOut)
+	}
+}
+
+// MulNew multiplies ctIn by op1 and creates a new element ctOut to store the result.
+func (eval *evaluator) MulNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, ctIn.Degree()+op1.Degree(), utils.MinInt(ctIn.Level(), op1.Level()))
+	eval.Mul(ctIn, op1, ctOut)
+	return
+}
+
+// MulAndAdd multiplies ctIn with op1 and adds the result on ctOut.
+// As the product is one level below the operands, ctOut is brought to the minimum of its level and of the level
+// of the product by dropping its last moduli, and the product is brought to the scale of ctOut (see Add).
+func (eval *evaluator) MulAndAdd(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+
+	level := utils.MinInt(ctIn.Level(), op1.Level())
+
+	ct2 := &Ciphertext{Ciphertext: buffCiphertextLvl(level, ctIn.Degree()+op1.Degree(), eval.buffQ[3])}
+
+	eval.Mul(ctIn, op1, ct2)
+
+	scale := ctOut.Scale
+	eval.Add(ctOut, ct2, ctOut)
+	ctOut.Scale = scale
+}
+
+// Relinearize relinearizes the ciphertext ctIn of degree > 1 until it is of degree 1, and returns the result in ctOut.
+//
+// It requires a correct evaluation key as additional input:
+//
+// - it must match the secret-key that was used to create the public key under which the current ct0 is encrypted.
+//
+// - it must be of degree high enough to relinearize the input ciphertext to degree 1 (e.g., a ciphertext
+// of degree 3 will require that the evaluation key stores the keys for both degree 3 and degree 2 ciphertexts).
+func (eval *evaluator) Relinearize(ctIn *Ciphertext, ctOut *Ciphertext) {
+	eval.keySwitch(ctIn, ctOut, eval.Evaluator.Relinearize)
+}
+
+// RelinearizeNew relinearizes the ciphertext ctIn of degree > 1 until it is of degree 1, and creates a new ciphertext to store the result.
+func (eval *evaluator) RelinearizeNew(ctIn *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, 1, ctIn.Level())
+	eval.Relinearize(ctIn, ctOut)
+	return
+}
+
+// SwitchKeys applies the key-switching procedure to the ciphertext ct0 and returns the result in ctOut. It requires as an additional input a valid switching-key:
+// it must encrypt the target key under the public key under which ct0 is currently encrypted.
+func (eval *evaluator) SwitchKeys(ctIn *Ciphertext, switchKey *rlwe.SwitchingKey, ctOut *Ciphertext) {
+	eval.keySwitch(ctIn, ctOut, func(ctIn, ctOut *rlwe.Ciphertext) {
+		eval.Evaluator.SwitchKeys(ctIn, switchKey, ctOut)
+	})
+}
+
+// SwitchKeysNew applies the key-switching procedure to the ciphertext ct0 and creates a new ciphertext to store the result. It requires as an additional input a valid switching-key:
+// it must encrypt the target key under the public key under which ct0 is currently encrypted.
+func (eval *evaluator) SwitchKeysNew(ctIn *Ciphertext, switchkey *rlwe.SwitchingKey) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, 1, ctIn.Level())
+	eval.SwitchKeys(ctIn, switchkey, ctOut)
+	return
+}
+
+// RotateColumns rotates the columns of ct0 by k positions to the left and returns the result in ctOut. As an additional input it requires a RotationKeys struct.
+func (eval *evaluator) RotateColumns(ctIn *Ciphertext, k int, ctOut *Ciphertext) {
+	galEl := eval.params.GaloisElementForColumnRotationBy(k)
+	eval.keySwitch(ctIn, ctOut, func(ctIn, ctOut *rlwe.Ciphertext) {
+		eval.Automorphism(ctIn, galEl, ctOut)
+	})
+}
+
+// RotateColumnsNew applies RotateColumns and returns the result in a new Ciphertext.
+func (eval *evaluator) RotateColumnsNew(ctIn *Ciphertext, k int) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, 1, ctIn.Level())
+	eval.RotateColumns(ctIn, k, ctOut)
+	return
+}
+
+// RotateHoistedNew takes an input Ciphertext and a list of rotations and returns a map of Ciphertext, where each element of the map is the input Ciphertext
+// rotation by one element of the list. It is much faster than sequential calls to Rotate.
+func (eval *evaluator) RotateHoistedNew(ctIn *Ciphertext, rotations []int) (ctOut map[int]*Ciphertext) {
+	ctOut = make(map[int]*Ciphertext)
+	for _, i := range rotations {
+		ctOut[i] = NewCiphertextLvl(eval.params, 1, ctIn.Level())
+	}
+	eval.RotateHoisted(ctIn, rotations, ctOut)
+	return
+}
+
+// RotateHoisted takes an input Ciphertext and a list of rotations and populates a map of pre-allocated Ciphertexts,
+// where each element of the map is the input Ciphertext rotation by one element of the list.
+// It is much faster than sequential calls to Rotate if the parameters have a modulus P, and falls back on them otherwise.
+func (eval *evaluator) RotateHoisted(ctIn *Ciphertext, rotations []int, ctOut map[int]*Ciphertext) {
+
+	if eval.params.PCount() == 0 {
+		for _, i := range rotations {
+			eval.RotateColumns(ctIn, i, ctOut[i])
+		}
+		return
+	}
+
+	level := ctIn.Level()
+
+	tmp := eval.mulByTInv(ctIn)
+
+	eval.DecomposeNTT(level, eval.params.PCount()-1, eval.params.PCount(), tmp.Value[1], eval.BuffDecompQP)
+	for _, i := range rotations {
+		ctOut[i].Resize(1, level)
+		eval.AutomorphismHoisted(level, tmp, eval.BuffDecompQP, eval.params.GaloisElementForColumnRotationBy(i), ctOut[i].Ciphertext)
+		eval.mulByT(ctOut[i])
+		ctOut[i].Scale = ctIn.Scale
+	}
+}
+
+// RotateRows rotates the rows of ct0 and returns the result in ctOut.
+func (eval *evaluator) RotateRows(ctIn *Ciphertext, ctOut *Ciphertext) {
+	galEl := eval.params.GaloisElementForRowRotation()
+	eval.keySwitch(ctIn, ctOut, func(ctIn, ctOut *rlwe.Ciphertext) {
+		eval.Automorphism(ctIn, galEl, ctOut)
+	})
+}
+
+// RotateRowsNew rotates the rows of ctIn and returns the result a new Ciphertext.
+func (eval *evaluator) RotateRowsNew(ctIn *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(eval.params, 1, ctIn.Level())
+	eval.RotateRows(ctIn, ctOut)
+	return
+}
+
+// InnerSum computes the inner sum of ctIn and returns the result in ctOut. It requires a rotation key that stores all the left powers of two rotations.
+// The resulting vector will be of the form [sum, sum, .., sum, sum].
+func (eval *evaluator) InnerSum(ctIn *Ciphertext, ctOut *Ciphertext) {
+	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot InnerSum: input and output must be of degree 1")
+	}
+	cTmp := NewCiphertextLvl(eval.params, 1, ctIn.Level())
+	ctOut.Resize(1, ctIn.Level())
+	ctOut.Copy(ctIn)
+
+	for i := 1; i < int(eval.ringQ.N>>1); i <<= 1 {
+		eval.RotateColumns(ctOut, i, cTmp)
+		eval.Add(cTmp, ctOut, ctOut)
+	}
+
+	eval.RotateRows(ctOut, cTmp)
+	eval.Add(ctOut, cTmp, ctOut)
+}
+
+// keySwitch applies the key-switching operation op on t^-1 * ctIn and multiplies the result by t, so that
+// the error introduced by the key-switching, which is not a multiple of t, is multiplied by t.
+func (eval *evaluator) keySwitch(ctIn, ctOut *Ciphertext, op func(ctIn, ctOut *rlwe.Ciphertext)) {
+	tmp := eval.mulByTInv(ctIn)
+	ctOut.Resize(1, ctIn.Level())
+	op(tmp, ctOut.Ciphertext)
+	eval.mulByT(ctOut)
+	ctOut.Scale = ctIn.Scale
+}
+
+// mulByTInv returns t^-1 * ctIn mod Q on the buffer buffQ[0].
+func (eval *evaluator) mulByTInv(ctIn *Ciphertext) (ctOut *rlwe.Ciphertext) {
+	level := ctIn.Level()
+	ctOut = buffCiphertextLvl(level, ctIn.Degree(), eval.buffQ[0])
+	for i := range ctIn.Value {
+		eval.ringQ.MulScalarBigintLvl(level, ctIn.Value[i], eval.tInvModQ, ctOut.Value[i])
+	}
+	return
+}
+
+// mulByT multiplies ct by t.
+func (eval *evaluator) mulByT(ct *Ciphertext) {
+	for i := range ct.Value {
+		eval.ringQ.MulScalarLvl(ct.Level(), ct.Value[i], eval.t, ct.Value[i])
+	}
+}
+
+// buffCiphertextLvl returns a ciphertext of the given degree and level whose polynomials share
+// the backing arrays of the buffer polynomials.
+func buffCiphertextLvl(level, degree int, buff []*ring.Poly) (ct *rlwe.Ciphertext) {
+	ct = &rlwe.Ciphertext{Value: make([]*ring.Poly, degree+1)}
+	for i := range ct.Value {
+		ct.Value[i] = new(ring.Poly)
+		ct.Value[i].Coeffs = buff[i].Coeffs[:level+1]
+		ct.Value[i].Buff = buff[i].Buff[:buff[i].N()*(level+1)]
+	}
+	return
+}
+
+// ShallowCopy creates a shallow copy of this evaluator in which the read-only data-structures are
+// shared with the receiver.
+func (eval *evaluator) ShallowCopy() Evaluator {
+	return &evaluator{
+		evaluatorBase:    eval.evaluatorBase,
+		Evaluator:        eval.Evaluator.ShallowCopy(),
+		evaluatorBuffers: newEvaluatorBuffer(eval.params),
+	}
+}
+
+// WithKey creates a shallow copy of this evaluator in which the read-only data-structures are
+// shared with the receiver but the EvaluationKey is evaluationKey.
+func (eval *evaluator) WithKey(evaluationKey rlwe.EvaluationKey) Evaluator {
+	return &evaluator{
+		evaluatorBase:    eval.evaluatorBase,
+		Evaluator:        eval.Evaluator.WithKey(&evaluationKey),
+		evaluatorBuffers: eval.evaluatorBuffers,
+	}
+}
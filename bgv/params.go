@@ -0,0 +1,230 @@
+package bgv
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+var (
+	// PN13QP218 is a set of default parameters with logN=13 and logQP=218
+	PN13QP218 = ParametersLiteral{
+		LogN: 13,
+		Q:    []uint64{0x3fffffffef8001, 0x4000000011c001, 0x40000000120001}, // 54 + 54 + 54 bits
+		P:    []uint64{0x7ffffffffb4001},                                     // 55 bits
+		T:    65537,
+	}
+
+	// PN14QP438 is a set of default parameters with logN=14 and logQP=438
+	PN14QP438 = ParametersLiteral{
+		LogN: 14,
+		Q: []uint64{0x100000000060001, 0x80000000068001, 0x80000000080001,
+			0x3fffffffef8001, 0x40000000120001, 0x3fffffffeb8001}, // 56 + 55 + 55 + 54 + 54 + 54 bits
+		P: []uint64{0x80000000130001, 0x7fffffffe90001}, // 55 + 55 bits
+		T: 65537,
+	}
+
+	// PN15QP880 is a set of default parameters with logN=15 and logQP=880
+	PN15QP880 = ParametersLiteral{
+		LogN: 15,
+		Q: []uint64{0x7ffffffffe70001, 0x7ffffffffe10001, 0x7ffffffffcc0001, // 59 + 59 + 59 bits
+			0x400000000270001, 0x400000000350001, 0x400000000360001, // 58 + 58 + 58 bits
+			0x3ffffffffc10001, 0x3ffffffffbe0001, 0x3ffffffffbd0001, // 58 + 58 + 58 bits
+			0x4000000004d0001, 0x400000000570001, 0x400000000660001}, // 58 + 58 + 58 bits
+		P: []uint64{0xffffffffffc0001, 0x10000000001d0001, 0x10000000006e0001}, // 60 + 60 + 60 bits
+		T: 65537,
+	}
+)
+
+// DefaultParams is a set of default BGV parameters ensuring 128 bit security in the classic setting.
+// Each modulus of Q but the first one is consumed by the modulus switching that follows a multiplication.
+var DefaultParams = []ParametersLiteral{PN13QP218, PN14QP438, PN15QP880}
+
+// ParametersLiteral is a literal representation of BGV parameters.  It has public
+// fields and is used to express unchecked user-defined parameters literally into
+// Go programs. The NewParametersFromLiteral function is used to generate the actual
+// checked parameters from the literal representation.
+//
+// Users must set the polynomial degree (LogN) and the coefficient modulus, by either setting
+// the Q and P fields to the desired moduli chain, or by setting the LogQ and LogP fields to
+// the desired moduli sizes. Users must also specify the coefficient modulus in plaintext-space
+// (T), which must be coprime with the moduli of Q.
+//
+// Optionally, users may specify the error variance (Sigma) and secrets' density (H). If left
+// unset, standard default values for these field are substituted at parameter creation (see
+// NewParametersFromLiteral).
+type ParametersLiteral struct {
+	LogN     int
+	Q        []uint64
+	P        []uint64
+	LogQ     []int `json:",omitempty"`
+	LogP     []int `json:",omitempty"`
+	Pow2Base int
+	Sigma    float64
+	H        int
+	T        uint64 // Plaintext modulus
+}
+
+// RLWEParameters returns the rlwe.ParametersLiteral from the target bgv.ParametersLiteral.
+func (p ParametersLiteral) RLWEParameters() rlwe.ParametersLiteral {
+	return rlwe.ParametersLiteral{
+		LogN:     p.LogN,
+		Q:        p.Q,
+		P:        p.P,
+		LogQ:     p.LogQ,
+		LogP:     p.LogP,
+		Pow2Base: p.Pow2Base,
+		Sigma:    p.Sigma,
+		H:        p.H,
+		RingType: ring.Standard,
+	}
+}
+
+// Parameters represents a parameter set for the BGV cryptosystem. Its fields are private and
+// immutable. See ParametersLiteral for user-specified parameters.
+type Parameters struct {
+	rlwe.Parameters
+	ringT *ring.Ring
+}
+
+// NewParameters instantiate a set of BGV parameters from the generic RLWE parameters and the BGV-specific ones.
+// It returns the empty parameters Parameters{} and a non-nil error if the specified parameters are invalid.
+func NewParameters(rlweParams rlwe.Parameters, t uint64) (p Parameters, err error) {
+
+	if rlweParams.Equals(rlwe.Parameters{}) {
+		return Parameters{}, fmt.Errorf("provided RLWE parameters are invalid")
+	}
+
+	if t < 2 {
+		return Parameters{}, fmt.Errorf("t=%d must be at least 2", t)
+	}
+
+	// The moduli of Q are prime, hence t is coprime with Q if no modulus divides it.
+	for _, qi := range rlweParams.Q() {
+		if t%qi == 0 {
+			return Parameters{}, fmt.Errorf("t=%d must be coprime with Q but is divisible by %d", t, qi)
+		}
+	}
+
+	var ringT *ring.Ring
+	if ringT, err = newRingT(rlweParams.N(), t); err != nil {
+		return Parameters{}, err
+	}
+
+	return Parameters{rlweParams, ringT}, nil
+}
+
+// newRingT returns the plaintext ring, which supports the NTT (and thus the slots) only if t = 1 mod 2N.
+func newRingT(N int, t uint64) (ringT *ring.Ring, err error) {
+	if ringT, err = ring.NewRing(N, []uint64{t}); err != nil {
+		return ring.NewRingWithoutNTT(N, []uint64{t})
+	}
+	return
+}
+
+// NewParametersFromLiteral instantiate a set of BGV parameters from a ParametersLiteral specification.
+// It returns the empty parameters Parameters{} and a non-nil error if the specified parameters are invalid.
+//
+// See `rlwe.NewParametersFromLiteral` for default values of the optional fields.
+func NewParametersFromLiteral(pl ParametersLiteral) (Parameters, error) {
+	rlweParams, err := rlwe.NewParametersFromLiteral(pl.RLWEParameters())
+	if err != nil {
+		return Parameters{}, err
+	}
+	return NewParameters(rlweParams, pl.T)
+}
+
+// T returns the plaintext coefficient modulus t.
+func (p Parameters) T() uint64 {
+	return p.ringT.Modulus[0]
+}
+
+// LogT returns log2(plaintext coefficient modulus).
+func (p Parameters) LogT() int {
+	return bits.Len64(p.T())
+}
+
+// RingT returns a pointer to the plaintext ring.
+func (p Parameters) RingT() *ring.Ring {
+	return p.ringT
+}
+
+// QiInvModT returns q_i^-1 mod t, the factor by which the modulus switching from
+// level i to level i-1 multiplies the scale of a ciphertext.
+func (p Parameters) QiInvModT(i int) uint64 {
+	T := new(big.Int).SetUint64(p.T())
+	return new(big.Int).ModInverse(new(big.Int).SetUint64(p.Q()[i]), T).Uint64()
+}
+
+// Equals compares two sets of parameters for equality.
+func (p Parameters) Equals(other Parameters) bool {
+	res := p.Parameters.Equals(other.Parameters)
+	res = res && (p.T() == other.T())
+	return res
+}
+
+// MarshalBinary returns a []byte representation of the parameter set.
+func (p Parameters) MarshalBinary() ([]byte, error) {
+	if p.LogN() == 0 { // if N is 0, then p is the zero value
+		return []byte{}, nil
+	}
+
+	rlweBytes, err := p.Parameters.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	// len(rlweBytes) : RLWE parameters
+	// 8 byte : T
+	var tBytes [8]byte
+	binary.BigEndian.PutUint64(tBytes[:], p.T())
+	data := append(rlweBytes, tBytes[:]...)
+	return data, nil
+}
+
+// UnmarshalBinary decodes a []byte into a parameter set struct.
+func (p *Parameters) UnmarshalBinary(data []byte) (err error) {
+	if err := p.Parameters.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	t := binary.BigEndian.Uint64(data[len(data)-8:])
+
+	if p.ringT, err = newRingT(p.N(), t); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalBinarySize returns the length of the []byte encoding of the reciever.
+func (p Parameters) MarshalBinarySize() int {
+	return p.Parameters.MarshalBinarySize() + 8
+}
+
+// MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
+func (p Parameters) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ParametersLiteral{
+		LogN:  p.LogN(),
+		Q:     p.Q(),
+		P:     p.P(),
+		H:     p.HammingWeight(),
+		Sigma: p.Sigma(),
+		T:     p.T(),
+	})
+}
+
+// UnmarshalJSON reads a JSON representation of a parameter set into the receiver Parameter. See `Unmarshal` from the `encoding/json` package.
+func (p *Parameters) UnmarshalJSON(data []byte) (err error) {
+	var params ParametersLiteral
+	if err = json.Unmarshal(data, &params); err != nil {
+		return
+	}
+	*p, err = NewParametersFromLiteral(params)
+	return
+}
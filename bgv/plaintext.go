@@ -0,0 +1,41 @@
+package bgv
+
+import (
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Plaintext is a Element with only one Poly. It represents a plaintext element of R_t multiplied by its scale
+// and lifted to R_q with centered coefficients: unlike in BFV, the message is not scaled up by Q/t.
+// The scale is an element of Z_t that is tracked through the homomorphic operations and removed at decoding.
+type Plaintext struct {
+	*rlwe.Plaintext
+	Scale uint64
+}
+
+// NewPlaintext creates and allocates a new plaintext in RingQ (multiple moduli of Q) at the max level and with scale 1.
+func NewPlaintext(params Parameters) *Plaintext {
+	return NewPlaintextLvl(params, params.MaxLevel())
+}
+
+// NewPlaintextLvl creates and allocates a new plaintext in RingQ (multiple moduli of Q) with level+1 moduli and scale 1.
+func NewPlaintextLvl(params Parameters, level int) *Plaintext {
+	return &Plaintext{Plaintext: rlwe.NewPlaintext(params.Parameters, level), Scale: 1}
+}
+
+// NewPlaintextAtLevelFromPoly construct a new Plaintext at a specific level and with scale 1
+// where the message is set to the passed poly. No checks are performed on poly and
+// the returned Plaintext will share its backing array of coefficient.
+func NewPlaintextAtLevelFromPoly(level int, poly *ring.Poly) *Plaintext {
+	return &Plaintext{Plaintext: rlwe.NewPlaintextAtLevelFromPoly(level, poly), Scale: 1}
+}
+
+// ScalingFactor returns the scaling factor of the plaintext.
+func (pt *Plaintext) ScalingFactor() uint64 {
+	return pt.Scale
+}
+
+// SetScalingFactor sets the scaling factor of the plaintext.
+func (pt *Plaintext) SetScalingFactor(scale uint64) {
+	pt.Scale = scale
+}
@@ -0,0 +1,62 @@
+package bgv
+
+import (
+	"math/big"
+
+	"github.com/cipherflow-fhe/lattigo/ring"
+)
+
+// modulusSwitcher divides polynomials of R_Q by the last moduli of Q while preserving their class modulo t:
+// given p = m + t * e mod Q, it returns p' = (q_l^-1 mod t) * m + t * e' mod Q/q_l with |e'| ~ |e|/q_l + 1/2.
+// It computes p' = t * round((t^-1 * p mod Q) / q_l), where the rounding error is multiplied by t.
+type modulusSwitcher struct {
+	ringQ    *ring.Ring
+	t        uint64
+	tInvModQ *big.Int // t^-1 mod Q
+
+	buffQ [2]*ring.Poly
+}
+
+func newModulusSwitcher(params Parameters) *modulusSwitcher {
+	ringQ := params.RingQ()
+	return &modulusSwitcher{
+		ringQ:    ringQ,
+		t:        params.T(),
+		tInvModQ: new(big.Int).ModInverse(new(big.Int).SetUint64(params.T()), ringQ.ModulusAtLevel[params.MaxLevel()]),
+		buffQ:    [2]*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly()},
+	}
+}
+
+// shallowCopy returns a copy of the receiver with the same read-only data and new buffers.
+func (ms *modulusSwitcher) shallowCopy() *modulusSwitcher {
+	return &modulusSwitcher{
+		ringQ:    ms.ringQ,
+		t:        ms.t,
+		tInvModQ: ms.tInvModQ,
+		buffQ:    [2]*ring.Poly{ms.ringQ.NewPoly(), ms.ringQ.NewPoly()},
+	}
+}
+
+// switchModulusLvl divides p0, of level `level`, by its last `nbDrops` moduli and writes the result on p1,
+// of level `level`-`nbDrops`. The message of p0 is multiplied by the inverse modulo t of the dropped moduli.
+func (ms *modulusSwitcher) switchModulusLvl(level, nbDrops int, p0, p1 *ring.Poly) {
+
+	if nbDrops == 0 {
+		if p0 != p1 {
+			ring.CopyValuesLvl(level, p0, p1)
+		}
+		return
+	}
+
+	ms.ringQ.MulScalarBigintLvl(level, p0, ms.tInvModQ, ms.buffQ[0])
+	ms.ringQ.DivRoundByLastModulusManyLvl(level, nbDrops, ms.buffQ[0], ms.buffQ[1], p1)
+	ms.ringQ.MulScalarLvl(level-nbDrops, p1, ms.t, p1)
+}
+
+// scaleDown returns prod_{level-nbDrops < i <= level} q_i^-1 mod t, the factor by which switchModulusLvl
+// multiplies the message.
+func scaleDown(ringQ *ring.Ring, level, nbDrops int, t uint64) uint64 {
+	T := new(big.Int).SetUint64(t)
+	QDrop := new(big.Int).Quo(ringQ.ModulusAtLevel[level], ringQ.ModulusAtLevel[level-nbDrops])
+	return QDrop.ModInverse(QDrop.Mod(QDrop, T), T).Uint64()
+}
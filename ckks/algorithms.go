@@ -7,6 +7,31 @@ import (
 // PowerOf2 computes op^(2^logPow2), consuming logPow2 levels, and returns the result on opOut. Providing an evaluation
 // key is necessary when logPow2 > 1.
 func (eval *evaluator) PowerOf2(op *Ciphertext, logPow2 int, opOut *Ciphertext) {
+	powerOf2(eval, op, logPow2, opOut)
+}
+
+// PowerNew computes op^degree, consuming log(degree) levels, and returns the result on a new element. Providing an evaluation
+// key is necessary when degree > 2.
+func (eval *evaluator) PowerNew(op *Ciphertext, degree int) (opOut *Ciphertext) {
+	opOut = NewCiphertext(eval.params, 1, op.Level(), op.Scale)
+	eval.Power(op, degree, opOut)
+	return
+}
+
+// Power computes op^degree, consuming log(degree) levels, and returns the result on opOut. Providing an evaluation
+// key is necessary when degree > 2.
+func (eval *evaluator) Power(op *Ciphertext, degree int, opOut *Ciphertext) {
+	power(eval, eval.params, op, degree, opOut)
+}
+
+// InverseNew computes 1/op and returns the result on a new element, iterating for n steps and consuming n levels. The algorithm requires the encrypted values to be in the range
+// [-1.5 - 1.5i, 1.5 + 1.5i] or the result will be wrong. Each iteration increases the precision.
+func (eval *evaluator) InverseNew(op *Ciphertext, steps int) (opOut *Ciphertext) {
+	return inverseNew(eval, op, steps)
+}
+
+// powerOf2 is the implementation of Evaluator.PowerOf2 that only relies on the interface methods of eval.
+func powerOf2(eval Evaluator, op *Ciphertext, logPow2 int, opOut *Ciphertext) {
 
 	if logPow2 == 0 {
 
@@ -33,17 +58,8 @@ func (eval *evaluator) PowerOf2(op *Ciphertext, logPow2 int, opOut *Ciphertext)
 	}
 }
 
-// PowerNew computes op^degree, consuming log(degree) levels, and returns the result on a new element. Providing an evaluation
-// key is necessary when degree > 2.
-func (eval *evaluator) PowerNew(op *Ciphertext, degree int) (opOut *Ciphertext) {
-	opOut = NewCiphertext(eval.params, 1, op.Level(), op.Scale)
-	eval.Power(op, degree, opOut)
-	return
-}
-
-// Power computes op^degree, consuming log(degree) levels, and returns the result on opOut. Providing an evaluation
-// key is necessary when degree > 2.
-func (eval *evaluator) Power(op *Ciphertext, degree int, opOut *Ciphertext) {
+// power is the implementation of Evaluator.Power that only relies on the interface methods of eval.
+func power(eval Evaluator, params Parameters, op *Ciphertext, degree int, opOut *Ciphertext) {
 
 	if degree < 1 {
 		panic("eval.Power -> degree cannot be smaller than 1")
@@ -65,7 +81,7 @@ func (eval *evaluator) Power(op *Ciphertext, degree int, opOut *Ciphertext) {
 		logDegree = bits.Len64(uint64(degree)) - 1
 		po2Degree = 1 << logDegree
 
-		tmp := NewCiphertext(eval.params, 1, tmpct0.Level(), tmpct0.Scale)
+		tmp := NewCiphertext(params, 1, tmpct0.Level(), tmpct0.Scale)
 
 		eval.PowerOf2(tmpct0, logDegree, tmp)
 
@@ -79,9 +95,8 @@ func (eval *evaluator) Power(op *Ciphertext, degree int, opOut *Ciphertext) {
 	}
 }
 
-// InverseNew computes 1/op and returns the result on a new element, iterating for n steps and consuming n levels. The algorithm requires the encrypted values to be in the range
-// [-1.5 - 1.5i, 1.5 + 1.5i] or the result will be wrong. Each iteration increases the precision.
-func (eval *evaluator) InverseNew(op *Ciphertext, steps int) (opOut *Ciphertext) {
+// inverseNew is the implementation of Evaluator.InverseNew that only relies on the interface methods of eval.
+func inverseNew(eval Evaluator, op *Ciphertext, steps int) (opOut *Ciphertext) {
 
 	cbar := eval.NegNew(op)
 
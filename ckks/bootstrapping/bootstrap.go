@@ -2,6 +2,7 @@
 package bootstrapping
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/cipherflow-fhe/lattigo/ckks"
@@ -14,7 +15,29 @@ import (
 // can be used to do a scale matching.
 func (btp *Bootstrapper) Bootstrapp(ctIn *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
 
-	ctOut = ctIn.CopyNew()
+	// Steps 1 to 3 : ModUp, CoeffsToSlots and EvalMod
+	ctReal, ctImag := btp.HalfBootstrapp(ctIn)
+
+	// The SlotsToCoeffs matrices scale the values from ScalingFactor/MessageRatio to params.DefaultScale().
+	ctReal.Scale = btp.params.DefaultScale()
+	if ctImag != nil {
+		ctImag.Scale = btp.params.DefaultScale()
+	}
+
+	// Step 4 : SlotsToCoeffs (Homomorphic decoding)
+	ctOut = btp.SlotsToCoeffsNew(ctReal, ctImag, btp.stcMatrices)
+
+	return
+}
+
+// HalfBootstrapp evaluates the bootstrapping without its last step, the SlotsToCoeffs, and returns the output of the EvalMod step
+// at the level LevelStart of the SlotsToCoeffs and at scale ScalingFactor/MessageRatio of the EvalMod step.
+// It has the same requirements on the input ciphertext as Bootstrapp.
+// The slots of the output are the coefficients of the plaintext of the input ciphertext divided by its scale, in bit-reversed order:
+// If n < N/2 then ctReal = Ecd(real|imag) and ctImag = nil, else ctReal = Ecd(real) and ctImag = Ecd(imag).
+func (btp *Bootstrapper) HalfBootstrapp(ctIn *ckks.Ciphertext) (ctReal, ctImag *ckks.Ciphertext) {
+
+	ctOut := ctIn.CopyNew()
 
 	// Drops the level to 1
 	for ctOut.Level() > 1 {
@@ -59,22 +82,81 @@ func (btp *Bootstrapper) Bootstrapp(ctIn *ckks.Ciphertext) (ctOut *ckks.Cipherte
 	btp.Trace(ctOut, btp.params.LogSlots(), ctOut)
 
 	// Step 2 : CoeffsToSlots (Homomorphic encoding)
-	ctReal, ctImag := btp.CoeffsToSlotsNew(ctOut, btp.ctsMatrices)
+	ctReal, ctImag = btp.CoeffsToSlotsNew(ctOut, btp.ctsMatrices)
 
 	// Step 3 : EvalMod (Homomorphic modular reduction)
 	// ctReal = Ecd(real)
 	// ctImag = Ecd(imag)
 	// If n < N/2 then ctReal = Ecd(real|imag)
+	scale := btp.evalModPoly.ScalingFactor() / btp.evalModPoly.MessageRatio()
+
 	ctReal = btp.EvalModNew(ctReal, btp.evalModPoly)
-	ctReal.Scale = btp.params.DefaultScale()
+	ctReal.Scale = scale
 
 	if ctImag != nil {
 		ctImag = btp.EvalModNew(ctImag, btp.evalModPoly)
-		ctImag.Scale = btp.params.DefaultScale()
+		ctImag.Scale = scale
 	}
 
-	// Step 4 : SlotsToCoeffs (Homomorphic decoding)
-	ctOut = btp.SlotsToCoeffsNew(ctReal, ctImag, btp.stcMatrices)
+	return
+}
+
+// BootstrappHighPrecision re-encrypts a ciphertext with the iterative bootstrapping of Bae et al., META-BTS: Bootstrapping Precision Beyond the Limit.
+// After a first call to Bootstrapp, each of the next iterations computes homomorphically the difference between the input and the
+// output, scales it up by 2^logScaling for free by dividing its scale, bootstraps it, and adds it back to the output after a division
+// by 2^logScaling, so that each iteration improves the precision by up to logScaling bits, until it reaches the rounding error of the
+// rescaling at scale params.DefaultScale().
+// logScaling must be chosen such that 2^logScaling times the error of a single bootstrapping stays within the range of the EvalMod step,
+// e.g. a few bits smaller than the precision of Bootstrapp.
+// The input ciphertext must have scale params.DefaultScale(), or be at level one or more, in which case one level is used to set its scale.
+// Each iteration after the first consumes one level, hence the output ciphertext is at level OutputLevel()-(iterations-1).
+func (btp *Bootstrapper) BootstrappHighPrecision(ctIn *ckks.Ciphertext, iterations, logScaling int) (ctOut *ckks.Ciphertext) {
+
+	if iterations < 1 {
+		panic("cannot BootstrappHighPrecision: iterations must be at least 1")
+	}
+
+	if iterations-1 > btp.OutputLevel() {
+		panic(fmt.Sprintf("cannot BootstrappHighPrecision: %d iterations consume more than OutputLevel() = %d levels", iterations, btp.OutputLevel()))
+	}
+
+	ctIn = ctIn.CopyNew()
+
+	// The residual ctIn - ctOut can only be computed if both have the same scale.
+	if ctIn.Scale != btp.params.DefaultScale() {
+
+		if ctIn.Level() == 0 {
+			panic("cannot BootstrappHighPrecision: input ciphertext at level 0 must have scale params.DefaultScale()")
+		}
+
+		btp.SetScale(ctIn, btp.params.DefaultScale())
+	}
+
+	ctOut = btp.Bootstrapp(ctIn)
+
+	scaling := math.Exp2(float64(logScaling))
+
+	for i := 1; i < iterations; i++ {
+
+		// The operand with the higher level is dropped to the level of the other one.
+		if ctIn.Level() > ctOut.Level() {
+			btp.DropLevel(ctIn, ctIn.Level()-ctOut.Level())
+		}
+
+		// 2^logScaling * (ctIn - ctOut)
+		tmp := btp.DropLevelNew(ctOut, ctOut.Level()-ctIn.Level())
+		btp.Sub(ctIn, tmp, tmp)
+		tmp.Scale /= scaling
+
+		tmp = btp.Bootstrapp(tmp)
+
+		// ctOut + 2^-logScaling * Bootstrapp(2^logScaling * (ctIn - ctOut))
+		btp.MultByConstAndAdd(tmp, 1/scaling, ctOut)
+
+		if err := btp.Rescale(ctOut, btp.params.DefaultScale(), ctOut); err != nil {
+			panic(err)
+		}
+	}
 
 	return
 }
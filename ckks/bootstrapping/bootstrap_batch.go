@@ -0,0 +1,126 @@
+package bootstrapping
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ring"
+)
+
+// BootstrappReal re-encrypts two ciphertexts encrypting real values at the cost of a single bootstrapping.
+// The two ciphertexts are packed into the real and imaginary parts of a single ciphertext, which is bootstrapped
+// and then split back with one conjugation, which requires the rotation key for the row rotation.
+// The message of the packed ciphertext is halved by changing its scale, so that its magnitude does not exceed the
+// largest magnitude of the two inputs. This costs one bit of precision compared to Bootstrapp.
+// The two input ciphertexts must have the same scale and their values must have zero imaginary parts: the outputs
+// are Re(ct0) - Im(ct1) and Re(ct1) + Im(ct0), so a non-zero imaginary part of one input leaks into the other output.
+func (btp *Bootstrapper) BootstrappReal(ct0, ct1 *ckks.Ciphertext) (ctOut0, ctOut1 *ckks.Ciphertext) {
+
+	if btp.params.RingType() != ring.Standard {
+		panic("cannot BootstrappReal: method is only supported when params.RingType() == ring.Standard")
+	}
+
+	if ct0.Scale != ct1.Scale {
+		panic("cannot BootstrappReal: input ciphertexts must have the same scale")
+	}
+
+	// ct = (ct0 + i*ct1)/2
+	ct := btp.MultByiNew(ct1)
+	btp.Add(ct, ct0, ct)
+	ct.Scale *= 2
+
+	ct = btp.Bootstrapp(ct)
+
+	// ctOut0 = ct + conj(ct) and ctOut1 = (ct - conj(ct))/i
+	ctConj := btp.ConjugateNew(ct)
+
+	ctOut0 = btp.AddNew(ct, ctConj)
+
+	ctOut1 = btp.SubNew(ct, ctConj)
+	btp.DivByi(ctOut1, ctOut1)
+
+	return
+}
+
+// BootstrappSparse re-encrypts several ciphertexts encrypting 2^logSlots slots each at the cost of a single bootstrapping,
+// by packing up to 2^(params.LogSlots()-logSlots) of them into the disjoint blocks of a single ciphertext of 2^params.LogSlots() slots.
+// The packing and the unpacking each consume one level: the input ciphertexts must be at level one or more, must have the same
+// scale and, as for Bootstrapp, the scale of the packed ciphertext must be an exact power of two if it is at level zero. The output
+// ciphertexts are at level OutputLevel()-1 and unpacking them requires the rotation keys of RotationsForSparseBootstrapping.
+func (btp *Bootstrapper) BootstrappSparse(logSlots int, cts []*ckks.Ciphertext) (ctOut []*ckks.Ciphertext) {
+
+	btpLogSlots := btp.params.LogSlots()
+
+	if logSlots >= btpLogSlots {
+		panic(fmt.Sprintf("cannot BootstrappSparse: logSlots must be smaller than params.LogSlots() = %d", btpLogSlots))
+	}
+
+	if len(cts) > 1<<(btpLogSlots-logSlots) {
+		panic(fmt.Sprintf("cannot BootstrappSparse: cannot pack more than 2^(params.LogSlots()-logSlots) = %d ciphertexts", 1<<(btpLogSlots-logSlots)))
+	}
+
+	level := cts[0].Level()
+	for _, ct := range cts {
+
+		if ct.Scale != cts[0].Scale {
+			panic("cannot BootstrappSparse: input ciphertexts must have the same scale")
+		}
+
+		if ct.Level() < level {
+			level = ct.Level()
+		}
+	}
+
+	if level == 0 {
+		panic("cannot BootstrappSparse: input ciphertexts must be at level one or more")
+	}
+
+	encoder := ckks.NewEncoder(btp.params)
+
+	// Since the input ciphertexts are replicated every 2^logSlots slots, masking
+	// the i-th one on the i-th block is enough to pack them without rotations.
+	ct := ckks.NewCiphertext(btp.params, 1, level, cts[0].Scale*btp.params.QiFloat64(level))
+	tmp := ckks.NewCiphertext(btp.params, 1, level, ct.Scale)
+	for i := range cts {
+		btp.Mul(cts[i], btp.blockMask(encoder, logSlots, i, level, 1), tmp)
+		btp.Add(ct, tmp, ct)
+	}
+
+	if err := btp.Rescale(ct, cts[0].Scale, ct); err != nil {
+		panic(err)
+	}
+
+	ct = btp.Bootstrapp(ct)
+
+	// The Trace averages the 2^(params.LogSlots()-logSlots) blocks of the
+	// masked ciphertext, hence the masks are scaled by the number of blocks.
+	ctOut = make([]*ckks.Ciphertext, len(cts))
+	for i := range cts {
+
+		ctOut[i] = btp.MulNew(ct, btp.blockMask(encoder, logSlots, i, ct.Level(), float64(int(1)<<(btpLogSlots-logSlots))))
+
+		if err := btp.Rescale(ctOut[i], btp.params.DefaultScale(), ctOut[i]); err != nil {
+			panic(err)
+		}
+
+		btp.Trace(ctOut[i], logSlots, ctOut[i])
+	}
+
+	return
+}
+
+// blockMask returns a plaintext at the given level whose slots are equal to value on the i-th block of 2^logSlots slots
+// and to zero elsewhere. Its scale is the i-th modulus of the moduli chain, so that the rescaling after the multiplication
+// by the mask preserves the scale of the ciphertext.
+func (btp *Bootstrapper) blockMask(encoder ckks.Encoder, logSlots, i, level int, value float64) (pt *ckks.Plaintext) {
+
+	values := make([]float64, btp.params.Slots())
+	for j := i << logSlots; j < (i+1)<<logSlots; j++ {
+		values[j] = value
+	}
+
+	pt = ckks.NewPlaintext(btp.params, level, btp.params.QiFloat64(level))
+	encoder.Encode(values, pt, btp.params.LogSlots())
+
+	return
+}
@@ -1,6 +1,8 @@
 package bootstrapping
 
 import (
+	"fmt"
+
 	"github.com/cipherflow-fhe/lattigo/ckks"
 	"github.com/cipherflow-fhe/lattigo/ckks/advanced"
 	"github.com/cipherflow-fhe/lattigo/utils"
@@ -83,6 +85,29 @@ func (p *Parameters) UnmarshalBinary(data []byte) (err error) {
 	return
 }
 
+// validate checks that the EvalMod parameters are consistent and that the starting levels of
+// the CoeffsToSlots, EvalMod and SlotsToCoeffs steps match their depths.
+func (p *Parameters) validate() error {
+	if p.EvalModParameters.SineType == advanced.Sin && p.EvalModParameters.DoubleAngle != 0 {
+		return fmt.Errorf("cannot use double angle formul for SineType = Sin -> must use SineType = Cos")
+	}
+	if p.EvalModParameters.SineType == advanced.Cos1 && p.EvalModParameters.SineDeg < 2*(p.EvalModParameters.K-1) {
+		return fmt.Errorf("SineType 'advanced.Cos1' uses a minimum degree of 2*(K-1) but EvalMod degree is smaller")
+	}
+	if p.CoeffsToSlotsParameters.LevelStart-p.CoeffsToSlotsParameters.Depth(true) != p.EvalModParameters.LevelStart {
+		return fmt.Errorf("starting level and depth of CoeffsToSlotsParameters inconsistent starting level of SineEvalParameters")
+	}
+	if p.EvalModParameters.LevelStart-p.EvalModParameters.Depth() != p.SlotsToCoeffsParameters.LevelStart {
+		return fmt.Errorf("starting level and depth of SineEvalParameters inconsistent starting level of CoeffsToSlotsParameters")
+	}
+	return nil
+}
+
+// OutputLevel returns the level of the ciphertexts returned by the bootstrapping.
+func (p *Parameters) OutputLevel() int {
+	return p.SlotsToCoeffsParameters.LevelStart - p.SlotsToCoeffsParameters.Depth(true)
+}
+
 // RotationsForBootstrapping returns the list of rotations performed during the Bootstrapping operation.
 func (p *Parameters) RotationsForBootstrapping(params ckks.Parameters) (rotations []int) {
 
@@ -110,3 +135,18 @@ func (p *Parameters) RotationsForBootstrapping(params ckks.Parameters) (rotation
 
 	return
 }
+
+// RotationsForSparseBootstrapping returns the list of rotations performed during the BootstrappSparse operation
+// for ciphertexts of 2^logSlots slots, which are those of the Bootstrapping and those of the Trace used by the unpacking.
+func (p *Parameters) RotationsForSparseBootstrapping(params ckks.Parameters, logSlots int) (rotations []int) {
+
+	rotations = p.RotationsForBootstrapping(params)
+
+	for i := logSlots; i < params.LogSlots(); i++ {
+		if !utils.IsInSliceInt(1<<i, rotations) {
+			rotations = append(rotations, 1<<i)
+		}
+	}
+
+	return
+}
@@ -1,6 +1,8 @@
 package bootstrapping
 
 import (
+	"bytes"
+	"encoding/binary"
 	"flag"
 	"fmt"
 	"runtime"
@@ -8,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ckks/advanced"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
 	"github.com/cipherflow-fhe/lattigo/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,6 +46,184 @@ func TestBootstrapParametersMarshalling(t *testing.T) {
 	assert.Equal(t, bootstrapParams, *bootstrapParamsNew)
 }
 
+func TestBootstrapEvaluationKeysMarshalling(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping bootstrapping tests for GOARCH=wasm")
+	}
+
+	paramSet := DefaultParametersSparse[0]
+	ckksParams := paramSet.SchemeParams
+	btpParams := paramSet.BootstrappingParams
+
+	// Insecure params for fast testing only
+	if !*flagLongTest {
+		ckksParams.LogN = 13
+		ckksParams.LogSlots = 12
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckksParams)
+	require.NoError(t, err)
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	evk := GenEvaluationKeys(btpParams, params, sk)
+
+	buff := new(bytes.Buffer)
+	n, err := WriteEvaluationKeys(buff, params, btpParams, evk)
+	require.NoError(t, err)
+	require.Equal(t, int64(buff.Len()), n)
+
+	data := buff.Bytes()
+
+	t.Run(ParamsToString(params, "Bootstrapping/Marshalling/All/"), func(t *testing.T) {
+
+		paramsNew, btpParamsNew, evkNew, err := ReadEvaluationKeys(bytes.NewReader(data), StageAll)
+		require.NoError(t, err)
+
+		require.True(t, params.Equals(paramsNew))
+		require.Equal(t, btpParams, btpParamsNew)
+
+		require.True(t, evk.Rlk.Equals(evkNew.Rlk))
+		require.True(t, evk.SwkDtS.Equals(evkNew.SwkDtS))
+		require.True(t, evk.SwkStD.Equals(evkNew.SwkStD))
+		require.Equal(t, len(evk.Rtks.Keys), len(evkNew.Rtks.Keys))
+		for galEl, key := range evk.Rtks.Keys {
+			require.True(t, key.Equals(evkNew.Rtks.Keys[galEl]))
+		}
+
+		encoder := ckks.NewEncoder(paramsNew)
+		encryptor := ckks.NewEncryptor(paramsNew, sk)
+		decryptor := ckks.NewDecryptor(paramsNew, sk)
+
+		btp, err := NewBootstrapper(paramsNew, btpParamsNew, evkNew)
+		require.NoError(t, err)
+
+		values := make([]complex128, paramsNew.Slots())
+		for i := range values {
+			values[i] = utils.RandComplex128(-1, 1)
+		}
+
+		ciphertext := btp.Bootstrapp(encryptor.EncryptNew(encoder.EncodeNew(values, 0, paramsNew.DefaultScale(), paramsNew.LogSlots())))
+
+		verifyTestVectors(paramsNew, encoder, decryptor, values, ciphertext, paramsNew.LogSlots(), 0, t)
+	})
+
+	t.Run(ParamsToString(params, "Bootstrapping/Marshalling/LinearTransforms/"), func(t *testing.T) {
+
+		_, _, evkNew, err := ReadEvaluationKeys(bytes.NewReader(data), StageCoeffsToSlots|StageSlotsToCoeffs)
+		require.NoError(t, err)
+
+		require.Nil(t, evkNew.Rlk)
+		require.Nil(t, evkNew.SwkDtS)
+		require.Nil(t, evkNew.SwkStD)
+
+		galEls := btpParams.galoisElementsForStages(params, StageCoeffsToSlots|StageSlotsToCoeffs)
+		require.Equal(t, len(galEls), len(evkNew.Rtks.Keys))
+		for galEl := range galEls {
+			require.True(t, evk.Rtks.Keys[galEl].Equals(evkNew.Rtks.Keys[galEl]))
+		}
+
+		// The Trace, CoeffsToSlots and SlotsToCoeffs steps evaluated with the loaded keys match the ones
+		// evaluated with all the keys
+		bb, err := NewBootstrapperBase(params, btpParams)
+		require.NoError(t, err)
+
+		encoder := ckks.NewEncoder(params)
+		encryptor := ckks.NewEncryptor(params, sk)
+
+		values := make([]complex128, params.Slots())
+		for i := range values {
+			values[i] = utils.RandComplex128(-1, 1)
+		}
+
+		ciphertext := encryptor.EncryptNew(encoder.EncodeNew(values, bb.ctsMatrices.LevelStart, params.DefaultScale(), params.LogSlots()))
+
+		linearTransforms := func(evk rlwe.EvaluationKey) *ckks.Ciphertext {
+			eval := advanced.NewEvaluator(params, evk)
+			ct := eval.TraceNew(ciphertext, params.LogSlots())
+			ctReal, ctImag := eval.CoeffsToSlotsNew(ct, bb.ctsMatrices)
+			return eval.SlotsToCoeffsNew(ctReal, ctImag, bb.stcMatrices)
+		}
+
+		ctWant := linearTransforms(rlwe.EvaluationKey{Rtks: evk.Rtks})
+		ctHave := linearTransforms(rlwe.EvaluationKey{Rtks: evkNew.Rtks})
+
+		require.Equal(t, ctWant.Scale, ctHave.Scale)
+		for i := range ctWant.Value {
+			require.True(t, ctWant.Value[i].Equals(ctHave.Value[i]))
+		}
+
+		// Truncated data
+		_, _, _, err = ReadEvaluationKeys(bytes.NewReader(data[:len(data)-1]), StageSlotsToCoeffs)
+		require.Error(t, err)
+	})
+
+	paramsBytes, err := params.MarshalBinary()
+	require.NoError(t, err)
+	btpParamsBytes, err := btpParams.MarshalBinary()
+	require.NoError(t, err)
+
+	// Start of the records of the keys, after the two records of the parameters
+	keysStart := 2*17 + len(paramsBytes) + len(btpParamsBytes)
+
+	t.Run(ParamsToString(params, "Bootstrapping/Marshalling/Binary/"), func(t *testing.T) {
+
+		// Releases the keys decoded by the previous tests
+		runtime.GC()
+
+		// MarshalBinary writes the same records as WriteEvaluationKeys, without the parameters
+		evkBytes, err := evk.MarshalBinary()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data[keysStart:], evkBytes))
+		evkBytes = nil
+		runtime.GC()
+
+		evkNew := new(EvaluationKeys)
+		require.NoError(t, evkNew.UnmarshalBinary(data[keysStart:]))
+
+		require.True(t, evk.Rlk.Equals(evkNew.Rlk))
+		require.True(t, evk.SwkDtS.Equals(evkNew.SwkDtS))
+		require.True(t, evk.SwkStD.Equals(evkNew.SwkStD))
+		require.True(t, evk.Rtks.Equals(evkNew.Rtks))
+
+		require.Error(t, evkNew.UnmarshalBinary(data[keysStart:len(data)-1]))
+	})
+
+	t.Run(ParamsToString(params, "Bootstrapping/Marshalling/Corrupted/"), func(t *testing.T) {
+
+		// corrupt overwrites data[start:end] with value, reads the keys with each of the stages and restores data.
+		corrupt := func(start, end int, value byte, stages ...Stage) {
+			backup := append([]byte{}, data[start:end]...)
+			for i := start; i < end; i++ {
+				data[i] = value
+			}
+			for _, stage := range stages {
+				_, _, _, err := ReadEvaluationKeys(bytes.NewReader(data), stage)
+				require.Error(t, err)
+			}
+			copy(data[start:end], backup)
+		}
+
+		// The length of the first key record is larger than the maximum for params
+		corrupt(keysStart+9, keysStart+17, 0xff, StageAll, StageSlotsToCoeffs)
+
+		// The length of the first parameters record is larger than the maximum
+		corrupt(9, 17, 0xff, StageAll)
+
+		// The parameters records have their own tag
+		corrupt(0, 1, recordRlk, StageAll)
+
+		// A duplicate of the first key record is rejected, whether the key is decoded or skipped
+		rlkEnd := keysStart + 17 + int(binary.BigEndian.Uint64(data[keysStart+9:keysStart+17]))
+		duplicate := append(append(append([]byte{}, data[:rlkEnd]...), data[keysStart:rlkEnd]...), data[rlkEnd:]...)
+		for _, stage := range []Stage{StageAll, StageSlotsToCoeffs} {
+			_, _, _, err := ReadEvaluationKeys(bytes.NewReader(duplicate), stage)
+			require.Error(t, err)
+		}
+	})
+}
+
 func TestBootstrap(t *testing.T) {
 
 	if runtime.GOARCH == "wasm" {
@@ -151,6 +334,208 @@ func testbootstrap(params ckks.Parameters, original bool, btpParams Parameters,
 	})
 }
 
+func TestBootstrapBatch(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping bootstrapping tests for GOARCH=wasm")
+	}
+
+	paramSet := DefaultParametersSparse[0]
+	ckksParams := paramSet.SchemeParams
+	btpParams := paramSet.BootstrappingParams
+
+	// Insecure params for fast testing only
+	if !*flagLongTest {
+		ckksParams.LogN = 13
+		ckksParams.LogSlots = 12
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckksParams)
+	require.NoError(t, err)
+
+	logSlots := params.LogSlots() - 2
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	encoder := ckks.NewEncoder(params)
+	encryptor := ckks.NewEncryptor(params, sk)
+	decryptor := ckks.NewDecryptor(params, sk)
+
+	evk := GenEvaluationKeys(btpParams, params, sk)
+	evk.Rtks = kgen.GenRotationKeysForRotations(btpParams.RotationsForSparseBootstrapping(params, logSlots), true, sk)
+
+	btp, err := NewBootstrapper(params, btpParams, evk)
+	require.NoError(t, err)
+
+	t.Run(ParamsToString(params, "Bootstrapping/Real/"), func(t *testing.T) {
+
+		values := make([][]complex128, 2)
+		ciphertexts := make([]*ckks.Ciphertext, 2)
+		for i := range values {
+			values[i] = make([]complex128, params.Slots())
+			for j := range values[i] {
+				values[i][j] = complex(utils.RandFloat64(-1, 1), 0)
+			}
+			ciphertexts[i] = encryptor.EncryptNew(encoder.EncodeNew(values[i], 0, params.DefaultScale(), params.LogSlots()))
+		}
+
+		ciphertexts[0], ciphertexts[1] = btp.BootstrappReal(ciphertexts[0], ciphertexts[1])
+
+		for i := range ciphertexts {
+			require.Equal(t, btpParams.OutputLevel(), ciphertexts[i].Level())
+			verifyTestVectors(params, encoder, decryptor, values[i], ciphertexts[i], params.LogSlots(), 0, t)
+		}
+	})
+
+	t.Run(ParamsToString(params, "Bootstrapping/Sparse/"), func(t *testing.T) {
+
+		n := 1 << (params.LogSlots() - logSlots)
+
+		values := make([][]complex128, n)
+		ciphertexts := make([]*ckks.Ciphertext, n)
+		for i := range values {
+			values[i] = make([]complex128, 1<<logSlots)
+			for j := range values[i] {
+				values[i][j] = utils.RandComplex128(-1, 1)
+			}
+			ciphertexts[i] = encryptor.EncryptNew(encoder.EncodeNew(values[i], 2, params.DefaultScale(), logSlots))
+		}
+
+		ciphertexts = btp.BootstrappSparse(logSlots, ciphertexts)
+
+		for i := range ciphertexts {
+			require.Equal(t, btpParams.OutputLevel()-1, ciphertexts[i].Level())
+			verifyTestVectors(params, encoder, decryptor, values[i], ciphertexts[i], logSlots, 0, t)
+		}
+	})
+}
+
+func TestBootstrapHighPrecision(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping bootstrapping tests for GOARCH=wasm")
+	}
+
+	paramSet := DefaultParametersSparse[0]
+	ckksParams := paramSet.SchemeParams
+	btpParams := paramSet.BootstrappingParams
+
+	// Insecure params for fast testing only
+	if !*flagLongTest {
+		ckksParams.LogN = 13
+		ckksParams.LogSlots = 12
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckksParams)
+	require.NoError(t, err)
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	encoder := ckks.NewEncoder(params)
+	encryptor := ckks.NewEncryptor(params, sk)
+	decryptor := ckks.NewDecryptor(params, sk)
+
+	btp, err := NewBootstrapper(params, btpParams, GenEvaluationKeys(btpParams, params, sk))
+	require.NoError(t, err)
+
+	t.Run(ParamsToString(params, "Bootstrapping/HighPrecision/"), func(t *testing.T) {
+
+		values := make([]complex128, params.Slots())
+		for i := range values {
+			values[i] = utils.RandComplex128(-1, 1)
+		}
+
+		ciphertext := encryptor.EncryptNew(encoder.EncodeNew(values, 0, params.DefaultScale(), params.LogSlots()))
+
+		precStats := make([]ckks.PrecisionStats, 2)
+		for i := range precStats {
+
+			ciphertextOut := btp.BootstrappHighPrecision(ciphertext, i+1, 16)
+			require.Equal(t, btpParams.OutputLevel()-i, ciphertextOut.Level())
+			require.Equal(t, params.DefaultScale(), ciphertextOut.Scale)
+
+			precStats[i] = ckks.GetPrecisionStats(params, encoder, decryptor, values, ciphertextOut, params.LogSlots(), 0)
+
+			if *printPrecisionStats {
+				t.Log(precStats[i].String())
+			}
+		}
+
+		// The second iteration must improve the precision, up to the rounding error of the rescaling at params.DefaultScale().
+		require.Greater(t, precStats[1].MeanPrecision.Real, precStats[0].MeanPrecision.Real+4)
+		require.Greater(t, precStats[1].MeanPrecision.Imag, precStats[0].MeanPrecision.Imag+4)
+	})
+
+	t.Run(ParamsToString(params, "Bootstrapping/HighPrecision/AboveOutputLevel/"), func(t *testing.T) {
+
+		values := make([]complex128, params.Slots())
+		for i := range values {
+			values[i] = utils.RandComplex128(-1, 1)
+		}
+
+		// The input is above the output level of the bootstrapping, so it is dropped to the level of the residual.
+		ciphertext := encryptor.EncryptNew(encoder.EncodeNew(values, params.MaxLevel(), params.DefaultScale(), params.LogSlots()))
+		require.Greater(t, ciphertext.Level(), btpParams.OutputLevel())
+
+		ciphertextOut := btp.BootstrappHighPrecision(ciphertext, 2, 16)
+		require.Equal(t, btpParams.OutputLevel()-1, ciphertextOut.Level())
+		require.Equal(t, params.DefaultScale(), ciphertextOut.Scale)
+		require.Equal(t, params.MaxLevel(), ciphertext.Level())
+
+		verifyTestVectors(params, encoder, decryptor, values, ciphertextOut, params.LogSlots(), 0, t)
+	})
+}
+
+func TestBootstrapConjugateInvariant(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping bootstrapping tests for GOARCH=wasm")
+	}
+
+	paramSet := DefaultParametersSparse[0]
+	ckksParams := paramSet.SchemeParams
+	btpParams := paramSet.BootstrappingParams
+
+	ckksParams.RingType = ring.ConjugateInvariant
+	ckksParams.LogN--
+	ckksParams.LogSlots = ckksParams.LogN
+
+	// Insecure params for fast testing only
+	if !*flagLongTest {
+		ckksParams.LogN = 12
+		ckksParams.LogSlots = 12
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckksParams)
+	require.NoError(t, err)
+
+	t.Run(ParamsToString(params, "Bootstrapping/ConjugateInvariant/"), func(t *testing.T) {
+
+		kgen := ckks.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		encoder := ckks.NewEncoder(params)
+		encryptor := ckks.NewEncryptor(params, sk)
+		decryptor := ckks.NewDecryptor(params, sk)
+
+		btp, err := NewConjugateInvariantBootstrapper(params, btpParams, GenConjugateInvariantEvaluationKeys(btpParams, params, sk))
+		require.NoError(t, err)
+
+		values := make([]complex128, params.Slots())
+		for i := range values {
+			values[i] = complex(utils.RandFloat64(-1, 1), 0)
+		}
+
+		ciphertext := encryptor.EncryptNew(encoder.EncodeNew(values, 0, params.DefaultScale(), params.LogSlots()))
+
+		ciphertext = btp.Bootstrapp(ciphertext)
+
+		require.Equal(t, btpParams.OutputLevel(), ciphertext.Level())
+		require.Equal(t, params.DefaultScale(), ciphertext.Scale)
+
+		verifyTestVectors(params, encoder, decryptor, values, ciphertext, params.LogSlots(), 0, t)
+	})
+}
+
 func verifyTestVectors(params ckks.Parameters, encoder ckks.Encoder, decryptor ckks.Decryptor, valuesWant []complex128, element interface{}, logSlots int, bound float64, t *testing.T) {
 	precStats := ckks.GetPrecisionStats(params, encoder, decryptor, valuesWant, element, logSlots, bound)
 	if *printPrecisionStats {
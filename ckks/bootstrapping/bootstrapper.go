@@ -46,17 +46,8 @@ type EvaluationKeys struct {
 // The result can be passed to NewBootstrapperFromBase to complete initialization,
 // allowing GenEvaluationKeys and NewBootstrapperBase to run concurrently.
 func NewBootstrapperBase(params ckks.Parameters, btpParams Parameters) (*BootstrapperBase, error) {
-	if btpParams.EvalModParameters.SineType == advanced.Sin && btpParams.EvalModParameters.DoubleAngle != 0 {
-		return nil, fmt.Errorf("cannot use double angle formul for SineType = Sin -> must use SineType = Cos")
-	}
-	if btpParams.EvalModParameters.SineType == advanced.Cos1 && btpParams.EvalModParameters.SineDeg < 2*(btpParams.EvalModParameters.K-1) {
-		return nil, fmt.Errorf("SineType 'advanced.Cos1' uses a minimum degree of 2*(K-1) but EvalMod degree is smaller")
-	}
-	if btpParams.CoeffsToSlotsParameters.LevelStart-btpParams.CoeffsToSlotsParameters.Depth(true) != btpParams.EvalModParameters.LevelStart {
-		return nil, fmt.Errorf("starting level and depth of CoeffsToSlotsParameters inconsistent starting level of SineEvalParameters")
-	}
-	if btpParams.EvalModParameters.LevelStart-btpParams.EvalModParameters.Depth() != btpParams.SlotsToCoeffsParameters.LevelStart {
-		return nil, fmt.Errorf("starting level and depth of SineEvalParameters inconsistent starting level of CoeffsToSlotsParameters")
+	if err := btpParams.validate(); err != nil {
+		return nil, err
 	}
 	return newBootstrapperBase(params, btpParams), nil
 }
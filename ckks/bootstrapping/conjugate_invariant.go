@@ -0,0 +1,117 @@
+package bootstrapping
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// ConjugateInvariantBootstrapper is a struct to bootstrap ciphertexts of the conjugate invariant variant of CKKS
+// (params.RingType() == ring.ConjugateInvariant). The ciphertexts are switched to the standard ring of twice the degree,
+// bootstrapped with a Bootstrapper instantiated with the standard parameters and switched back.
+type ConjugateInvariantBootstrapper struct {
+	*Bootstrapper
+	ckks.DomainSwitcher
+	params ckks.Parameters
+}
+
+// ConjugateInvariantEvaluationKeys is a type for the keys of the ConjugateInvariantBootstrapper: the bootstrapping keys
+// of the standard parameters, which are under a secret key of the standard ring, and the switching keys between the
+// secret key of the standard ring and the secret key of the conjugate invariant ring.
+type ConjugateInvariantEvaluationKeys struct {
+	EvaluationKeys
+	SwkComplexToReal *ckks.SwkComplexToReal
+	SwkRealToComplex *ckks.SwkRealToComplex
+}
+
+// NewConjugateInvariantBootstrapper creates a new ConjugateInvariantBootstrapper for the conjugate invariant parameters params.
+// The bootstrapping parameters btpParams must be valid for the standard parameters params.StandardParameters().
+func NewConjugateInvariantBootstrapper(params ckks.Parameters, btpParams Parameters, btpKeys ConjugateInvariantEvaluationKeys) (btp *ConjugateInvariantBootstrapper, err error) {
+
+	if params.RingType() != ring.ConjugateInvariant {
+		return nil, fmt.Errorf("cannot NewConjugateInvariantBootstrapper: params.RingType() must be ring.ConjugateInvariant")
+	}
+
+	if btpKeys.SwkComplexToReal == nil || btpKeys.SwkRealToComplex == nil {
+		return nil, fmt.Errorf("invalid bootstrapping key: switching keys between the conjugate invariant and standard rings are nil")
+	}
+
+	stdParams, err := params.StandardParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	btp = &ConjugateInvariantBootstrapper{params: params}
+
+	if btp.Bootstrapper, err = NewBootstrapper(stdParams, btpParams, btpKeys.EvaluationKeys); err != nil {
+		return nil, err
+	}
+
+	if btp.DomainSwitcher, err = ckks.NewDomainSwitcher(stdParams, btpKeys.SwkComplexToReal, btpKeys.SwkRealToComplex); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// GenConjugateInvariantEvaluationKeys generates the ConjugateInvariantEvaluationKeys for the conjugate invariant secret key sk.
+// The keys of the standard ring are generated under a fresh secret key of the standard ring, which is not returned,
+// since the bootstrapped ciphertexts are always switched back to the conjugate invariant ring.
+func GenConjugateInvariantEvaluationKeys(btpParams Parameters, params ckks.Parameters, sk *rlwe.SecretKey) ConjugateInvariantEvaluationKeys {
+
+	stdParams, err := params.StandardParameters()
+	if err != nil {
+		panic(err)
+	}
+
+	kgen := ckks.NewKeyGenerator(stdParams)
+	skStd := kgen.GenSecretKey()
+
+	swkCtR, swkRtC := kgen.GenSwitchingKeysForBridge(skStd, sk)
+
+	return ConjugateInvariantEvaluationKeys{
+		EvaluationKeys:   GenEvaluationKeys(btpParams, stdParams, skStd),
+		SwkComplexToReal: swkCtR,
+		SwkRealToComplex: swkRtC,
+	}
+}
+
+// ShallowCopy creates a shallow copy of this ConjugateInvariantBootstrapper in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// ConjugateInvariantBootstrapper can be used concurrently.
+func (btp *ConjugateInvariantBootstrapper) ShallowCopy() *ConjugateInvariantBootstrapper {
+
+	stdParams, _ := btp.params.StandardParameters()
+
+	switcher, err := ckks.NewDomainSwitcher(stdParams, btp.SwkComplexToReal, btp.SwkRealToComplex)
+	if err != nil {
+		panic(err)
+	}
+
+	return &ConjugateInvariantBootstrapper{
+		Bootstrapper:   btp.Bootstrapper.ShallowCopy(),
+		DomainSwitcher: switcher,
+		params:         btp.params,
+	}
+}
+
+// Bootstrapp re-encrypts a ciphertext of the conjugate invariant ring at lvl Q0 to a ciphertext of the conjugate invariant
+// ring at level OutputLevel(), with the same constraints on the input as Bootstrapper.Bootstrapp.
+// The message of the ciphertext of the standard ring is halved before the bootstrapping by changing its scale, since the
+// switch back to the conjugate invariant ring doubles it. This costs one bit of precision compared to Bootstrapper.Bootstrapp.
+func (btp *ConjugateInvariantBootstrapper) Bootstrapp(ctIn *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+
+	ctStd := ckks.NewCiphertext(btp.Bootstrapper.params, 1, ctIn.Level(), ctIn.Scale)
+	btp.RealToComplex(ctIn, ctStd)
+	ctStd.Scale *= 2
+
+	ctStd = btp.Bootstrapper.Bootstrapp(ctStd)
+
+	ctOut = ckks.NewCiphertext(btp.params, 1, ctStd.Level(), ctStd.Scale)
+	btp.ComplexToReal(ctStd, ctOut)
+	ctOut.Scale = ctStd.Scale
+
+	return
+}
@@ -291,7 +291,7 @@ var (
 			Sigma: rlwe.DefaultSigma,
 			H:     192,
 			Q: []uint64{
-				0x1fff90001,       // 32 Q0
+				0x1fff90001,       // 33 Q0
 				0x4000000420001,   // 50
 				0x1fc0001,         // 25
 				0xffffffffffc0001, // 60 StC (30+30)
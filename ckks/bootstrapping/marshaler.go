@@ -0,0 +1,418 @@
+package bootstrapping
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Stage is a bit-set of the steps of the bootstrapping, used to select the keys read by ReadEvaluationKeys.
+type Stage int
+
+const (
+	// StageEncapsulation selects the switching keys SwkDtS and SwkStD to and from the ephemeral sparse secret.
+	StageEncapsulation = Stage(1 << iota)
+	// StageCoeffsToSlots selects the rotation keys of the Trace and of the CoeffsToSlots step, and the conjugation key.
+	StageCoeffsToSlots
+	// StageEvalMod selects the relinearization key.
+	StageEvalMod
+	// StageSlotsToCoeffs selects the rotation keys of the SlotsToCoeffs step.
+	StageSlotsToCoeffs
+	// StageAll selects all the keys, including the rotation keys that are not used by the bootstrapping.
+	StageAll = StageEncapsulation | StageCoeffsToSlots | StageEvalMod | StageSlotsToCoeffs
+)
+
+// Tags of the records of the parameters and of the keys written by WriteEvaluationKeys.
+const (
+	recordParameters = uint8(iota)
+	recordRlk
+	recordSwkDtS
+	recordSwkStD
+	recordRotationKey
+)
+
+// maxParametersRecordLen bounds the length of the records of the parameters: the ckks.Parameters store at most
+// 255 moduli Q and 255 moduli P, and the bootstrapping Parameters three sub-records of at most 255 bytes.
+const maxParametersRecordLen = 1 << 13
+
+// WriteEvaluationKeys writes the parameters params and btpParams followed by the keys btpKeys on w.
+// Each key is written as a separate record, so that ReadEvaluationKeys can skip the keys of the stages
+// it does not load without decoding them. The rotation keys are written by increasing Galois element.
+func WriteEvaluationKeys(w io.Writer, params ckks.Parameters, btpParams Parameters, btpKeys EvaluationKeys) (n int64, err error) {
+
+	var data []byte
+	var inc int64
+
+	if data, err = params.MarshalBinary(); err != nil {
+		return n, err
+	}
+
+	if inc, err = writeRecord(w, recordParameters, 0, data); err != nil {
+		return n + inc, err
+	}
+	n += inc
+
+	if data, err = btpParams.MarshalBinary(); err != nil {
+		return n, err
+	}
+
+	if inc, err = writeRecord(w, recordParameters, 0, data); err != nil {
+		return n + inc, err
+	}
+	n += inc
+
+	inc, err = btpKeys.write(w)
+
+	return n + inc, err
+}
+
+// MarshalBinary encodes the keys btpKeys on a slice of bytes, with the same records as WriteEvaluationKeys
+// but without the parameters.
+func (btpKeys *EvaluationKeys) MarshalBinary() (data []byte, err error) {
+	buff := bytes.NewBuffer(make([]byte, 0, btpKeys.getDataLen()))
+	if _, err = btpKeys.write(buff); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target EvaluationKeys.
+func (btpKeys *EvaluationKeys) UnmarshalBinary(data []byte) (err error) {
+	*btpKeys = EvaluationKeys{}
+	return btpKeys.read(bytes.NewReader(data), StageAll, nil, len(data))
+}
+
+// getDataLen returns the length in bytes of the records of the keys btpKeys.
+func (btpKeys *EvaluationKeys) getDataLen() (dataLen int) {
+
+	if btpKeys.Rlk != nil {
+		dataLen += 17 + btpKeys.Rlk.GetDataLen(true)
+	}
+
+	for _, swk := range []*rlwe.SwitchingKey{btpKeys.SwkDtS, btpKeys.SwkStD} {
+		if swk != nil {
+			dataLen += 17 + swk.GetDataLen(true)
+		}
+	}
+
+	if btpKeys.Rtks != nil {
+		for _, swk := range btpKeys.Rtks.Keys {
+			dataLen += 17 + swk.GetDataLen(true)
+		}
+	}
+
+	return
+}
+
+// write writes the keys btpKeys on w, each key as a separate record.
+func (btpKeys *EvaluationKeys) write(w io.Writer) (n int64, err error) {
+
+	var data []byte
+	var inc int64
+
+	if btpKeys.Rlk != nil {
+
+		if data, err = btpKeys.Rlk.MarshalBinary(); err != nil {
+			return n, err
+		}
+
+		if inc, err = writeRecord(w, recordRlk, 0, data); err != nil {
+			return n + inc, err
+		}
+		n += inc
+	}
+
+	for _, swk := range []struct {
+		tag uint8
+		key *rlwe.SwitchingKey
+	}{{recordSwkDtS, btpKeys.SwkDtS}, {recordSwkStD, btpKeys.SwkStD}} {
+
+		if swk.key == nil {
+			continue
+		}
+
+		if inc, err = writeRecord(w, swk.tag, 0, marshalSwitchingKey(swk.key)); err != nil {
+			return n + inc, err
+		}
+		n += inc
+	}
+
+	if btpKeys.Rtks != nil {
+
+		galEls := make([]uint64, 0, len(btpKeys.Rtks.Keys))
+		for galEl := range btpKeys.Rtks.Keys {
+			galEls = append(galEls, galEl)
+		}
+
+		sort.Slice(galEls, func(i, j int) bool { return galEls[i] < galEls[j] })
+
+		for _, galEl := range galEls {
+			if inc, err = writeRecord(w, recordRotationKey, galEl, marshalSwitchingKey(btpKeys.Rtks.Keys[galEl])); err != nil {
+				return n + inc, err
+			}
+			n += inc
+		}
+	}
+
+	return
+}
+
+// ReadEvaluationKeys reads the parameters and the keys written by WriteEvaluationKeys from r.
+// Only the keys of the selected stages are decoded, the other ones are skipped: for example, a server
+// carrying out the linear transformations of the bootstrapping separately can load only the keys of
+// StageCoeffsToSlots | StageSlotsToCoeffs. The rotation key set of the returned keys is never nil,
+// but the keys of the stages that are not selected are nil and NewBootstrapper requires StageAll.
+func ReadEvaluationKeys(r io.Reader, stages Stage) (params ckks.Parameters, btpParams Parameters, btpKeys EvaluationKeys, err error) {
+
+	var data []byte
+
+	if data, err = readParametersRecord(r); err != nil {
+		return params, btpParams, btpKeys, fmt.Errorf("cannot ReadEvaluationKeys: cannot read ckks.Parameters: %w", err)
+	}
+
+	if err = params.UnmarshalBinary(data); err != nil {
+		return params, btpParams, btpKeys, fmt.Errorf("cannot ReadEvaluationKeys: %w", err)
+	}
+
+	if data, err = readParametersRecord(r); err != nil {
+		return params, btpParams, btpKeys, fmt.Errorf("cannot ReadEvaluationKeys: cannot read bootstrapping.Parameters: %w", err)
+	}
+
+	if err = btpParams.UnmarshalBinary(data); err != nil {
+		return params, btpParams, btpKeys, fmt.Errorf("cannot ReadEvaluationKeys: %w", err)
+	}
+
+	if err = btpKeys.read(r, stages, btpParams.galoisElementsForStages(params, stages), maxKeyRecordLen(params)); err != nil {
+		return params, btpParams, btpKeys, fmt.Errorf("cannot ReadEvaluationKeys: %w", err)
+	}
+
+	return
+}
+
+// read reads the records of the keys from r until the end of the stream. Only the keys of the selected stages,
+// and the rotation keys whose Galois element is in galEls, are decoded. The records longer than maxLen and the
+// records of a key that has already been read, whether it is decoded or skipped, are rejected.
+func (btpKeys *EvaluationKeys) read(r io.Reader, stages Stage, galEls map[uint64]bool, maxLen int) (err error) {
+
+	var data []byte
+
+	btpKeys.Rtks = &rlwe.RotationKeySet{Keys: make(map[uint64]*rlwe.SwitchingKey)}
+
+	// The tag and the Galois element of the header identify the key of a record
+	seen := make(map[[9]byte]bool)
+
+	for {
+
+		var header [9]byte
+		if _, err = io.ReadFull(r, header[:]); err != nil {
+
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		tag, galEl := header[0], binary.BigEndian.Uint64(header[1:])
+
+		if seen[header] {
+			return fmt.Errorf("duplicate record with tag %d and Galois element %d", tag, galEl)
+		}
+		seen[header] = true
+
+		var load bool
+		switch tag {
+		case recordRlk:
+			load = stages&StageEvalMod != 0
+		case recordSwkDtS, recordSwkStD:
+			load = stages&StageEncapsulation != 0
+		case recordRotationKey:
+			_, load = galEls[galEl]
+			load = load || stages == StageAll
+		default:
+			return fmt.Errorf("invalid record tag %d", tag)
+		}
+
+		if !load {
+			if err = skipRecordData(r, maxLen); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if data, err = readRecordData(r, maxLen); err != nil {
+			return err
+		}
+
+		switch tag {
+		case recordRlk:
+			btpKeys.Rlk = new(rlwe.RelinearizationKey)
+			err = btpKeys.Rlk.UnmarshalBinary(data)
+		case recordSwkDtS:
+			btpKeys.SwkDtS, err = unmarshalSwitchingKey(data)
+		case recordSwkStD:
+			btpKeys.SwkStD, err = unmarshalSwitchingKey(data)
+		case recordRotationKey:
+			btpKeys.Rtks.Keys[galEl], err = unmarshalSwitchingKey(data)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// maxKeyRecordLen returns the length of the record of a relinearization key of degree one at the maximum levels
+// of params, which bounds the length of the records of all the keys of the bootstrapping.
+func maxKeyRecordLen(params ckks.Parameters) int {
+
+	levelQ, levelP := params.MaxLevel(), params.PCount()-1
+
+	// Each element of the gadget ciphertext is a pair of ringqp.Poly
+	polyQP := 2 + ring.GetDataLen64(params.N(), levelQ, true)
+	if levelP > -1 {
+		polyQP += ring.GetDataLen64(params.N(), levelP, true)
+	}
+
+	// One byte for the degree of the relinearization key, and two for the dimensions of the gadget ciphertext
+	return 3 + 2*polyQP*params.DecompRNS(levelQ, levelP)*params.DecompPw2(levelQ, levelP)
+}
+
+// galoisElementsForStages returns the set of Galois elements of the rotation keys used by the selected stages.
+func (p *Parameters) galoisElementsForStages(params ckks.Parameters, stages Stage) (galEls map[uint64]bool) {
+
+	galEls = make(map[uint64]bool)
+
+	// Copies the encoding matrices parameters, whose rotations depend on the ring degree and the number of slots.
+	ctsParams, stcParams := p.CoeffsToSlotsParameters, p.SlotsToCoeffsParameters
+	ctsParams.LogN, ctsParams.LogSlots = params.LogN(), params.LogSlots()
+	stcParams.LogN, stcParams.LogSlots = params.LogN(), params.LogSlots()
+
+	if stages&StageCoeffsToSlots != 0 {
+
+		for _, galEl := range params.GaloisElementsForTrace(params.LogSlots()) {
+			galEls[galEl] = true
+		}
+
+		for _, k := range ctsParams.Rotations() {
+			galEls[params.GaloisElementForColumnRotationBy(k)] = true
+		}
+
+		galEls[params.GaloisElementForRowRotation()] = true
+	}
+
+	if stages&StageSlotsToCoeffs != 0 {
+		for _, k := range stcParams.Rotations() {
+			galEls[params.GaloisElementForColumnRotationBy(k)] = true
+		}
+	}
+
+	return
+}
+
+func marshalSwitchingKey(swk *rlwe.SwitchingKey) (data []byte) {
+	data = make([]byte, swk.GetDataLen(true))
+	if _, err := swk.Encode(0, data); err != nil {
+		panic(err)
+	}
+	return
+}
+
+func unmarshalSwitchingKey(data []byte) (swk *rlwe.SwitchingKey, err error) {
+	swk = &rlwe.SwitchingKey{NMFormBits: 64}
+	if _, err = swk.Decode(data); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// writeRecord writes a record [tag, galEl, len(data), data] on w.
+func writeRecord(w io.Writer, tag uint8, galEl uint64, data []byte) (n int64, err error) {
+
+	var header [17]byte
+	header[0] = tag
+	binary.BigEndian.PutUint64(header[1:], galEl)
+	binary.BigEndian.PutUint64(header[9:], uint64(len(data)))
+
+	var inc int
+	if inc, err = w.Write(header[:]); err != nil {
+		return int64(inc), err
+	}
+	n += int64(inc)
+
+	inc, err = w.Write(data)
+
+	return n + int64(inc), err
+}
+
+// readParametersRecord reads a record of parameters written by writeRecord from r.
+func readParametersRecord(r io.Reader) (data []byte, err error) {
+
+	var header [9]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+
+	if header[0] != recordParameters {
+		return nil, fmt.Errorf("invalid record tag %d, expected the parameters", header[0])
+	}
+
+	return readRecordData(r, maxParametersRecordLen)
+}
+
+// readRecordData reads the length and the data of a record whose tag and Galois element have been read.
+// It returns an error if the length is larger than maxLen.
+func readRecordData(r io.Reader, maxLen int) (data []byte, err error) {
+
+	var size int
+	if size, err = readRecordLen(r, maxLen); err != nil {
+		return nil, err
+	}
+
+	data = make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// skipRecordData discards the length and the data of a record whose tag and Galois element have been read.
+// It returns an error if the length is larger than maxLen.
+func skipRecordData(r io.Reader, maxLen int) (err error) {
+
+	var size int
+	if size, err = readRecordLen(r, maxLen); err != nil {
+		return
+	}
+
+	if _, err = io.CopyN(io.Discard, r, int64(size)); err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return
+}
+
+// readRecordLen reads the length of a record and checks that it is at most maxLen.
+func readRecordLen(r io.Reader, maxLen int) (size int, err error) {
+
+	var buff [8]byte
+	if _, err = io.ReadFull(r, buff[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+
+	if length := binary.BigEndian.Uint64(buff[:]); length > uint64(maxLen) {
+		return 0, fmt.Errorf("invalid record length %d, larger than the maximum %d", length, maxLen)
+	}
+
+	return int(binary.BigEndian.Uint64(buff[:])), nil
+}
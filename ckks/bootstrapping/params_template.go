@@ -0,0 +1,343 @@
+package bootstrapping
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ckks/advanced"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// TemplateSelectionLiteral is a struct for the requirements of SelectTemplate.
+type TemplateSelectionLiteral struct {
+	LogN                  int     // Log2 of the ring degree.
+	LogSlots              int     // Log2 of the number of slots. If 0, LogN-1 is used.
+	Security              int     // Target security in bits: 128, 192 or 256.
+	ResidualLevels        int     // Number of levels available after the bootstrapping.
+	MinTemplatePrecision  float64 // Minimum TemplatePrecision, in bits, of the selected template.
+	H                     int     // Hamming weight of the secret. If 0, 192 is used as in DefaultParametersSparse.
+	EphemeralSecretWeight int     // Hamming weight of the ephemeral secret. If 0, 32 is used as in DefaultParametersSparse.
+}
+
+// TemplateSelection is a struct for the parameters instantiated by SelectTemplate and their estimated costs.
+type TemplateSelection struct {
+	SchemeParams        ckks.ParametersLiteral
+	BootstrappingParams Parameters
+
+	LogQP int // Log2 of the modulus QP.
+
+	// TemplatePrecision is the precision in bits of the bootstrapping measured on the default parameters of
+	// DefaultParametersSparse from which the template of the result is taken, with their own LogN, H and residual levels.
+	// It is a reference value for the template, not a guarantee for the result: the actual precision depends on
+	// LogN, LogSlots, H and ResidualLevels, and must be measured by running the bootstrapping.
+	TemplatePrecision float64
+
+	KeySize       int // Estimated size in bytes of the EvaluationKeys.
+	KeySwitchings int // Estimated number of key-switchings of one bootstrapping.
+	NTTs          int // Estimated number of NTTs of size N of the key-switchings of one bootstrapping.
+}
+
+// EstimatedRuntime returns the estimated runtime of the key-switchings of one bootstrapping given the runtime of one NTT of size N.
+// Only the NTTs of the key-switchings are counted, so this is a lower bound of the runtime of the bootstrapping.
+func (r *TemplateSelection) EstimatedRuntime(ntt time.Duration) time.Duration {
+	return time.Duration(r.NTTs) * ntt
+}
+
+// bootstrappingTemplate is the structure of the moduli chain and of the EvalMod step of one of the default parameters.
+type bootstrappingTemplate struct {
+	precision    float64 // precision in bits of the default parameters of the template
+	logScale     int     // default scale and size of the residual moduli
+	logQ0        int
+	logStC       int // size of the SlotsToCoeffs moduli
+	depthStC     int
+	logEvalMod   int // size of the EvalMod moduli
+	messageRatio float64
+	arcSineDeg   int
+	logCtS       int // size of the CoeffsToSlots moduli
+	depthCtS     int
+}
+
+// bootstrappingTemplates are the templates of DefaultParametersSparse, sorted by increasing precision and cost.
+// They are hardcoded copies of the default parameters and must be kept in sync with them.
+var bootstrappingTemplates = []bootstrappingTemplate{
+	{precision: 15.4, logScale: 25, logQ0: 33, logStC: 30, depthStC: 2, logEvalMod: 50, messageRatio: 256, arcSineDeg: 0, logCtS: 49, depthCtS: 2}, // N15QP768H192H32
+	{precision: 19.1, logScale: 30, logQ0: 55, logStC: 30, depthStC: 2, logEvalMod: 55, messageRatio: 256, arcSineDeg: 0, logCtS: 53, depthCtS: 4}, // N16QP1553H192H32
+	{precision: 26.6, logScale: 40, logQ0: 60, logStC: 39, depthStC: 3, logEvalMod: 60, messageRatio: 256, arcSineDeg: 0, logCtS: 56, depthCtS: 4}, // N16QP1546H192H32
+	{precision: 32.1, logScale: 45, logQ0: 60, logStC: 42, depthStC: 3, logEvalMod: 60, messageRatio: 4, arcSineDeg: 7, logCtS: 58, depthCtS: 4},   // N16QP1547H192H32
+}
+
+// maxLogQP returns the maximum log2 of the modulus QP for the given ring degree and security, according to
+// the tables of the Homomorphic Encryption Standard for uniform ternary secrets. The Standard stops at LogN = 15:
+// the bounds for LogN = 16 and LogN = 17 are extrapolated by doubling and are not part of it.
+func maxLogQP(logN, security int) (logQP int, err error) {
+
+	tables := map[int][]int{
+		128: {27, 54, 109, 218, 438, 881, 1761, 3523},
+		192: {19, 37, 75, 152, 305, 611, 1228, 2458},
+		256: {14, 29, 58, 118, 237, 476, 956, 1914},
+	}
+
+	table, ok := tables[security]
+	if !ok {
+		return 0, fmt.Errorf("invalid security: must be 128, 192 or 256 but is %d", security)
+	}
+
+	if logN < 10 || logN >= 10+len(table) {
+		return 0, fmt.Errorf("invalid LogN: must be between 10 and %d but is %d", 10+len(table)-1, logN)
+	}
+
+	return table[logN-10], nil
+}
+
+// SelectTemplate selects the cheapest of the templates of DefaultParametersSparse whose TemplatePrecision is at least
+// lit.MinTemplatePrecision, that leaves the required number of levels after the bootstrapping and whose modulus QP
+// does not exceed the bound for the target security, and instantiates it for the given LogN and LogSlots.
+// It does not search or optimize the moduli: the sizes of the moduli and the EvalMod step are the ones of the template,
+// only the number of residual levels and special primes and the depth of the homomorphic encoding are adapted.
+// The number of special primes is reduced until the security bound is satisfied.
+//
+// The security bound is the one of uniform ternary secrets, extrapolated for LogN > 15: the security of sparse secrets
+// must be assessed separately, e.g. with the lattice estimator. The TemplatePrecision is a reference value of the template
+// and not an estimate of the precision of the result, and the costs are estimates: both should be checked by running
+// the bootstrapping with the returned parameters.
+func SelectTemplate(lit TemplateSelectionLiteral) (res TemplateSelection, err error) {
+
+	if lit.LogSlots == 0 {
+		lit.LogSlots = lit.LogN - 1
+	}
+
+	if lit.LogSlots < 1 || lit.LogSlots > lit.LogN-1 {
+		return res, fmt.Errorf("cannot SelectTemplate: LogSlots must be between 1 and LogN-1")
+	}
+
+	if lit.ResidualLevels < 0 {
+		return res, fmt.Errorf("cannot SelectTemplate: ResidualLevels cannot be negative")
+	}
+
+	if lit.H == 0 {
+		lit.H = 192
+	}
+
+	if lit.EphemeralSecretWeight == 0 {
+		lit.EphemeralSecretWeight = 32
+	}
+
+	maxLogQP, err := maxLogQP(lit.LogN, lit.Security)
+	if err != nil {
+		return res, fmt.Errorf("cannot SelectTemplate: %w", err)
+	}
+
+	var minLogQP int
+	for _, template := range bootstrappingTemplates {
+
+		if template.precision < lit.MinTemplatePrecision {
+			continue
+		}
+
+		var errSecurity bool
+		if res, errSecurity, err = template.parameters(lit, maxLogQP); err == nil {
+			return res, nil
+		}
+
+		if !errSecurity {
+			return res, fmt.Errorf("cannot SelectTemplate: %w", err)
+		}
+
+		if minLogQP == 0 || res.LogQP < minLogQP {
+			minLogQP = res.LogQP
+		}
+	}
+
+	if minLogQP == 0 {
+		return res, fmt.Errorf("cannot SelectTemplate: no template has a TemplatePrecision of %.1f bits", lit.MinTemplatePrecision)
+	}
+
+	return TemplateSelection{}, fmt.Errorf("cannot SelectTemplate: the smallest modulus QP has %d bits but %d-bit security with LogN=%d allows at most %d bits", minLogQP, lit.Security, lit.LogN, maxLogQP)
+}
+
+// parameters instantiates the template for the given requirements. If the parameters do not satisfy the security bound,
+// errSecurity is true and the LogQP of the result is the one of the parameters with a single special prime.
+func (t bootstrappingTemplate) parameters(lit TemplateSelectionLiteral, maxLogQP int) (res TemplateSelection, errSecurity bool, err error) {
+
+	// The homomorphic encoding cannot have more levels than log2 of the number of slots.
+	depthCtS := utils.MinInt(t.depthCtS, lit.LogSlots)
+	depthStC := utils.MinInt(t.depthStC, lit.LogSlots)
+
+	evalMod := advanced.EvalModLiteral{
+		SineType:      advanced.Cos1,
+		MessageRatio:  t.messageRatio,
+		K:             16,
+		SineDeg:       30,
+		DoubleAngle:   3,
+		ArcSineDeg:    t.arcSineDeg,
+		ScalingFactor: math.Exp2(float64(t.logEvalMod)),
+	}
+
+	// Moduli chain: Q0, residual levels, SlotsToCoeffs, EvalMod, CoeffsToSlots.
+	logQ := []int{t.logQ0}
+	for i := 0; i < lit.ResidualLevels; i++ {
+		logQ = append(logQ, t.logScale)
+	}
+	for i := 0; i < depthStC; i++ {
+		logQ = append(logQ, t.logStC)
+	}
+	for i := 0; i < evalMod.Depth(); i++ {
+		logQ = append(logQ, t.logEvalMod)
+	}
+	for i := 0; i < depthCtS; i++ {
+		logQ = append(logQ, t.logCtS)
+	}
+
+	var logQSum int
+	for _, qi := range logQ {
+		logQSum += qi
+	}
+
+	// Special primes of 61 bits, which are larger than any modulus of the chain. As in the default parameters,
+	// there is up to one special prime for five moduli, and as many as the security bound allows.
+	const logPi = 61
+	pCount := utils.MinInt((maxLogQP-logQSum)/logPi, (len(logQ)+4)/5)
+
+	if pCount < 1 {
+		return TemplateSelection{LogQP: logQSum + logPi}, true, fmt.Errorf("modulus QP of %d bits exceeds the security bound of %d bits", logQSum+logPi, maxLogQP)
+	}
+
+	logP := make([]int, pCount)
+	for i := range logP {
+		logP[i] = logPi
+	}
+
+	Q, P, err := rlwe.GenModuli(lit.LogN, logQ, logP)
+	if err != nil {
+		return res, false, err
+	}
+
+	// A ciphertext at level 0 can only be bootstrapped if Q0/MessageRatio, rounded to a power of two, is at least its scale.
+	if math.Round(math.Log2(float64(Q[0])/t.messageRatio)) < float64(t.logScale) {
+		return res, false, fmt.Errorf("Q0/MessageRatio is smaller than the default scale 2^%d", t.logScale)
+	}
+
+	// The Q0 of the default parameters with small scales is smaller than the EvalMod moduli.
+	evalMod.Q = Q[0]
+
+	res.SchemeParams = ckks.ParametersLiteral{
+		LogN:         lit.LogN,
+		Q:            Q,
+		P:            P,
+		Sigma:        rlwe.DefaultSigma,
+		H:            lit.H,
+		LogSlots:     lit.LogSlots,
+		DefaultScale: math.Exp2(float64(t.logScale)),
+	}
+
+	levelStC := lit.ResidualLevels + depthStC
+	levelEvalMod := levelStC + evalMod.Depth()
+	levelCtS := levelEvalMod + depthCtS
+
+	evalMod.LevelStart = levelEvalMod
+
+	res.BootstrappingParams = Parameters{
+		EphemeralSecretWeight: lit.EphemeralSecretWeight,
+		SlotsToCoeffsParameters: advanced.EncodingMatrixLiteral{
+			LinearTransformType: advanced.SlotsToCoeffs,
+			RepackImag2Real:     true,
+			LevelStart:          levelStC,
+			BSGSRatio:           2.0,
+			BitReversed:         false,
+			ScalingFactor:       scalingFactors(Q[levelStC-depthStC+1 : levelStC+1]),
+		},
+		EvalModParameters: evalMod,
+		CoeffsToSlotsParameters: advanced.EncodingMatrixLiteral{
+			LinearTransformType: advanced.CoeffsToSlots,
+			RepackImag2Real:     true,
+			LevelStart:          levelCtS,
+			BSGSRatio:           2.0,
+			BitReversed:         false,
+			ScalingFactor:       scalingFactors(Q[levelCtS-depthCtS+1 : levelCtS+1]),
+		},
+	}
+
+	if err = res.BootstrappingParams.validate(); err != nil {
+		return res, false, err
+	}
+
+	params, err := ckks.NewParametersFromLiteral(res.SchemeParams)
+	if err != nil {
+		return res, false, err
+	}
+
+	res.LogQP = params.LogQP()
+	res.TemplatePrecision = t.precision
+	res.estimateCosts(params)
+
+	return
+}
+
+// scalingFactors returns the scaling factors of an encoding matrix consuming one level per modulus.
+func scalingFactors(moduli []uint64) (scalingFactor [][]float64) {
+	scalingFactor = make([][]float64, len(moduli))
+	for i, qi := range moduli {
+		scalingFactor[i] = []float64{float64(qi)}
+	}
+	return
+}
+
+// estimateCosts sets the estimated size of the keys and number of key-switchings and NTTs of the bootstrapping.
+func (r *TemplateSelection) estimateCosts(params ckks.Parameters) {
+
+	btpParams := r.BootstrappingParams
+
+	levelQ, levelP := params.QCount()-1, params.PCount()-1
+	decompRNS := params.DecompRNS(levelQ, levelP)
+
+	// Size of a switching key: decompRNS pairs of polynomials in QP.
+	swkSize := decompRNS * 2 * params.N() * (params.QCount() + params.PCount()) * 8
+
+	rotations := btpParams.RotationsForBootstrapping(params)
+
+	galEls := map[uint64]bool{params.GaloisElementForRowRotation(): true}
+	for _, k := range rotations {
+		galEls[params.GaloisElementForColumnRotationBy(k)] = true
+	}
+
+	// Rotation keys, relinearization key and encapsulation keys.
+	nbKeys := len(galEls) + 1
+	if btpParams.EphemeralSecretWeight != 0 {
+		nbKeys += 2
+	}
+
+	r.KeySize = nbKeys * swkSize
+
+	// Key-switchings of the homomorphic encoding and decoding (one per rotation) and of the EvalMod step
+	// (one per non-scalar multiplication of the baby-step giant-step polynomial evaluations).
+	nbEvalMod := 1
+	if params.LogSlots() == params.LogN()-1 {
+		nbEvalMod = 2
+	}
+
+	evalMod := btpParams.EvalModParameters
+	relins := polynomialEvaluationMultiplications(utils.MaxInt(evalMod.SineDeg, 2*evalMod.K-1)) + evalMod.DoubleAngle
+	if evalMod.ArcSineDeg > 0 {
+		relins += polynomialEvaluationMultiplications(evalMod.ArcSineDeg)
+	}
+
+	r.KeySwitchings = len(rotations) + nbEvalMod*relins
+	if btpParams.EphemeralSecretWeight != 0 {
+		r.KeySwitchings += 2
+	}
+
+	// A key-switching at the top level computes decompRNS NTTs in QP for the decomposition and
+	// about two more for the reduction modulo Q of the two output polynomials.
+	r.NTTs = r.KeySwitchings * (decompRNS + 2) * (params.QCount() + params.PCount())
+}
+
+// polynomialEvaluationMultiplications returns the number of non-scalar multiplications of the
+// baby-step giant-step evaluation of a polynomial of the given degree.
+func polynomialEvaluationMultiplications(degree int) int {
+	logDegree := int(math.Ceil(math.Log2(float64(degree + 1))))
+	logSplit := (logDegree >> 1) + 1
+	return (1 << logSplit) + (1 << (logDegree - logSplit)) + logDegree
+}
@@ -0,0 +1,85 @@
+package bootstrapping
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectTemplate(t *testing.T) {
+
+	t.Run("Consistency", func(t *testing.T) {
+
+		res, err := SelectTemplate(TemplateSelectionLiteral{LogN: 16, Security: 128, ResidualLevels: 9, MinTemplatePrecision: 25})
+		require.NoError(t, err)
+
+		params, err := ckks.NewParametersFromLiteral(res.SchemeParams)
+		require.NoError(t, err)
+
+		// Same structure as N16QP1546H192H32.
+		require.Equal(t, 26.6, res.TemplatePrecision)
+		require.Equal(t, 25, params.QCount())
+		require.Equal(t, 5, params.PCount())
+		require.Equal(t, 9, res.BootstrappingParams.OutputLevel())
+		require.Equal(t, params.MaxLevel(), res.BootstrappingParams.CoeffsToSlotsParameters.LevelStart)
+		require.Equal(t, params.Q()[0], res.BootstrappingParams.EvalModParameters.Q)
+		require.LessOrEqual(t, res.LogQP, 1761)
+		require.NoError(t, res.BootstrappingParams.validate())
+
+		require.Greater(t, res.KeySize, 0)
+		require.Greater(t, res.KeySwitchings, 0)
+		require.Greater(t, res.NTTs, res.KeySwitchings)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+
+		// Not enough modulus for a bootstrapping with LogN=13 at 128-bit security.
+		_, err := SelectTemplate(TemplateSelectionLiteral{LogN: 13, Security: 128, ResidualLevels: 1, MinTemplatePrecision: 10})
+		require.Error(t, err)
+
+		// No template reaches 40 bits of precision.
+		_, err = SelectTemplate(TemplateSelectionLiteral{LogN: 16, Security: 128, ResidualLevels: 1, MinTemplatePrecision: 40})
+		require.Error(t, err)
+
+		_, err = SelectTemplate(TemplateSelectionLiteral{LogN: 16, Security: 100, ResidualLevels: 1, MinTemplatePrecision: 20})
+		require.Error(t, err)
+	})
+
+	t.Run("Bootstrapping", func(t *testing.T) {
+
+		if runtime.GOARCH == "wasm" {
+			t.Skip("skipping bootstrapping tests for GOARCH=wasm")
+		}
+
+		// The smallest secure ring degree for a bootstrapping, with sparse slots to bound the size of the keys.
+		res, err := SelectTemplate(TemplateSelectionLiteral{LogN: 15, LogSlots: 8, Security: 128, ResidualLevels: 2, MinTemplatePrecision: 15})
+		require.NoError(t, err)
+
+		// The bootstrapping is run with the selected parameters, at the requested LogN.
+		params, err := ckks.NewParametersFromLiteral(res.SchemeParams)
+		require.NoError(t, err)
+		require.Equal(t, 15, params.LogN())
+
+		kgen := ckks.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		encoder := ckks.NewEncoder(params)
+		encryptor := ckks.NewEncryptor(params, sk)
+		decryptor := ckks.NewDecryptor(params, sk)
+
+		btp, err := NewBootstrapper(params, res.BootstrappingParams, GenEvaluationKeys(res.BootstrappingParams, params, sk))
+		require.NoError(t, err)
+
+		values := make([]complex128, params.Slots())
+		for i := range values {
+			values[i] = utils.RandComplex128(-1, 1)
+		}
+
+		ciphertext := btp.Bootstrapp(encryptor.EncryptNew(encoder.EncodeNew(values, 0, params.DefaultScale(), params.LogSlots())))
+		require.Equal(t, 2, ciphertext.Level())
+
+		verifyTestVectors(params, encoder, decryptor, values, ciphertext, params.LogSlots(), 0, t)
+	})
+}
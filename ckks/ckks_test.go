@@ -114,6 +114,8 @@ func TestCKKS(t *testing.T) {
 			testInnerSum,
 			testReplicate,
 			testLinearTransform,
+			testManagedEvaluator,
+			testSimulator,
 			testMarshaller,
 		} {
 			testSet(tc, t)
@@ -911,6 +913,26 @@ func testEvaluatePoly(tc *testContext, t *testing.T) {
 
 		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, valuesWant, ciphertext, tc.params.LogSlots(), 0, t)
 	})
+
+	t.Run(GetTestName(tc.params, "Polynomial/Evaluate"), func(t *testing.T) {
+
+		// 1 + x + x^2/2 + x^3/6
+		monomial := NewPoly([]complex128{1, 1, 1.0 / 2, 1.0 / 6})
+
+		// Chebyshev interpolant of sin on [-1.5, 1.5]
+		chebyshev := Approximate(cmplx.Sin, -1.5, 1.5, 15)
+
+		for _, x := range []complex128{-1.5, -0.75, 0, 0.5, 1.5, complex(0.5, 0.25), complex(-1, -0.5)} {
+			want := 1 + x + x*x/2 + x*x*x/6
+			have := monomial.Evaluate(x)
+			require.InDelta(t, real(want), real(have), 1e-15)
+			require.InDelta(t, imag(want), imag(have), 1e-15)
+
+			want, have = cmplx.Sin(x), chebyshev.Evaluate(x)
+			require.InDelta(t, real(want), real(have), 1e-9)
+			require.InDelta(t, imag(want), imag(have), 1e-9)
+		}
+	})
 }
 
 func testChebyshevInterpolator(tc *testContext, t *testing.T) {
@@ -1391,6 +1413,265 @@ func testLinearTransform(tc *testContext, t *testing.T) {
 	})
 }
 
+func testManagedEvaluator(tc *testContext, t *testing.T) {
+
+	if tc.params.PCount() == 0 {
+		return
+	}
+
+	eval := NewManagedEvaluator(tc.params, rlwe.EvaluationKey{Rlk: tc.rlk})
+
+	t.Run(GetTestName(tc.params, "ManagedEvaluator/Add/DifferentLevelsAndScales"), func(t *testing.T) {
+
+		values1, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+		values2, _, ciphertext2 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		values3, _, ciphertext3 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		// ciphertext2 is one level below and at a scale that is not an integer multiple of the scale of ciphertext1.
+		tc.evaluator.MulRelin(ciphertext2, ciphertext3, ciphertext2)
+		require.NoError(t, tc.evaluator.Rescale(ciphertext2, tc.params.DefaultScale(), ciphertext2))
+		require.NotEqual(t, ciphertext1.Scale, ciphertext2.Scale)
+
+		for i := range values2 {
+			values2[i] *= values3[i]
+			values1[i] += values2[i]
+		}
+
+		ciphertext3, err := eval.AddNew(ciphertext1, ciphertext2)
+		require.NoError(t, err)
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values1, ciphertext3, tc.params.LogSlots(), 0, t)
+
+		for i := range values1 {
+			values1[i] -= values2[i]
+		}
+
+		require.NoError(t, eval.Sub(ciphertext3, ciphertext2, ciphertext3))
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values1, ciphertext3, tc.params.LogSlots(), 0, t)
+	})
+
+	t.Run(GetTestName(tc.params, "ManagedEvaluator/Add/Plaintext"), func(t *testing.T) {
+
+		values1, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+		values2 := make([]complex128, tc.params.Slots())
+		copy(values2, values1)
+
+		plaintext := tc.encoder.EncodeNew(values2, tc.params.MaxLevel(), tc.params.DefaultScale()*1.5, tc.params.LogSlots())
+
+		for i := range values1 {
+			values1[i] += values2[i]
+		}
+
+		ciphertext2, err := eval.AddNew(ciphertext1, plaintext)
+		require.NoError(t, err)
+		require.Equal(t, plaintext.Scale, ciphertext2.Scale)
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values1, ciphertext2, tc.params.LogSlots(), 0, t)
+	})
+
+	t.Run(GetTestName(tc.params, "ManagedEvaluator/Mul/Lazy"), func(t *testing.T) {
+
+		if tc.params.MaxLevel() < 3 {
+			t.Skip("not enough levels")
+		}
+
+		values1, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+		values2, _, ciphertext2 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+		values3, _, ciphertext3 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		// (x1 * x2 + x3) * x3 * x1 with a single rescaling per multiplicative level.
+		for i := range values1 {
+			values1[i] = (values1[i]*values2[i] + values3[i]) * values3[i] * values1[i]
+		}
+
+		ctOut, err := eval.MulNew(ciphertext1, ciphertext2)
+		require.NoError(t, err)
+		require.Equal(t, tc.params.MaxLevel(), ctOut.Level())
+
+		require.NoError(t, eval.Add(ctOut, ciphertext3, ctOut))
+		require.Equal(t, tc.params.MaxLevel(), ctOut.Level())
+
+		require.NoError(t, eval.Mul(ctOut, ciphertext3, ctOut))
+		require.Equal(t, tc.params.MaxLevel()-1, ctOut.Level())
+
+		require.NoError(t, eval.Mul(ctOut, ciphertext1, ctOut))
+		require.Equal(t, tc.params.MaxLevel()-2, ctOut.Level())
+
+		ctOut, err = eval.RescaleNew(ctOut)
+		require.NoError(t, err)
+		require.Equal(t, tc.params.MaxLevel()-3, ctOut.Level())
+		require.InDelta(t, math.Log2(tc.params.DefaultScale()), math.Log2(ctOut.Scale), 1)
+
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values1, ctOut, tc.params.LogSlots(), 0, t)
+	})
+
+	t.Run(GetTestName(tc.params, "ManagedEvaluator/Rotate"), func(t *testing.T) {
+
+		rots := []int{1}
+		evalRot := eval.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: tc.kgen.GenRotationKeysForRotations(rots, false, tc.sk)})
+
+		values1, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		ciphertext2 := evalRot.RotateNew(ciphertext1, rots[0])
+		require.Equal(t, ciphertext1.Level(), ciphertext2.Level())
+		require.Equal(t, ciphertext1.Scale, ciphertext2.Scale)
+
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, utils.RotateComplex128Slice(values1, rots[0]), ciphertext2, tc.params.LogSlots(), 0, t)
+	})
+
+	t.Run(GetTestName(tc.params, "ManagedEvaluator/Errors"), func(t *testing.T) {
+
+		_, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+		_, _, ciphertext2 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		tc.evaluator.DropLevel(ciphertext1, ciphertext1.Level())
+
+		// A product at level 0 would leave no room for the message.
+		_, err := eval.MulNew(ciphertext1, ciphertext1)
+		if math.Log2(tc.params.DefaultScale())*2+1 > math.Log2(tc.params.QiFloat64(0)) {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+
+		// Matching a non-integer scale ratio requires one level.
+		tc.evaluator.DropLevel(ciphertext2, ciphertext2.Level())
+		ciphertext2.Scale *= 1.5
+		_, err = eval.AddNew(ciphertext1, ciphertext2)
+		require.Error(t, err)
+	})
+}
+
+func testSimulator(tc *testContext, t *testing.T) {
+
+	params := tc.params
+
+	rots := []int{1, -1, 4, 63}
+
+	sim := NewSimulator(params, NewSimulatorEvaluationKey(params, rots, params.RingType() == ring.Standard), false)
+	encryptor := sim.NewEncryptor()
+	decryptor := sim.NewDecryptor()
+
+	t.Run(GetTestName(params, "Simulator/MulRelin/Rescale/Add"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("#Pi is empty")
+		}
+
+		values1, plaintext1, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+		values2, plaintext2, ciphertext2 := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		simCiphertext1 := encryptor.EncryptNew(plaintext1)
+		simCiphertext2 := encryptor.EncryptNew(plaintext2)
+
+		for i := range values1 {
+			values1[i] *= values2[i]
+		}
+
+		tc.evaluator.MulRelin(ciphertext1, ciphertext2, ciphertext1)
+		require.NoError(t, tc.evaluator.Rescale(ciphertext1, params.DefaultScale(), ciphertext1))
+
+		sim.MulRelin(simCiphertext1, simCiphertext2, simCiphertext1)
+		require.NoError(t, sim.Rescale(simCiphertext1, params.DefaultScale(), simCiphertext1))
+
+		require.Equal(t, ciphertext1.Degree(), simCiphertext1.Degree())
+		require.Equal(t, ciphertext1.Level(), simCiphertext1.Level())
+		require.Equal(t, ciphertext1.Scale, simCiphertext1.Scale)
+		verifyTestVectors(params, tc.encoder, decryptor, values1, simCiphertext1, params.LogSlots(), 0, t)
+
+		// The scales differ after the rescaling, so the Simulator must reproduce the error of the Evaluator.
+		ciphertext1 = tc.evaluator.AddNew(ciphertext1, ciphertext2)
+		simCiphertext1 = sim.AddNew(simCiphertext1, simCiphertext2)
+
+		require.Equal(t, ciphertext1.Level(), simCiphertext1.Level())
+		require.Equal(t, ciphertext1.Scale, simCiphertext1.Scale)
+		verifyTestVectors(params, tc.encoder, decryptor, tc.encoder.Decode(tc.decryptor.DecryptNew(ciphertext1), params.LogSlots()), simCiphertext1, params.LogSlots(), 0, t)
+	})
+
+	t.Run(GetTestName(params, "Simulator/Rotate"), func(t *testing.T) {
+
+		values, plaintext, _ := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		ciphertext := encryptor.EncryptNew(plaintext)
+
+		for _, k := range rots {
+			verifyTestVectors(params, tc.encoder, decryptor, utils.RotateComplex128Slice(values, k), sim.RotateNew(ciphertext, k), params.LogSlots(), 0, t)
+		}
+
+		if params.RingType() == ring.Standard {
+			for i := range values {
+				values[i] = complex(real(values[i]), -imag(values[i]))
+			}
+			verifyTestVectors(params, tc.encoder, decryptor, values, sim.ConjugateNew(ciphertext), params.LogSlots(), 0, t)
+		}
+	})
+
+	t.Run(GetTestName(params, "Simulator/EvaluatePoly"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("#Pi is empty")
+		}
+
+		if params.MaxLevel() < 3 {
+			t.Skip("skipping test for params max level < 3")
+		}
+
+		values, plaintext, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, 0), complex(1, 0), t)
+
+		poly := NewPoly([]complex128{1, 1, 1.0 / 2, 1.0 / 6, 1.0 / 24, 1.0 / 120, 1.0 / 720, 1.0 / 5040})
+
+		for i := range values {
+			values[i] = cmplx.Exp(values[i])
+		}
+
+		ciphertext, err := tc.evaluator.EvaluatePoly(ciphertext, poly, ciphertext.Scale)
+		require.NoError(t, err)
+
+		simCiphertext, err := sim.EvaluatePoly(encryptor.EncryptNew(plaintext), poly, plaintext.Scale)
+		require.NoError(t, err)
+
+		require.Equal(t, ciphertext.Level(), simCiphertext.Level())
+		require.Equal(t, ciphertext.Scale, simCiphertext.Scale)
+
+		verifyTestVectors(params, tc.encoder, decryptor, values, simCiphertext, params.LogSlots(), 0, t)
+	})
+
+	t.Run(GetTestName(params, "Simulator/MissingKeys"), func(t *testing.T) {
+
+		_, plaintext, _ := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		ciphertext := encryptor.EncryptNew(plaintext)
+
+		require.Panics(t, func() { sim.RotateNew(ciphertext, 2) })
+
+		simNoKeys := sim.WithKey(rlwe.EvaluationKey{})
+		require.Panics(t, func() { simNoKeys.MulRelinNew(ciphertext, ciphertext) })
+		require.NotPanics(t, func() { simNoKeys.MulNew(ciphertext, ciphertext) })
+	})
+
+	t.Run(GetTestName(params, "Simulator/Rescale/Level0"), func(t *testing.T) {
+
+		_, plaintext, _ := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		ciphertext := encryptor.EncryptNew(plaintext)
+		sim.DropLevel(ciphertext, ciphertext.Level())
+
+		require.Error(t, sim.Rescale(ciphertext, params.DefaultScale(), ciphertext))
+	})
+
+	t.Run(GetTestName(params, "Simulator/Noise"), func(t *testing.T) {
+
+		values, plaintext, _ := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		ciphertext := NewSimulator(params, rlwe.EvaluationKey{}, true).NewEncryptor().EncryptNew(plaintext)
+
+		precStats := GetPrecisionStats(params, tc.encoder, decryptor, values, ciphertext, params.LogSlots(), 0)
+
+		// The noise of a fresh encryption is visible but does not exceed a few bits.
+		require.Less(t, precStats.MeanPrecision.Real, 60.0)
+		require.GreaterOrEqual(t, precStats.MeanPrecision.Real, minPrec)
+	})
+}
+
 func testMarshaller(testctx *testContext, t *testing.T) {
 
 	t.Run(GetTestName(testctx.params, "Marshaller/Parameters/Binary"), func(t *testing.T) {
@@ -0,0 +1,218 @@
+package conv
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var printPrecisionStats = flag.Bool("print-precision", false, "print precision stats")
+
+var minPrec float64 = 15
+
+type testContext struct {
+	params    ckks.Parameters
+	kgen      rlwe.KeyGenerator
+	sk        *rlwe.SecretKey
+	encoder   ckks.Encoder
+	encryptor ckks.Encryptor
+	decryptor ckks.Decryptor
+}
+
+func TestConv(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping convolution tests for GOARCH=wasm")
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:         12,
+		LogQ:         []int{55, 40, 40, 40},
+		LogP:         []int{61},
+		LogSlots:     11,
+		DefaultScale: 1 << 40,
+	})
+	require.NoError(t, err)
+
+	tc := &testContext{params: params}
+	tc.kgen = ckks.NewKeyGenerator(params)
+	tc.sk = tc.kgen.GenSecretKey()
+	tc.encoder = ckks.NewEncoder(params)
+	tc.encryptor = ckks.NewEncryptor(params, tc.sk)
+	tc.decryptor = ckks.NewDecryptor(params, tc.sk)
+
+	testLayout(tc, t)
+	testLayers(tc, t)
+}
+
+func randomTensor(channels, height, width int) (tensor [][][]float64) {
+	tensor = NewTensor(channels, height, width)
+	for c := range tensor {
+		for i := range tensor[c] {
+			for j := range tensor[c][i] {
+				tensor[c][i][j] = utils.RandFloat64(-1, 1)
+			}
+		}
+	}
+	return
+}
+
+func randomConv2DLiteral(in, out, kernel, stride, padding int) (conv Conv2DLiteral) {
+	conv = Conv2DLiteral{InChannels: in, OutChannels: out, KernelSize: kernel, Stride: stride, Padding: padding}
+	conv.Weights = make([][][][]float64, out)
+	conv.Bias = make([]float64, out)
+	for co := range conv.Weights {
+		conv.Weights[co] = randomTensor(in, kernel, kernel)
+		conv.Bias[co] = utils.RandFloat64(-1, 1)
+	}
+	return
+}
+
+func testLayout(tc *testContext, t *testing.T) {
+
+	t.Run("Layout/Multiplexed", func(t *testing.T) {
+		l := Layout{Channels: 5, Height: 3, Width: 4, Gap: 2}
+		require.Equal(t, 2, l.Blocks())
+		require.Equal(t, 2*6*8, l.Size())
+
+		seen := make(map[int]bool)
+		for c := 0; c < l.Channels; c++ {
+			for i := 0; i < l.Height; i++ {
+				for j := 0; j < l.Width; j++ {
+					idx := l.Index(c, i, j)
+					require.False(t, seen[idx])
+					require.Less(t, idx, l.Size())
+					seen[idx] = true
+				}
+			}
+		}
+
+		tensor := randomTensor(l.Channels, l.Height, l.Width)
+		require.Equal(t, tensor, l.Decode(l.Encode(tensor, tc.params.Slots())))
+		require.Error(t, l.Check(l.Size()-1))
+	})
+
+	t.Run("Layer/Plaintext", func(t *testing.T) {
+		conv := randomConv2DLiteral(3, 4, 3, 2, 1)
+		in := Layout{Channels: 3, Height: 6, Width: 6, Gap: 2}
+		layer, err := NewConv2D(conv, in, tc.params.Slots())
+		require.NoError(t, err)
+
+		tensor := randomTensor(3, 6, 6)
+		have := layer.Out.Decode(layer.Evaluate(in.Encode(tensor, tc.params.Slots())))
+		want := Conv2D(conv, tensor)
+
+		for c := range want {
+			for i := range want[c] {
+				for j := range want[c][i] {
+					require.InDelta(t, want[c][i][j], have[c][i][j], 1e-12)
+				}
+			}
+		}
+	})
+}
+
+func testLayers(tc *testContext, t *testing.T) {
+
+	params := tc.params
+	slots := params.Slots()
+
+	conv := randomConv2DLiteral(2, 4, 3, 2, 1)
+	in := NewLayout(2, 8, 8)
+
+	convLayer, err := NewConv2D(conv, in, slots)
+	require.NoError(t, err)
+
+	poolLayer, err := NewAvgPool2D(convLayer.Out, 2, 2, slots)
+	require.NoError(t, err)
+
+	flattenLayer, err := NewFlatten(poolLayer.Out, slots)
+	require.NoError(t, err)
+
+	layers := []*Layer{convLayer, poolLayer, flattenLayer}
+
+	for _, BSGSRatio := range []float64{0, 2} {
+
+		rotKeys := tc.kgen.GenRotationKeysForRotations(RotationsForLayers(params, layers, BSGSRatio), false, tc.sk)
+
+		eval := NewEvaluator(params, rlwe.EvaluationKey{Rtks: rotKeys})
+
+		tensor := randomTensor(in.Channels, in.Height, in.Width)
+
+		wantConv := Conv2D(conv, tensor)
+		wantPool := AvgPool2D(wantConv, 2, 2)
+		wantFlatten := Flatten(wantPool)
+
+		t.Run(fmt.Sprintf("Conv2D/BSGSRatio=%v", BSGSRatio), func(t *testing.T) {
+			ct := encryptTensor(tc, in, tensor, params.MaxLevel())
+			ct, err := eval.EvaluateNew(ct, convLayer.Encode(params, tc.encoder, ct.Level(), BSGSRatio))
+			require.NoError(t, err)
+			verifyTestVectors(tc, convLayer.Out.Encode(wantConv, slots), ct, t)
+		})
+
+		t.Run(fmt.Sprintf("AvgPool2D/BSGSRatio=%v", BSGSRatio), func(t *testing.T) {
+			ct := encryptTensor(tc, convLayer.Out, wantConv, params.MaxLevel()-1)
+			ct, err := eval.EvaluateNew(ct, poolLayer.Encode(params, tc.encoder, ct.Level(), BSGSRatio))
+			require.NoError(t, err)
+			verifyTestVectors(tc, poolLayer.Out.Encode(wantPool, slots), ct, t)
+		})
+
+		t.Run(fmt.Sprintf("Flatten/BSGSRatio=%v", BSGSRatio), func(t *testing.T) {
+			ct := encryptTensor(tc, poolLayer.Out, wantPool, params.MaxLevel()-2)
+			ct, err := eval.EvaluateNew(ct, flattenLayer.Encode(params, tc.encoder, ct.Level(), BSGSRatio))
+			require.NoError(t, err)
+
+			want := make([]float64, slots)
+			copy(want, wantFlatten)
+			verifyTestVectors(tc, want, ct, t)
+
+			_, err = eval.EvaluateNew(ct, flattenLayer.Encode(params, tc.encoder, ct.Level(), BSGSRatio))
+			require.Error(t, err)
+		})
+
+		t.Run(fmt.Sprintf("EvaluateMany/BSGSRatio=%v", BSGSRatio), func(t *testing.T) {
+
+			// Each layer consumes one level
+			encoded := make([]*EncodedLayer, len(layers))
+			for i, layer := range layers {
+				encoded[i] = layer.Encode(params, tc.encoder, params.MaxLevel()-i, BSGSRatio)
+			}
+
+			ct := encryptTensor(tc, in, tensor, params.MaxLevel())
+			ct, err := eval.EvaluateManyNew(ct, encoded)
+			require.NoError(t, err)
+			require.Equal(t, params.MaxLevel()-len(layers), ct.Level())
+
+			want := make([]float64, slots)
+			copy(want, wantFlatten)
+			verifyTestVectors(tc, want, ct, t)
+
+			// The output layout of the convolution is not the input layout of the flattening
+			ct = encryptTensor(tc, in, tensor, params.MaxLevel())
+			_, err = eval.EvaluateManyNew(ct, []*EncodedLayer{encoded[0], encoded[2]})
+			require.Error(t, err)
+		})
+	}
+}
+
+// encryptTensor encrypts the tensor in the given layout at the given level.
+func encryptTensor(tc *testContext, layout Layout, tensor [][][]float64, level int) *ckks.Ciphertext {
+	return tc.encryptor.EncryptNew(tc.encoder.EncodeNew(layout.Encode(tensor, tc.params.Slots()), level, tc.params.DefaultScale(), tc.params.LogSlots()))
+}
+
+func verifyTestVectors(tc *testContext, valuesWant []float64, ct *ckks.Ciphertext, t *testing.T) {
+
+	precStats := ckks.GetPrecisionStats(tc.params, tc.encoder, tc.decryptor, valuesWant, ct, tc.params.LogSlots(), 0)
+
+	if *printPrecisionStats {
+		t.Log(precStats.String())
+	}
+
+	require.GreaterOrEqual(t, precStats.MeanPrecision.Real, minPrec)
+}
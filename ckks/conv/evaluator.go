@@ -0,0 +1,79 @@
+package conv
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Evaluator is a struct to evaluate encoded layers on ciphertexts.
+type Evaluator struct {
+	ckks.Evaluator
+	params  ckks.Parameters
+	encoder ckks.Encoder
+}
+
+// NewEvaluator creates a new Evaluator from the given parameters and evaluation keys.
+// The rotation keys must contain the rotations returned by RotationsForLayers for all the layers to evaluate.
+func NewEvaluator(params ckks.Parameters, evaluationKey rlwe.EvaluationKey) *Evaluator {
+	return &Evaluator{
+		Evaluator: ckks.NewEvaluator(params, evaluationKey),
+		params:    params,
+		encoder:   ckks.NewEncoder(params),
+	}
+}
+
+// ShallowCopy creates a shallow copy of this Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluators can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	return &Evaluator{
+		Evaluator: eval.Evaluator.ShallowCopy(),
+		params:    eval.params,
+		encoder:   eval.encoder.ShallowCopy(),
+	}
+}
+
+// EvaluateNew evaluates the encoded layer on ctIn and returns the result on a new ciphertext.
+// The evaluation consumes exactly one level and preserves the scale of ctIn.
+// Returns an error if ctIn is at level 0.
+func (eval *Evaluator) EvaluateNew(ctIn *ckks.Ciphertext, layer *EncodedLayer) (ctOut *ckks.Ciphertext, err error) {
+
+	if ctIn.Level() == 0 {
+		return nil, fmt.Errorf("cannot EvaluateNew: input ciphertext is at level 0 but the layer consumes one level")
+	}
+
+	ctOut = eval.LinearTransformNew(ctIn, layer.LinearTransform)[0]
+
+	if err = eval.Rescale(ctOut, ctIn.Scale, ctOut); err != nil {
+		return nil, err
+	}
+
+	if layer.Bias != nil {
+		pt := eval.encoder.EncodeNew(layer.Bias, ctOut.Level(), ctOut.Scale, layer.LogSlots)
+		eval.Add(ctOut, pt, ctOut)
+	}
+
+	return
+}
+
+// EvaluateManyNew sequentially evaluates the encoded layers on ctIn and returns the result on a new ciphertext.
+// The evaluation consumes len(layers) levels.
+func (eval *Evaluator) EvaluateManyNew(ctIn *ckks.Ciphertext, layers []*EncodedLayer) (ctOut *ckks.Ciphertext, err error) {
+
+	ctOut = ctIn
+
+	for i, layer := range layers {
+
+		if i > 0 && layers[i-1].Out != layer.In {
+			return nil, fmt.Errorf("cannot EvaluateManyNew: output layout of layer %d does not match input layout of layer %d", i-1, i)
+		}
+
+		if ctOut, err = eval.EvaluateNew(ctOut, layer); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
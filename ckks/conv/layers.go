@@ -0,0 +1,352 @@
+package conv
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+)
+
+// Layer is a linear layer compiled into a diagonalized slot-domain matrix.
+// The layer maps a tensor packed according to In on a tensor packed according to Out, followed
+// by the optional addition of Bias (given in the slot domain, packed according to Out).
+type Layer struct {
+	In        Layout
+	Out       Layout
+	Slots     int
+	Diagonals map[int][]float64
+	Bias      []float64
+}
+
+// Conv2DLiteral is a user-friendly struct to describe a multi-channel 2D convolution.
+// Weights are indexed as Weights[out channel][in channel][kernel row][kernel column] and
+// Bias, if not nil, must contain one value per output channel.
+type Conv2DLiteral struct {
+	InChannels  int
+	OutChannels int
+	KernelSize  int
+	Stride      int
+	Padding     int
+	Weights     [][][][]float64
+	Bias        []float64
+}
+
+// OutputShape returns the spatial dimensions of the output of the convolution for an input of shape height x width.
+func (c Conv2DLiteral) OutputShape(height, width int) (int, int) {
+	return (height+2*c.Padding-c.KernelSize)/c.Stride + 1, (width+2*c.Padding-c.KernelSize)/c.Stride + 1
+}
+
+func (c Conv2DLiteral) check() (err error) {
+
+	if c.InChannels < 1 || c.OutChannels < 1 || c.KernelSize < 1 || c.Stride < 1 || c.Padding < 0 {
+		return fmt.Errorf("invalid Conv2DLiteral: in=%d, out=%d, kernel=%d, stride=%d, padding=%d", c.InChannels, c.OutChannels, c.KernelSize, c.Stride, c.Padding)
+	}
+
+	if len(c.Weights) != c.OutChannels {
+		return fmt.Errorf("invalid Conv2DLiteral: expected weights for %d output channels but got %d", c.OutChannels, len(c.Weights))
+	}
+
+	for _, w := range c.Weights {
+		if len(w) != c.InChannels {
+			return fmt.Errorf("invalid Conv2DLiteral: expected weights for %d input channels but got %d", c.InChannels, len(w))
+		}
+		for _, k := range w {
+			if len(k) != c.KernelSize {
+				return fmt.Errorf("invalid Conv2DLiteral: kernel must be %dx%d", c.KernelSize, c.KernelSize)
+			}
+			for _, row := range k {
+				if len(row) != c.KernelSize {
+					return fmt.Errorf("invalid Conv2DLiteral: kernel must be %dx%d", c.KernelSize, c.KernelSize)
+				}
+			}
+		}
+	}
+
+	if c.Bias != nil && len(c.Bias) != c.OutChannels {
+		return fmt.Errorf("invalid Conv2DLiteral: expected %d bias values but got %d", c.OutChannels, len(c.Bias))
+	}
+
+	return
+}
+
+// NewConv2D compiles the convolution described by conv into a Layer acting on tensors packed according to in,
+// for ciphertexts of the given number of slots.
+// The output is packed with a gap multiplied by the stride, as in the multiplexed packing.
+func NewConv2D(conv Conv2DLiteral, in Layout, slots int) (layer *Layer, err error) {
+
+	if err = conv.check(); err != nil {
+		return nil, err
+	}
+
+	if in.Channels != conv.InChannels {
+		return nil, fmt.Errorf("cannot NewConv2D: input layout has %d channels but convolution expects %d", in.Channels, conv.InChannels)
+	}
+
+	hOut, wOut := conv.OutputShape(in.Height, in.Width)
+
+	if hOut < 1 || wOut < 1 {
+		return nil, fmt.Errorf("cannot NewConv2D: kernel of size %d does not fit in the padded %dx%d input", conv.KernelSize, in.Height, in.Width)
+	}
+
+	out := Layout{Channels: conv.OutChannels, Height: hOut, Width: wOut, Gap: in.Gap * conv.Stride}
+
+	if layer, err = newLayer(in, out, slots); err != nil {
+		return nil, err
+	}
+
+	for co := 0; co < conv.OutChannels; co++ {
+		for i := 0; i < hOut; i++ {
+			for j := 0; j < wOut; j++ {
+
+				idxOut := out.Index(co, i, j)
+
+				for ci := 0; ci < conv.InChannels; ci++ {
+					for di := 0; di < conv.KernelSize; di++ {
+
+						x := i*conv.Stride + di - conv.Padding
+
+						if x < 0 || x >= in.Height {
+							continue
+						}
+
+						for dj := 0; dj < conv.KernelSize; dj++ {
+
+							y := j*conv.Stride + dj - conv.Padding
+
+							if y < 0 || y >= in.Width {
+								continue
+							}
+
+							layer.addEntry(idxOut, in.Index(ci, x, y), conv.Weights[co][ci][di][dj])
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if conv.Bias != nil {
+		layer.Bias = make([]float64, slots)
+		for co := 0; co < conv.OutChannels; co++ {
+			for i := 0; i < hOut; i++ {
+				for j := 0; j < wOut; j++ {
+					layer.Bias[out.Index(co, i, j)] = conv.Bias[co]
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// NewAvgPool2D compiles a channel-wise average pooling with a kernelSize x kernelSize window and the given stride into a Layer
+// acting on tensors packed according to in, for ciphertexts of the given number of slots.
+// The output is packed with a gap multiplied by the stride, as in the multiplexed packing.
+func NewAvgPool2D(in Layout, kernelSize, stride, slots int) (layer *Layer, err error) {
+
+	if kernelSize < 1 || stride < 1 || kernelSize > in.Height || kernelSize > in.Width {
+		return nil, fmt.Errorf("cannot NewAvgPool2D: invalid kernel size %d or stride %d for a %dx%d input", kernelSize, stride, in.Height, in.Width)
+	}
+
+	out := Layout{
+		Channels: in.Channels,
+		Height:   (in.Height-kernelSize)/stride + 1,
+		Width:    (in.Width-kernelSize)/stride + 1,
+		Gap:      in.Gap * stride,
+	}
+
+	if layer, err = newLayer(in, out, slots); err != nil {
+		return nil, err
+	}
+
+	w := 1 / float64(kernelSize*kernelSize)
+
+	for c := 0; c < out.Channels; c++ {
+		for i := 0; i < out.Height; i++ {
+			for j := 0; j < out.Width; j++ {
+				idxOut := out.Index(c, i, j)
+				for di := 0; di < kernelSize; di++ {
+					for dj := 0; dj < kernelSize; dj++ {
+						layer.addEntry(idxOut, in.Index(c, i*stride+di, j*stride+dj), w)
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// NewFlatten compiles the flattening of a tensor packed according to in into a Layer for ciphertexts of the given number of slots.
+// The value (c, i, j) is mapped on the slot c*Height*Width + i*Width + j, i.e. the output layout is 1 x 1 x (Channels*Height*Width).
+func NewFlatten(in Layout, slots int) (layer *Layer, err error) {
+
+	out := Layout{Channels: 1, Height: 1, Width: in.Len(), Gap: 1}
+
+	if layer, err = newLayer(in, out, slots); err != nil {
+		return nil, err
+	}
+
+	for c := 0; c < in.Channels; c++ {
+		for i := 0; i < in.Height; i++ {
+			for j := 0; j < in.Width; j++ {
+				layer.addEntry(out.Index(0, 0, (c*in.Height+i)*in.Width+j), in.Index(c, i, j), 1)
+			}
+		}
+	}
+
+	return
+}
+
+// NewDense compiles a fully connected layer into a Layer acting on tensors packed according to in, for ciphertexts of the given number of slots.
+// The input tensor is read in row-major order, i.e. the value (c, i, j) is the input c*Height*Width + i*Width + j, and weights are indexed
+// as weights[output][input]. The output layout is 1 x 1 x len(weights). Bias, if not nil, must contain one value per output.
+func NewDense(weights [][]float64, bias []float64, in Layout, slots int) (layer *Layer, err error) {
+
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("cannot NewDense: weights cannot be empty")
+	}
+
+	for _, row := range weights {
+		if len(row) != in.Len() {
+			return nil, fmt.Errorf("cannot NewDense: expected %d weights per output but got %d", in.Len(), len(row))
+		}
+	}
+
+	if bias != nil && len(bias) != len(weights) {
+		return nil, fmt.Errorf("cannot NewDense: expected %d bias values but got %d", len(weights), len(bias))
+	}
+
+	out := Layout{Channels: 1, Height: 1, Width: len(weights), Gap: 1}
+
+	if layer, err = newLayer(in, out, slots); err != nil {
+		return nil, err
+	}
+
+	for k := range weights {
+		idxOut := out.Index(0, 0, k)
+		for c := 0; c < in.Channels; c++ {
+			for i := 0; i < in.Height; i++ {
+				for j := 0; j < in.Width; j++ {
+					if w := weights[k][(c*in.Height+i)*in.Width+j]; w != 0 {
+						layer.addEntry(idxOut, in.Index(c, i, j), w)
+					}
+				}
+			}
+		}
+	}
+
+	if bias != nil {
+		layer.Bias = make([]float64, slots)
+		for k := range bias {
+			layer.Bias[out.Index(0, 0, k)] = bias[k]
+		}
+	}
+
+	return
+}
+
+func newLayer(in, out Layout, slots int) (layer *Layer, err error) {
+
+	if slots < 1 || slots&(slots-1) != 0 {
+		return nil, fmt.Errorf("invalid number of slots %d: must be a power of two", slots)
+	}
+
+	if err = in.Check(slots); err != nil {
+		return nil, err
+	}
+
+	if err = out.Check(slots); err != nil {
+		return nil, err
+	}
+
+	return &Layer{In: in, Out: out, Slots: slots, Diagonals: make(map[int][]float64)}, nil
+}
+
+// addEntry adds w to the entry (row, col) of the matrix, stored in the diagonal (col - row) mod slots.
+func (l *Layer) addEntry(row, col int, w float64) {
+
+	diag := (col - row + l.Slots) & (l.Slots - 1)
+
+	if _, ok := l.Diagonals[diag]; !ok {
+		l.Diagonals[diag] = make([]float64, l.Slots)
+	}
+
+	l.Diagonals[diag][row] += w
+}
+
+// LogSlots returns the log2 of the number of slots the layer acts on.
+func (l *Layer) LogSlots() (logSlots int) {
+	for 1<<logSlots < l.Slots {
+		logSlots++
+	}
+	return
+}
+
+// Rotations returns the exact list of rotations needed to evaluate the layer with the given BSGSRatio
+// (BSGSRatio == 0 for the naive approach).
+func (l *Layer) Rotations(params ckks.Parameters, BSGSRatio float64) (rotations []int) {
+	return params.RotationsForLinearTransform(l.Diagonals, l.LogSlots(), BSGSRatio)
+}
+
+// Encode encodes the layer on an EncodedLayer at the given level, using the given BSGSRatio (BSGSRatio == 0 for the naive approach).
+// The matrix is encoded with a scale equal to the modulus at the given level so that the evaluation of the layer
+// followed by a rescaling preserves the scale of the input ciphertext.
+func (l *Layer) Encode(params ckks.Parameters, encoder ckks.Encoder, level int, BSGSRatio float64) (el *EncodedLayer) {
+
+	scale := float64(params.Q()[level])
+
+	el = &EncodedLayer{In: l.In, Out: l.Out, Bias: l.Bias}
+
+	if BSGSRatio == 0 {
+		el.LinearTransform = ckks.GenLinearTransform(encoder, l.Diagonals, level, scale, l.LogSlots())
+	} else {
+		el.LinearTransform = ckks.GenLinearTransformBSGS(encoder, l.Diagonals, level, scale, BSGSRatio, l.LogSlots())
+	}
+
+	return
+}
+
+// Evaluate applies the layer in the clear on a vector of slots packed according to In and returns
+// the result packed according to Out. The result is exact up to floating point arithmetic.
+func (l *Layer) Evaluate(values []float64) (res []float64) {
+
+	res = make([]float64, l.Slots)
+
+	for diag, vec := range l.Diagonals {
+		for i := range res {
+			res[i] += vec[i] * values[(i+diag)&(l.Slots-1)]
+		}
+	}
+
+	if l.Bias != nil {
+		for i := range res {
+			res[i] += l.Bias[i]
+		}
+	}
+
+	return
+}
+
+// EncodedLayer is a Layer encoded on a plaintext linear transform, ready to be evaluated.
+type EncodedLayer struct {
+	ckks.LinearTransform
+	In   Layout
+	Out  Layout
+	Bias []float64
+}
+
+// RotationsForLayers returns the union of the rotations needed to evaluate all the given layers with the given BSGSRatio.
+func RotationsForLayers(params ckks.Parameters, layers []*Layer, BSGSRatio float64) (rotations []int) {
+
+	rotIndex := make(map[int]bool)
+	for _, l := range layers {
+		for _, k := range l.Rotations(params, BSGSRatio) {
+			if !rotIndex[k] {
+				rotIndex[k] = true
+				rotations = append(rotations, k)
+			}
+		}
+	}
+
+	return
+}
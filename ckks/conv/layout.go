@@ -0,0 +1,125 @@
+// Package conv implements homomorphic 2D convolution, average pooling and flattening layers for the CKKS scheme.
+// Multi-channel tensors are packed in the slots of a single ciphertext using a multiplexed layout, and each layer
+// is compiled into a diagonalized plaintext matrix evaluated with the linear transform of the ckks package.
+package conv
+
+import (
+	"fmt"
+)
+
+// Layout describes how a tensor of shape Channels x Height x Width is packed in the slots of a ciphertext.
+//
+// The layout follows the multiplexed packing: the tensor is stored on a grid of (Height*Gap) x (Width*Gap)
+// slots, and Gap*Gap channels are interleaved on the same grid. Channel c is stored in the block c / (Gap*Gap)
+// and the value (c, i, j) is found at the grid position (i*Gap + (c%(Gap*Gap))/Gap, j*Gap + c%Gap).
+// Strided layers increase the gap instead of repacking the values, which keeps the number of non-zero
+// diagonals (and therefore rotations) low.
+type Layout struct {
+	Channels int
+	Height   int
+	Width    int
+	Gap      int
+}
+
+// NewLayout creates a new compact Layout (Gap = 1) for tensors of shape channels x height x width.
+func NewLayout(channels, height, width int) Layout {
+	return Layout{Channels: channels, Height: height, Width: width, Gap: 1}
+}
+
+// Blocks returns the number of (Height*Gap) x (Width*Gap) grids used by the layout.
+func (l Layout) Blocks() int {
+	g2 := l.Gap * l.Gap
+	return (l.Channels + g2 - 1) / g2
+}
+
+// Size returns the number of slots spanned by the layout.
+func (l Layout) Size() int {
+	return l.Blocks() * l.Height * l.Gap * l.Width * l.Gap
+}
+
+// Index returns the slot index of the value (c, i, j).
+func (l Layout) Index(c, i, j int) int {
+	g2 := l.Gap * l.Gap
+	block, sub := c/g2, c%g2
+	row := i*l.Gap + sub/l.Gap
+	col := j*l.Gap + sub%l.Gap
+	return block*l.Height*l.Gap*l.Width*l.Gap + row*l.Width*l.Gap + col
+}
+
+// Len returns the number of values of the tensor described by the layout.
+func (l Layout) Len() int {
+	return l.Channels * l.Height * l.Width
+}
+
+// Check returns an error if the layout is malformed or if it does not fit in the given number of slots.
+func (l Layout) Check(slots int) (err error) {
+	if l.Channels < 1 || l.Height < 1 || l.Width < 1 || l.Gap < 1 {
+		return fmt.Errorf("invalid layout: all dimensions must be positive, got %dx%dx%d with gap %d", l.Channels, l.Height, l.Width, l.Gap)
+	}
+
+	if l.Size() > slots {
+		return fmt.Errorf("invalid layout: layout spans %d slots but only %d are available", l.Size(), slots)
+	}
+
+	return
+}
+
+// Encode packs the tensor values[c][i][j] on a vector of slots according to the layout.
+// Unused slots are set to zero.
+func (l Layout) Encode(values [][][]float64, slots int) (vec []float64) {
+
+	if len(values) != l.Channels {
+		panic(fmt.Sprintf("cannot Encode: tensor has %d channels but layout expects %d", len(values), l.Channels))
+	}
+
+	vec = make([]float64, slots)
+
+	for c := range values {
+		for i := 0; i < l.Height; i++ {
+			for j := 0; j < l.Width; j++ {
+				vec[l.Index(c, i, j)] = values[c][i][j]
+			}
+		}
+	}
+
+	return
+}
+
+// Decode unpacks a vector of slots into a tensor of shape Channels x Height x Width according to the layout.
+// values.(type) can be either []complex128 or []float64, in which case only the real part is considered.
+func (l Layout) Decode(values interface{}) (tensor [][][]float64) {
+
+	var get func(i int) float64
+	switch v := values.(type) {
+	case []complex128:
+		get = func(i int) float64 { return real(v[i]) }
+	case []float64:
+		get = func(i int) float64 { return v[i] }
+	default:
+		panic("cannot Decode: values must be []complex128 or []float64")
+	}
+
+	tensor = NewTensor(l.Channels, l.Height, l.Width)
+
+	for c := range tensor {
+		for i := range tensor[c] {
+			for j := range tensor[c][i] {
+				tensor[c][i][j] = get(l.Index(c, i, j))
+			}
+		}
+	}
+
+	return
+}
+
+// NewTensor allocates a new zero tensor of shape channels x height x width.
+func NewTensor(channels, height, width int) (tensor [][][]float64) {
+	tensor = make([][][]float64, channels)
+	for c := range tensor {
+		tensor[c] = make([][]float64, height)
+		for i := range tensor[c] {
+			tensor[c][i] = make([]float64, width)
+		}
+	}
+	return
+}
@@ -0,0 +1,86 @@
+package conv
+
+// Conv2D evaluates in the clear the convolution described by conv on the tensor in[c][i][j].
+// It serves as a reference for the homomorphic evaluation.
+func Conv2D(conv Conv2DLiteral, in [][][]float64) (out [][][]float64) {
+
+	height, width := len(in[0]), len(in[0][0])
+
+	hOut, wOut := conv.OutputShape(height, width)
+
+	out = NewTensor(conv.OutChannels, hOut, wOut)
+
+	for co := range out {
+		for i := range out[co] {
+			for j := range out[co][i] {
+
+				var acc float64
+
+				for ci := 0; ci < conv.InChannels; ci++ {
+					for di := 0; di < conv.KernelSize; di++ {
+
+						x := i*conv.Stride + di - conv.Padding
+
+						if x < 0 || x >= height {
+							continue
+						}
+
+						for dj := 0; dj < conv.KernelSize; dj++ {
+
+							y := j*conv.Stride + dj - conv.Padding
+
+							if y < 0 || y >= width {
+								continue
+							}
+
+							acc += conv.Weights[co][ci][di][dj] * in[ci][x][y]
+						}
+					}
+				}
+
+				if conv.Bias != nil {
+					acc += conv.Bias[co]
+				}
+
+				out[co][i][j] = acc
+			}
+		}
+	}
+
+	return
+}
+
+// AvgPool2D evaluates in the clear a channel-wise average pooling on the tensor in[c][i][j].
+// It serves as a reference for the homomorphic evaluation.
+func AvgPool2D(in [][][]float64, kernelSize, stride int) (out [][][]float64) {
+
+	height, width := len(in[0]), len(in[0][0])
+
+	out = NewTensor(len(in), (height-kernelSize)/stride+1, (width-kernelSize)/stride+1)
+
+	w := 1 / float64(kernelSize*kernelSize)
+
+	for c := range out {
+		for i := range out[c] {
+			for j := range out[c][i] {
+				for di := 0; di < kernelSize; di++ {
+					for dj := 0; dj < kernelSize; dj++ {
+						out[c][i][j] += w * in[c][i*stride+di][j*stride+dj]
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// Flatten returns the tensor in[c][i][j] as a vector in row-major order.
+func Flatten(in [][][]float64) (out []float64) {
+	for c := range in {
+		for i := range in[c] {
+			out = append(out, in[c][i]...)
+		}
+	}
+	return
+}
@@ -227,7 +227,7 @@ func (eval *evaluator) PermuteNTTIndexesForKey(rtks *rlwe.RotationKeySet) *map[u
 	return &PermuteNTTIndex
 }
 
-func (eval *evaluator) checkBinary(op0, op1, opOut Operand, opOutMinDegree int) {
+func (eval *evaluatorBase) checkBinary(op0, op1, opOut Operand, opOutMinDegree int) {
 	if op0 == nil || op1 == nil || opOut == nil {
 		panic("cannot checkBinary: operands cannot be nil")
 	}
@@ -253,7 +253,7 @@ func (eval *evaluator) checkBinary(op0, op1, opOut Operand, opOutMinDegree int)
 	}
 }
 
-func (eval *evaluator) newCiphertextBinary(op0, op1 Operand) (ctOut *Ciphertext) {
+func (eval *evaluatorBase) newCiphertextBinary(op0, op1 Operand) (ctOut *Ciphertext) {
 
 	maxDegree := utils.MaxInt(op0.Degree(), op1.Degree())
 	maxScale := utils.MaxFloat64(op0.ScalingFactor(), op1.ScalingFactor())
@@ -479,7 +479,7 @@ func (eval *evaluator) AddConstNew(ct0 *Ciphertext, constant interface{}) (ctOut
 	return ctOut
 }
 
-func (eval *evaluator) getConstAndScale(level int, constant interface{}) (cReal, cImag, scale float64) {
+func (eval *evaluatorBase) getConstAndScale(level int, constant interface{}) (cReal, cImag, scale float64) {
 
 	// Converts to float64 and determines if a scaling is required (which is the case if either real or imag have a rational part)
 	scale = 1
@@ -4,6 +4,7 @@ import (
 	"runtime"
 
 	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
 	"github.com/cipherflow-fhe/lattigo/rlwe/ringqp"
 	"github.com/cipherflow-fhe/lattigo/utils"
 )
@@ -295,66 +296,31 @@ func GenLinearTransformBSGS(encoder Encoder, value interface{}, level int, scale
 
 // BsgsIndex returns the index map and needed rotation for the BSGS matrix-vector multiplication algorithm.
 func BsgsIndex(el interface{}, slots, N1 int) (index map[int][]int, rotN1, rotN2 []int) {
-	index = make(map[int][]int)
-	rotN1Map := make(map[int]bool)
-	rotN2Map := make(map[int]bool)
-	var nonZeroDiags []int
+	return rlwe.BsgsIndex(nonZeroDiagonals(el), slots, N1)
+}
+
+// nonZeroDiagonals returns the indexes of the diagonals of el.
+func nonZeroDiagonals(el interface{}) (nonZeroDiags []int) {
 	switch element := el.(type) {
 	case map[int][]complex128:
-		nonZeroDiags = make([]int, len(element))
-		var i int
 		for key := range element {
-			nonZeroDiags[i] = key
-			i++
+			nonZeroDiags = append(nonZeroDiags, key)
 		}
 	case map[int][]float64:
-		nonZeroDiags = make([]int, len(element))
-		var i int
 		for key := range element {
-			nonZeroDiags[i] = key
-			i++
+			nonZeroDiags = append(nonZeroDiags, key)
 		}
 	case map[int]bool:
-		nonZeroDiags = make([]int, len(element))
-		var i int
 		for key := range element {
-			nonZeroDiags[i] = key
-			i++
+			nonZeroDiags = append(nonZeroDiags, key)
 		}
 	case map[int]ringqp.Poly:
-		nonZeroDiags = make([]int, len(element))
-		var i int
 		for key := range element {
-			nonZeroDiags[i] = key
-			i++
+			nonZeroDiags = append(nonZeroDiags, key)
 		}
 	case []int:
 		nonZeroDiags = element
 	}
-
-	for _, rot := range nonZeroDiags {
-		rot &= (slots - 1)
-		idxN1 := ((rot / N1) * N1) & (slots - 1)
-		idxN2 := rot & (N1 - 1)
-		if index[idxN1] == nil {
-			index[idxN1] = []int{idxN2}
-		} else {
-			index[idxN1] = append(index[idxN1], idxN2)
-		}
-		rotN1Map[idxN1] = true
-		rotN2Map[idxN2] = true
-	}
-
-	rotN1 = []int{}
-	for i := range rotN1Map {
-		rotN1 = append(rotN1, i)
-	}
-
-	rotN2 = []int{}
-	for i := range rotN2Map {
-		rotN2 = append(rotN2, i)
-	}
-
 	return
 }
 
@@ -377,23 +343,7 @@ func interfaceMapToMapOfInterface(m interface{}) map[int]interface{} {
 
 // FindBestBSGSSplit finds the best N1*N2 = N for the baby-step giant-step algorithm for matrix multiplication.
 func FindBestBSGSSplit(diagMatrix interface{}, maxN int, maxRatio float64) (minN int) {
-
-	for N1 := 1; N1 < maxN; N1 <<= 1 {
-
-		_, rotN1, rotN2 := BsgsIndex(diagMatrix, maxN, N1)
-
-		nbN1, nbN2 := len(rotN1)-1, len(rotN2)-1
-
-		if float64(nbN2)/float64(nbN1) == maxRatio {
-			return N1
-		}
-
-		if float64(nbN2)/float64(nbN1) > maxRatio {
-			return N1 / 2
-		}
-	}
-
-	return 1
+	return rlwe.FindBestBSGSSplit(nonZeroDiagonals(diagMatrix), maxN, maxRatio)
 }
 
 // LinearTransformNew evaluates a linear transform on the ciphertext and returns the result on a new ciphertext.
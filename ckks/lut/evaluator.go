@@ -0,0 +1,262 @@
+// Package lut implements the slot-wise evaluation of arbitrary functions on CKKS ciphertexts with look-up tables (LUT),
+// evaluated by the blind rotation of the rgsw/lut package.
+//
+// The evaluation moves the slots to the coefficients with the homomorphic encoding (SlotsToCoeffs), switches the ciphertext at
+// level zero to the LWE secret of smaller ring degree, evaluates the LUT on the LWE sample of each slot by blind rotation, repacks
+// the outputs in a single ciphertext at the maximum level, and moves them back to the slots with the homomorphic decoding
+// (CoeffsToSlots). Unlike EvaluatePoly, its precision does not depend on the smoothness of the function, but only on the
+// resolution 2/N of the LUT and on the error of the LWE samples, which makes it suitable for discontinuous functions such as sign.
+package lut
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ckks/advanced"
+	rgswlut "github.com/cipherflow-fhe/lattigo/rgsw/lut"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Parameters is a struct for the parameters of the LUT evaluation.
+type Parameters struct {
+	LogNLWE int     // Log2 of the ring degree of the LWE samples, between LogSlots+1 and LogN. The blind rotation of each slot has N_LWE external products.
+	HLWE    int     // Hamming weight of the LWE secret. If 0, it is set to N_LWE/2.
+	A, B    float64 // Interval of the input values. It should include a margin for the error of the switch of the LWE samples modulo 2N.
+}
+
+// EvaluationKeys is a struct for the keys of the LUT evaluation.
+type EvaluationKeys struct {
+	Rtks             *rlwe.RotationKeySet  // Rotation keys of the homomorphic encoding and decoding and of the repacking.
+	SwkToLWE         *rlwe.SwitchingKey    // Switching key from the CKKS secret to the LWE secret at level zero.
+	BlindRotationKey rgswlut.EvaluationKey // RGSW encryptions of the LWE secret under the CKKS secret.
+}
+
+// Evaluator is a struct to evaluate functions on CKKS ciphertexts with look-up tables.
+//
+// The blind rotations are evaluated at the maximum level of the CKKS parameters, whose number of moduli sets the cost of
+// the external products, hence the parameters should have few levels, as the ciphertexts are refreshed by the evaluation:
+// the input ciphertext must be at level one or more and at scale params.DefaultScale(), and the output ciphertext is at
+// level params.MaxLevel()-1 and at scale params.DefaultScale().
+type Evaluator struct {
+	advanced.Evaluator
+	params    ckks.Parameters
+	lutParams Parameters
+
+	paramsLWE rlwe.Parameters
+	ksEval    *rlwe.Evaluator
+	lutEval   *rgswlut.Evaluator
+	evk       EvaluationKeys
+
+	stcMatrices advanced.EncodingMatrix
+	ctsMatrices advanced.EncodingMatrix
+}
+
+// NewEvaluator creates a new Evaluator for the parameters params, which must have at least two moduli.
+func NewEvaluator(params ckks.Parameters, lutParams Parameters, evk EvaluationKeys) (eval *Evaluator, err error) {
+
+	if err = lutParams.validate(params); err != nil {
+		return nil, fmt.Errorf("cannot NewEvaluator: %w", err)
+	}
+
+	eval = &Evaluator{params: params, lutParams: lutParams, evk: evk}
+
+	var paramsKS rlwe.Parameters
+	if eval.paramsLWE, paramsKS, err = lutParams.lweParameters(params); err != nil {
+		return nil, fmt.Errorf("cannot NewEvaluator: %w", err)
+	}
+
+	eval.Evaluator = advanced.NewEvaluator(params, rlwe.EvaluationKey{Rtks: evk.Rtks})
+	eval.ksEval = rlwe.NewEvaluator(paramsKS, nil)
+	eval.lutEval = rgswlut.NewEvaluator(params.Parameters, eval.paramsLWE, evk.Rtks)
+
+	encoder := ckks.NewEncoder(params)
+	stcParams, ctsParams := lutParams.encodingMatricesLiteral(params)
+	eval.stcMatrices = advanced.NewHomomorphicEncodingMatrixFromLiteral(stcParams, encoder)
+	eval.ctsMatrices = advanced.NewHomomorphicEncodingMatrixFromLiteral(ctsParams, encoder)
+
+	return
+}
+
+// GenEvaluationKeys generates the keys of the LUT evaluation. It generates a new LWE secret, which is only
+// used to generate the keys.
+func GenEvaluationKeys(params ckks.Parameters, lutParams Parameters, sk *rlwe.SecretKey) (evk EvaluationKeys, err error) {
+
+	if err = lutParams.validate(params); err != nil {
+		return evk, fmt.Errorf("cannot GenEvaluationKeys: %w", err)
+	}
+
+	paramsLWE, paramsKS, err := lutParams.lweParameters(params)
+	if err != nil {
+		return evk, fmt.Errorf("cannot GenEvaluationKeys: %w", err)
+	}
+
+	skLWE := rlwe.NewKeyGenerator(paramsLWE).GenSecretKey()
+
+	return EvaluationKeys{
+		Rtks:             ckks.NewKeyGenerator(params).GenRotationKeysForRotations(lutParams.Rotations(params), true, sk),
+		SwkToLWE:         rlwe.NewKeyGenerator(paramsKS).GenSwitchingKey(sk, skLWE),
+		BlindRotationKey: rgswlut.GenEvaluationKey(params.Parameters, sk, paramsLWE, skLWE),
+	}, nil
+}
+
+// Rotations returns the list of rotations required by the homomorphic encoding and decoding and by the repacking.
+// The repacking also requires the conjugation key.
+func (p *Parameters) Rotations(params ckks.Parameters) (rotations []int) {
+
+	stcParams, ctsParams := p.encodingMatricesLiteral(params)
+
+	rotKeys := make(map[int]bool)
+
+	for _, i := range append(stcParams.Rotations(), ctsParams.Rotations()...) {
+		rotKeys[i] = true
+	}
+
+	// Rotations of the repacking
+	for i := 1; i < params.N()>>1; i <<= 1 {
+		rotKeys[i] = true
+	}
+
+	rotations = make([]int, 0, len(rotKeys))
+	for i := range rotKeys {
+		if i != 0 {
+			rotations = append(rotations, i)
+		}
+	}
+
+	return
+}
+
+func (p *Parameters) validate(params ckks.Parameters) (err error) {
+
+	if params.MaxLevel() < 1 {
+		return fmt.Errorf("invalid parameters: must have at least two moduli")
+	}
+
+	if p.LogNLWE < params.LogSlots()+1 || p.LogNLWE > params.LogN() {
+		return fmt.Errorf("invalid LogNLWE: must be between LogSlots+1 = %d and LogN = %d", params.LogSlots()+1, params.LogN())
+	}
+
+	if p.A >= p.B {
+		return fmt.Errorf("invalid interval: A must be smaller than B")
+	}
+
+	return
+}
+
+// lweParameters returns the parameters of the LWE samples and the parameters of the key-switching to the LWE secret,
+// which share the modulus Q0 and the auxiliary moduli of params.
+func (p *Parameters) lweParameters(params ckks.Parameters) (paramsLWE, paramsKS rlwe.Parameters, err error) {
+
+	if paramsLWE, err = rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN:     p.LogNLWE,
+		Q:        params.Q()[:1],
+		P:        params.P(),
+		Pow2Base: params.Pow2Base(),
+		H:        p.HLWE,
+		Sigma:    params.Sigma(),
+	}); err != nil {
+		return
+	}
+
+	paramsKS, err = rlwe.NewParameters(params.LogN(), params.Q()[:1], params.P(), params.Pow2Base(), params.HammingWeight(), params.Sigma(), params.RingType())
+
+	return
+}
+
+// encodingMatricesLiteral returns the literals of the homomorphic encoding, which consumes the level one and
+// normalizes the values from [A, B] to [-1, 1] scaled by Q0/(4 * params.DefaultScale()), and of the homomorphic
+// decoding, which consumes the maximum level.
+func (p *Parameters) encodingMatricesLiteral(params ckks.Parameters) (stcParams, ctsParams advanced.EncodingMatrixLiteral) {
+
+	stcParams = advanced.EncodingMatrixLiteral{
+		LinearTransformType: advanced.SlotsToCoeffs,
+		LogN:                params.LogN(),
+		LogSlots:            params.LogSlots(),
+		Scaling:             2 / (p.B - p.A) * params.QiFloat64(0) / (4 * params.DefaultScale()),
+		LevelStart:          1,
+		BSGSRatio:           2.0,
+		ScalingFactor:       [][]float64{{params.QiFloat64(1)}},
+	}
+
+	ctsParams = advanced.EncodingMatrixLiteral{
+		LinearTransformType: advanced.CoeffsToSlots,
+		LogN:                params.LogN(),
+		LogSlots:            params.LogSlots(),
+		Scaling:             1 / float64(params.Slots()),
+		LevelStart:          params.MaxLevel(),
+		BSGSRatio:           2.0,
+		ScalingFactor:       [][]float64{{params.QiFloat64(params.MaxLevel())}},
+	}
+
+	return
+}
+
+// NewLUT returns the LUT polynomial of the function f on the interval [A, B], whose outputs are scaled by params.DefaultScale().
+func (eval *Evaluator) NewLUT(f func(x float64) (y float64)) (lut *ring.Poly) {
+	return rgswlut.InitLUT(f, eval.params.DefaultScale(), eval.params.RingQ(), eval.lutParams.A, eval.lutParams.B)
+}
+
+// EvaluateNew evaluates the function f on each slot of the real part of ctIn and returns the result on a new ciphertext,
+// whose imaginary part is zero. The values of ctIn must be in the interval [A, B].
+func (eval *Evaluator) EvaluateNew(ctIn *ckks.Ciphertext, f func(x float64) (y float64)) (ctOut *ckks.Ciphertext) {
+	return eval.EvaluateLUTNew(ctIn, eval.NewLUT(f))
+}
+
+// EvaluateLUTNew evaluates the LUT polynomial lut, created with NewLUT, on each slot of the real part of ctIn
+// and returns the result on a new ciphertext, whose imaginary part is zero. The values of ctIn must be in the interval [A, B].
+func (eval *Evaluator) EvaluateLUTNew(ctIn *ckks.Ciphertext, lut *ring.Poly) (ctOut *ckks.Ciphertext) {
+
+	if ctIn.Degree() != 1 {
+		panic("cannot EvaluateLUTNew: input ciphertext must be of degree 1")
+	}
+
+	if ctIn.Level() < 1 {
+		panic("cannot EvaluateLUTNew: input ciphertext must be at level 1 or more")
+	}
+
+	if ctIn.Scale != eval.params.DefaultScale() {
+		panic("cannot EvaluateLUTNew: input ciphertext must be at scale params.DefaultScale()")
+	}
+
+	params := eval.params
+	slots := params.Slots()
+
+	// Centers the interval [A, B] on zero
+	ct := eval.DropLevelNew(ctIn, ctIn.Level()-1)
+	eval.AddConst(ct, -(eval.lutParams.A+eval.lutParams.B)/2, ct)
+
+	// Homomorphic encoding: the real parts of the slots, normalized to [-1, 1] and scaled by Q0/4, are
+	// placed on the coefficients of index i * N/(2*slots), followed by the imaginary parts.
+	ct = eval.SlotsToCoeffsNew(ct, nil, eval.stcMatrices)
+	ct.Scale = params.QiFloat64(0) / 4
+
+	// Key-switch to the LWE secret and switch of the ring degree to N_LWE
+	ctLWE := rlwe.NewCiphertextNTT(eval.paramsLWE, 1, 0)
+	if eval.paramsLWE.N() == params.N() {
+		eval.ksEval.SwitchKeys(ct.Ciphertext, eval.evk.SwkToLWE, ctLWE)
+	} else {
+		ctTmp := rlwe.NewCiphertextNTT(params.Parameters, 1, 0)
+		eval.ksEval.SwitchKeys(ct.Ciphertext, eval.evk.SwkToLWE, ctTmp)
+		rlwe.SwitchCiphertextRingDegreeNTT(ctTmp, eval.paramsLWE.RingQ(), params.RingQ(), ctLWE)
+	}
+
+	// Blind rotation of the LWE samples of the real parts and repacking of the outputs
+	gapLWE := eval.paramsLWE.N() / (2 * slots)
+	gap := params.N() / (2 * slots)
+
+	lutPolyMap := make(map[int]*ring.Poly, slots)
+	repackIndex := make(map[int]int, slots)
+	for i := 0; i < slots; i++ {
+		lutPolyMap[i*gapLWE] = lut
+		repackIndex[i*gapLWE] = i * gap
+	}
+
+	ctOut = ckks.NewCiphertext(params, 1, params.MaxLevel(), params.DefaultScale())
+	ctOut.Ciphertext = eval.lutEval.EvaluateAndRepack(ctLWE, lutPolyMap, repackIndex, eval.evk.BlindRotationKey)
+
+	// Homomorphic decoding
+	ctOut, _ = eval.CoeffsToSlotsNew(ctOut, eval.ctsMatrices)
+
+	return
+}
@@ -0,0 +1,112 @@
+package lut
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(x float64) float64 {
+	if x > 0 {
+		return 1
+	} else if x < 0 {
+		return -1
+	}
+	return 0
+}
+
+func TestLUT(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping LUT tests for GOARCH=wasm")
+	}
+
+	// Insecure parameters for fast testing only
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:         10,
+		Q:            []uint64{0x800004001, 0x40002001},
+		P:            []uint64{0x4000026001},
+		LogSlots:     3,
+		DefaultScale: 1 << 32,
+	})
+	require.NoError(t, err)
+
+	lutParams := Parameters{
+		LogNLWE: 9,
+		A:       -8,
+		B:       8,
+	}
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	encoder := ckks.NewEncoder(params)
+	encryptor := ckks.NewEncryptor(params, sk)
+	decryptor := ckks.NewDecryptor(params, sk)
+
+	evk, err := GenEvaluationKeys(params, lutParams, sk)
+	require.NoError(t, err)
+
+	eval, err := NewEvaluator(params, lutParams, evk)
+	require.NoError(t, err)
+
+	for _, f := range []struct {
+		name string
+		f    func(x float64) float64
+		tol  float64
+	}{
+		{"Sign", sign, 1e-3},
+		{"Floor", math.Floor, 1e-3},
+	} {
+		t.Run(fmt.Sprintf("LUT/logN=%d/logSlots=%d/logNLWE=%d/%s", params.LogN(), params.LogSlots(), lutParams.LogNLWE, f.name), func(t *testing.T) {
+
+			values := make([]float64, params.Slots())
+			for i := range values {
+				values[i] = -7.5 + 15*float64(i)/float64(params.Slots()-1)
+			}
+
+			ct := encryptor.EncryptNew(encoder.EncodeNew(values, params.MaxLevel(), params.DefaultScale(), params.LogSlots()))
+
+			ct = eval.EvaluateNew(ct, f.f)
+
+			require.Equal(t, params.MaxLevel()-1, ct.Level())
+			require.Equal(t, params.DefaultScale(), ct.Scale)
+
+			have := encoder.Decode(decryptor.DecryptNew(ct), params.LogSlots())
+			for i := range values {
+				require.InDelta(t, f.f(values[i]), real(have[i]), f.tol)
+				require.InDelta(t, 0, imag(have[i]), f.tol)
+			}
+		})
+	}
+
+	t.Run(fmt.Sprintf("LUT/logN=%d/logSlots=%d/logNLWE=%d/Interval=[0,4]/Sqrt", params.LogN(), params.LogSlots(), lutParams.LogNLWE), func(t *testing.T) {
+
+		lutParams := Parameters{
+			LogNLWE: 9,
+			A:       0,
+			B:       4,
+		}
+
+		eval, err := NewEvaluator(params, lutParams, evk)
+		require.NoError(t, err)
+
+		values := make([]float64, params.Slots())
+		for i := range values {
+			values[i] = 0.25 + 3.5*float64(i)/float64(params.Slots()-1)
+		}
+
+		ct := encryptor.EncryptNew(encoder.EncodeNew(values, params.MaxLevel(), params.DefaultScale(), params.LogSlots()))
+
+		ct = eval.EvaluateNew(ct, math.Sqrt)
+
+		// The resolution of the LUT is (B-A)/N
+		have := encoder.Decode(decryptor.DecryptNew(ct), params.LogSlots())
+		for i := range values {
+			require.InDelta(t, math.Sqrt(values[i]), real(have[i]), 0.05)
+		}
+	})
+}
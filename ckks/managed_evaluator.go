@@ -0,0 +1,293 @@
+package ckks
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// ManagedEvaluator is an evaluator that automatically manages the levels and scales of the operands.
+// Before additions and subtractions, the operands are brought to the same level and to exactly the same scale.
+// If the ratio between the scales is an integer this is done without consuming a level, else one level is
+// consumed, preferably on the operand with the highest level.
+// Multiplications are relinearized and rescaled lazily: the product is left at its scale and the operands are
+// rescaled before the next multiplication, so that sums of products only require a single rescaling and the
+// scale stays close to the default scale of the parameters.
+// Instead of producing incorrect results, the operations return an error if they cannot be carried out at the
+// remaining level.
+// The ManagedEvaluator only provides the managed operations and the rotations: the underlying Evaluator is not
+// exposed, so that the levels and scales of the operands cannot be modified without being managed.
+type ManagedEvaluator struct {
+	evaluator Evaluator
+	*evaluatorBase
+}
+
+// NewManagedEvaluator creates a new ManagedEvaluator from the given parameters and evaluation keys.
+func NewManagedEvaluator(params Parameters, evaluationKey rlwe.EvaluationKey) *ManagedEvaluator {
+	return &ManagedEvaluator{
+		evaluator:     NewEvaluator(params, evaluationKey),
+		evaluatorBase: newEvaluatorBase(params),
+	}
+}
+
+// ShallowCopy creates a shallow copy of this ManagedEvaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// ManagedEvaluators can be used concurrently.
+func (eval *ManagedEvaluator) ShallowCopy() *ManagedEvaluator {
+	return &ManagedEvaluator{
+		evaluator:     eval.evaluator.ShallowCopy(),
+		evaluatorBase: eval.evaluatorBase,
+	}
+}
+
+// WithKey creates a shallow copy of the receiver ManagedEvaluator for which the new EvaluationKey is evaluationKey
+// and where the temporary buffers are shared. The receiver and the returned ManagedEvaluators cannot be used concurrently.
+func (eval *ManagedEvaluator) WithKey(evaluationKey rlwe.EvaluationKey) *ManagedEvaluator {
+	return &ManagedEvaluator{
+		evaluator:     eval.evaluator.WithKey(evaluationKey),
+		evaluatorBase: eval.evaluatorBase,
+	}
+}
+
+// Add adds op1 to ctIn and returns the result in ctOut, after matching the levels and scales of the operands.
+func (eval *ManagedEvaluator) Add(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	if ctIn, op1, err = eval.matchScales(ctIn, op1); err != nil {
+		return fmt.Errorf("cannot Add: %w", err)
+	}
+	eval.evaluator.Add(ctIn, op1, ctOut)
+	return
+}
+
+// AddNew adds op1 to ctIn and returns the result in a newly created element, after matching the levels and scales of the operands.
+func (eval *ManagedEvaluator) AddNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext, err error) {
+	if ctIn, op1, err = eval.matchScales(ctIn, op1); err != nil {
+		return nil, fmt.Errorf("cannot AddNew: %w", err)
+	}
+	return eval.evaluator.AddNew(ctIn, op1), nil
+}
+
+// Sub subtracts op1 from ctIn and returns the result in ctOut, after matching the levels and scales of the operands.
+func (eval *ManagedEvaluator) Sub(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	if ctIn, op1, err = eval.matchScales(ctIn, op1); err != nil {
+		return fmt.Errorf("cannot Sub: %w", err)
+	}
+	eval.evaluator.Sub(ctIn, op1, ctOut)
+	return
+}
+
+// SubNew subtracts op1 from ctIn and returns the result in a newly created element, after matching the levels and scales of the operands.
+func (eval *ManagedEvaluator) SubNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext, err error) {
+	if ctIn, op1, err = eval.matchScales(ctIn, op1); err != nil {
+		return nil, fmt.Errorf("cannot SubNew: %w", err)
+	}
+	return eval.evaluator.SubNew(ctIn, op1), nil
+}
+
+// Mul multiplies ctIn with op1, relinearizes the result and returns it in ctOut.
+// The ciphertext operands are rescaled beforehand if their scale can be brought closer to the default scale,
+// and the result is not rescaled.
+// Returns an error if the scale of the result would exceed the modulus at the level of the operands.
+func (eval *ManagedEvaluator) Mul(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	if ctIn, op1, err = eval.prepareMul(ctIn, op1); err != nil {
+		return fmt.Errorf("cannot Mul: %w", err)
+	}
+	eval.evaluator.MulRelin(ctIn, op1, ctOut)
+	return
+}
+
+// MulNew multiplies ctIn with op1, relinearizes the result and returns it in a newly created element.
+// The ciphertext operands are rescaled beforehand if their scale can be brought closer to the default scale,
+// and the result is not rescaled.
+// Returns an error if the scale of the result would exceed the modulus at the level of the operands.
+func (eval *ManagedEvaluator) MulNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext, err error) {
+	if ctIn, op1, err = eval.prepareMul(ctIn, op1); err != nil {
+		return nil, fmt.Errorf("cannot MulNew: %w", err)
+	}
+	return eval.evaluator.MulRelinNew(ctIn, op1), nil
+}
+
+// MultByConst multiplies ctIn by the constant and returns the result in ctOut.
+// The ciphertext is rescaled beforehand if its scale can be brought closer to the default scale,
+// and the result is not rescaled.
+// Returns an error if the scale of the result would exceed the modulus at the level of the operand.
+func (eval *ManagedEvaluator) MultByConst(ctIn *Ciphertext, constant interface{}, ctOut *Ciphertext) (err error) {
+	if ctIn, err = eval.prepareMultByConst(ctIn, constant); err != nil {
+		return fmt.Errorf("cannot MultByConst: %w", err)
+	}
+	eval.evaluator.MultByConst(ctIn, constant, ctOut)
+	return
+}
+
+// MultByConstNew multiplies ctIn by the constant and returns the result in a newly created element.
+// The ciphertext is rescaled beforehand if its scale can be brought closer to the default scale,
+// and the result is not rescaled.
+// Returns an error if the scale of the result would exceed the modulus at the level of the operand.
+func (eval *ManagedEvaluator) MultByConstNew(ctIn *Ciphertext, constant interface{}) (ctOut *Ciphertext, err error) {
+	if ctIn, err = eval.prepareMultByConst(ctIn, constant); err != nil {
+		return nil, fmt.Errorf("cannot MultByConstNew: %w", err)
+	}
+	return eval.evaluator.MultByConstNew(ctIn, constant), nil
+}
+
+// Rotate rotates the slots of ctIn by k positions to the left and returns the result in ctOut.
+// The level and the scale of the ciphertext are unchanged.
+func (eval *ManagedEvaluator) Rotate(ctIn *Ciphertext, k int, ctOut *Ciphertext) {
+	eval.evaluator.Rotate(ctIn, k, ctOut)
+}
+
+// RotateNew rotates the slots of ctIn by k positions to the left and returns the result in a newly created element.
+// The level and the scale of the ciphertext are unchanged.
+func (eval *ManagedEvaluator) RotateNew(ctIn *Ciphertext, k int) (ctOut *Ciphertext) {
+	return eval.evaluator.RotateNew(ctIn, k)
+}
+
+// RescaleNew rescales ctIn as long as its scale can be brought closer to the default scale and returns the
+// result in a newly created element. This enables users to settle the pending rescalings, for example before a
+// rotation or before the ciphertext is sent.
+func (eval *ManagedEvaluator) RescaleNew(ctIn *Ciphertext) (ctOut *Ciphertext, err error) {
+	ctOut = NewCiphertext(eval.params, ctIn.Degree(), ctIn.Level(), ctIn.Scale)
+	if ctIn.Level() == 0 {
+		ctOut.Copy(ctIn)
+		return
+	}
+	return ctOut, eval.evaluator.Rescale(ctIn, eval.params.DefaultScale(), ctOut)
+}
+
+// prepareMul settles the pending rescalings of the operands and checks that their product can be
+// carried out at their level.
+func (eval *ManagedEvaluator) prepareMul(ctIn *Ciphertext, op1 Operand) (op0 *Ciphertext, op1Out Operand, err error) {
+
+	if op0, err = eval.rescaleLazy(ctIn); err != nil {
+		return
+	}
+
+	op1Out = op1
+	if ct, isCt := op1.(*Ciphertext); isCt {
+		if op1Out, err = eval.rescaleLazy(ct); err != nil {
+			return
+		}
+	}
+
+	level := utils.MinInt(op0.Level(), op1Out.Level())
+
+	return op0, op1Out, eval.checkScale(op0.Scale*op1Out.ScalingFactor(), level)
+}
+
+// prepareMultByConst settles the pending rescalings of ctIn and checks that its product with the constant
+// can be carried out at its level.
+func (eval *ManagedEvaluator) prepareMultByConst(ctIn *Ciphertext, constant interface{}) (ctOut *Ciphertext, err error) {
+
+	if ctOut, err = eval.rescaleLazy(ctIn); err != nil {
+		return
+	}
+
+	_, _, scale := eval.getConstAndScale(ctOut.Level(), constant)
+
+	return ctOut, eval.checkScale(ctOut.Scale*scale, ctOut.Level())
+}
+
+// rescaleLazy returns ctIn rescaled as long as its scale can be brought closer to the default scale.
+// The input ciphertext is not modified.
+func (eval *ManagedEvaluator) rescaleLazy(ctIn *Ciphertext) (ctOut *Ciphertext, err error) {
+
+	level := ctIn.Level()
+
+	if level == 0 || ctIn.Scale/eval.params.QiFloat64(level) < eval.params.DefaultScale()/2 {
+		return ctIn, nil
+	}
+
+	ctOut = NewCiphertext(eval.params, ctIn.Degree(), level, ctIn.Scale)
+
+	return ctOut, eval.evaluator.Rescale(ctIn, eval.params.DefaultScale(), ctOut)
+}
+
+// matchScales returns the operands with exactly the same scale. The input operands are not modified.
+// If op1 is a Plaintext, ctIn is brought to the scale of op1. Else, if the ratio between the scales is an
+// integer, the operand with the smallest scale is brought to the scale of the other without consuming a level.
+// Otherwise, the operand at the highest level (or with the smallest scale if both are at the same level) is
+// brought to the scale of the other, so that the level consumed is, if possible, one that would have been
+// dropped anyway.
+func (eval *ManagedEvaluator) matchScales(ctIn *Ciphertext, op1 Operand) (op0 *Ciphertext, op1Out Operand, err error) {
+
+	op0, op1Out = ctIn, op1
+
+	if ctIn.Scale == op1.ScalingFactor() {
+		return
+	}
+
+	ct1, isCt := op1.(*Ciphertext)
+
+	if !isCt {
+		op0, err = eval.setScale(ctIn, op1.ScalingFactor())
+		return
+	}
+
+	small, big := ctIn, ct1
+	if small.Scale > big.Scale {
+		small, big = big, small
+	}
+
+	target := small
+	if ratio := big.Scale / small.Scale; ratio != math.Trunc(ratio) && big.Level() > small.Level() {
+		target = big
+	}
+
+	if target == ctIn {
+		op0, err = eval.setScale(ctIn, ct1.Scale)
+	} else {
+		op1Out, err = eval.setScale(ct1, ctIn.Scale)
+	}
+
+	return
+}
+
+// setScale returns ctIn at exactly the given scale, without modifying the encrypted values.
+// If the ratio between the scales is an integer, ctIn is multiplied by this integer, else it is
+// multiplied by the ratio encoded at the scale of the last modulus and rescaled, consuming one level.
+func (eval *ManagedEvaluator) setScale(ctIn *Ciphertext, scale float64) (ctOut *Ciphertext, err error) {
+
+	ratio := scale / ctIn.Scale
+
+	if ratio >= 1<<63 {
+		return nil, fmt.Errorf("cannot match scales 2^%.2f and 2^%.2f: ratio is too large", math.Log2(ctIn.Scale), math.Log2(scale))
+	}
+
+	if ratio >= 1 && ratio == math.Trunc(ratio) {
+		ctOut = eval.evaluator.MultByConstNew(ctIn, uint64(ratio))
+		ctOut.Scale = scale
+		return
+	}
+
+	if ctIn.Level() == 0 {
+		return nil, fmt.Errorf("cannot match scales 2^%.2f and 2^%.2f: ciphertext is at level 0", math.Log2(ctIn.Scale), math.Log2(scale))
+	}
+
+	// The values are multiplied by ratio and the scale is left unchanged after the rescaling,
+	// which is equivalent to setting the scale to ctIn.Scale * ratio.
+	ctOut = eval.evaluator.MultByConstNew(ctIn, ratio)
+
+	if err = eval.evaluator.Rescale(ctOut, ctIn.Scale, ctOut); err != nil {
+		return nil, err
+	}
+
+	ctOut.Scale = scale
+
+	return
+}
+
+// checkScale returns an error if a ciphertext at the given scale and level leaves less than one bit to the message.
+func (eval *ManagedEvaluator) checkScale(scale float64, level int) (err error) {
+
+	var logQ float64
+	for i := 0; i < level+1; i++ {
+		logQ += math.Log2(eval.params.QiFloat64(i))
+	}
+
+	if logScale := math.Log2(scale); logScale+1 > logQ {
+		return fmt.Errorf("scale 2^%.2f exceeds the modulus 2^%.2f at level %d", logScale, logQ, level)
+	}
+
+	return
+}
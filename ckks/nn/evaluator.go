@@ -0,0 +1,93 @@
+package nn
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ckks/conv"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Bootstrapper is an interface for the bootstrapping operation used by the Evaluator.
+// It is implemented by *bootstrapping.Bootstrapper.
+type Bootstrapper interface {
+	Bootstrapp(ctIn *ckks.Ciphertext) (ctOut *ckks.Ciphertext)
+}
+
+// Evaluator is a struct to run encrypted inference on compiled Networks.
+type Evaluator struct {
+	*conv.Evaluator
+	params       ckks.Parameters
+	bootstrapper Bootstrapper
+}
+
+// NewEvaluator creates a new Evaluator from the given parameters and evaluation keys.
+// The evaluation keys must contain the relinearization key and the rotation keys returned by Network.Rotations.
+// The bootstrapper can be nil if the evaluated Networks do not require bootstrapping.
+func NewEvaluator(params ckks.Parameters, evaluationKey rlwe.EvaluationKey, bootstrapper Bootstrapper) *Evaluator {
+	return &Evaluator{
+		Evaluator:    conv.NewEvaluator(params, evaluationKey),
+		params:       params,
+		bootstrapper: bootstrapper,
+	}
+}
+
+// InferNew evaluates the Network on ctIn, which must encrypt the input tensor packed according to net.Input,
+// and returns the result on a new ciphertext encrypting the output tensor packed according to net.Output.
+// Returns an error if ctIn is below the input level of the Network, if the schedule requires bootstrapping
+// but the Evaluator has no Bootstrapper, or if a step fails.
+func (eval *Evaluator) InferNew(net *Network, ctIn *ckks.Ciphertext) (ctOut *ckks.Ciphertext, err error) {
+
+	if ctIn.Level() < net.InputLevel {
+		return nil, fmt.Errorf("cannot InferNew: input ciphertext is at level %d but the network expects level %d", ctIn.Level(), net.InputLevel)
+	}
+
+	ctOut = ctIn
+
+	for i, step := range net.Steps {
+
+		switch step.Type {
+		case LinearStep:
+
+			if ctOut, err = eval.EvaluateNew(ctOut, step.Encoded); err != nil {
+				return nil, fmt.Errorf("cannot InferNew: step %d: %w", i, err)
+			}
+
+		case ActivationStep:
+
+			if ctOut, err = eval.evaluateActivation(ctOut, step.Polynomial); err != nil {
+				return nil, fmt.Errorf("cannot InferNew: step %d: %w", i, err)
+			}
+
+		case BootstrappingStep:
+
+			if eval.bootstrapper == nil {
+				return nil, fmt.Errorf("cannot InferNew: step %d: network requires bootstrapping but no Bootstrapper was provided", i)
+			}
+
+			ctOut = eval.bootstrapper.Bootstrapp(ctOut)
+		}
+	}
+
+	return
+}
+
+// evaluateActivation evaluates pol on ctIn, applying beforehand the change of variable if pol is in the Chebyshev basis.
+func (eval *Evaluator) evaluateActivation(ctIn *ckks.Ciphertext, pol *ckks.Polynomial) (ctOut *ckks.Ciphertext, err error) {
+
+	scale := ctIn.Scale
+
+	ctOut = ctIn
+
+	if pol.BasisType == ckks.Chebyshev {
+
+		ctOut = eval.MultByConstNew(ctIn, 2/(pol.B-pol.A))
+		eval.AddConst(ctOut, (-pol.A-pol.B)/(pol.B-pol.A), ctOut)
+
+		if err = eval.Rescale(ctOut, scale, ctOut); err != nil {
+			return nil, err
+		}
+	}
+
+	return eval.EvaluatePoly(ctOut, pol, scale)
+}
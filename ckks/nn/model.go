@@ -0,0 +1,207 @@
+// Package nn implements encrypted neural network inference for the CKKS scheme.
+// A model is described with a ModelLiteral (that can be read from JSON), compiled into a level-aware
+// Network for a given set of parameters, and evaluated on ciphertexts with an Evaluator which inserts
+// bootstrappings where the schedule requires it.
+package nn
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+)
+
+// LayerType is a type for the layers of a model.
+type LayerType string
+
+// The different types of layers supported by a model.
+const (
+	Conv2D     = LayerType("conv2d")
+	Dense      = LayerType("dense")
+	BatchNorm  = LayerType("batchnorm")
+	Activation = LayerType("activation")
+	AvgPool2D  = LayerType("avgpool2d")
+	Flatten    = LayerType("flatten")
+)
+
+// ActivationType is a type for the activation functions.
+type ActivationType string
+
+// The different activation functions.
+// Poly is an arbitrary polynomial given by its coefficients in the monomial basis,
+// the other functions are approximated by a Chebyshev interpolant over the given interval.
+const (
+	ReLU    = ActivationType("relu")
+	Sigmoid = ActivationType("sigmoid")
+	GELU    = ActivationType("gelu")
+	Poly    = ActivationType("poly")
+)
+
+// InputLiteral describes the shape of the input of a model.
+type InputLiteral struct {
+	Channels int `json:"channels"`
+	Height   int `json:"height"`
+	Width    int `json:"width"`
+}
+
+// LayerLiteral is a user-friendly struct to describe a layer of a model.
+// Only the fields relevant to the Type of the layer are read:
+//
+//	conv2d:     Kernel[out][in][row][col], Bias, Stride (default 1), Padding
+//	dense:      Weights[out][in], Bias
+//	batchnorm:  Gamma, Beta, Mean, Variance, Epsilon (folded into the preceding conv2d or dense layer)
+//	activation: Function, Interval, Degree (for relu, sigmoid and gelu) or Coeffs (for poly)
+//	avgpool2d:  KernelSize, Stride (default KernelSize)
+//	flatten:    -
+type LayerLiteral struct {
+	Type LayerType `json:"type"`
+
+	Kernel  [][][][]float64 `json:"kernel,omitempty"`
+	Weights [][]float64     `json:"weights,omitempty"`
+	Bias    []float64       `json:"bias,omitempty"`
+	Stride  int             `json:"stride,omitempty"`
+	Padding int             `json:"padding,omitempty"`
+
+	Gamma    []float64 `json:"gamma,omitempty"`
+	Beta     []float64 `json:"beta,omitempty"`
+	Mean     []float64 `json:"mean,omitempty"`
+	Variance []float64 `json:"variance,omitempty"`
+	Epsilon  float64   `json:"epsilon,omitempty"`
+
+	Function ActivationType `json:"function,omitempty"`
+	Interval [2]float64     `json:"interval,omitempty"`
+	Degree   int            `json:"degree,omitempty"`
+	Coeffs   []float64      `json:"coeffs,omitempty"`
+
+	KernelSize int `json:"kernel_size,omitempty"`
+}
+
+// ModelLiteral is a user-friendly struct to describe a sequential model.
+type ModelLiteral struct {
+	Input  InputLiteral   `json:"input"`
+	Layers []LayerLiteral `json:"layers"`
+}
+
+// UnmarshalModel decodes a ModelLiteral from its JSON representation.
+func UnmarshalModel(data []byte) (model ModelLiteral, err error) {
+	if err = json.Unmarshal(data, &model); err != nil {
+		return model, fmt.Errorf("cannot UnmarshalModel: %w", err)
+	}
+	return
+}
+
+// foldBatchNorm folds the batch normalization bn into the linear layer l, which must be a conv2d or a dense layer.
+func foldBatchNorm(l LayerLiteral, bn LayerLiteral) (LayerLiteral, error) {
+
+	var n int
+	switch l.Type {
+	case Conv2D:
+		n = len(l.Kernel)
+	case Dense:
+		n = len(l.Weights)
+	default:
+		return l, fmt.Errorf("cannot fold batchnorm: must follow a conv2d or dense layer but follows %s", l.Type)
+	}
+
+	if len(bn.Gamma) != n || len(bn.Beta) != n || len(bn.Mean) != n || len(bn.Variance) != n {
+		return l, fmt.Errorf("cannot fold batchnorm: expected %d values per statistic", n)
+	}
+
+	folded := l
+
+	folded.Bias = make([]float64, n)
+	if l.Bias != nil {
+		copy(folded.Bias, l.Bias)
+	}
+
+	switch l.Type {
+	case Conv2D:
+		folded.Kernel = make([][][][]float64, n)
+	case Dense:
+		folded.Weights = make([][]float64, n)
+	}
+
+	for k := 0; k < n; k++ {
+
+		a := bn.Gamma[k] / math.Sqrt(bn.Variance[k]+bn.Epsilon)
+
+		switch l.Type {
+		case Conv2D:
+			folded.Kernel[k] = make([][][]float64, len(l.Kernel[k]))
+			for ci := range l.Kernel[k] {
+				folded.Kernel[k][ci] = make([][]float64, len(l.Kernel[k][ci]))
+				for i := range l.Kernel[k][ci] {
+					folded.Kernel[k][ci][i] = make([]float64, len(l.Kernel[k][ci][i]))
+					for j := range l.Kernel[k][ci][i] {
+						folded.Kernel[k][ci][i][j] = a * l.Kernel[k][ci][i][j]
+					}
+				}
+			}
+		case Dense:
+			folded.Weights[k] = make([]float64, len(l.Weights[k]))
+			for i := range l.Weights[k] {
+				folded.Weights[k][i] = a * l.Weights[k][i]
+			}
+		}
+
+		folded.Bias[k] = a*(folded.Bias[k]-bn.Mean[k]) + bn.Beta[k]
+	}
+
+	return folded, nil
+}
+
+// activationFunction returns the function evaluated by the activation layer.
+func activationFunction(l LayerLiteral) (f func(float64) float64, err error) {
+	switch l.Function {
+	case ReLU:
+		return func(x float64) float64 { return math.Max(x, 0) }, nil
+	case Sigmoid:
+		return func(x float64) float64 { return 1 / (1 + math.Exp(-x)) }, nil
+	case GELU:
+		return func(x float64) float64 { return 0.5 * x * (1 + math.Erf(x/math.Sqrt2)) }, nil
+	case Poly:
+		coeffs := l.Coeffs
+		return func(x float64) (y float64) {
+			for i := len(coeffs) - 1; i >= 0; i-- {
+				y = y*x + coeffs[i]
+			}
+			return
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown activation function %q", l.Function)
+	}
+}
+
+// activationPolynomial returns the polynomial evaluated homomorphically by the activation layer.
+func activationPolynomial(l LayerLiteral) (pol *ckks.Polynomial, err error) {
+
+	if l.Function == Poly {
+
+		if len(l.Coeffs) == 0 {
+			return nil, fmt.Errorf("invalid activation: poly requires at least one coefficient")
+		}
+
+		coeffs := make([]complex128, len(l.Coeffs))
+		for i := range coeffs {
+			coeffs[i] = complex(l.Coeffs[i], 0)
+		}
+
+		return ckks.NewPoly(coeffs), nil
+	}
+
+	f, err := activationFunction(l)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.Degree < 1 {
+		return nil, fmt.Errorf("invalid activation: %s requires a degree of at least one", l.Function)
+	}
+
+	if l.Interval[0] >= l.Interval[1] {
+		return nil, fmt.Errorf("invalid activation: interval [%f, %f] is empty", l.Interval[0], l.Interval[1])
+	}
+
+	return ckks.Approximate(f, l.Interval[0], l.Interval[1], l.Degree), nil
+}
@@ -0,0 +1,267 @@
+package nn
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ckks/conv"
+)
+
+// StepType is a type for the steps of a compiled Network.
+type StepType int
+
+// The different steps of a compiled Network.
+const (
+	LinearStep = StepType(iota)
+	ActivationStep
+	BootstrappingStep
+)
+
+// String returns the name of the step type.
+func (t StepType) String() string {
+	switch t {
+	case LinearStep:
+		return "linear"
+	case ActivationStep:
+		return "activation"
+	case BootstrappingStep:
+		return "bootstrapping"
+	default:
+		return "unknown"
+	}
+}
+
+// Step is a single operation of a compiled Network.
+// LevelIn is the minimum level at which the input ciphertext is expected and LevelOut the level of the output ciphertext.
+type Step struct {
+	Type     StepType
+	LevelIn  int
+	LevelOut int
+
+	// LinearStep
+	Layer   *conv.Layer
+	Encoded *conv.EncodedLayer
+
+	// ActivationStep
+	Polynomial *ckks.Polynomial
+	Function   func(float64) float64
+}
+
+// Config is a struct storing the compilation settings of a Network.
+type Config struct {
+	InputLevel         int     // Level of the input ciphertext.
+	Bootstrapping      bool    // If true, bootstrappings are inserted when the remaining levels are insufficient.
+	BootstrappingLevel int     // Level of the ciphertext after bootstrapping (see bootstrapping.Parameters.OutputLevel).
+	BSGSRatio          float64 // BSGS ratio used to evaluate the linear layers (0 for the naive approach).
+}
+
+// Network is a model compiled into a level-aware schedule of homomorphic operations.
+type Network struct {
+	params ckks.Parameters
+	Config
+	Input  conv.Layout
+	Output conv.Layout
+	Steps  []Step
+}
+
+// Compile compiles the model into a Network for the given parameters.
+// Batch normalization layers are folded into the preceding linear layer, linear layers are encoded at the level
+// at which they are scheduled, and bootstrappings are inserted (if enabled) when the remaining levels are not
+// sufficient to evaluate the next layer.
+// Returns an error if the model is malformed or if it cannot be scheduled within the available levels.
+func Compile(model ModelLiteral, params ckks.Parameters, config Config) (net *Network, err error) {
+
+	if config.InputLevel > params.MaxLevel() || config.InputLevel < 0 {
+		return nil, fmt.Errorf("cannot Compile: invalid input level %d", config.InputLevel)
+	}
+
+	if config.Bootstrapping && (config.BootstrappingLevel > params.MaxLevel() || config.BootstrappingLevel < 1) {
+		return nil, fmt.Errorf("cannot Compile: invalid bootstrapping level %d", config.BootstrappingLevel)
+	}
+
+	var layers []LayerLiteral
+	for i, l := range model.Layers {
+		if l.Type == BatchNorm {
+			if len(layers) == 0 {
+				return nil, fmt.Errorf("cannot Compile: layer %d: batchnorm cannot be the first layer", i)
+			}
+			if layers[len(layers)-1], err = foldBatchNorm(layers[len(layers)-1], l); err != nil {
+				return nil, fmt.Errorf("cannot Compile: layer %d: %w", i, err)
+			}
+		} else {
+			layers = append(layers, l)
+		}
+	}
+
+	encoder := ckks.NewEncoder(params)
+	slots := params.Slots()
+
+	net = &Network{params: params, Config: config}
+	net.Input = conv.NewLayout(model.Input.Channels, model.Input.Height, model.Input.Width)
+
+	if err = net.Input.Check(slots); err != nil {
+		return nil, fmt.Errorf("cannot Compile: input: %w", err)
+	}
+
+	layout := net.Input
+	level := config.InputLevel
+
+	for i, l := range layers {
+
+		var step Step
+		var depth int
+
+		switch l.Type {
+		case Conv2D, Dense, AvgPool2D, Flatten:
+
+			if step.Layer, err = newLinearLayer(l, layout, slots); err != nil {
+				return nil, fmt.Errorf("cannot Compile: layer %d: %w", i, err)
+			}
+
+			step.Type = LinearStep
+			depth = 1
+			layout = step.Layer.Out
+
+		case Activation:
+
+			if step.Polynomial, err = activationPolynomial(l); err != nil {
+				return nil, fmt.Errorf("cannot Compile: layer %d: %w", i, err)
+			}
+
+			if step.Function, err = activationFunction(l); err != nil {
+				return nil, fmt.Errorf("cannot Compile: layer %d: %w", i, err)
+			}
+
+			step.Type = ActivationStep
+			depth = activationDepth(step.Polynomial)
+
+		default:
+			return nil, fmt.Errorf("cannot Compile: layer %d: unknown layer type %q", i, l.Type)
+		}
+
+		if level < depth {
+
+			if !config.Bootstrapping {
+				return nil, fmt.Errorf("cannot Compile: layer %d: %s requires %d levels but only %d remain and bootstrapping is disabled", i, l.Type, depth, level)
+			}
+
+			if config.BootstrappingLevel < depth {
+				return nil, fmt.Errorf("cannot Compile: layer %d: %s requires %d levels but bootstrapping only provides %d", i, l.Type, depth, config.BootstrappingLevel)
+			}
+
+			net.Steps = append(net.Steps, Step{Type: BootstrappingStep, LevelIn: level, LevelOut: config.BootstrappingLevel})
+			level = config.BootstrappingLevel
+		}
+
+		step.LevelIn = level
+		step.LevelOut = level - depth
+
+		if step.Type == LinearStep {
+			step.Encoded = step.Layer.Encode(params, encoder, level, config.BSGSRatio)
+		}
+
+		net.Steps = append(net.Steps, step)
+		level -= depth
+	}
+
+	net.Output = layout
+
+	return
+}
+
+func newLinearLayer(l LayerLiteral, in conv.Layout, slots int) (*conv.Layer, error) {
+	switch l.Type {
+	case Conv2D:
+
+		if len(l.Kernel) == 0 || len(l.Kernel[0]) == 0 || len(l.Kernel[0][0]) == 0 {
+			return nil, fmt.Errorf("conv2d: kernel cannot be empty")
+		}
+
+		stride := l.Stride
+		if stride == 0 {
+			stride = 1
+		}
+
+		return conv.NewConv2D(conv.Conv2DLiteral{
+			InChannels:  len(l.Kernel[0]),
+			OutChannels: len(l.Kernel),
+			KernelSize:  len(l.Kernel[0][0]),
+			Stride:      stride,
+			Padding:     l.Padding,
+			Weights:     l.Kernel,
+			Bias:        l.Bias,
+		}, in, slots)
+
+	case Dense:
+		return conv.NewDense(l.Weights, l.Bias, in, slots)
+
+	case AvgPool2D:
+
+		stride := l.Stride
+		if stride == 0 {
+			stride = l.KernelSize
+		}
+
+		return conv.NewAvgPool2D(in, l.KernelSize, stride, slots)
+
+	case Flatten:
+		return conv.NewFlatten(in, slots)
+	}
+
+	return nil, fmt.Errorf("%s is not a linear layer", l.Type)
+}
+
+// activationDepth returns the number of levels consumed by the evaluation of the activation.
+// Polynomials in the Chebyshev basis require one additional level for the change of variable.
+func activationDepth(pol *ckks.Polynomial) int {
+	if pol.BasisType == ckks.Chebyshev {
+		return pol.Depth() + 1
+	}
+	return pol.Depth()
+}
+
+// Rotations returns the list of rotations needed to evaluate the Network (excluding the bootstrapping).
+func (net *Network) Rotations() (rotations []int) {
+	var layers []*conv.Layer
+	for _, step := range net.Steps {
+		if step.Type == LinearStep {
+			layers = append(layers, step.Layer)
+		}
+	}
+	return conv.RotationsForLayers(net.params, layers, net.BSGSRatio)
+}
+
+// Bootstrappings returns the number of bootstrappings in the schedule of the Network.
+func (net *Network) Bootstrappings() (n int) {
+	for _, step := range net.Steps {
+		if step.Type == BootstrappingStep {
+			n++
+		}
+	}
+	return
+}
+
+// EvaluatePlaintext evaluates the Network in the clear on the input tensor and returns the output tensor.
+// If exact is true, the activations are evaluated with the exact functions, else with the same polynomials
+// as the homomorphic evaluation, so that the approximation error and the homomorphic error can be measured separately.
+func (net *Network) EvaluatePlaintext(input [][][]float64, exact bool) (output [][][]float64) {
+
+	values := net.Input.Encode(input, net.params.Slots())
+
+	for _, step := range net.Steps {
+		switch step.Type {
+		case LinearStep:
+			values = step.Layer.Evaluate(values)
+		case ActivationStep:
+			for i := range values {
+				if exact {
+					values[i] = step.Function(values[i])
+				} else {
+					values[i] = real(step.Polynomial.Evaluate(complex(values[i], 0)))
+				}
+			}
+		}
+	}
+
+	return net.Output.Decode(values)
+}
@@ -0,0 +1,232 @@
+package nn
+
+import (
+	"encoding/json"
+	"flag"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ckks/bootstrapping"
+	"github.com/cipherflow-fhe/lattigo/ckks/conv"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var printPrecisionStats = flag.Bool("print-precision", false, "print precision stats")
+
+var minPrec float64 = 15
+
+var _ Bootstrapper = (*bootstrapping.Bootstrapper)(nil)
+
+// testBootstrapper is a mock bootstrapper that decrypts and re-encrypts the ciphertext at a given level.
+type testBootstrapper struct {
+	params    ckks.Parameters
+	encoder   ckks.Encoder
+	encryptor ckks.Encryptor
+	decryptor ckks.Decryptor
+	level     int
+	calls     int
+}
+
+func (btp *testBootstrapper) Bootstrapp(ctIn *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+	btp.calls++
+	values := btp.encoder.Decode(btp.decryptor.DecryptNew(ctIn), btp.params.LogSlots())
+	return btp.encryptor.EncryptNew(btp.encoder.EncodeNew(values, btp.level, btp.params.DefaultScale(), btp.params.LogSlots()))
+}
+
+func randomSlice(n int, a, b float64) (v []float64) {
+	v = make([]float64, n)
+	for i := range v {
+		v[i] = utils.RandFloat64(a, b)
+	}
+	return
+}
+
+func randomMatrix(rows, cols int, a, b float64) (m [][]float64) {
+	m = make([][]float64, rows)
+	for i := range m {
+		m[i] = randomSlice(cols, a, b)
+	}
+	return
+}
+
+func testModel() ModelLiteral {
+
+	kernel := make([][][][]float64, 2)
+	for i := range kernel {
+		kernel[i] = [][][]float64{randomMatrix(3, 3, -0.3, 0.3)}
+	}
+
+	return ModelLiteral{
+		Input: InputLiteral{Channels: 1, Height: 8, Width: 8},
+		Layers: []LayerLiteral{
+			{Type: Conv2D, Kernel: kernel, Bias: randomSlice(2, -0.1, 0.1), Padding: 1},
+			{Type: BatchNorm, Gamma: []float64{1.1, 0.9}, Beta: []float64{0.1, -0.1}, Mean: []float64{0.05, -0.05}, Variance: []float64{1.2, 0.8}, Epsilon: 1e-5},
+			{Type: Activation, Function: Poly, Coeffs: []float64{0, 0.5, 0.25}},
+			{Type: AvgPool2D, KernelSize: 2},
+			{Type: Activation, Function: GELU, Interval: [2]float64{-4, 4}, Degree: 15},
+			{Type: Flatten},
+			{Type: Dense, Weights: randomMatrix(4, 32, -0.2, 0.2), Bias: randomSlice(4, -0.1, 0.1)},
+			{Type: Activation, Function: Sigmoid, Interval: [2]float64{-4, 4}, Degree: 7},
+		},
+	}
+}
+
+func TestNN(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping nn tests for GOARCH=wasm")
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:         12,
+		LogQ:         []int{55, 40, 40, 40, 40, 40, 40},
+		LogP:         []int{61, 61},
+		LogSlots:     11,
+		DefaultScale: 1 << 40,
+	})
+	require.NoError(t, err)
+
+	model := testModel()
+
+	t.Run("Model/JSON", func(t *testing.T) {
+		data, err := json.Marshal(model)
+		require.NoError(t, err)
+		modelNew, err := UnmarshalModel(data)
+		require.NoError(t, err)
+		require.Equal(t, model, modelNew)
+	})
+
+	t.Run("Model/BatchNorm", func(t *testing.T) {
+		dense := LayerLiteral{Type: Dense, Weights: [][]float64{{2, 4}}, Bias: []float64{1}}
+		bn := LayerLiteral{Type: BatchNorm, Gamma: []float64{3}, Beta: []float64{1}, Mean: []float64{0.5}, Variance: []float64{4}}
+		folded, err := foldBatchNorm(dense, bn)
+		require.NoError(t, err)
+		require.Equal(t, [][]float64{{3, 6}}, folded.Weights)
+		require.Equal(t, []float64{1.75}, folded.Bias)
+
+		_, err = foldBatchNorm(LayerLiteral{Type: Flatten}, bn)
+		require.Error(t, err)
+	})
+
+	t.Run("Compile/NoBootstrapping", func(t *testing.T) {
+		_, err := Compile(model, params, Config{InputLevel: params.MaxLevel()})
+		require.Error(t, err)
+	})
+
+	net, err := Compile(model, params, Config{InputLevel: params.MaxLevel(), Bootstrapping: true, BootstrappingLevel: params.MaxLevel()})
+	require.NoError(t, err)
+
+	t.Run("Compile/Schedule", func(t *testing.T) {
+		require.Equal(t, conv.Layout{Channels: 1, Height: 1, Width: 4, Gap: 1}, net.Output)
+		require.Greater(t, net.Bootstrappings(), 0)
+
+		level := params.MaxLevel()
+		for _, step := range net.Steps {
+			require.Equal(t, level, step.LevelIn)
+			require.GreaterOrEqual(t, step.LevelOut, 0)
+			level = step.LevelOut
+		}
+	})
+
+	t.Run("Inference", func(t *testing.T) {
+
+		kgen := ckks.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		encoder := ckks.NewEncoder(params)
+		encryptor := ckks.NewEncryptor(params, sk)
+		decryptor := ckks.NewDecryptor(params, sk)
+
+		btp := &testBootstrapper{params: params, encoder: encoder, encryptor: encryptor, decryptor: decryptor, level: params.MaxLevel()}
+
+		eval := NewEvaluator(params, rlwe.EvaluationKey{
+			Rlk:  kgen.GenRelinearizationKey(sk, 1),
+			Rtks: kgen.GenRotationKeysForRotations(net.Rotations(), false, sk),
+		}, btp)
+
+		input := [][][]float64{randomMatrix(8, 8, -1, 1)}
+
+		ct := encryptor.EncryptNew(encoder.EncodeNew(net.Input.Encode(input, params.Slots()), params.MaxLevel(), params.DefaultScale(), params.LogSlots()))
+
+		ct, err := eval.InferNew(net, ct)
+		require.NoError(t, err)
+		require.Equal(t, net.Bootstrappings(), btp.calls)
+
+		have := net.Output.Decode(encoder.Decode(decryptor.DecryptNew(ct), params.LogSlots()))
+		want := net.EvaluatePlaintext(input, false)
+		exact := net.EvaluatePlaintext(input, true)
+
+		precStats := ckks.GetPrecisionStats(params, encoder, nil, conv.Flatten(want), conv.Flatten(have), 2, 0)
+
+		if *printPrecisionStats {
+			t.Log(precStats.String())
+		}
+
+		require.GreaterOrEqual(t, precStats.MeanPrecision.Real, minPrec)
+
+		for i := range exact[0][0] {
+			require.InDelta(t, exact[0][0][i], want[0][0][i], 1e-2)
+		}
+
+		_, err = NewEvaluator(params, rlwe.EvaluationKey{}, nil).InferNew(net, ct)
+		require.Error(t, err)
+	})
+}
+
+func TestNNBootstrapper(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping bootstrapping tests for GOARCH=wasm")
+	}
+
+	paramSet := bootstrapping.DefaultParametersSparse[0]
+	ckksParams := paramSet.SchemeParams
+	btpParams := paramSet.BootstrappingParams
+
+	// Insecure params for fast testing only
+	ckksParams.LogN = 13
+	ckksParams.LogSlots = 12
+
+	params, err := ckks.NewParametersFromLiteral(ckksParams)
+	require.NoError(t, err)
+
+	// The input is encrypted at the output level of the bootstrapping, so that the
+	// network has to bootstrap at least once.
+	net, err := Compile(testModel(), params, Config{InputLevel: btpParams.OutputLevel(), Bootstrapping: true, BootstrappingLevel: btpParams.OutputLevel()})
+	require.NoError(t, err)
+	require.Greater(t, net.Bootstrappings(), 0)
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	encoder := ckks.NewEncoder(params)
+	encryptor := ckks.NewEncryptor(params, sk)
+	decryptor := ckks.NewDecryptor(params, sk)
+
+	btp, err := bootstrapping.NewBootstrapper(params, btpParams, bootstrapping.GenEvaluationKeys(btpParams, params, sk))
+	require.NoError(t, err)
+
+	eval := NewEvaluator(params, rlwe.EvaluationKey{
+		Rlk:  kgen.GenRelinearizationKey(sk, 1),
+		Rtks: kgen.GenRotationKeysForRotations(net.Rotations(), false, sk),
+	}, btp)
+
+	input := [][][]float64{randomMatrix(8, 8, -1, 1)}
+
+	ct := encryptor.EncryptNew(encoder.EncodeNew(net.Input.Encode(input, params.Slots()), net.InputLevel, params.DefaultScale(), params.LogSlots()))
+
+	ct, err = eval.InferNew(net, ct)
+	require.NoError(t, err)
+
+	have := net.Output.Decode(encoder.Decode(decryptor.DecryptNew(ct), params.LogSlots()))
+	want := net.EvaluatePlaintext(input, false)
+
+	precStats := ckks.GetPrecisionStats(params, encoder, nil, conv.Flatten(want), conv.Flatten(have), 2, 0)
+
+	if *printPrecisionStats {
+		t.Log(precStats.String())
+	}
+
+	require.GreaterOrEqual(t, precStats.MeanPrecision.Real, minPrec)
+}
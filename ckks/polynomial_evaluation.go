@@ -47,6 +47,30 @@ func (p *Polynomial) Degree() int {
 	return len(p.Coeffs) - 1
 }
 
+// Evaluate evaluates the polynomial on x in the clear.
+// If the polynomial is in the Chebyshev basis, the change of variable x' = (2x - a - b)/(b - a) is applied beforehand.
+func (p *Polynomial) Evaluate(x complex128) (y complex128) {
+
+	if p.BasisType == Chebyshev {
+
+		x = (2*x - complex(p.A+p.B, 0)) / complex(p.B-p.A, 0)
+
+		// Clenshaw recurrence
+		var b1, b2 complex128
+		for i := len(p.Coeffs) - 1; i > 0; i-- {
+			b1, b2 = 2*x*b1-b2+p.Coeffs[i], b1
+		}
+
+		return x*b1 - b2 + p.Coeffs[0]
+	}
+
+	for i := len(p.Coeffs) - 1; i >= 0; i-- {
+		y = y*x + p.Coeffs[i]
+	}
+
+	return
+}
+
 // NewPoly creates a new Poly from the input coefficients
 func NewPoly(coeffs []complex128) (p *Polynomial) {
 	c := make([]complex128, len(coeffs))
@@ -70,10 +94,18 @@ func checkEnoughLevels(levels, depth int, c complex128) (err error) {
 	return nil
 }
 
-type polynomialEvaluator struct {
+// polynomialBackend is the Evaluator on which a polynomial is evaluated.
+type polynomialBackend interface {
 	Evaluator
+	// encodeConstant sets the first polynomial of ct, a zero ciphertext, to the encoding of values at the scale of ct.
+	encodeConstant(encoder Encoder, values []complex128, ct *Ciphertext)
+}
+
+type polynomialEvaluator struct {
+	polynomialBackend
 	Encoder
 	PolynomialBasis
+	params     Parameters
 	slotsIndex map[int][]int
 	logDegree  int
 	logSplit   int
@@ -94,7 +126,7 @@ type polynomialEvaluator struct {
 // targetScale: the desired output scale. This value shouldn't differ too much from the original ciphertext scale. It can
 // for example be used to correct small deviations in the ciphertext scale and reset it to the default scale.
 func (eval *evaluator) EvaluatePoly(input interface{}, pol *Polynomial, targetScale float64) (opOut *Ciphertext, err error) {
-	return eval.evaluatePolyVector(input, polynomialVector{Value: []*Polynomial{pol}}, targetScale)
+	return evaluatePolyVector(eval, eval.params, input, polynomialVector{Value: []*Polynomial{pol}}, targetScale)
 }
 
 type polynomialVector struct {
@@ -123,6 +155,24 @@ type polynomialVector struct {
 // Example: if pols = []*Polynomial{pol0, pol1} and slotsIndex = map[int][]int:{0:[1, 2, 4, 5, 7], 1:[0, 3]},
 // then pol0 will be applied to slots [1, 2, 4, 5, 7], pol1 to slots [0, 3] and the slot 6 will be zero-ed.
 func (eval *evaluator) EvaluatePolyVector(input interface{}, pols []*Polynomial, encoder Encoder, slotsIndex map[int][]int, targetScale float64) (opOut *Ciphertext, err error) {
+
+	var pol polynomialVector
+	if pol, err = newPolynomialVector(pols, encoder, slotsIndex); err != nil {
+		return nil, err
+	}
+
+	return evaluatePolyVector(eval, eval.params, input, pol, targetScale)
+}
+
+// encodeConstant encodes values directly on the first polynomial of ct.
+func (eval *evaluator) encodeConstant(encoder Encoder, values []complex128, ct *Ciphertext) {
+	pt := NewPlaintextAtLevelFromPoly(ct.Level(), ct.Value[0])
+	pt.Scale = ct.Scale
+	encoder.EncodeSlots(values, pt, eval.params.LogSlots())
+}
+
+// newPolynomialVector checks that the polynomials share the same basis and degree and returns the corresponding polynomialVector.
+func newPolynomialVector(pols []*Polynomial, encoder Encoder, slotsIndex map[int][]int) (polynomialVector, error) {
 	var maxDeg int
 	var basis BasisType
 	for i := range pols {
@@ -132,15 +182,15 @@ func (eval *evaluator) EvaluatePolyVector(input interface{}, pols []*Polynomial,
 
 	for i := range pols {
 		if basis != pols[i].BasisType {
-			return nil, fmt.Errorf("polynomial basis must be the same for all polynomials in a polynomial vector")
+			return polynomialVector{}, fmt.Errorf("polynomial basis must be the same for all polynomials in a polynomial vector")
 		}
 
 		if maxDeg != pols[i].MaxDeg {
-			return nil, fmt.Errorf("polynomial degree must all be the same")
+			return polynomialVector{}, fmt.Errorf("polynomial degree must all be the same")
 		}
 	}
 
-	return eval.evaluatePolyVector(input, polynomialVector{Encoder: encoder, Value: pols, SlotsIndex: slotsIndex}, targetScale)
+	return polynomialVector{Encoder: encoder, Value: pols, SlotsIndex: slotsIndex}, nil
 }
 
 func optimalSplit(logDegree int) (logSplit int) {
@@ -154,7 +204,8 @@ func optimalSplit(logDegree int) (logSplit int) {
 	return
 }
 
-func (eval *evaluator) evaluatePolyVector(input interface{}, pol polynomialVector, targetScale float64) (opOut *Ciphertext, err error) {
+// evaluatePolyVector evaluates pol on the input with eval, only relying on the interface methods of eval.
+func evaluatePolyVector(eval polynomialBackend, params Parameters, input interface{}, pol polynomialVector, targetScale float64) (opOut *Ciphertext, err error) {
 
 	if pol.SlotsIndex != nil && pol.Encoder == nil {
 		return nil, fmt.Errorf("cannot EvaluatePolyVector: missing Encoder input")
@@ -186,7 +237,7 @@ func (eval *evaluator) evaluatePolyVector(input interface{}, pol polynomialVecto
 		odd, even = odd && tmp0, even && tmp1
 	}
 
-	isRingStandard := eval.params.RingType() == ring.Standard
+	isRingStandard := params.RingType() == ring.Standard
 
 	for i := (1 << logSplit) - 1; i > 1; i-- {
 		if !(even || odd) || (i&1 == 0 && even) || (i&1 == 1 && odd) {
@@ -204,8 +255,9 @@ func (eval *evaluator) evaluatePolyVector(input interface{}, pol polynomialVecto
 
 	polyEval := &polynomialEvaluator{}
 	polyEval.slotsIndex = pol.SlotsIndex
-	polyEval.Evaluator = eval
+	polyEval.polynomialBackend = eval
 	polyEval.Encoder = pol.Encoder
+	polyEval.params = params
 	polyEval.PolynomialBasis = *monomialBasis
 	polyEval.logDegree = logDegree
 	polyEval.logSplit = logSplit
@@ -431,7 +483,7 @@ func splitCoeffsPolyVector(poly polynomialVector, split int) (polyq, polyr polyn
 
 func (polyEval *polynomialEvaluator) recurse(targetLevel int, targetScale float64, pol polynomialVector) (res *Ciphertext, err error) {
 
-	params := polyEval.Evaluator.(*evaluator).params
+	params := polyEval.params
 
 	logSplit := polyEval.logSplit
 
@@ -444,8 +496,9 @@ func (polyEval *polynomialEvaluator) recurse(targetLevel int, targetScale float6
 			logSplit := logDegree >> 1
 
 			polyEvalBis := new(polynomialEvaluator)
-			polyEvalBis.Evaluator = polyEval.Evaluator
+			polyEvalBis.polynomialBackend = polyEval.polynomialBackend
 			polyEvalBis.Encoder = polyEval.Encoder
+			polyEvalBis.params = polyEval.params
 			polyEvalBis.slotsIndex = polyEval.slotsIndex
 			polyEvalBis.logDegree = logDegree
 			polyEvalBis.logSplit = logSplit
@@ -511,7 +564,7 @@ func (polyEval *polynomialEvaluator) evaluatePolyFromPolynomialBasis(targetScale
 
 	X := polyEval.PolynomialBasis.Value
 
-	params := polyEval.Evaluator.(*evaluator).params
+	params := polyEval.params
 	slotsIndex := polyEval.slotsIndex
 
 	minimumDegreeNonZeroCoefficient := len(pol.Value[0].Coeffs) - 1
@@ -554,9 +607,7 @@ func (polyEval *polynomialEvaluator) evaluatePolyFromPolynomialBasis(targetScale
 
 			// If a non-zero coefficient was found, encode the values, adds on the ciphertext, and returns
 			if toEncode {
-				pt := NewPlaintextAtLevelFromPoly(level, res.Value[0])
-				pt.Scale = res.Scale
-				polyEval.EncodeSlots(values, pt, params.LogSlots())
+				polyEval.encodeConstant(polyEval.Encoder, values, res)
 			}
 
 			return
@@ -566,7 +617,7 @@ func (polyEval *polynomialEvaluator) evaluatePolyFromPolynomialBasis(targetScale
 		res = NewCiphertext(params, maximumCiphertextDegree, level, targetScale)
 
 		// Allocates a temporary plaintext to encode the values
-		pt := NewPlaintextAtLevelFromPoly(level, polyEval.Evaluator.BuffCt().Value[0])
+		pt := NewPlaintextAtLevelFromPoly(level, polyEval.BuffCt().Value[0])
 
 		// Looks for a non-zero coefficient among the degree zero coefficient of the polynomials
 		for i, p := range pol.Value {
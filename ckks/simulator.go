@@ -0,0 +1,1348 @@
+package ckks
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"math/rand"
+
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/rlwe/ringqp"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// Simulator is an implementation of the Evaluator interface that operates on cleartext vectors instead of
+// ciphertexts, for testing homomorphic circuits at a fraction of their cost.
+//
+// The ciphertexts manipulated by the Simulator are regular Ciphertext structs whose degree, level and scale are
+// tracked exactly as by the Evaluator, but whose first polynomial stores the scaled message (value * scale) of
+// all the slots instead of an encryption of it. They must be created with the Encryptor returned by
+// Simulator.NewEncryptor (or allocated with NewCiphertext, which encrypts zero) and can only be decrypted with
+// the Decryptor returned by Simulator.NewDecryptor.
+//
+// The Simulator panics or returns an error on the same missing keys, insufficient levels and invalid operands
+// as the Evaluator, so that circuits written against the Evaluator interface can be unit-tested with it.
+// Only the availability of the keys is checked, which enables the use of the placeholder keys returned by
+// NewSimulatorEvaluationKey instead of generated ones.
+//
+// Optionally, the Simulator adds to the messages an estimate of the noise of the CKKS scheme: the noise of a fresh
+// (secret-key) encryption, and the rounding noise of the rescaling and of the key-switching (relinearization and
+// rotations). The noise is sampled as a Gaussian in each slot with the average-case variance of the canonical
+// embedding and is meant to give the order of magnitude of the precision of a circuit, not its exact value.
+//
+// The low level methods RotateHoistedNoModDownNew and DecomposeNTTNew are not supported by the Simulator,
+// and the decomposed ciphertexts given as argument to the other methods are ignored.
+type Simulator struct {
+	*evaluatorBase
+	encoder Encoder
+	evk     rlwe.EvaluationKey
+	buffQ   [3]*ring.Poly
+	buffCt  *Ciphertext
+	prng    *rand.Rand
+}
+
+// NewSimulator creates a new Simulator from the given parameters and evaluation keys.
+// If noise is true, the Simulator adds a modeled CKKS noise to the messages.
+func NewSimulator(params Parameters, evaluationKey rlwe.EvaluationKey, noise bool) *Simulator {
+	sim := &Simulator{
+		evaluatorBase: newEvaluatorBase(params),
+		encoder:       NewEncoder(params),
+		evk:           evaluationKey,
+	}
+
+	ringQ := params.RingQ()
+	sim.buffQ = [3]*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly(), ringQ.NewPoly()}
+	sim.buffCt = NewCiphertext(params, 2, params.MaxLevel(), params.DefaultScale())
+
+	if noise {
+		sim.prng = rand.New(rand.NewSource(int64(utils.RandUint64())))
+	}
+
+	return sim
+}
+
+// NewSimulatorEvaluationKey returns an EvaluationKey that contains a relinearization key and the rotation keys for
+// the given rotations (and for the conjugation if conjugate is true), but whose switching keys are empty.
+// Since the Simulator only checks the availability of the keys, this avoids the generation of the keys when testing
+// circuits. The returned EvaluationKey cannot be used with the Evaluator.
+func NewSimulatorEvaluationKey(params Parameters, rotations []int, conjugate bool) rlwe.EvaluationKey {
+
+	rtks := &rlwe.RotationKeySet{Keys: make(map[uint64]*rlwe.SwitchingKey)}
+
+	for _, k := range rotations {
+		rtks.Keys[params.GaloisElementForColumnRotationBy(k)] = nil
+	}
+
+	if conjugate {
+		rtks.Keys[params.GaloisElementForRowRotation()] = nil
+	}
+
+	return rlwe.EvaluationKey{
+		Rlk:  &rlwe.RelinearizationKey{Keys: []*rlwe.SwitchingKey{nil}},
+		Rtks: rtks,
+	}
+}
+
+// NewEncryptor returns an Encryptor that creates the ciphertexts of the Simulator.
+func (sim *Simulator) NewEncryptor() Encryptor {
+	return &simulatorEncryptor{sim}
+}
+
+// NewDecryptor returns a Decryptor for the ciphertexts of the Simulator.
+func (sim *Simulator) NewDecryptor() Decryptor {
+	return &simulatorDecryptor{sim}
+}
+
+// GetRLWEEvaluator returns nil since the Simulator does not rely on an *rlwe.Evaluator.
+func (sim *Simulator) GetRLWEEvaluator() *rlwe.Evaluator {
+	return nil
+}
+
+// BuffQ returns a pointer to the internal memory buffer buffQ.
+func (sim *Simulator) BuffQ() [3]*ring.Poly {
+	return sim.buffQ
+}
+
+// BuffCt returns a pointer to the internal memory buffer buffCt.
+func (sim *Simulator) BuffCt() *Ciphertext {
+	return sim.buffCt
+}
+
+// ShallowCopy creates a shallow copy of this Simulator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Simulators can be used concurrently.
+func (sim *Simulator) ShallowCopy() Evaluator {
+	return NewSimulator(sim.params, sim.evk, sim.prng != nil)
+}
+
+// WithKey creates a shallow copy of the receiver Simulator for which the new EvaluationKey is evaluationKey
+// and where the temporary buffers are shared. The receiver and the returned Simulators cannot be used concurrently.
+func (sim *Simulator) WithKey(evaluationKey rlwe.EvaluationKey) Evaluator {
+	return &Simulator{
+		evaluatorBase: sim.evaluatorBase,
+		encoder:       sim.encoder,
+		evk:           evaluationKey,
+		buffQ:         sim.buffQ,
+		buffCt:        sim.buffCt,
+		prng:          sim.prng,
+	}
+}
+
+// Add adds op1 to ctIn and returns the result in ctOut.
+func (sim *Simulator) Add(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	sim.checkBinary(ctIn, op1, ctOut, utils.MaxInt(ctIn.Degree(), op1.Degree()))
+	sim.evaluateInPlace(ctIn, op1, ctOut, func(a, b complex128) complex128 { return a + b })
+}
+
+// AddNoMod adds op1 to ctIn and returns the result in ctOut.
+func (sim *Simulator) AddNoMod(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	sim.Add(ctIn, op1, ctOut)
+}
+
+// AddNew adds op1 to ctIn and returns the result in a newly created element.
+func (sim *Simulator) AddNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = sim.newCiphertextBinary(ctIn, op1)
+	sim.Add(ctIn, op1, ctOut)
+	return
+}
+
+// AddNoModNew adds op1 to ctIn and returns the result in a newly created element.
+func (sim *Simulator) AddNoModNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	return sim.AddNew(ctIn, op1)
+}
+
+// Sub subtracts op1 from ctIn and returns the result in ctOut.
+func (sim *Simulator) Sub(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	sim.checkBinary(ctIn, op1, ctOut, utils.MaxInt(ctIn.Degree(), op1.Degree()))
+	sim.evaluateInPlace(ctIn, op1, ctOut, func(a, b complex128) complex128 { return a - b })
+}
+
+// SubNoMod subtracts op1 from ctIn and returns the result in ctOut.
+func (sim *Simulator) SubNoMod(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	sim.Sub(ctIn, op1, ctOut)
+}
+
+// SubNew subtracts op1 from ctIn and returns the result in a newly created element.
+func (sim *Simulator) SubNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = sim.newCiphertextBinary(ctIn, op1)
+	sim.Sub(ctIn, op1, ctOut)
+	return
+}
+
+// SubNoModNew subtracts op1 from ctIn and returns the result in a newly created element.
+func (sim *Simulator) SubNoModNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	return sim.SubNew(ctIn, op1)
+}
+
+// evaluateInPlace mirrors evaluator.evaluateInPlace: the operand with the smallest scale is multiplied by
+// the integer part of the ratio between the scales before the evaluation and the output has the largest scale.
+func (sim *Simulator) evaluateInPlace(c0, c1 Operand, ctOut *Ciphertext, evaluate func(a, b complex128) complex128) {
+
+	level := utils.MinInt(utils.MinInt(c0.Level(), c1.Level()), ctOut.Level())
+	maxDegree := utils.MaxInt(c0.Degree(), c1.Degree())
+
+	c0Scale, c1Scale := c0.ScalingFactor(), c1.ScalingFactor()
+
+	m0, m1 := sim.message(c0), sim.message(c1)
+
+	if c0Scale > c1Scale && math.Floor(c0Scale/c1Scale) > 1 {
+		scaleMessage(m1, complex(math.Floor(c0Scale/c1Scale), 0))
+	} else if c1Scale > c0Scale && math.Floor(c1Scale/c0Scale) > 1 {
+		scaleMessage(m0, complex(math.Floor(c1Scale/c0Scale), 0))
+	}
+
+	for i := range m0 {
+		m0[i] = evaluate(m0[i], m1[i])
+	}
+
+	sim.setMessage(ctOut, m0, maxDegree, level)
+	ctOut.Scale = utils.MaxFloat64(c0Scale, c1Scale)
+}
+
+// Neg negates the value of ct0 and returns the result in ctOut.
+func (sim *Simulator) Neg(ct0 *Ciphertext, ctOut *Ciphertext) {
+
+	if ct0.Degree() != ctOut.Degree() {
+		panic("cannot Negate: invalid receiver Ciphertext does not match input Ciphertext degree")
+	}
+
+	m := sim.message(ct0)
+	scaleMessage(m, -1)
+
+	sim.setMessage(ctOut, m, ct0.Degree(), utils.MinInt(ct0.Level(), ctOut.Level()))
+	ctOut.Scale = ct0.Scale
+}
+
+// NegNew negates ct0 and returns the result in a newly created element.
+func (sim *Simulator) NegNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	sim.Neg(ct0, ctOut)
+	return
+}
+
+// AddConstNew adds the input constant (which can be a uint64, int64, float64 or complex128) to ct0 and returns the result in a new element.
+func (sim *Simulator) AddConstNew(ct0 *Ciphertext, constant interface{}) (ctOut *Ciphertext) {
+	ctOut = ct0.CopyNew()
+	sim.AddConst(ct0, constant, ctOut)
+	return ctOut
+}
+
+// AddConst adds the input constant (which can be a uint64, int64, float64 or complex128) to ct0 and returns the result in ctOut.
+func (sim *Simulator) AddConst(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
+
+	level := utils.MinInt(ct0.Level(), ctOut.Level())
+
+	cReal, cImag, _ := sim.getConstAndScale(level, constant)
+
+	c := complex(math.Round(cReal*ct0.Scale), math.Round(cImag*ct0.Scale))
+
+	m := sim.message(ct0)
+	for i := range m {
+		m[i] += c
+	}
+
+	sim.setMessage(ctOut, m, ct0.Degree(), level)
+	ctOut.Scale = ct0.Scale
+}
+
+// MultByConstNew multiplies ct0 by the input constant and returns the result in a newly created element.
+// The scale of the output element will depend on the scale of the input element and the constant (if the constant
+// needs to be scaled (its rational part is not zero)). The constant can be a uint64, int64, float64 or complex128.
+func (sim *Simulator) MultByConstNew(ct0 *Ciphertext, constant interface{}) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	sim.MultByConst(ct0, constant, ctOut)
+	return
+}
+
+// MultByConst multiplies ct0 by the input constant and returns the result in ctOut.
+// The scale of the output element will depend on the scale of the input element and the constant (if the constant
+// needs to be scaled (its rational part is not zero)). The constant can be a uint64, int64, float64 or complex128.
+func (sim *Simulator) MultByConst(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
+
+	level := utils.MinInt(ct0.Level(), ctOut.Level())
+
+	cReal, cImag, scale := sim.getConstAndScale(level, constant)
+
+	m := sim.message(ct0)
+	scaleMessage(m, complex(math.Round(cReal*scale), math.Round(cImag*scale)))
+
+	sim.setMessage(ctOut, m, ct0.Degree(), level)
+	ctOut.Scale = ct0.Scale * scale
+}
+
+// MultByGaussianInteger multiples the ct0 by the gaussian integer cReal + i*cImag and returns the result on ctOut.
+// Accepted types for cReal and cImag are uint64, int64 and big.Int.
+func (sim *Simulator) MultByGaussianInteger(ct0 *Ciphertext, cReal, cImag interface{}, ctOut *Ciphertext) {
+
+	m := sim.message(ct0)
+	scaleMessage(m, sim.gaussianInteger(cReal, cImag))
+
+	sim.setMessage(ctOut, m, ct0.Degree(), utils.MinInt(ct0.Level(), ctOut.Level()))
+	ctOut.Scale = ct0.Scale
+}
+
+// MultByConstAndAdd multiplies ct0 by the input constant, and adds it to the receiver element (it does not modify the input
+// element), e.g., ctOut(x) = ctOut(x) + ct0(x) * (a+bi).
+// The level and the scale of the receiver element are set as by the Evaluator.
+func (sim *Simulator) MultByConstAndAdd(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
+
+	level := utils.MinInt(ct0.Level(), ctOut.Level())
+
+	if ctOut.Level() > level {
+		sim.DropLevel(ctOut, ctOut.Level()-level)
+	}
+
+	cReal, cImag, scale := sim.getConstAndScale(level, constant)
+
+	// Same scale management as the Evaluator.
+	if scale != 1 {
+		if ctOut.Scale < ct0.Scale*scale {
+			if scale := math.Floor((scale * ct0.Scale) / ctOut.Scale); scale > 1 {
+				sim.MultByConst(ctOut, scale, ctOut)
+			}
+			ctOut.Scale = scale * ct0.Scale
+		} else if ctOut.Scale > ct0.Scale*scale {
+			scale = ctOut.Scale / ct0.Scale
+		}
+	} else {
+		if ctOut.Scale > ct0.Scale {
+			scale = ctOut.Scale / ct0.Scale
+		} else if ct0.Scale > ctOut.Scale {
+			if scale := math.Floor(ct0.Scale / ctOut.Scale); scale > 1 {
+				sim.MultByConst(ctOut, scale, ctOut)
+			}
+			ctOut.Scale = ct0.Scale
+		}
+	}
+
+	c := complex(math.Round(cReal*scale), math.Round(cImag*scale))
+
+	m0, mOut := sim.message(ct0), sim.message(ctOut)
+	for i := range mOut {
+		mOut[i] += m0[i] * c
+	}
+
+	sim.setMessage(ctOut, mOut, utils.MaxInt(ct0.Degree(), ctOut.Degree()), level)
+}
+
+// MultByGaussianIntegerAndAdd multiples the ct0 by the gaussian integer cReal + i*cImag and adds the result on ctOut.
+// Accepted types for cReal and cImag are uint64, int64 and big.Int.
+func (sim *Simulator) MultByGaussianIntegerAndAdd(ct0 *Ciphertext, cReal, cImag interface{}, ctOut *Ciphertext) {
+
+	c := sim.gaussianInteger(cReal, cImag)
+
+	m0, mOut := sim.message(ct0), sim.message(ctOut)
+	for i := range mOut {
+		mOut[i] += m0[i] * c
+	}
+
+	sim.setMessage(ctOut, mOut, utils.MaxInt(ct0.Degree(), ctOut.Degree()), utils.MinInt(ct0.Level(), ctOut.Level()))
+}
+
+// MultByiNew multiplies ct0 by the imaginary number i, and returns the result in a newly created element.
+// It does not change the scale.
+func (sim *Simulator) MultByiNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
+
+	if sim.params.RingType() == ring.ConjugateInvariant {
+		panic("method MultByi is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	ctOut = NewCiphertext(sim.params, 1, ct0.Level(), ct0.Scale)
+	sim.MultByi(ct0, ctOut)
+	return ctOut
+}
+
+// MultByi multiplies ct0 by the imaginary number i, and returns the result in ctOut.
+// It does not change the scale.
+func (sim *Simulator) MultByi(ct0 *Ciphertext, ctOut *Ciphertext) {
+
+	if sim.params.RingType() == ring.ConjugateInvariant {
+		panic("method MultByi is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	m := sim.message(ct0)
+	scaleMessage(m, 1i)
+
+	sim.setMessage(ctOut, m, ctOut.Degree(), utils.MinInt(ct0.Level(), ctOut.Level()))
+	ctOut.Scale = ct0.Scale
+}
+
+// DivByiNew multiplies ct0 by the imaginary number 1/i = -i, and returns the result in a newly created element.
+// It does not change the scale.
+func (sim *Simulator) DivByiNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
+
+	if sim.params.RingType() == ring.ConjugateInvariant {
+		panic("method DivByi is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	ctOut = NewCiphertext(sim.params, 1, ct0.Level(), ct0.Scale)
+	sim.DivByi(ct0, ctOut)
+	return
+}
+
+// DivByi multiplies ct0 by the imaginary number 1/i = -i, and returns the result in ctOut.
+// It does not change the scale.
+func (sim *Simulator) DivByi(ct0 *Ciphertext, ctOut *Ciphertext) {
+
+	if sim.params.RingType() == ring.ConjugateInvariant {
+		panic("method DivByi is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	m := sim.message(ct0)
+	scaleMessage(m, -1i)
+
+	sim.setMessage(ctOut, m, ctOut.Degree(), utils.MinInt(ct0.Level(), ctOut.Level()))
+	ctOut.Scale = ct0.Scale
+}
+
+// ConjugateNew conjugates ct0 and returns the result in a newly created element.
+// The rotation key for the row rotation needs to be provided.
+func (sim *Simulator) ConjugateNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
+
+	if sim.params.RingType() == ring.ConjugateInvariant {
+		panic("cannot ConjugateNew: method is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	sim.Conjugate(ct0, ctOut)
+	return
+}
+
+// Conjugate conjugates ct0 and returns the result in ctOut.
+// The rotation key for the row rotation needs to be provided.
+func (sim *Simulator) Conjugate(ct0 *Ciphertext, ctOut *Ciphertext) {
+
+	if sim.params.RingType() == ring.ConjugateInvariant {
+		panic("cannot Conjugate: method is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	sim.automorphism(ct0, sim.params.GaloisElementForRowRotation(), ctOut)
+}
+
+// Mul multiplies ctIn with op1 without relinearization and returns the result in ctOut.
+// The procedure will panic if either ctIn or op1 are have a degree higher than 1.
+// The procedure will panic if ctOut.Degree != ctIn.Degree + op1.Degree.
+func (sim *Simulator) Mul(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	sim.mulRelin(ctIn, op1, false, ctOut)
+}
+
+// MulNew multiplies ctIn with op1 without relinearization and returns the result in a newly created element.
+// The procedure will panic if either ctIn.Degree or op1.Degree > 1.
+func (sim *Simulator) MulNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, ctIn.Degree()+op1.Degree(), utils.MinInt(ctIn.Level(), op1.Level()), 0)
+	sim.mulRelin(ctIn, op1, false, ctOut)
+	return
+}
+
+// MulRelin multiplies ctIn with op1 with relinearization and returns the result in ctOut.
+// The procedure will panic if either ctIn.Degree or op1.Degree > 1.
+// The procedure will panic if the evaluator was not created with an relinearization key.
+func (sim *Simulator) MulRelin(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	sim.mulRelin(ctIn, op1, true, ctOut)
+}
+
+// MulRelinNew multiplies ctIn with op1 with relinearization and returns the result in a newly created element.
+// The procedure will panic if either ctIn.Degree or op1.Degree > 1.
+// The procedure will panic if the evaluator was not created with an relinearization key.
+func (sim *Simulator) MulRelinNew(ctIn *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, 1, utils.MinInt(ctIn.Level(), op1.Level()), 0)
+	sim.mulRelin(ctIn, op1, true, ctOut)
+	return
+}
+
+func (sim *Simulator) mulRelin(ctIn *Ciphertext, op1 Operand, relin bool, ctOut *Ciphertext) {
+
+	sim.checkBinary(ctIn, op1, ctOut, utils.MaxInt(ctIn.Degree(), op1.Degree()))
+
+	level := utils.MinInt(utils.MinInt(ctIn.Level(), op1.Level()), ctOut.Level())
+
+	if ctIn.Degree()+op1.Degree() > 2 {
+		panic("cannot MulRelin: the sum of the input elements' total degree cannot be larger than 2")
+	}
+
+	m0, m1 := sim.message(ctIn), sim.message(op1)
+	for i := range m0 {
+		m0[i] *= m1[i]
+	}
+
+	degree := ctIn.Degree() + op1.Degree()
+
+	if relin && degree == 2 {
+		sim.checkRelinearizationKey(degree)
+		sim.addKeySwitchingNoise(m0)
+		degree = 1
+	}
+
+	ctOut.Scale = ctIn.ScalingFactor() * op1.ScalingFactor()
+	sim.setMessage(ctOut, m0, degree, level)
+}
+
+// MulAndAdd multiplies ctIn with op1 without relinearization and adds the result on ctOut.
+// User must ensure that ctOut.Scale <= ctIn.Scale * op1.Scale.
+// If ctOut.Scale < ctIn.Scale * op1.Scale, then scales up ctOut before adding the result.
+// The procedure will panic if either ctIn or op1 are have a degree higher than 1.
+// The procedure will panic if ctOut = ctIn or op1.
+func (sim *Simulator) MulAndAdd(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	sim.mulRelinAndAdd(ctIn, op1, false, ctOut)
+}
+
+// MulRelinAndAdd multiplies ctIn with op1 with relinearization and adds the result on ctOut.
+// User must ensure that ctOut.Scale <= ctIn.Scale * op1.Scale.
+// If ctOut.Scale < ctIn.Scale * op1.Scale, then scales up ctOut before adding the result.
+// The procedure will panic if either ctIn.Degree or op1.Degree > 1.
+// The procedure will panic if the evaluator was not created with an relinearization key.
+// The procedure will panic if ctOut = ctIn or op1.
+func (sim *Simulator) MulRelinAndAdd(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+	sim.mulRelinAndAdd(ctIn, op1, true, ctOut)
+}
+
+func (sim *Simulator) mulRelinAndAdd(ctIn *Ciphertext, op1 Operand, relin bool, ctOut *Ciphertext) {
+
+	sim.checkBinary(ctIn, op1, ctOut, utils.MaxInt(ctIn.Degree(), op1.Degree()))
+
+	level := utils.MinInt(utils.MinInt(ctIn.Level(), op1.Level()), ctOut.Level())
+
+	if ctIn.Degree()+op1.Degree() > 2 {
+		panic("cannot MulRelinAndAdd: the sum of the input elements' degree cannot be larger than 2")
+	}
+
+	if ctIn.El() == ctOut.El() || op1.El() == ctOut.El() {
+		panic("cannot MulRelinAndAdd: ctOut must be different from op0 and op1")
+	}
+
+	resScale := ctIn.Scale * op1.ScalingFactor()
+
+	if ctOut.Scale < resScale {
+		sim.MultByConst(ctOut, math.Round(resScale/ctOut.Scale), ctOut)
+		ctOut.Scale = resScale
+	}
+
+	m0, m1 := sim.message(ctIn), sim.message(op1)
+	for i := range m0 {
+		m0[i] *= m1[i]
+	}
+
+	degree := utils.MaxInt(ctOut.Degree(), ctIn.Degree())
+
+	if ctIn.Degree() == 1 && op1.Degree() == 1 {
+		if relin {
+			sim.checkRelinearizationKey(2)
+			sim.addKeySwitchingNoise(m0)
+		} else {
+			degree = 2
+		}
+	}
+
+	mOut := sim.message(ctOut)
+	for i := range mOut {
+		mOut[i] += m0[i]
+	}
+
+	sim.setMessage(ctOut, mOut, degree, level)
+}
+
+// RotateNew rotates the columns of ct0 by k positions to the left, and returns the result in a newly created element.
+// The rotation key for the specific rotation needs to be provided.
+func (sim *Simulator) RotateNew(ct0 *Ciphertext, k int) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	sim.Rotate(ct0, k, ctOut)
+	return
+}
+
+// Rotate rotates the columns of ct0 by k positions to the left and returns the result in ctOut.
+// The rotation key for the specific rotation needs to be provided.
+func (sim *Simulator) Rotate(ct0 *Ciphertext, k int, ctOut *Ciphertext) {
+	sim.automorphism(ct0, sim.params.GaloisElementForColumnRotationBy(k), ctOut)
+}
+
+// RotateHoistedNew takes an input Ciphertext and a list of rotations and returns a map of Ciphertext, where each element of the map is the input Ciphertext
+// rotation by one element of the list.
+func (sim *Simulator) RotateHoistedNew(ctIn *Ciphertext, rotations []int) (ctOut map[int]*Ciphertext) {
+	ctOut = make(map[int]*Ciphertext)
+	for _, i := range rotations {
+		ctOut[i] = NewCiphertext(sim.params, 1, ctIn.Level(), ctIn.Scale)
+	}
+	sim.RotateHoisted(ctIn, rotations, ctOut)
+	return
+}
+
+// RotateHoisted takes an input Ciphertext and a list of rotations and populates a map of pre-allocated Ciphertexts,
+// where each element of the map is the input Ciphertext rotation by one element of the list.
+func (sim *Simulator) RotateHoisted(ctIn *Ciphertext, rotations []int, ctOut map[int]*Ciphertext) {
+	for _, i := range rotations {
+		sim.Rotate(ctIn, i, ctOut[i])
+	}
+}
+
+// RotateHoistedNoModDownNew is not supported by the Simulator and panics.
+func (sim *Simulator) RotateHoistedNoModDownNew(level int, rotations []int, c0 *ring.Poly, c2DecompQP []ringqp.Poly) (cOut map[int][2]ringqp.Poly) {
+	panic("cannot RotateHoistedNoModDownNew: method is not supported by the Simulator")
+}
+
+// DecomposeNTTNew is not supported by the Simulator and panics.
+func (sim *Simulator) DecomposeNTTNew(levelQ, levelP, nbPi int, c2 *ring.Poly) (BuffDecompQP []ringqp.Poly) {
+	panic("cannot DecomposeNTTNew: method is not supported by the Simulator")
+}
+
+// AutomorphismHoistedNew applies the automorphism X -> X^galEl on ctIn and returns the result on a new ciphertext.
+// The decomposition c1DecompQP is ignored.
+func (sim *Simulator) AutomorphismHoistedNew(level int, ctIn *Ciphertext, c1DecompQP []ringqp.Poly, galEl uint64) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, 1, utils.MinInt(level, ctIn.Level()), ctIn.Scale)
+	sim.automorphism(ctIn, galEl, ctOut)
+	return
+}
+
+// MulByPow2New multiplies ct0 by 2^pow2 and returns the result in a newly created element.
+func (sim *Simulator) MulByPow2New(ct0 *Ciphertext, pow2 int) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	sim.MulByPow2(ct0, pow2, ctOut)
+	return
+}
+
+// MulByPow2 multiplies ct0 by 2^pow2 and returns the result in ctOut.
+func (sim *Simulator) MulByPow2(ct0 *Ciphertext, pow2 int, ctOut *Ciphertext) {
+	m := sim.message(ct0)
+	scaleMessage(m, complex(math.Exp2(float64(pow2)), 0))
+	sim.setMessage(ctOut, m, ctOut.Degree(), utils.MinInt(ct0.Level(), ctOut.Level()))
+	ctOut.Scale = ct0.Scale
+}
+
+// PowerOf2 computes op^(2^logPow2), consuming logPow2 levels, and returns the result on opOut.
+func (sim *Simulator) PowerOf2(op *Ciphertext, logPow2 int, opOut *Ciphertext) {
+	powerOf2(sim, op, logPow2, opOut)
+}
+
+// Power computes op^degree, consuming log(degree) levels, and returns the result on opOut.
+func (sim *Simulator) Power(op *Ciphertext, degree int, opOut *Ciphertext) {
+	power(sim, sim.params, op, degree, opOut)
+}
+
+// PowerNew computes op^degree, consuming log(degree) levels, and returns the result on a new element.
+func (sim *Simulator) PowerNew(op *Ciphertext, degree int) (opOut *Ciphertext) {
+	opOut = NewCiphertext(sim.params, 1, op.Level(), op.Scale)
+	sim.Power(op, degree, opOut)
+	return
+}
+
+// EvaluatePoly evaluates a polynomial on the input Ciphertext with the same algorithm as the Evaluator.
+func (sim *Simulator) EvaluatePoly(input interface{}, pol *Polynomial, targetScale float64) (opOut *Ciphertext, err error) {
+	return evaluatePolyVector(sim, sim.params, input, polynomialVector{Value: []*Polynomial{pol}}, targetScale)
+}
+
+// EvaluatePolyVector evaluates a vector of Polynomials on the input Ciphertext with the same algorithm as the Evaluator.
+func (sim *Simulator) EvaluatePolyVector(input interface{}, pols []*Polynomial, encoder Encoder, slotsIndex map[int][]int, targetScale float64) (opOut *Ciphertext, err error) {
+
+	var pol polynomialVector
+	if pol, err = newPolynomialVector(pols, encoder, slotsIndex); err != nil {
+		return nil, err
+	}
+
+	return evaluatePolyVector(sim, sim.params, input, pol, targetScale)
+}
+
+// encodeConstant encodes values on a buffer plaintext and adds it to ct, since the first polynomial of ct
+// stores the message of ct and not its encoding.
+func (sim *Simulator) encodeConstant(encoder Encoder, values []complex128, ct *Ciphertext) {
+	pt := NewPlaintextAtLevelFromPoly(ct.Level(), sim.buffCt.Value[0])
+	pt.Scale = ct.Scale
+	encoder.EncodeSlots(values, pt, sim.params.LogSlots())
+	sim.Add(ct, pt, ct)
+}
+
+// InverseNew computes 1/op and returns the result on a new element, iterating for n steps and consuming n levels.
+func (sim *Simulator) InverseNew(op *Ciphertext, steps int) (opOut *Ciphertext) {
+	return inverseNew(sim, op, steps)
+}
+
+// LinearTransformNew evaluates a linear transform on the ciphertext and returns the result on a new ciphertext.
+// The linearTransform can either be an (ordered) list of LinearTransform or a single LinearTransform.
+func (sim *Simulator) LinearTransformNew(ctIn *Ciphertext, linearTransform interface{}) (ctOut []*Ciphertext) {
+
+	switch LTs := linearTransform.(type) {
+	case []LinearTransform:
+		ctOut = make([]*Ciphertext, len(LTs))
+
+		var maxLevel int
+		for _, LT := range LTs {
+			maxLevel = utils.MaxInt(maxLevel, LT.Level)
+		}
+
+		minLevel := utils.MinInt(maxLevel, ctIn.Level())
+
+		for i := range LTs {
+			ctOut[i] = NewCiphertext(sim.params, 1, minLevel, ctIn.Scale)
+		}
+
+	case LinearTransform:
+		ctOut = []*Ciphertext{NewCiphertext(sim.params, 1, utils.MinInt(LTs.Level, ctIn.Level()), ctIn.Scale)}
+	}
+
+	sim.LinearTransform(ctIn, linearTransform, ctOut)
+
+	return
+}
+
+// LinearTransform evaluates a linear transform on the pre-allocated ciphertexts.
+// The linearTransform can either be an (ordered) list of LinearTransform or a single LinearTransform.
+func (sim *Simulator) LinearTransform(ctIn *Ciphertext, linearTransform interface{}, ctOut []*Ciphertext) {
+
+	switch LTs := linearTransform.(type) {
+	case []LinearTransform:
+		for i, LT := range LTs {
+			if LT.N1 == 0 {
+				sim.MultiplyByDiagMatrix(ctIn, LT, nil, ctOut[i])
+			} else {
+				sim.MultiplyByDiagMatrixBSGS(ctIn, LT, nil, ctOut[i])
+			}
+		}
+
+	case LinearTransform:
+		if LTs.N1 == 0 {
+			sim.MultiplyByDiagMatrix(ctIn, LTs, nil, ctOut[0])
+		} else {
+			sim.MultiplyByDiagMatrixBSGS(ctIn, LTs, nil, ctOut[0])
+		}
+	}
+}
+
+// MultiplyByDiagMatrix multiplies the ciphertext "ctIn" by the plaintext matrix "matrix" and returns the result on the ciphertext
+// "ctOut", using the same rotations as the Evaluator. The decomposition BuffDecompQP is ignored.
+func (sim *Simulator) MultiplyByDiagMatrix(ctIn *Ciphertext, matrix LinearTransform, BuffDecompQP []ringqp.Poly, ctOut *Ciphertext) {
+
+	level := utils.MinInt(ctOut.Level(), utils.MinInt(ctIn.Level(), matrix.Level))
+
+	m := sim.message(ctIn)
+	res := make([]complex128, len(m))
+
+	for k := range matrix.Vec {
+
+		rot := k & (sim.params.MaxSlots() - 1)
+
+		if rot != 0 && !sim.hasRotationKey(sim.params.GaloisElementForColumnRotationBy(rot)) {
+			panic("cannot MultiplyByDiagMatrix: switching key not available")
+		}
+
+		diag := sim.decodeDiagonal(matrix, k)
+		mRot := utils.RotateComplex128Slice(m, rot)
+		for i := range res {
+			res[i] += diag[i] * mRot[i]
+		}
+	}
+
+	sim.addKeySwitchingNoise(res)
+
+	sim.setMessage(ctOut, res, ctOut.Degree(), level)
+	ctOut.Scale = matrix.Scale * ctIn.Scale
+}
+
+// MultiplyByDiagMatrixBSGS multiplies the ciphertext "ctIn" by the plaintext matrix "matrix" and returns the result on the ciphertext
+// "ctOut", using the same baby-step giant-step decomposition and rotations as the Evaluator. The decomposition PoolDecompQP is ignored.
+func (sim *Simulator) MultiplyByDiagMatrixBSGS(ctIn *Ciphertext, matrix LinearTransform, PoolDecompQP []ringqp.Poly, ctOut *Ciphertext) {
+
+	level := utils.MinInt(ctOut.Level(), utils.MinInt(ctIn.Level(), matrix.Level))
+
+	index, _, rotN2 := BsgsIndex(matrix.Vec, 1<<matrix.LogSlots, matrix.N1)
+
+	m := sim.message(ctIn)
+
+	// Baby-steps
+	mRot := make(map[int][]complex128)
+	for _, i := range rotN2 {
+		if i != 0 {
+			if galEl := sim.params.GaloisElementForColumnRotationBy(i); !sim.hasRotationKey(galEl) {
+				panic(fmt.Sprintf("cannot AutomorphismHoistedNoModDown: galEl key 5^%d missing", sim.params.InverseGaloisElement(galEl)))
+			}
+		}
+		mRot[i] = utils.RotateComplex128Slice(m, i)
+	}
+
+	// Giant-steps
+	res := make([]complex128, len(m))
+	tmp := make([]complex128, len(m))
+	for j := range index {
+
+		for i := range tmp {
+			tmp[i] = 0
+		}
+
+		for _, i := range index[j] {
+			diag := sim.decodeDiagonal(matrix, j+i)
+			for u := range tmp {
+				tmp[u] += diag[u] * mRot[i][u]
+			}
+		}
+
+		if j != 0 && !sim.hasRotationKey(sim.params.GaloisElementForColumnRotationBy(j)) {
+			panic("cannot MultiplyByDiagMatrixBSGS: switching key not available")
+		}
+
+		for u, v := range utils.RotateComplex128Slice(tmp, j) {
+			res[u] += v
+		}
+	}
+
+	sim.addKeySwitchingNoise(res)
+
+	sim.setMessage(ctOut, res, ctOut.Degree(), level)
+	ctOut.Scale = matrix.Scale * ctIn.Scale
+}
+
+// InnerSumLog applies an inner sum on the ciphertext, using the same rotations as the Evaluator.
+// The operation assumes that `ctIn` encrypts SlotCount/`batchSize` sub-vectors of size `batchSize` which it adds together (in parallel) by groups of `n`.
+// It outputs in ctOut a ciphertext for which the "leftmost" sub-vector of each group is equal to the sum of the group.
+func (sim *Simulator) InnerSumLog(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphertext) {
+	sim.innerSum(ctIn, batchSize, n, sim.params.RotationsForInnerSumLog(batchSize, n), ctOut)
+}
+
+// InnerSum applies an inner sum on the ciphertext, using the same rotations as the Evaluator.
+// The operation assumes that `ctIn` encrypts SlotCount/`batchSize` sub-vectors of size `batchSize` which it adds together (in parallel) by groups of `n`.
+// It outputs in ctOut a ciphertext for which the "leftmost" sub-vector of each group is equal to the sum of the group.
+func (sim *Simulator) InnerSum(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphertext) {
+	sim.innerSum(ctIn, batchSize, n, sim.params.RotationsForInnerSum(batchSize, n), ctOut)
+}
+
+// innerSum sets ctOut to sum(rot(ctIn, i * batchSize)) for 0 <= i < n, after checking that the keys of the given rotations are available.
+func (sim *Simulator) innerSum(ctIn *Ciphertext, batchSize, n int, rotations []int, ctOut *Ciphertext) {
+
+	m := sim.message(ctIn)
+	res := make([]complex128, len(m))
+	copy(res, m)
+
+	if n > 1 {
+
+		for _, k := range rotations {
+			sim.checkRotationKey(sim.params.GaloisElementForColumnRotationBy(k))
+		}
+
+		for i := 1; i < n; i++ {
+			for u, v := range utils.RotateComplex128Slice(m, i*batchSize) {
+				res[u] += v
+			}
+		}
+
+		sim.addKeySwitchingNoise(res)
+	}
+
+	sim.setMessage(ctOut, res, ctOut.Degree(), ctIn.Level())
+	ctOut.Scale = ctIn.Scale
+}
+
+// Average returns the average of vectors of batchSize elements.
+// The operation assumes that ctIn encrypts SlotCount/'batchSize' sub-vectors of size 'batchSize'.
+// It then replaces all values of those sub-vectors by the component-wise average between all the sub-vectors.
+// Required rotation keys can be generated with 'RotationsForInnerSumLog(batchSize, SlotCount/batchSize)”
+func (sim *Simulator) Average(ctIn *Ciphertext, logBatchSize int, ctOut *Ciphertext) {
+
+	if logBatchSize > sim.params.LogSlots() {
+		panic("cannot Average: batchSize must be smaller or equal to the number of slots")
+	}
+
+	n := sim.params.Slots() / (1 << logBatchSize)
+
+	m := sim.message(ctIn)
+	scaleMessage(m, complex(1/float64(n), 0))
+	sim.setMessage(ctOut, m, ctOut.Degree(), utils.MinInt(ctIn.Level(), ctOut.Level()))
+	ctOut.Scale = ctIn.Scale
+
+	sim.InnerSumLog(ctOut, 1<<logBatchSize, n, ctOut)
+}
+
+// ReplicateLog applies a replication on the ciphertext, using the same rotations as the Evaluator.
+// It acts as the inverse of a inner sum (summing elements from left to right).
+func (sim *Simulator) ReplicateLog(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphertext) {
+	sim.InnerSumLog(ctIn, -batchSize, n, ctOut)
+}
+
+// Replicate applies a replication on the ciphertext, using the same rotations as the Evaluator.
+// It acts as the inverse of a inner sum (summing elements from left to right).
+func (sim *Simulator) Replicate(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphertext) {
+	sim.InnerSum(ctIn, -batchSize, n, ctOut)
+}
+
+// Trace maps X -> sum((-1)^i * X^{i*n+1}) for 0 <= i < N, for log(n) = logSlots, using the same automorphisms as the Evaluator.
+func (sim *Simulator) Trace(ctIn *Ciphertext, logSlots int, ctOut *Ciphertext) {
+
+	level := utils.MinInt(ctIn.Level(), ctOut.Level())
+
+	m := sim.message(ctIn)
+
+	gap := 1 << (sim.params.LogN() - logSlots - 1)
+
+	if logSlots == 0 {
+		gap <<= 1
+	}
+
+	if gap > 1 {
+
+		scaleMessage(m, complex(1/float64(gap), 0))
+
+		galEls := []uint64{}
+		for i := logSlots; i < sim.params.LogN()-1; i++ {
+			galEls = append(galEls, sim.params.GaloisElementForColumnRotationBy(1<<i))
+		}
+
+		if logSlots == 0 {
+			galEls = append(galEls, sim.params.RingQ().NthRoot-1)
+		}
+
+		for _, galEl := range galEls {
+			sim.checkRotationKey(galEl)
+			for u, v := range sim.applyGaloisElement(m, galEl) {
+				m[u] += v
+			}
+			sim.addKeySwitchingNoise(m)
+		}
+	}
+
+	sim.setMessage(ctOut, m, ctOut.Degree(), level)
+	ctOut.Scale = ctIn.Scale
+}
+
+// TraceNew maps X -> sum((-1)^i * X^{i*n+1}) for 0 <= i < N and returns the result on a new ciphertext.
+// For log(n) = logSlots.
+func (sim *Simulator) TraceNew(ctIn *Ciphertext, logSlots int) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, 1, ctIn.Level(), ctIn.Scale)
+	sim.Trace(ctIn, logSlots, ctOut)
+	return
+}
+
+// SwitchKeysNew simulates the re-encryption of ct0 under a different key and returns the result in a newly created element.
+func (sim *Simulator) SwitchKeysNew(ct0 *Ciphertext, switchingKey *rlwe.SwitchingKey) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	sim.SwitchKeys(ct0, switchingKey, ctOut)
+	return
+}
+
+// SwitchKeys simulates the re-encryption of ctIn under a different key and returns the result in ctOut.
+func (sim *Simulator) SwitchKeys(ctIn *Ciphertext, switchingKey *rlwe.SwitchingKey, ctOut *Ciphertext) {
+
+	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot SwitchKeys: input and output Ciphertext must be of degree 1")
+	}
+
+	m := sim.message(ctIn)
+	sim.addKeySwitchingNoise(m)
+	sim.setMessage(ctOut, m, 1, utils.MinInt(ctIn.Level(), ctOut.Level()))
+	ctOut.Scale = ctIn.Scale
+}
+
+// RelinearizeNew applies the relinearization procedure on ct0 and returns the result in a newly
+// created Ciphertext. The input Ciphertext must be of degree two.
+func (sim *Simulator) RelinearizeNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, 1, ct0.Level(), ct0.Scale)
+	sim.Relinearize(ct0, ctOut)
+	return
+}
+
+// Relinearize applies the relinearization procedure on ct0 and returns the result in ctOut. The input Ciphertext must be of degree two.
+func (sim *Simulator) Relinearize(ct0 *Ciphertext, ctOut *Ciphertext) {
+
+	sim.checkRelinearizationKey(ct0.Degree())
+
+	m := sim.message(ct0)
+
+	if ct0.Degree() > 1 {
+		sim.addKeySwitchingNoise(m)
+	}
+
+	sim.setMessage(ctOut, m, 1, utils.MinInt(ct0.Level(), ctOut.Level()))
+	ctOut.Scale = ct0.Scale
+}
+
+// ScaleUpNew multiplies ct0 by scale and sets its scale to its previous scale times scale.
+// It returns the result in a newly created element.
+func (sim *Simulator) ScaleUpNew(ct0 *Ciphertext, scale float64) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	sim.ScaleUp(ct0, scale, ctOut)
+	return
+}
+
+// ScaleUp multiplies ct0 by scale and sets its scale to its previous scale times scale.
+// It returns the result in ctOut.
+func (sim *Simulator) ScaleUp(ct0 *Ciphertext, scale float64, ctOut *Ciphertext) {
+	sim.MultByConst(ct0, uint64(scale), ctOut)
+	ctOut.Scale = ct0.Scale * scale
+}
+
+// SetScale sets the scale of the ciphertext to the input scale (consumes a level).
+func (sim *Simulator) SetScale(ct *Ciphertext, scale float64) {
+	sim.MultByConst(ct, scale/ct.Scale, ct)
+	if err := sim.Rescale(ct, scale, ct); err != nil {
+		panic(err)
+	}
+	ct.Scale = scale
+}
+
+// RescaleNew divides ct0 by the last moduli of the moduli chain, as the Evaluator would, and returns the result
+// in a newly created element.
+func (sim *Simulator) RescaleNew(ct0 *Ciphertext, threshold float64) (ctOut *Ciphertext, err error) {
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	return ctOut, sim.Rescale(ct0, threshold, ctOut)
+}
+
+// Rescale divides ctIn by the last moduli of the moduli chain, as the Evaluator would, and returns the result in ctOut.
+// Returns an error if "minScale <= 0", ct.Scale = 0, ct.Level() = 0 or if ctIn.Degree() != ctOut.Degree().
+func (sim *Simulator) Rescale(ctIn *Ciphertext, minScale float64, ctOut *Ciphertext) (err error) {
+
+	ringQ := sim.params.RingQ()
+
+	if minScale <= 0 {
+		return errors.New("cannot Rescale: minScale is 0")
+	}
+
+	if ctIn.Scale == 0 {
+		return errors.New("cannot Rescale: ciphertext scale is 0")
+	}
+
+	if ctIn.Level() == 0 {
+		return errors.New("cannot Rescale: input Ciphertext already at level 0")
+	}
+
+	if ctOut.Degree() != ctIn.Degree() {
+		return errors.New("cannot Rescale: ctIn.Degree() != ctOut.Degree()")
+	}
+
+	m := sim.message(ctIn)
+	scale := ctIn.Scale
+
+	var nbRescales int
+	for ctIn.Level()-nbRescales >= 0 && scale/float64(ringQ.Modulus[ctIn.Level()-nbRescales]) >= minScale/2 {
+		scale /= float64(ringQ.Modulus[ctIn.Level()-nbRescales])
+		scaleMessage(m, complex(1/float64(ringQ.Modulus[ctIn.Level()-nbRescales]), 0))
+		nbRescales++
+	}
+
+	if nbRescales > 0 {
+		sim.addNoise(m, sim.roundingVariance())
+	}
+
+	sim.setMessage(ctOut, m, ctIn.Degree(), ctIn.Level()-nbRescales)
+	ctOut.Scale = scale
+
+	return nil
+}
+
+// DropLevelNew reduces the level of ct0 by levels and returns the result in a newly created element.
+// No rescaling is applied during this procedure.
+func (sim *Simulator) DropLevelNew(ct0 *Ciphertext, levels int) (ctOut *Ciphertext) {
+	ctOut = ct0.CopyNew()
+	sim.DropLevel(ctOut, levels)
+	return
+}
+
+// DropLevel reduces the level of ct0 by levels and returns the result in ct0.
+// No rescaling is applied during this procedure.
+func (sim *Simulator) DropLevel(ct0 *Ciphertext, levels int) {
+	ct0.Resize(ct0.Degree(), ct0.Level()-levels)
+}
+
+// ReduceNew returns a copy of ct0, since the messages of the Simulator do not need modular reductions.
+func (sim *Simulator) ReduceNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(sim.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	_ = sim.Reduce(ct0, ctOut)
+	return ctOut
+}
+
+// Reduce copies ct0 on ctOut, since the messages of the Simulator do not need modular reductions.
+func (sim *Simulator) Reduce(ct0 *Ciphertext, ctOut *Ciphertext) error {
+
+	if ct0.Degree() != ctOut.Degree() {
+		return errors.New("cannot Reduce: degrees of receiver Ciphertext and input Ciphertext do not match")
+	}
+
+	sim.setMessage(ctOut, sim.message(ct0), ct0.Degree(), utils.MinInt(ct0.Level(), ctOut.Level()))
+	ctOut.Scale = ct0.Scale
+
+	return nil
+}
+
+// automorphism applies the automorphism X -> X^galEl on the message of ctIn and returns the result in ctOut,
+// with the same checks as the rlwe.Evaluator.
+func (sim *Simulator) automorphism(ctIn *Ciphertext, galEl uint64, ctOut *Ciphertext) {
+
+	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot apply Automorphism: input and output Ciphertext must be of degree 1")
+	}
+
+	m := sim.message(ctIn)
+
+	if galEl != 1 {
+		sim.checkRotationKey(galEl)
+		m = sim.applyGaloisElement(m, galEl)
+		sim.addKeySwitchingNoise(m)
+	}
+
+	sim.setMessage(ctOut, m, 1, utils.MinInt(ctIn.Level(), ctOut.Level()))
+	ctOut.Scale = ctIn.Scale
+}
+
+// applyGaloisElement returns the slots of m permuted by the automorphism X -> X^galEl, which is a rotation
+// by k positions to the left if galEl = 5^k and its conjugate if galEl = -5^k.
+func (sim *Simulator) applyGaloisElement(m []complex128, galEl uint64) (res []complex128) {
+
+	nthRoot := sim.params.RingQ().NthRoot
+
+	for k, g := 0, uint64(1); k < len(m); k, g = k+1, (g*GaloisGen)&(nthRoot-1) {
+
+		if g == galEl {
+			return utils.RotateComplex128Slice(m, k)
+		}
+
+		if nthRoot-g == galEl {
+			res = utils.RotateComplex128Slice(m, k)
+			for i := range res {
+				res[i] = cmplx.Conj(res[i])
+			}
+			return
+		}
+	}
+
+	panic(fmt.Sprintf("cannot apply Automorphism: invalid Galois element %d", galEl))
+}
+
+func (sim *Simulator) hasRotationKey(galEl uint64) bool {
+	if sim.evk.Rtks == nil {
+		return false
+	}
+	_, generated := sim.evk.Rtks.GetRotationKey(galEl)
+	return generated
+}
+
+func (sim *Simulator) checkRotationKey(galEl uint64) {
+	if galEl != 1 && !sim.hasRotationKey(galEl) {
+		panic(fmt.Sprintf("galEl key 5^%d missing", sim.params.InverseGaloisElement(galEl)))
+	}
+}
+
+func (sim *Simulator) checkRelinearizationKey(degree int) {
+	if sim.evk.Rlk == nil || degree-1 > len(sim.evk.Rlk.Keys) {
+		panic("cannot Relinearize: relinearization key missing (or ciphertext degree is too large)")
+	}
+}
+
+// gaussianInteger converts the gaussian integer cReal + i*cImag to a complex128.
+func (sim *Simulator) gaussianInteger(cReal, cImag interface{}) complex128 {
+
+	toFloat64 := func(x interface{}) float64 {
+		switch x := x.(type) {
+		case uint64:
+			return float64(x)
+		case int64:
+			return float64(x)
+		case *big.Int:
+			f, _ := new(big.Float).SetInt(x).Float64()
+			return f
+		default:
+			panic("constant must either be uint64, int64 or *big.Int")
+		}
+	}
+
+	if sim.params.RingType() == ring.ConjugateInvariant {
+		return complex(toFloat64(cReal), 0)
+	}
+
+	return complex(toFloat64(cReal), toFloat64(cImag))
+}
+
+// decodeDiagonal returns the values of the diagonal of index k of the matrix, multiplied by the scale of the matrix.
+func (sim *Simulator) decodeDiagonal(matrix LinearTransform, k int) []complex128 {
+	poly := matrix.Vec[k].Q.CopyNew()
+	sim.params.RingQ().InvMFormLvl(poly.Level(), poly, poly)
+	poly.IsNTT = true
+	poly.IsMForm = false
+	return sim.decodePoly(poly)
+}
+
+// decodePoly returns the values of all the slots of the plaintext polynomial, without dividing them by the scale.
+func (sim *Simulator) decodePoly(poly *ring.Poly) (m []complex128) {
+
+	if poly.IsMForm {
+		poly = poly.CopyNew()
+		sim.params.RingQ().InvMFormLvl(poly.Level(), poly, poly)
+		poly.IsMForm = false
+	}
+
+	m = sim.encoder.Decode(&Plaintext{Plaintext: &rlwe.Plaintext{Value: poly}, Scale: 1}, sim.params.MaxLogSlots())
+
+	if sim.params.RingType() == ring.ConjugateInvariant {
+		for i := range m {
+			m[i] = complex(real(m[i]), 0)
+		}
+	}
+
+	return
+}
+
+// message returns the scaled message of all the slots of the operand:
+// the values stored in the first polynomial of a ciphertext or the decoded values of a plaintext.
+func (sim *Simulator) message(op Operand) (m []complex128) {
+
+	if op.Degree() == 0 {
+		return sim.decodePoly(op.El().Value[0])
+	}
+
+	slots := sim.params.MaxSlots()
+	coeffs := op.El().Value[0].Coeffs[0]
+
+	m = make([]complex128, slots)
+
+	if sim.params.RingType() == ring.Standard {
+		for i := range m {
+			m[i] = complex(math.Float64frombits(coeffs[i]), math.Float64frombits(coeffs[i+slots]))
+		}
+	} else {
+		for i := range m {
+			m[i] = complex(math.Float64frombits(coeffs[i]), 0)
+		}
+	}
+
+	return
+}
+
+// setMessage resizes ct to the given degree and level and stores the scaled message m in its first polynomial.
+func (sim *Simulator) setMessage(ct *Ciphertext, m []complex128, degree, level int) {
+
+	ct.Resize(degree, level)
+
+	slots := sim.params.MaxSlots()
+	coeffs := ct.Value[0].Coeffs[0]
+
+	if sim.params.RingType() == ring.Standard {
+		for i, v := range m {
+			coeffs[i] = math.Float64bits(real(v))
+			coeffs[i+slots] = math.Float64bits(imag(v))
+		}
+	} else {
+		for i, v := range m {
+			coeffs[i] = math.Float64bits(real(v))
+		}
+	}
+}
+
+// freshVariance returns the variance of the coefficients of the noise of a fresh secret-key encryption.
+func (sim *Simulator) freshVariance() float64 {
+	return sim.params.Sigma() * sim.params.Sigma()
+}
+
+// roundingVariance returns the variance of the coefficients of the rounding noise of the rescaling and
+// of the key-switching, e0 + e1 * s with e0 and e1 uniform in [-1/2, 1/2].
+func (sim *Simulator) roundingVariance() float64 {
+	return float64(1+sim.params.HammingWeight()) / 12
+}
+
+func (sim *Simulator) addKeySwitchingNoise(m []complex128) {
+	sim.addNoise(m, sim.roundingVariance())
+}
+
+// addNoise adds to each slot of m a Gaussian noise of variance N * variance, which is the variance
+// of the canonical embedding of a polynomial whose coefficients have the given variance.
+func (sim *Simulator) addNoise(m []complex128, variance float64) {
+
+	if sim.prng == nil {
+		return
+	}
+
+	sigma := math.Sqrt(float64(sim.params.N()) * variance)
+
+	if sim.params.RingType() == ring.Standard {
+		sigma /= math.Sqrt2
+		for i := range m {
+			m[i] += complex(sigma*sim.prng.NormFloat64(), sigma*sim.prng.NormFloat64())
+		}
+	} else {
+		for i := range m {
+			m[i] += complex(sigma*sim.prng.NormFloat64(), 0)
+		}
+	}
+}
+
+// scaleMessage multiplies all the values of m by c.
+func scaleMessage(m []complex128, c complex128) {
+	for i := range m {
+		m[i] *= c
+	}
+}
+
+type simulatorEncryptor struct {
+	sim *Simulator
+}
+
+// Encrypt sets the message of ciphertext to the values of plaintext, at the level and scale of plaintext.
+func (enc *simulatorEncryptor) Encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
+	m := enc.sim.decodePoly(plaintext.Value)
+	enc.sim.addNoise(m, enc.sim.freshVariance())
+	enc.sim.setMessage(ciphertext, m, 1, utils.MinInt(plaintext.Level(), ciphertext.Level()))
+	ciphertext.Scale = plaintext.Scale
+}
+
+// EncryptNew returns a new ciphertext whose message is the values of plaintext.
+func (enc *simulatorEncryptor) EncryptNew(plaintext *Plaintext) *Ciphertext {
+	ciphertext := NewCiphertext(enc.sim.params, 1, plaintext.Level(), plaintext.Scale)
+	enc.Encrypt(plaintext, ciphertext)
+	return ciphertext
+}
+
+// EncryptZero sets the message of ciphertext to zero.
+func (enc *simulatorEncryptor) EncryptZero(ciphertext *Ciphertext) {
+	m := make([]complex128, enc.sim.params.MaxSlots())
+	enc.sim.addNoise(m, enc.sim.freshVariance())
+	enc.sim.setMessage(ciphertext, m, 1, ciphertext.Level())
+}
+
+// EncryptZeroNew returns a new ciphertext whose message is zero.
+func (enc *simulatorEncryptor) EncryptZeroNew(level int, scale float64) *Ciphertext {
+	ciphertext := NewCiphertext(enc.sim.params, 1, level, scale)
+	enc.EncryptZero(ciphertext)
+	return ciphertext
+}
+
+// ShallowCopy creates a shallow copy of this Encryptor that can be used concurrently with the receiver.
+func (enc *simulatorEncryptor) ShallowCopy() Encryptor {
+	return &simulatorEncryptor{enc.sim.ShallowCopy().(*Simulator)}
+}
+
+// WithKey returns the receiver, since the ciphertexts of the Simulator are not encrypted.
+func (enc *simulatorEncryptor) WithKey(key interface{}) Encryptor {
+	return enc
+}
+
+// EncryptCompressed is not supported by the Simulator and panics.
+func (enc *simulatorEncryptor) EncryptCompressed(plaintext *Plaintext, ciphertext *CompressedCiphertext) {
+	panic("cannot EncryptCompressed: method is not supported by the Simulator")
+}
+
+type simulatorDecryptor struct {
+	sim *Simulator
+}
+
+// DecryptNew returns a new plaintext encoding the message of ciphertext, at its level and scale.
+func (dec *simulatorDecryptor) DecryptNew(ciphertext *Ciphertext) (plaintext *Plaintext) {
+	plaintext = NewPlaintext(dec.sim.params, ciphertext.Level(), ciphertext.Scale)
+	dec.Decrypt(ciphertext, plaintext)
+	return
+}
+
+// Decrypt encodes the message of ciphertext on plaintext, at the scale of ciphertext.
+func (dec *simulatorDecryptor) Decrypt(ciphertext *Ciphertext, plaintext *Plaintext) {
+
+	m := dec.sim.message(ciphertext)
+	scaleMessage(m, complex(1/ciphertext.Scale, 0))
+
+	plaintext.Value.Resize(utils.MinInt(ciphertext.Level(), plaintext.Level()))
+	plaintext.Scale = ciphertext.Scale
+
+	dec.sim.encoder.Encode(m, plaintext, dec.sim.params.MaxLogSlots())
+}
+
+// ShallowCopy creates a shallow copy of this Decryptor that can be used concurrently with the receiver.
+func (dec *simulatorDecryptor) ShallowCopy() Decryptor {
+	return &simulatorDecryptor{dec.sim.ShallowCopy().(*Simulator)}
+}
+
+// WithKey returns the receiver, since the ciphertexts of the Simulator are not encrypted.
+func (dec *simulatorDecryptor) WithKey(sk *rlwe.SecretKey) Decryptor {
+	return dec
+}
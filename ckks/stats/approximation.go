@@ -0,0 +1,139 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+)
+
+// Approximation is a struct storing the settings of the Chebyshev approximations used by the
+// non-linear statistics: the interval [A, B] on which the function is approximated and the degree
+// of the approximation.
+type Approximation struct {
+	A, B   float64
+	Degree int
+}
+
+// Depth returns the number of levels consumed by the evaluation of the approximation,
+// including the change of variable.
+func (approx Approximation) Depth() int {
+	return bits.Len(uint(approx.Degree)) + 1
+}
+
+// Polynomial returns the Chebyshev approximation of f.
+func (approx Approximation) Polynomial(f func(float64) float64) *ckks.Polynomial {
+	return ckks.Approximate(f, approx.A, approx.B, approx.Degree)
+}
+
+// Precision returns the precision in bits (i.e. -log2 of the maximum absolute error) of the
+// approximation of f, measured on a uniform grid of the interval.
+// This enables users to report the approximation error separately from the homomorphic error.
+func (approx Approximation) Precision(f func(float64) float64) float64 {
+
+	pol := approx.Polynomial(f)
+
+	const points = 1024
+
+	var maxErr float64
+	for i := 0; i <= points; i++ {
+		x := approx.A + (approx.B-approx.A)*float64(i)/points
+		maxErr = math.Max(maxErr, math.Abs(real(pol.Evaluate(complex(x, 0)))-f(x)))
+	}
+
+	return -math.Log2(maxErr)
+}
+
+func (approx Approximation) check() error {
+	if approx.B <= approx.A {
+		return fmt.Errorf("invalid approximation interval [%f, %f]", approx.A, approx.B)
+	}
+	if approx.Degree < 1 {
+		return fmt.Errorf("invalid approximation degree %d", approx.Degree)
+	}
+	return nil
+}
+
+// Quantile returns the q-quantile of a distribution given the values of its cumulative distribution
+// function cdf at the given thresholds (for example, decrypted outputs of Evaluator.CDFNew), by linear
+// interpolation between the two closest thresholds.
+// Returns an error if the thresholds and cdf have different lengths, if q is not in [0, 1] or if
+// the thresholds are empty.
+func Quantile(thresholds, cdf []float64, q float64) (float64, error) {
+
+	if len(thresholds) != len(cdf) {
+		return 0, fmt.Errorf("cannot Quantile: %d thresholds but %d cdf values", len(thresholds), len(cdf))
+	}
+
+	if len(thresholds) == 0 {
+		return 0, fmt.Errorf("cannot Quantile: thresholds cannot be empty")
+	}
+
+	if q < 0 || q > 1 {
+		return 0, fmt.Errorf("cannot Quantile: q must be in [0, 1] but is %f", q)
+	}
+
+	idx := make([]int, len(thresholds))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	sort.Slice(idx, func(i, j int) bool { return thresholds[idx[i]] < thresholds[idx[j]] })
+
+	if q <= cdf[idx[0]] {
+		return thresholds[idx[0]], nil
+	}
+
+	for k := 1; k < len(idx); k++ {
+
+		x0, x1 := thresholds[idx[k-1]], thresholds[idx[k]]
+		y0, y1 := cdf[idx[k-1]], cdf[idx[k]]
+
+		if q <= y1 {
+			if y1 == y0 {
+				return x0, nil
+			}
+			return x0 + (q-y0)*(x1-x0)/(y1-y0), nil
+		}
+	}
+
+	return thresholds[idx[len(idx)-1]], nil
+}
+
+// evaluateApproximation evaluates the Chebyshev approximation of f on ctIn and returns the result at targetScale.
+// The operation always consumes approx.Depth() levels.
+func (eval *Evaluator) evaluateApproximation(ctIn *ckks.Ciphertext, f func(float64) float64, approx Approximation, targetScale float64) (ctOut *ckks.Ciphertext, err error) {
+
+	if err = approx.check(); err != nil {
+		return nil, err
+	}
+
+	if ctIn.Level() < approx.Depth() {
+		return nil, fmt.Errorf("approximation requires %d levels but ciphertext is at level %d", approx.Depth(), ctIn.Level())
+	}
+
+	pol := approx.Polynomial(f)
+
+	ctOut = eval.MultByConstNew(ctIn, 2/(pol.B-pol.A))
+	eval.AddConst(ctOut, (-pol.A-pol.B)/(pol.B-pol.A), ctOut)
+
+	// Integer constants do not increase the scale, in which case a level is dropped
+	// so that the number of consumed levels does not depend on the interval.
+	if ctOut.Scale == ctIn.Scale {
+		eval.DropLevel(ctOut, 1)
+	} else if err = eval.rescaleOnce(ctOut); err != nil {
+		return nil, err
+	}
+
+	if ctOut, err = eval.EvaluatePoly(ctOut, pol, targetScale); err != nil {
+		return nil, err
+	}
+
+	if err = setScale(ctOut, targetScale); err != nil {
+		return nil, err
+	}
+
+	return
+}
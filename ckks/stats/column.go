@@ -0,0 +1,67 @@
+// Package stats implements statistics over encrypted columns for the CKKS scheme.
+// A column of values is stored across one or many ciphertexts, and the statistics (sums, means, variances, covariances,
+// correlations and empirical distribution functions) are returned as ciphertexts encrypting the result in every slot.
+// Unused slots are masked during the computation and the scales are managed so that all the intermediate
+// results are combined at matching scales.
+package stats
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+)
+
+// Column is an encrypted column of Len values, stored in the slots of the ciphertexts in order:
+// the value i is stored in the slot i % Slots of the ciphertext i / Slots.
+// The content of the slots after the last value is ignored.
+type Column struct {
+	Ciphertexts []*ckks.Ciphertext
+	Len         int
+}
+
+// NewColumn creates a new Column from the given ciphertexts, storing n values.
+func NewColumn(params ckks.Parameters, cts []*ckks.Ciphertext, n int) (col *Column, err error) {
+
+	if n < 1 {
+		return nil, fmt.Errorf("cannot NewColumn: column must store at least one value")
+	}
+
+	if len(cts) != (n+params.Slots()-1)/params.Slots() {
+		return nil, fmt.Errorf("cannot NewColumn: %d values require %d ciphertexts but %d were given", n, (n+params.Slots()-1)/params.Slots(), len(cts))
+	}
+
+	return &Column{Ciphertexts: cts, Len: n}, nil
+}
+
+// EncryptColumnNew encodes and encrypts the values on a new Column at the maximum level and default scale.
+func EncryptColumnNew(params ckks.Parameters, encoder ckks.Encoder, encryptor ckks.Encryptor, values []float64) (col *Column, err error) {
+
+	if len(values) < 1 {
+		return nil, fmt.Errorf("cannot EncryptColumnNew: column must store at least one value")
+	}
+
+	slots := params.Slots()
+
+	col = &Column{Len: len(values)}
+
+	for i := 0; i < len(values); i += slots {
+
+		vec := make([]float64, slots)
+		copy(vec, values[i:])
+
+		col.Ciphertexts = append(col.Ciphertexts, encryptor.EncryptNew(encoder.EncodeNew(vec, params.MaxLevel(), params.DefaultScale(), params.LogSlots())))
+	}
+
+	return
+}
+
+// Level returns the minimum level of the ciphertexts of the column.
+func (col *Column) Level() (level int) {
+	level = col.Ciphertexts[0].Level()
+	for _, ct := range col.Ciphertexts[1:] {
+		if ct.Level() < level {
+			level = ct.Level()
+		}
+	}
+	return
+}
@@ -0,0 +1,319 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Evaluator is a struct to compute statistics over encrypted columns.
+// All the statistics are returned as a ciphertext encrypting the result in every slot, at the default scale of the parameters.
+// The scales are managed so that the outputs are at the default scale up to the floating point rounding of the scales,
+// which is checked and corrected before returning.
+type Evaluator struct {
+	ckks.Evaluator
+	params  ckks.Parameters
+	encoder ckks.Encoder
+}
+
+// RotationsForStats returns the list of rotations needed by the Evaluator.
+func RotationsForStats(params ckks.Parameters) []int {
+	return params.RotationsForInnerSumLog(1, params.Slots())
+}
+
+// NewEvaluator creates a new Evaluator from the given parameters and evaluation keys.
+// The evaluation keys must contain the rotations returned by RotationsForStats and, for the
+// non-linear statistics, the relinearization key.
+func NewEvaluator(params ckks.Parameters, evaluationKey rlwe.EvaluationKey) *Evaluator {
+	return &Evaluator{
+		Evaluator: ckks.NewEvaluator(params, evaluationKey),
+		params:    params,
+		encoder:   ckks.NewEncoder(params),
+	}
+}
+
+// ShallowCopy creates a shallow copy of this Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluators can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	return &Evaluator{
+		Evaluator: eval.Evaluator.ShallowCopy(),
+		params:    eval.params,
+		encoder:   eval.encoder.ShallowCopy(),
+	}
+}
+
+// SumNew returns the sum of the values of the column.
+// The operation consumes one level.
+func (eval *Evaluator) SumNew(col *Column) (ctOut *ckks.Ciphertext, err error) {
+	return eval.weightedSum(col.Ciphertexts, col.Len, func(i int) float64 { return 1 }, eval.params.DefaultScale())
+}
+
+// MeanNew returns the mean of the values of the column.
+// The operation consumes one level.
+func (eval *Evaluator) MeanNew(col *Column) (ctOut *ckks.Ciphertext, err error) {
+	n := float64(col.Len)
+	return eval.weightedSum(col.Ciphertexts, col.Len, func(i int) float64 { return 1 / n }, eval.params.DefaultScale())
+}
+
+// WeightedSumNew returns sum(weights[i] * col[i]).
+// The operation consumes one level.
+func (eval *Evaluator) WeightedSumNew(col *Column, weights []float64) (ctOut *ckks.Ciphertext, err error) {
+
+	if len(weights) != col.Len {
+		return nil, fmt.Errorf("cannot WeightedSumNew: column has %d values but %d weights were given", col.Len, len(weights))
+	}
+
+	return eval.weightedSum(col.Ciphertexts, col.Len, func(i int) float64 { return weights[i] }, eval.params.DefaultScale())
+}
+
+// VarianceNew returns the (population) variance of the values of the column, computed as E[x^2] - E[x]^2.
+// The operation consumes two levels.
+func (eval *Evaluator) VarianceNew(col *Column) (ctOut *ckks.Ciphertext, err error) {
+	return eval.CovarianceNew(col, col)
+}
+
+// CovarianceNew returns the (population) covariance between the columns x and y, computed as E[xy] - E[x]E[y].
+// The operation consumes two levels.
+func (eval *Evaluator) CovarianceNew(x, y *Column) (ctOut *ckks.Ciphertext, err error) {
+
+	if x.Len != y.Len {
+		return nil, fmt.Errorf("cannot CovarianceNew: columns have different lengths (%d != %d)", x.Len, y.Len)
+	}
+
+	level := x.Level()
+	if y.Level() < level {
+		level = y.Level()
+	}
+
+	if level < 2 {
+		return nil, fmt.Errorf("cannot CovarianceNew: requires two levels but columns are at level %d", level)
+	}
+
+	targetScale := eval.params.DefaultScale()
+	n := float64(x.Len)
+	invN := func(i int) float64 { return 1 / n }
+
+	xCts := eval.dropLevel(x.Ciphertexts, level)
+	yCts := xCts
+	if x != y {
+		yCts = eval.dropLevel(y.Ciphertexts, level)
+	}
+
+	// E[xy] at level-2 and scale targetScale
+	prods := make([]*ckks.Ciphertext, len(xCts))
+	for i := range prods {
+		prods[i] = eval.MulRelinNew(xCts[i], yCts[i])
+		if err = eval.rescaleOnce(prods[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if ctOut, err = eval.weightedSum(prods, x.Len, invN, targetScale); err != nil {
+		return nil, err
+	}
+
+	// E[x]E[y] at level-2 and scale targetScale: the means are computed at the scale
+	// sqrt(targetScale * Q[level-1]) so that their rescaled product is at targetScale.
+	meanScale := math.Sqrt(targetScale * eval.params.QiFloat64(level-1))
+
+	var meanX, meanY *ckks.Ciphertext
+
+	if meanX, err = eval.weightedSum(xCts, x.Len, invN, meanScale); err != nil {
+		return nil, err
+	}
+
+	meanY = meanX
+	if x != y {
+		if meanY, err = eval.weightedSum(yCts, y.Len, invN, meanScale); err != nil {
+			return nil, err
+		}
+	}
+
+	meanXY := eval.MulRelinNew(meanX, meanY)
+	if err = eval.rescaleOnce(meanXY); err != nil {
+		return nil, err
+	}
+
+	eval.Sub(ctOut, meanXY, ctOut)
+
+	if err = setScale(ctOut, targetScale); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// StdNew returns the (population) standard deviation of the values of the column.
+// The square root is evaluated with a Chebyshev approximation over [approx.A, approx.B], which must contain the variance.
+// The operation consumes 2 + approx.Depth() levels.
+func (eval *Evaluator) StdNew(col *Column, approx Approximation) (ctOut *ckks.Ciphertext, err error) {
+
+	if ctOut, err = eval.VarianceNew(col); err != nil {
+		return nil, err
+	}
+
+	return eval.evaluateApproximation(ctOut, math.Sqrt, approx, eval.params.DefaultScale())
+}
+
+// CorrelationNew returns the Pearson correlation coefficient between the columns x and y, computed as
+// cov(x, y) / sqrt(var(x) * var(y)).
+// The inverse square root is evaluated with a Chebyshev approximation over [approx.A, approx.B], which must contain var(x) * var(y).
+// The operation consumes 4 + approx.Depth() levels.
+func (eval *Evaluator) CorrelationNew(x, y *Column, approx Approximation) (ctOut *ckks.Ciphertext, err error) {
+
+	var cov, varX, varY *ckks.Ciphertext
+
+	if cov, err = eval.CovarianceNew(x, y); err != nil {
+		return nil, err
+	}
+
+	if varX, err = eval.VarianceNew(x); err != nil {
+		return nil, err
+	}
+
+	if varY, err = eval.VarianceNew(y); err != nil {
+		return nil, err
+	}
+
+	if cov.Level() < 2+approx.Depth() {
+		return nil, fmt.Errorf("cannot CorrelationNew: requires %d levels after the covariance but only %d remain", 2+approx.Depth(), cov.Level())
+	}
+
+	varXY := eval.MulRelinNew(varX, varY)
+	if err = eval.rescaleOnce(varXY); err != nil {
+		return nil, err
+	}
+
+	// The inverse square root is evaluated at the scale Q[level] / cov.Scale so that
+	// its rescaled product with the covariance is exactly at the default scale.
+	level := varXY.Level() - approx.Depth()
+
+	invSqrt := func(x float64) float64 { return 1 / math.Sqrt(x) }
+
+	if varXY, err = eval.evaluateApproximation(varXY, invSqrt, approx, eval.params.DefaultScale()*eval.params.QiFloat64(level)/cov.Scale); err != nil {
+		return nil, err
+	}
+
+	eval.DropLevel(cov, cov.Level()-level)
+
+	ctOut = eval.MulRelinNew(varXY, cov)
+
+	if err = eval.rescaleOnce(ctOut); err != nil {
+		return nil, err
+	}
+
+	if err = setScale(ctOut, eval.params.DefaultScale()); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// CDFNew returns an approximation of the empirical cumulative distribution function of the column evaluated at threshold,
+// i.e. the fraction of the values that are smaller than threshold.
+// The indicator function is replaced by the smooth step 1/(1 + exp(-sharpness * (threshold - x))), evaluated
+// with a Chebyshev approximation over [approx.A, approx.B], which must contain all the values of the column.
+// The operation consumes 1 + approx.Depth() levels.
+// Quantiles can be recovered from the decrypted values of the CDF at several thresholds with Quantile.
+func (eval *Evaluator) CDFNew(col *Column, threshold, sharpness float64, approx Approximation) (ctOut *ckks.Ciphertext, err error) {
+
+	step := func(x float64) float64 { return 1 / (1 + math.Exp(-sharpness*(threshold-x))) }
+
+	cts := make([]*ckks.Ciphertext, len(col.Ciphertexts))
+	for i := range cts {
+		if cts[i], err = eval.evaluateApproximation(col.Ciphertexts[i], step, approx, eval.params.DefaultScale()); err != nil {
+			return nil, err
+		}
+	}
+
+	n := float64(col.Len)
+
+	return eval.weightedSum(cts, col.Len, func(i int) float64 { return 1 / n }, eval.params.DefaultScale())
+}
+
+// weightedSum returns sum(weight(i) * cts[i / slots][i % slots]) for 0 <= i < n, at the scale targetScale, in all the slots.
+// The weights of the unused slots are set to zero, which masks their content.
+// The operation consumes one level.
+func (eval *Evaluator) weightedSum(cts []*ckks.Ciphertext, n int, weight func(i int) float64, targetScale float64) (ctOut *ckks.Ciphertext, err error) {
+
+	level := cts[0].Level()
+	for _, ct := range cts[1:] {
+		if ct.Level() < level {
+			level = ct.Level()
+		}
+	}
+
+	if level == 0 {
+		return nil, fmt.Errorf("cannot compute weighted sum: ciphertexts are at level 0")
+	}
+
+	slots := eval.params.Slots()
+
+	weights := make([]float64, slots)
+
+	for i, ct := range cts {
+
+		for j := range weights {
+			if idx := i*slots + j; idx < n {
+				weights[j] = weight(idx)
+			} else {
+				weights[j] = 0
+			}
+		}
+
+		// The plaintext scale is chosen such that the result is exactly at targetScale after the rescaling.
+		pt := eval.encoder.EncodeNew(weights, level, targetScale*eval.params.QiFloat64(level)/ct.Scale, eval.params.LogSlots())
+
+		if i == 0 {
+			ctOut = eval.MulNew(ct, pt)
+		} else {
+			eval.MulAndAdd(ct, pt, ctOut)
+		}
+	}
+
+	if err = eval.rescaleOnce(ctOut); err != nil {
+		return nil, err
+	}
+
+	if err = setScale(ctOut, targetScale); err != nil {
+		return nil, err
+	}
+
+	eval.InnerSumLog(ctOut, 1, slots, ctOut)
+
+	return
+}
+
+// scaleTolerance is the maximum relative difference between the actual and the expected scale of a result.
+const scaleTolerance = 1e-9
+
+// setScale sets the scale of ct to scale, which its actual scale must match up to the floating point
+// rounding of the scale management, and returns an error otherwise.
+func setScale(ct *ckks.Ciphertext, scale float64) (err error) {
+	if math.Abs(ct.Scale/scale-1) > scaleTolerance {
+		return fmt.Errorf("scale %f does not match the expected scale %f", ct.Scale, scale)
+	}
+	ct.Scale = scale
+	return
+}
+
+// rescaleOnce rescales the ciphertext by exactly one modulus.
+func (eval *Evaluator) rescaleOnce(ct *ckks.Ciphertext) (err error) {
+	return eval.Rescale(ct, ct.Scale/eval.params.QiFloat64(ct.Level()), ct)
+}
+
+// dropLevel returns the ciphertexts at the given level, copying only those that are above it.
+func (eval *Evaluator) dropLevel(cts []*ckks.Ciphertext, level int) (ctsOut []*ckks.Ciphertext) {
+	ctsOut = make([]*ckks.Ciphertext, len(cts))
+	for i, ct := range cts {
+		if ct.Level() > level {
+			ctsOut[i] = eval.DropLevelNew(ct, ct.Level()-level)
+		} else {
+			ctsOut[i] = ct
+		}
+	}
+	return
+}
@@ -0,0 +1,218 @@
+package stats
+
+import (
+	"flag"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var printPrecisionStats = flag.Bool("print-precision", false, "print precision stats")
+
+func randomSlice(n int, a, b float64) (v []float64) {
+	v = make([]float64, n)
+	for i := range v {
+		v[i] = utils.RandFloat64(a, b)
+	}
+	return
+}
+
+func mean(x []float64) (m float64) {
+	for _, v := range x {
+		m += v
+	}
+	return m / float64(len(x))
+}
+
+func covariance(x, y []float64) (c float64) {
+	mx, my := mean(x), mean(y)
+	for i := range x {
+		c += (x[i] - mx) * (y[i] - my)
+	}
+	return c / float64(len(x))
+}
+
+func TestStats(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping stats tests for GOARCH=wasm")
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckks.ParametersLiteral{
+		LogN:         12,
+		LogQ:         []int{55, 40, 40, 40, 40, 40, 40, 40, 40, 40},
+		LogP:         []int{61, 61},
+		LogSlots:     11,
+		DefaultScale: 1 << 40,
+	})
+	require.NoError(t, err)
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	encoder := ckks.NewEncoder(params)
+	encryptor := ckks.NewEncryptor(params, sk)
+	decryptor := ckks.NewDecryptor(params, sk)
+
+	eval := NewEvaluator(params, rlwe.EvaluationKey{
+		Rlk:  kgen.GenRelinearizationKey(sk, 1),
+		Rtks: kgen.GenRotationKeysForRotations(RotationsForStats(params), false, sk),
+	})
+
+	// Spans two ciphertexts, with the second one partially filled.
+	n := params.Slots() + 300
+
+	x := randomSlice(n, -1, 1)
+	y := make([]float64, n)
+	for i := range y {
+		y[i] = 0.5*x[i] + utils.RandFloat64(-0.5, 0.5)
+	}
+
+	colX, err := EncryptColumnNew(params, encoder, encryptor, x)
+	require.NoError(t, err)
+	colY, err := EncryptColumnNew(params, encoder, encryptor, y)
+	require.NoError(t, err)
+
+	// verify checks that all the slots of ct decrypt to want.
+	verify := func(t *testing.T, ct *ckks.Ciphertext, want float64, delta float64) {
+		require.Equal(t, params.DefaultScale(), ct.Scale)
+		have := encoder.Decode(decryptor.DecryptNew(ct), params.LogSlots())
+		if *printPrecisionStats {
+			t.Logf("want: %f, have: %f, err: %e", want, real(have[0]), math.Abs(want-real(have[0])))
+		}
+		for i := range have {
+			require.InDelta(t, want, real(have[i]), delta)
+		}
+	}
+
+	t.Run("Column", func(t *testing.T) {
+		require.Len(t, colX.Ciphertexts, 2)
+		_, err := NewColumn(params, colX.Ciphertexts, params.Slots())
+		require.Error(t, err)
+		_, err = NewColumn(params, colX.Ciphertexts, n)
+		require.NoError(t, err)
+		_, err = EncryptColumnNew(params, encoder, encryptor, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("Sum", func(t *testing.T) {
+		ct, err := eval.SumNew(colX)
+		require.NoError(t, err)
+		require.Equal(t, params.MaxLevel()-1, ct.Level())
+		verify(t, ct, mean(x)*float64(n), 1e-4)
+	})
+
+	t.Run("Mean", func(t *testing.T) {
+		ct, err := eval.MeanNew(colX)
+		require.NoError(t, err)
+		verify(t, ct, mean(x), 1e-6)
+	})
+
+	t.Run("WeightedSum", func(t *testing.T) {
+		weights := randomSlice(n, 0, 1)
+		var want float64
+		for i := range x {
+			want += weights[i] * x[i]
+		}
+		ct, err := eval.WeightedSumNew(colX, weights)
+		require.NoError(t, err)
+		verify(t, ct, want, 1e-4)
+
+		_, err = eval.WeightedSumNew(colX, weights[1:])
+		require.Error(t, err)
+	})
+
+	t.Run("Variance", func(t *testing.T) {
+		ct, err := eval.VarianceNew(colX)
+		require.NoError(t, err)
+		require.Equal(t, params.MaxLevel()-2, ct.Level())
+		verify(t, ct, covariance(x, x), 1e-6)
+	})
+
+	t.Run("Covariance", func(t *testing.T) {
+
+		// Columns at different levels are aligned.
+		colYLow := &Column{Len: colY.Len}
+		for _, ct := range colY.Ciphertexts {
+			colYLow.Ciphertexts = append(colYLow.Ciphertexts, eval.DropLevelNew(ct, 1))
+		}
+
+		ct, err := eval.CovarianceNew(colX, colYLow)
+		require.NoError(t, err)
+		verify(t, ct, covariance(x, y), 1e-6)
+	})
+
+	t.Run("Std", func(t *testing.T) {
+		approx := Approximation{A: 0.1, B: 1, Degree: 15}
+		ct, err := eval.StdNew(colX, approx)
+		require.NoError(t, err)
+		require.Equal(t, params.MaxLevel()-2-approx.Depth(), ct.Level())
+		verify(t, ct, math.Sqrt(covariance(x, x)), math.Exp2(-approx.Precision(math.Sqrt))+1e-4)
+	})
+
+	t.Run("Correlation", func(t *testing.T) {
+		approx := Approximation{A: 0.02, B: 0.2, Degree: 15}
+		invSqrt := func(x float64) float64 { return 1 / math.Sqrt(x) }
+		want := covariance(x, y) / math.Sqrt(covariance(x, x)*covariance(y, y))
+		ct, err := eval.CorrelationNew(colX, colY, approx)
+		require.NoError(t, err)
+		verify(t, ct, want, math.Exp2(-approx.Precision(invSqrt))+1e-3)
+	})
+
+	t.Run("CDF/Quantile", func(t *testing.T) {
+
+		approx := Approximation{A: -1, B: 1, Degree: 63}
+
+		thresholds := []float64{-0.5, 0, 0.5}
+		cdf := make([]float64, len(thresholds))
+
+		for i, threshold := range thresholds {
+
+			var want float64
+			for _, v := range x {
+				if v < threshold {
+					want++
+				}
+			}
+			want /= float64(n)
+
+			ct, err := eval.CDFNew(colX, threshold, 32, approx)
+			require.NoError(t, err)
+
+			have := encoder.Decode(decryptor.DecryptNew(ct), params.LogSlots())
+			cdf[i] = real(have[0])
+
+			require.InDelta(t, want, cdf[i], 0.02)
+		}
+
+		median, err := Quantile(thresholds, cdf, 0.5)
+		require.NoError(t, err)
+		require.InDelta(t, 0, median, 0.1)
+
+		_, err = Quantile(thresholds, cdf[1:], 0.5)
+		require.Error(t, err)
+	})
+
+	t.Run("Quantile", func(t *testing.T) {
+		q, err := Quantile([]float64{2, 0, 1}, []float64{1, 0, 0.5}, 0.75)
+		require.NoError(t, err)
+		require.InDelta(t, 1.5, q, 1e-12)
+
+		_, err = Quantile([]float64{0}, []float64{0}, 2)
+		require.Error(t, err)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		ct := eval.DropLevelNew(colX.Ciphertexts[0], params.MaxLevel()-1)
+		col, err := NewColumn(params, []*ckks.Ciphertext{ct}, 10)
+		require.NoError(t, err)
+		_, err = eval.VarianceNew(col)
+		require.Error(t, err)
+		_, err = eval.CovarianceNew(colX, &Column{Len: 1})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/cipherflow-fhe/lattigo/ckks/bootstrapping"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+var flagLogN = flag.Int("logN", 16, "log2 of the ring degree.")
+var flagLogSlots = flag.Int("logSlots", 0, "log2 of the number of slots (default logN-1).")
+var flagSecurity = flag.Int("security", 128, "target security in bits (128, 192 or 256).")
+var flagLevels = flag.Int("levels", 9, "number of levels available after the bootstrapping.")
+var flagPrecision = flag.Float64("precision", 20, "minimum reference precision in bits of the selected template.")
+
+func main() {
+
+	flag.Parse()
+
+	res, err := bootstrapping.SelectTemplate(bootstrapping.TemplateSelectionLiteral{
+		LogN:                 *flagLogN,
+		LogSlots:             *flagLogSlots,
+		Security:             *flagSecurity,
+		ResidualLevels:       *flagLevels,
+		MinTemplatePrecision: *flagPrecision,
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	schemeParams, err := json.MarshalIndent(res.SchemeParams, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+
+	btpParams, err := json.MarshalIndent(res.BootstrappingParams, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("ckks.ParametersLiteral:\n%s\n\n", schemeParams)
+	fmt.Printf("bootstrapping.Parameters:\n%s\n\n", btpParams)
+
+	fmt.Printf("LogQP: %d\n", res.LogQP)
+	fmt.Printf("Template precision (reference, not measured on these parameters): %.1f bits\n", res.TemplatePrecision)
+	fmt.Printf("Output level: %d\n", res.BootstrappingParams.OutputLevel())
+	fmt.Printf("Key size: %.2f MB\n", float64(res.KeySize)/(1<<20))
+	fmt.Printf("Key-switchings: %d\n", res.KeySwitchings)
+	fmt.Printf("NTTs: %d\n", res.NTTs)
+	fmt.Printf("Estimated key-switching runtime (single thread, lower bound): %s\n", res.EstimatedRuntime(timeNTT(*flagLogN, res.SchemeParams.Q[0])))
+}
+
+// timeNTT measures the average time of an NTT of size 2^logN modulo q.
+func timeNTT(logN int, q uint64) time.Duration {
+
+	ringQ, err := ring.NewRing(1<<logN, []uint64{q})
+	if err != nil {
+		panic(err)
+	}
+
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+
+	pol := ring.NewUniformSampler(prng, ringQ).ReadNew()
+
+	iterations := 64
+
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		ringQ.NTT(pol, pol)
+	}
+
+	return time.Since(start) / time.Duration(iterations)
+}
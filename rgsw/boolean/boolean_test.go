@@ -0,0 +1,158 @@
+package boolean
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// Insecure parameters for fast testing only
+var testParameters = ParametersLiteral{
+	LogN:     9,
+	Q:        0x7fff801,
+	Pow2Base: 6,
+	LogNLWE:  8,
+}
+
+func testString(params Parameters, opname string) string {
+	return fmt.Sprintf("%s/logN=%d/logNLWE=%d/logQ=%d", opname, params.LUTParameters().LogN(), params.LWEParameters().LogN(), params.LWEParameters().LogQ())
+}
+
+func TestBoolean(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping boolean tests for GOARCH=wasm")
+	}
+
+	params, err := NewParametersFromLiteral(testParameters)
+	require.NoError(t, err)
+
+	kgen := NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	evk := kgen.GenEvaluationKey(sk)
+
+	encryptor := NewEncryptor(params, sk)
+	decryptor := NewDecryptor(params, sk)
+	eval := NewEvaluator(params, evk)
+
+	bits := []bool{false, true}
+
+	t.Run(testString(params, "Encrypt"), func(t *testing.T) {
+		for _, b := range bits {
+			require.Equal(t, b, decryptor.Decrypt(encryptor.EncryptNew(b)))
+			require.Equal(t, b, decryptor.Decrypt(NewTrivialCiphertext(params, b)))
+		}
+	})
+
+	gates := []struct {
+		name string
+		gate func(ct0, ct1 *Ciphertext) *Ciphertext
+		want func(b0, b1 bool) bool
+	}{
+		{"And", eval.AndNew, func(b0, b1 bool) bool { return b0 && b1 }},
+		{"Or", eval.OrNew, func(b0, b1 bool) bool { return b0 || b1 }},
+		{"Nand", eval.NandNew, func(b0, b1 bool) bool { return !(b0 && b1) }},
+		{"Nor", eval.NorNew, func(b0, b1 bool) bool { return !(b0 || b1) }},
+		{"Xor", eval.XorNew, func(b0, b1 bool) bool { return b0 != b1 }},
+		{"Xnor", eval.XnorNew, func(b0, b1 bool) bool { return b0 == b1 }},
+	}
+
+	for _, g := range gates {
+		t.Run(testString(params, g.name), func(t *testing.T) {
+			for _, b0 := range bits {
+				for _, b1 := range bits {
+					ctOut := g.gate(encryptor.EncryptNew(b0), encryptor.EncryptNew(b1))
+					require.Equal(t, g.want(b0, b1), decryptor.Decrypt(ctOut), "%s(%t, %t)", g.name, b0, b1)
+				}
+			}
+		})
+	}
+
+	t.Run(testString(params, "Not"), func(t *testing.T) {
+		for _, b := range bits {
+			require.Equal(t, !b, decryptor.Decrypt(eval.NotNew(encryptor.EncryptNew(b))))
+		}
+	})
+
+	t.Run(testString(params, "Mux"), func(t *testing.T) {
+		for _, s := range bits {
+			for _, b1 := range bits {
+				for _, b0 := range bits {
+					ctOut := eval.MuxNew(encryptor.EncryptNew(s), encryptor.EncryptNew(b1), encryptor.EncryptNew(b0))
+					want := b0
+					if s {
+						want = b1
+					}
+					require.Equal(t, want, decryptor.Decrypt(ctOut), "Mux(%t, %t, %t)", s, b1, b0)
+				}
+			}
+		}
+	})
+
+	t.Run(testString(params, "Depth"), func(t *testing.T) {
+		// Chains gates on their own outputs, which is only possible thanks to the bootstrapping
+		ct := encryptor.EncryptNew(true)
+		want := true
+		for i := 0; i < 16; i++ {
+			b := i&1 == 0
+			ct = eval.XorNew(ct, encryptor.EncryptNew(b))
+			want = want != b
+		}
+		require.Equal(t, want, decryptor.Decrypt(ct))
+	})
+
+	t.Run(testString(params, "Integer"), func(t *testing.T) {
+
+		nbBits := 4
+		mask := uint64(1<<nbBits) - 1
+
+		a, b := utils.RandUint64()&mask, utils.RandUint64()&mask
+		ctA, ctB := encryptor.EncryptIntegerNew(a, nbBits), encryptor.EncryptIntegerNew(b, nbBits)
+
+		require.Equal(t, a, decryptor.DecryptInteger(ctA))
+		require.Equal(t, (a+b)&mask, decryptor.DecryptInteger(eval.Add(ctA, ctB)))
+		require.Equal(t, (a-b)&mask, decryptor.DecryptInteger(eval.Sub(ctA, ctB)))
+		require.Equal(t, a == b, decryptor.Decrypt(eval.Equal(ctA, ctB)))
+		require.True(t, decryptor.Decrypt(eval.Equal(ctA, ctA)))
+		require.Equal(t, a < b, decryptor.Decrypt(eval.LessThan(ctA, ctB)))
+		require.Equal(t, a <= b, decryptor.Decrypt(eval.LessOrEqual(ctA, ctB)))
+
+		min, max := a, b
+		if b < a {
+			min, max = b, a
+		}
+		require.Equal(t, min, decryptor.DecryptInteger(eval.Min(ctA, ctB)))
+		require.Equal(t, max, decryptor.DecryptInteger(eval.Max(ctA, ctB)))
+	})
+
+	t.Run(testString(params, "Marshaller"), func(t *testing.T) {
+
+		data, err := params.MarshalBinary()
+		require.NoError(t, err)
+		var paramsNew Parameters
+		require.NoError(t, paramsNew.UnmarshalBinary(data))
+		require.True(t, params.Equals(paramsNew))
+
+		ct := encryptor.EncryptNew(true)
+		data, err = ct.MarshalBinary()
+		require.NoError(t, err)
+		ctNew := new(Ciphertext)
+		require.NoError(t, ctNew.UnmarshalBinary(data))
+		require.Equal(t, ct.Value, ctNew.Value)
+
+		data, err = evk.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, evk.GetDataLen(), len(data))
+		evkNew := new(EvaluationKey)
+		require.NoError(t, evkNew.UnmarshalBinary(data))
+		require.True(t, evk.SwkToLWE.Equals(evkNew.SwkToLWE))
+
+		// The deserialized key must evaluate the gates
+		evalNew := NewEvaluator(paramsNew, evkNew)
+		require.False(t, decryptor.Decrypt(evalNew.AndNew(ctNew, encryptor.EncryptNew(false))))
+		require.True(t, decryptor.Decrypt(evalNew.AndNew(ctNew, encryptor.EncryptNew(true))))
+	})
+}
@@ -0,0 +1,76 @@
+package boolean
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Ciphertext is an LWE sample (b, a) encrypting a bit, whose phase is b + <a, s> mod Q.
+// Value[0] stores b and Value[1:] stores the n coefficients of a.
+type Ciphertext struct {
+	Value []uint64
+}
+
+// NewCiphertext allocates a new Ciphertext with zero value.
+func NewCiphertext(params Parameters) (ct *Ciphertext) {
+	return &Ciphertext{Value: make([]uint64, params.N()+1)}
+}
+
+// NewTrivialCiphertext returns a noiseless encryption of bit (with a = 0), which can be used as a public constant in the gates.
+func NewTrivialCiphertext(params Parameters, bit bool) (ct *Ciphertext) {
+	ct = NewCiphertext(params)
+	ct.Value[0] = encode(params, bit)
+	return
+}
+
+// N returns the dimension of the LWE sample.
+func (ct *Ciphertext) N() int {
+	return len(ct.Value) - 1
+}
+
+// CopyNew creates a deep copy of the receiver ciphertext and returns it.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	return &Ciphertext{Value: append([]uint64{}, ct.Value...)}
+}
+
+// Copy copies the value of ctIn on the receiver ciphertext.
+func (ct *Ciphertext) Copy(ctIn *Ciphertext) {
+	copy(ct.Value, ctIn.Value)
+}
+
+// GetDataLen returns the length in bytes of the target Ciphertext.
+func (ct *Ciphertext) GetDataLen() int {
+	return 8 * len(ct.Value)
+}
+
+// MarshalBinary encodes the target Ciphertext on a slice of bytes.
+func (ct *Ciphertext) MarshalBinary() (data []byte, err error) {
+	data = make([]byte, ct.GetDataLen())
+	for i, c := range ct.Value {
+		binary.BigEndian.PutUint64(data[i<<3:], c)
+	}
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target Ciphertext.
+func (ct *Ciphertext) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 16 || len(data)&7 != 0 {
+		return fmt.Errorf("cannot UnmarshalBinary: invalid boolean.Ciphertext serialization")
+	}
+
+	ct.Value = make([]uint64, len(data)>>3)
+	for i := range ct.Value {
+		ct.Value[i] = binary.BigEndian.Uint64(data[i<<3:])
+	}
+
+	return
+}
+
+// encode returns the encoding +Q/8 (true) or -Q/8 (false) of bit.
+func encode(params Parameters, bit bool) uint64 {
+	if bit {
+		return params.Mu()
+	}
+	return params.Q() - params.Mu()
+}
@@ -0,0 +1,93 @@
+package boolean
+
+import (
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Encryptor is a struct to encrypt bits under the LWE secret.
+type Encryptor struct {
+	params    Parameters
+	encryptor rlwe.Encryptor
+	pt        *rlwe.Plaintext
+	ct        *rlwe.Ciphertext
+}
+
+// NewEncryptor creates a new Encryptor from the LWE secret sk.
+func NewEncryptor(params Parameters, sk *rlwe.SecretKey) *Encryptor {
+	return &Encryptor{
+		params:    params,
+		encryptor: rlwe.NewEncryptor(params.paramsLWE, sk),
+		pt:        rlwe.NewPlaintext(params.paramsLWE, 0),
+		ct:        rlwe.NewCiphertext(params.paramsLWE, 1, 0),
+	}
+}
+
+// EncryptNew encrypts bit and returns the result on a new Ciphertext.
+func (enc *Encryptor) EncryptNew(bit bool) (ct *Ciphertext) {
+	ct = NewCiphertext(enc.params)
+	enc.Encrypt(bit, ct)
+	return
+}
+
+// Encrypt encrypts bit and writes the result on ct.
+func (enc *Encryptor) Encrypt(bit bool, ct *Ciphertext) {
+
+	// Encrypts the bit on the constant coefficient of an RLWE sample of degree n
+	enc.pt.Value.Coeffs[0][0] = encode(enc.params, bit)
+	enc.encryptor.Encrypt(enc.pt, enc.ct)
+
+	extractConstantCoefficient(enc.params.Q(), enc.ct, ct)
+}
+
+// Decryptor is a struct to decrypt bits under the LWE secret.
+type Decryptor struct {
+	params Parameters
+	sk     []uint64
+}
+
+// NewDecryptor creates a new Decryptor from the LWE secret sk.
+func NewDecryptor(params Parameters, sk *rlwe.SecretKey) *Decryptor {
+
+	ringQ := params.paramsLWE.RingQ()
+
+	skPoly := ringQ.NewPolyLvl(0)
+	ringQ.InvNTTLvl(0, sk.Value.Q, skPoly)
+	ringQ.InvMFormLvl(0, skPoly, skPoly)
+
+	return &Decryptor{params: params, sk: skPoly.Coeffs[0]}
+}
+
+// Phase returns the phase b + <a, s> mod Q of ct.
+func (dec *Decryptor) Phase(ct *Ciphertext) (phase uint64) {
+
+	Q := dec.params.Q()
+	bredParams := dec.params.paramsLWE.RingQ().BredParams[0]
+
+	phase = ct.Value[0]
+	for j, s := range dec.sk {
+		phase = ring.CRed(phase+ring.BRed(ct.Value[j+1], s, Q, bredParams), Q)
+	}
+
+	return
+}
+
+// Decrypt decrypts ct and returns the bit, which is true if the phase is in [0, Q/2).
+func (dec *Decryptor) Decrypt(ct *Ciphertext) bool {
+	return dec.Phase(ct) < dec.params.Q()>>1
+}
+
+// extractConstantCoefficient extracts the LWE sample of the constant coefficient of the RLWE ciphertext ctIn,
+// which must be outside of the NTT domain, and writes it on ctOut.
+func extractConstantCoefficient(Q uint64, ctIn *rlwe.Ciphertext, ctOut *Ciphertext) {
+
+	c0, c1 := ctIn.Value[0].Coeffs[0], ctIn.Value[1].Coeffs[0]
+	n := len(c1)
+
+	// The constant coefficient of c1 * s is c1[0]s[0] - sum_{j>0} c1[n-j]s[j]
+	ctOut.Value[0] = c0[0]
+	ctOut.Value[1] = c1[0]
+	for j := 1; j < n; j++ {
+		ctOut.Value[j+1] = (Q - c1[n-j]) % Q
+	}
+}
@@ -0,0 +1,239 @@
+package boolean
+
+import (
+	"github.com/cipherflow-fhe/lattigo/rgsw/lut"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Evaluator is a struct to evaluate bootstrapped binary gates on encrypted bits.
+// The gates take ciphertexts of any error as inputs, as long as the error of their linear combination is smaller
+// than Q/8 (Q/16 for XOR and XNOR), and return ciphertexts with the error of a fresh bootstrapping, except MUX
+// whose output has the sum of the errors of two bootstrappings.
+type Evaluator struct {
+	params  Parameters
+	evk     *EvaluationKey
+	lutEval *lut.Evaluator
+	ksEval  *rlwe.Evaluator
+
+	lutPolyMap map[int]*ring.Poly
+
+	ctRLWE    *rlwe.Ciphertext // RLWE sample of degree n in the NTT domain
+	ctLUT     *rlwe.Ciphertext // RLWE sample of degree N in the NTT domain
+	ctLWE     *rlwe.Ciphertext // RLWE sample of degree n in the NTT domain
+	ctExtract *rlwe.Ciphertext // RLWE sample of degree n outside of the NTT domain
+
+	buff [2]*Ciphertext
+}
+
+// NewEvaluator creates a new Evaluator from the evaluation key evk.
+func NewEvaluator(params Parameters, evk *EvaluationKey) (eval *Evaluator) {
+
+	paramsLUT, paramsLWE := params.paramsLUT, params.paramsLWE
+
+	eval = &Evaluator{
+		params:    params,
+		evk:       evk,
+		lutEval:   lut.NewEvaluator(paramsLUT, paramsLWE, nil),
+		ksEval:    rlwe.NewEvaluator(paramsLUT, nil),
+		ctRLWE:    rlwe.NewCiphertextNTT(paramsLWE, 1, 0),
+		ctLUT:     rlwe.NewCiphertextNTT(paramsLUT, 1, 0),
+		ctLWE:     rlwe.NewCiphertextNTT(paramsLWE, 1, 0),
+		ctExtract: rlwe.NewCiphertext(paramsLWE, 1, 0),
+		buff:      [2]*Ciphertext{NewCiphertext(params), NewCiphertext(params)},
+	}
+
+	// Test polynomial of the sign: the constant coefficient of -mu * sum X^i * X^phase
+	// is +mu if the phase is in (0, N) and -mu if it is in [N, 2N).
+	ringQ := paramsLUT.RingQ()
+	testPoly := ringQ.NewPoly()
+	for i := range testPoly.Coeffs[0] {
+		testPoly.Coeffs[0][i] = params.Q() - params.Mu()
+	}
+	ringQ.NTT(testPoly, testPoly)
+
+	eval.lutPolyMap = map[int]*ring.Poly{0: testPoly}
+
+	return
+}
+
+// Bootstrap evaluates the sign of the phase of ctIn and writes on ctOut a fresh encryption of true if it is in (0, Q/2)
+// and of false otherwise.
+func (eval *Evaluator) Bootstrap(ctIn, ctOut *Ciphertext) {
+
+	params := eval.params
+	paramsLUT, paramsLWE := params.paramsLUT, params.paramsLWE
+	ringQLWE := paramsLWE.RingQ()
+	Q := params.Q()
+	n := params.N()
+
+	// Embeds the LWE sample in the constant coefficient of an RLWE sample of degree n,
+	// such that c1[0]s[0] - sum_{j>0} c1[n-j]s[j] = <a, s>
+	c0, c1 := eval.ctRLWE.Value[0].Coeffs[0], eval.ctRLWE.Value[1].Coeffs[0]
+	for j := range c0 {
+		c0[j] = 0
+	}
+	c0[0] = ctIn.Value[0]
+	c1[0] = ctIn.Value[1]
+	for j := 1; j < n; j++ {
+		c1[n-j] = (Q - ctIn.Value[j+1]) % Q
+	}
+
+	ringQLWE.NTTLvl(0, eval.ctRLWE.Value[0], eval.ctRLWE.Value[0])
+	ringQLWE.NTTLvl(0, eval.ctRLWE.Value[1], eval.ctRLWE.Value[1])
+
+	// Blind rotation of the test polynomial by the phase
+	ct := eval.lutEval.Evaluate(eval.ctRLWE, eval.lutPolyMap, eval.evk.BlindRotationKey)[0]
+
+	// Key-switch to the LWE secret and switch of the ring degree to n
+	if paramsLWE.N() == paramsLUT.N() {
+		eval.ksEval.SwitchKeys(ct, eval.evk.SwkToLWE, eval.ctLWE)
+	} else {
+		eval.ksEval.SwitchKeys(ct, eval.evk.SwkToLWE, eval.ctLUT)
+		rlwe.SwitchCiphertextRingDegreeNTT(eval.ctLUT, ringQLWE, paramsLUT.RingQ(), eval.ctLWE)
+	}
+
+	ringQLWE.InvNTTLvl(0, eval.ctLWE.Value[0], eval.ctExtract.Value[0])
+	ringQLWE.InvNTTLvl(0, eval.ctLWE.Value[1], eval.ctExtract.Value[1])
+
+	extractConstantCoefficient(Q, eval.ctExtract, ctOut)
+}
+
+// Not evaluates NOT(ct) and writes the result on ctOut. It does not require a bootstrapping.
+func (eval *Evaluator) Not(ct, ctOut *Ciphertext) {
+	Q := eval.params.Q()
+	for i, c := range ct.Value {
+		ctOut.Value[i] = (Q - c) % Q
+	}
+}
+
+// NotNew evaluates NOT(ct) and returns the result on a new ciphertext.
+func (eval *Evaluator) NotNew(ct *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params)
+	eval.Not(ct, ctOut)
+	return
+}
+
+// And evaluates AND(ct0, ct1) and writes the result on ctOut.
+func (eval *Evaluator) And(ct0, ct1, ctOut *Ciphertext) {
+	eval.gate(ct0, 1, ct1, 1, eval.params.Q()-eval.params.Mu(), ctOut)
+}
+
+// AndNew evaluates AND(ct0, ct1) and returns the result on a new ciphertext.
+func (eval *Evaluator) AndNew(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params)
+	eval.And(ct0, ct1, ctOut)
+	return
+}
+
+// Or evaluates OR(ct0, ct1) and writes the result on ctOut.
+func (eval *Evaluator) Or(ct0, ct1, ctOut *Ciphertext) {
+	eval.gate(ct0, 1, ct1, 1, eval.params.Mu(), ctOut)
+}
+
+// OrNew evaluates OR(ct0, ct1) and returns the result on a new ciphertext.
+func (eval *Evaluator) OrNew(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params)
+	eval.Or(ct0, ct1, ctOut)
+	return
+}
+
+// Nand evaluates NAND(ct0, ct1) and writes the result on ctOut.
+func (eval *Evaluator) Nand(ct0, ct1, ctOut *Ciphertext) {
+	eval.gate(ct0, -1, ct1, -1, eval.params.Mu(), ctOut)
+}
+
+// NandNew evaluates NAND(ct0, ct1) and returns the result on a new ciphertext.
+func (eval *Evaluator) NandNew(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params)
+	eval.Nand(ct0, ct1, ctOut)
+	return
+}
+
+// Nor evaluates NOR(ct0, ct1) and writes the result on ctOut.
+func (eval *Evaluator) Nor(ct0, ct1, ctOut *Ciphertext) {
+	eval.gate(ct0, -1, ct1, -1, eval.params.Q()-eval.params.Mu(), ctOut)
+}
+
+// NorNew evaluates NOR(ct0, ct1) and returns the result on a new ciphertext.
+func (eval *Evaluator) NorNew(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params)
+	eval.Nor(ct0, ct1, ctOut)
+	return
+}
+
+// Xor evaluates XOR(ct0, ct1) and writes the result on ctOut.
+func (eval *Evaluator) Xor(ct0, ct1, ctOut *Ciphertext) {
+	eval.gate(ct0, 2, ct1, 2, 2*eval.params.Mu(), ctOut)
+}
+
+// XorNew evaluates XOR(ct0, ct1) and returns the result on a new ciphertext.
+func (eval *Evaluator) XorNew(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params)
+	eval.Xor(ct0, ct1, ctOut)
+	return
+}
+
+// Xnor evaluates XNOR(ct0, ct1) and writes the result on ctOut.
+func (eval *Evaluator) Xnor(ct0, ct1, ctOut *Ciphertext) {
+	eval.gate(ct0, -2, ct1, -2, eval.params.Q()-2*eval.params.Mu(), ctOut)
+}
+
+// XnorNew evaluates XNOR(ct0, ct1) and returns the result on a new ciphertext.
+func (eval *Evaluator) XnorNew(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params)
+	eval.Xnor(ct0, ct1, ctOut)
+	return
+}
+
+// Mux evaluates MUX(ctSel, ct1, ct0), that is ct1 if ctSel encrypts true and ct0 otherwise, and writes the result on ctOut.
+// It requires two bootstrappings: AND(ctSel, ct1) and AND(NOT(ctSel), ct0) cannot be both true, so their sum plus Q/8
+// is an encryption of their OR.
+func (eval *Evaluator) Mux(ctSel, ct1, ct0, ctOut *Ciphertext) {
+
+	Q := eval.params.Q()
+	mu := eval.params.Mu()
+
+	eval.gate(ctSel, 1, ct1, 1, Q-mu, eval.buff[1])
+	eval.gate(ctSel, -1, ct0, 1, Q-mu, ctOut)
+
+	for i := range ctOut.Value {
+		ctOut.Value[i] = ring.CRed(ctOut.Value[i]+eval.buff[1].Value[i], Q)
+	}
+
+	ctOut.Value[0] = ring.CRed(ctOut.Value[0]+mu, Q)
+}
+
+// MuxNew evaluates MUX(ctSel, ct1, ct0), that is ct1 if ctSel encrypts true and ct0 otherwise, and returns the result on a new ciphertext.
+func (eval *Evaluator) MuxNew(ctSel, ct1, ct0 *Ciphertext) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params)
+	eval.Mux(ctSel, ct1, ct0, ctOut)
+	return
+}
+
+// gate bootstraps k0 * ct0 + k1 * ct1 + constant and writes the result on ctOut.
+func (eval *Evaluator) gate(ct0 *Ciphertext, k0 int, ct1 *Ciphertext, k1 int, constant uint64, ctOut *Ciphertext) {
+
+	if ct0.N() != eval.params.N() || ct1.N() != eval.params.N() {
+		panic("cannot gate: input ciphertexts do not match the dimension of the parameters")
+	}
+
+	Q := eval.params.Q()
+
+	buff := eval.buff[0]
+	for i := range buff.Value {
+		buff.Value[i] = ring.CRed(mulBySmallInt(ct0.Value[i], k0, Q)+mulBySmallInt(ct1.Value[i], k1, Q), Q)
+	}
+
+	buff.Value[0] = ring.CRed(buff.Value[0]+constant, Q)
+
+	eval.Bootstrap(buff, ctOut)
+}
+
+// mulBySmallInt returns k * a mod Q for a small integer k.
+func mulBySmallInt(a uint64, k int, Q uint64) uint64 {
+	if k < 0 {
+		return (Q - a*uint64(-k)%Q) % Q
+	}
+	return a * uint64(k) % Q
+}
@@ -0,0 +1,142 @@
+package boolean
+
+// Integer is an encrypted unsigned integer, given by the ciphertexts of its bits in little-endian order.
+// The arithmetic on integers is modulo 2^len(Integer).
+type Integer []*Ciphertext
+
+// EncryptIntegerNew encrypts the bits-bit unsigned integer value and returns the result on a new Integer.
+func (enc *Encryptor) EncryptIntegerNew(value uint64, bits int) (ct Integer) {
+	ct = make(Integer, bits)
+	for i := range ct {
+		ct[i] = enc.EncryptNew((value>>i)&1 == 1)
+	}
+	return
+}
+
+// DecryptInteger decrypts ct and returns the unsigned integer.
+func (dec *Decryptor) DecryptInteger(ct Integer) (value uint64) {
+	for i := range ct {
+		if dec.Decrypt(ct[i]) {
+			value |= 1 << i
+		}
+	}
+	return
+}
+
+// Add evaluates ct0 + ct1 mod 2^len(ct0) with a ripple-carry adder and returns the result on a new Integer.
+// It requires four bootstrappings per bit.
+func (eval *Evaluator) Add(ct0, ct1 Integer) (ctOut Integer) {
+	return eval.addWithCarry(ct0, ct1, NewTrivialCiphertext(eval.params, false), false)
+}
+
+// Sub evaluates ct0 - ct1 mod 2^len(ct0), as ct0 + NOT(ct1) + 1, and returns the result on a new Integer.
+// It requires four bootstrappings per bit.
+func (eval *Evaluator) Sub(ct0, ct1 Integer) (ctOut Integer) {
+	return eval.addWithCarry(ct0, ct1, NewTrivialCiphertext(eval.params, true), true)
+}
+
+// Equal evaluates ct0 == ct1 and returns the result on a new Ciphertext.
+// It requires two bootstrappings per bit.
+func (eval *Evaluator) Equal(ct0, ct1 Integer) (ctOut *Ciphertext) {
+
+	checkIntegers(ct0, ct1)
+
+	ctOut = eval.XnorNew(ct0[0], ct1[0])
+
+	tmp := NewCiphertext(eval.params)
+	for i := 1; i < len(ct0); i++ {
+		eval.Xnor(ct0[i], ct1[i], tmp)
+		eval.And(ctOut, tmp, ctOut)
+	}
+
+	return
+}
+
+// LessThan evaluates ct0 < ct1 on unsigned integers and returns the result on a new Ciphertext.
+// It requires three bootstrappings per bit.
+func (eval *Evaluator) LessThan(ct0, ct1 Integer) (ctOut *Ciphertext) {
+
+	checkIntegers(ct0, ct1)
+
+	// From the least to the most significant bit, the comparison is decided by
+	// the bit of ct1 if the bits differ and is unchanged otherwise.
+	ctOut = NewTrivialCiphertext(eval.params, false)
+
+	diff := NewCiphertext(eval.params)
+	for i := range ct0 {
+		eval.Xor(ct0[i], ct1[i], diff)
+		eval.Mux(diff, ct1[i], ctOut, ctOut)
+	}
+
+	return
+}
+
+// LessOrEqual evaluates ct0 <= ct1 on unsigned integers, as NOT(ct1 < ct0), and returns the result on a new Ciphertext.
+// It requires three bootstrappings per bit.
+func (eval *Evaluator) LessOrEqual(ct0, ct1 Integer) (ctOut *Ciphertext) {
+	ctOut = eval.LessThan(ct1, ct0)
+	eval.Not(ctOut, ctOut)
+	return
+}
+
+// Select evaluates ct1 if ctSel encrypts true and ct0 otherwise, and returns the result on a new Integer.
+// It requires two bootstrappings per bit.
+func (eval *Evaluator) Select(ctSel *Ciphertext, ct1, ct0 Integer) (ctOut Integer) {
+
+	checkIntegers(ct0, ct1)
+
+	ctOut = make(Integer, len(ct0))
+	for i := range ctOut {
+		ctOut[i] = eval.MuxNew(ctSel, ct1[i], ct0[i])
+	}
+
+	return
+}
+
+// Min returns the minimum of the unsigned integers ct0 and ct1 on a new Integer.
+// It requires five bootstrappings per bit.
+func (eval *Evaluator) Min(ct0, ct1 Integer) (ctOut Integer) {
+	return eval.Select(eval.LessThan(ct0, ct1), ct0, ct1)
+}
+
+// Max returns the maximum of the unsigned integers ct0 and ct1 on a new Integer.
+// It requires five bootstrappings per bit.
+func (eval *Evaluator) Max(ct0, ct1 Integer) (ctOut Integer) {
+	return eval.Select(eval.LessThan(ct0, ct1), ct1, ct0)
+}
+
+// addWithCarry evaluates ct0 + ct1 + carry, or ct0 + NOT(ct1) + carry if negate is true, mod 2^len(ct0).
+func (eval *Evaluator) addWithCarry(ct0, ct1 Integer, carry *Ciphertext, negate bool) (ctOut Integer) {
+
+	checkIntegers(ct0, ct1)
+
+	ctOut = make(Integer, len(ct0))
+
+	b := NewCiphertext(eval.params)
+	sum := NewCiphertext(eval.params)
+	for i := range ct0 {
+
+		if negate {
+			eval.Not(ct1[i], b)
+		} else {
+			b.Copy(ct1[i])
+		}
+
+		// Full adder: s = a XOR b XOR c and c' = b if a XOR b is false and c otherwise
+		eval.Xor(ct0[i], b, sum)
+
+		ctOut[i] = eval.XorNew(sum, carry)
+
+		if i != len(ct0)-1 {
+			eval.Mux(sum, carry, b, carry)
+		}
+	}
+
+	return
+}
+
+func checkIntegers(ct0, ct1 Integer) {
+	if len(ct0) == 0 || len(ct0) != len(ct1) {
+		panic("cannot evaluate: integers must have the same non-zero number of bits")
+	}
+}
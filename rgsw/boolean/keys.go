@@ -0,0 +1,132 @@
+package boolean
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/rgsw"
+	"github.com/cipherflow-fhe/lattigo/rgsw/lut"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// EvaluationKey is a struct for the public keys of the gate bootstrapping.
+type EvaluationKey struct {
+	BlindRotationKey lut.EvaluationKey  // RGSW encryptions of the LWE secret under the secret of the blind rotation.
+	SwkToLWE         *rlwe.SwitchingKey // Switching key from the secret of the blind rotation to the LWE secret.
+}
+
+// KeyGenerator is a struct to generate the LWE secret and the evaluation key.
+type KeyGenerator struct {
+	params Parameters
+}
+
+// NewKeyGenerator creates a new KeyGenerator.
+func NewKeyGenerator(params Parameters) *KeyGenerator {
+	return &KeyGenerator{params: params}
+}
+
+// GenSecretKey generates a new LWE secret.
+func (kgen *KeyGenerator) GenSecretKey() (sk *rlwe.SecretKey) {
+	return rlwe.NewKeyGenerator(kgen.params.paramsLWE).GenSecretKey()
+}
+
+// GenEvaluationKey generates the evaluation key of the LWE secret sk. It generates a new secret for the
+// blind rotation, which is only used to generate the key.
+func (kgen *KeyGenerator) GenEvaluationKey(sk *rlwe.SecretKey) (evk *EvaluationKey) {
+
+	paramsLUT := kgen.params.paramsLUT
+
+	kgenLUT := rlwe.NewKeyGenerator(paramsLUT)
+	skLUT := kgenLUT.GenSecretKey()
+
+	return &EvaluationKey{
+		BlindRotationKey: lut.GenEvaluationKey(paramsLUT, skLUT, kgen.params.paramsLWE, sk),
+		SwkToLWE:         kgenLUT.GenSwitchingKey(skLUT, sk),
+	}
+}
+
+// GetDataLen returns the length in bytes of the target EvaluationKey.
+func (evk *EvaluationKey) GetDataLen() (dataLen int) {
+
+	dataLen = 8
+
+	for i := range evk.BlindRotationKey.SkPos {
+		for _, ct := range []*rgsw.Ciphertext{evk.BlindRotationKey.SkPos[i], evk.BlindRotationKey.SkNeg[i]} {
+			dataLen += ct.Value[0].GetDataLen(true) + ct.Value[1].GetDataLen(true)
+		}
+	}
+
+	return dataLen + evk.SwkToLWE.GetDataLen(true)
+}
+
+// MarshalBinary encodes the target EvaluationKey on a slice of bytes.
+func (evk *EvaluationKey) MarshalBinary() (data []byte, err error) {
+
+	data = make([]byte, evk.GetDataLen())
+
+	binary.BigEndian.PutUint64(data, uint64(len(evk.BlindRotationKey.SkPos)))
+
+	pointer := 8
+
+	for i := range evk.BlindRotationKey.SkPos {
+		for _, ct := range []*rgsw.Ciphertext{evk.BlindRotationKey.SkPos[i], evk.BlindRotationKey.SkNeg[i]} {
+			for j := range ct.Value {
+				if pointer, err = ct.Value[j].Encode(pointer, data); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if _, err = evk.SwkToLWE.Encode(pointer, data); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target EvaluationKey.
+func (evk *EvaluationKey) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 8 {
+		return fmt.Errorf("cannot UnmarshalBinary: invalid boolean.EvaluationKey serialization")
+	}
+
+	n := int(binary.BigEndian.Uint64(data))
+
+	pointer := 8
+
+	evk.BlindRotationKey.SkPos = make([]*rgsw.Ciphertext, n)
+	evk.BlindRotationKey.SkNeg = make([]*rgsw.Ciphertext, n)
+
+	var inc int
+	for i := 0; i < n; i++ {
+
+		evk.BlindRotationKey.SkPos[i] = new(rgsw.Ciphertext)
+		evk.BlindRotationKey.SkNeg[i] = new(rgsw.Ciphertext)
+
+		for _, ct := range []*rgsw.Ciphertext{evk.BlindRotationKey.SkPos[i], evk.BlindRotationKey.SkNeg[i]} {
+			for j := range ct.Value {
+				if pointer >= len(data) {
+					return fmt.Errorf("cannot UnmarshalBinary: invalid boolean.EvaluationKey serialization")
+				}
+
+				if inc, err = ct.Value[j].Decode(data[pointer:]); err != nil {
+					return fmt.Errorf("cannot UnmarshalBinary: %w", err)
+				}
+				pointer += inc
+			}
+		}
+	}
+
+	if pointer >= len(data) {
+		return fmt.Errorf("cannot UnmarshalBinary: invalid boolean.EvaluationKey serialization")
+	}
+
+	evk.SwkToLWE = &rlwe.SwitchingKey{NMFormBits: 64}
+	if _, err = evk.SwkToLWE.Decode(data[pointer:]); err != nil {
+		return fmt.Errorf("cannot UnmarshalBinary: %w", err)
+	}
+
+	return
+}
@@ -0,0 +1,140 @@
+// Package boolean implements TFHE-style boolean gate bootstrapping on top of the RGSW blind rotation of the rgsw/lut package.
+//
+// Bits are encrypted as LWE samples modulo Q, with true encoded as +Q/8 and false as -Q/8. Each binary gate is a linear
+// combination of its input samples followed by a bootstrapping, which evaluates the sign of the phase with a blind rotation
+// in the ring of degree N of the LUT parameters, key-switches the output back to the LWE secret of dimension n and extracts
+// the constant coefficient as a fresh LWE sample. The error of the outputs of the gates does not depend on the error of the
+// inputs, so that circuits of any depth can be evaluated. The package also implements a small arithmetic layer on unsigned
+// integers, given by their encrypted bits.
+package boolean
+
+import (
+	"fmt"
+
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+	"github.com/cipherflow-fhe/lattigo/utils"
+)
+
+// ParametersLiteral is a literal representation of the boolean parameters.
+type ParametersLiteral struct {
+	LogN     int     // Log2 of the ring degree N of the blind rotation
+	Q        uint64  // Modulus of the LWE samples and of the blind rotation, which must be NTT friendly for 2N
+	Pow2Base int     // Log2 of the base of the gadget decomposition of the blind rotation and of the key-switching
+	LogNLWE  int     // Log2 of the dimension n of the LWE samples, which must be smaller or equal to LogN
+	HLWE     int     // Hamming weight of the LWE secret. If 0, it is set to n/2.
+	Sigma    float64 // Standard deviation of the error. If 0, it is set to rlwe.DefaultSigma.
+}
+
+// ExampleParameters is an example of parameters with N=1024, n=512 and Q=0x7fff801 (27 bits). They are intended for
+// experiments and have not been selected for a specific security level.
+var ExampleParameters = ParametersLiteral{
+	LogN:     10,
+	Q:        0x7fff801,
+	Pow2Base: 6,
+	LogNLWE:  9,
+}
+
+// Parameters is a struct for the parameters of the boolean gate bootstrapping.
+type Parameters struct {
+	paramsLUT rlwe.Parameters
+	paramsLWE rlwe.Parameters
+}
+
+// NewParametersFromLiteral instantiates a set of Parameters from a ParametersLiteral.
+func NewParametersFromLiteral(pl ParametersLiteral) (params Parameters, err error) {
+
+	if pl.LogNLWE < 1 || pl.LogNLWE > pl.LogN {
+		return params, fmt.Errorf("cannot NewParametersFromLiteral: LogNLWE must be between 1 and LogN = %d", pl.LogN)
+	}
+
+	if pl.Pow2Base < 1 {
+		return params, fmt.Errorf("cannot NewParametersFromLiteral: Pow2Base must be positive")
+	}
+
+	if params.paramsLUT, err = rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN:     pl.LogN,
+		Q:        []uint64{pl.Q},
+		Pow2Base: pl.Pow2Base,
+		Sigma:    pl.Sigma,
+	}); err != nil {
+		return params, fmt.Errorf("cannot NewParametersFromLiteral: %w", err)
+	}
+
+	if params.paramsLWE, err = rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN:     pl.LogNLWE,
+		Q:        []uint64{pl.Q},
+		Pow2Base: pl.Pow2Base,
+		H:        pl.HLWE,
+		Sigma:    pl.Sigma,
+	}); err != nil {
+		return params, fmt.Errorf("cannot NewParametersFromLiteral: %w", err)
+	}
+
+	return
+}
+
+// LUTParameters returns the RLWE parameters of the blind rotation.
+func (p Parameters) LUTParameters() rlwe.Parameters {
+	return p.paramsLUT
+}
+
+// LWEParameters returns the RLWE parameters whose ring degree is the dimension of the LWE samples.
+func (p Parameters) LWEParameters() rlwe.Parameters {
+	return p.paramsLWE
+}
+
+// N returns the dimension n of the LWE samples.
+func (p Parameters) N() int {
+	return p.paramsLWE.N()
+}
+
+// Q returns the modulus of the LWE samples.
+func (p Parameters) Q() uint64 {
+	return p.paramsLWE.Q()[0]
+}
+
+// Mu returns the encoding Q/8 of the bit true. The bit false is encoded as -Q/8.
+func (p Parameters) Mu() uint64 {
+	return p.Q() >> 3
+}
+
+// Equals compares two sets of parameters for equality.
+func (p Parameters) Equals(other Parameters) bool {
+	return p.paramsLUT.Equals(other.paramsLUT) && p.paramsLWE.Equals(other.paramsLWE)
+}
+
+// MarshalBinary returns a []byte representation of the parameter set.
+func (p Parameters) MarshalBinary() (data []byte, err error) {
+
+	var dataLUT, dataLWE []byte
+	if dataLUT, err = p.paramsLUT.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	if dataLWE, err = p.paramsLWE.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	b := utils.NewBuffer(make([]byte, 0, 1+len(dataLUT)+len(dataLWE)))
+	b.WriteUint8(uint8(len(dataLUT)))
+	b.WriteUint8Slice(dataLUT)
+	b.WriteUint8Slice(dataLWE)
+
+	return b.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a []byte into a parameter set struct.
+func (p *Parameters) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return fmt.Errorf("invalid boolean.Parameters serialization")
+	}
+
+	dataLUT, dataLWE := data[1:1+int(data[0])], data[1+int(data[0]):]
+
+	if err = p.paramsLUT.UnmarshalBinary(dataLUT); err != nil {
+		return err
+	}
+
+	return p.paramsLWE.UnmarshalBinary(dataLWE)
+}
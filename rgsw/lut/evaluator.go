@@ -153,6 +153,75 @@ func (eval *Evaluator) EvaluateAndRepack(ct *rlwe.Ciphertext, lutPolyWihtSlotInd
 // lutKey : lut.Key
 // Returns a map[slot_index] -> LUT(ct[slot_index])
 func (eval *Evaluator) Evaluate(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[int]*ring.Poly, key EvaluationKey) (res map[int]*rlwe.Ciphertext) {
+	return eval.evaluate(ct, lutPolyWihtSlotIndex, 1, key)
+}
+
+// EvaluateMultiValueAndRepack extracts on the fly LWE samples, evaluates the multi-value LUTs created with InitMultiValueLUT
+// on the LWE and repacks the outputs of each function into a single rlwe.Ciphertext.
+// ct : a rlwe Ciphertext with coefficient encoded values at level 0
+// lutPolyWihtSlotIndex : a map with [slot_index] -> multi-value LUT of nbLUT functions
+// repackIndex : a map with [slot_index_have] -> slot_index_want
+// lutKey : LUTKey
+// Returns a slice with a *rlwe.Ciphertext per function
+func (eval *Evaluator) EvaluateMultiValueAndRepack(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[int]*ring.Poly, nbLUT int, repackIndex map[int]int, key EvaluationKey) (res []*rlwe.Ciphertext) {
+	cts := eval.EvaluateMultiValue(ct, lutPolyWihtSlotIndex, nbLUT, key)
+
+	res = make([]*rlwe.Ciphertext, nbLUT)
+	for j := range res {
+
+		ciphertexts := make(map[int]*rlwe.Ciphertext)
+
+		for i := range cts {
+			ciphertexts[repackIndex[i]] = cts[i][j]
+		}
+
+		res[j] = eval.MergeRLWE(ciphertexts)
+	}
+
+	return
+}
+
+// EvaluateMultiValue extracts on the fly LWE samples and evaluates the multi-value LUTs created with InitMultiValueLUT on the LWE,
+// with a single blind rotation per LWE sample for all the functions packed in the LUT. The LWE samples are switched modulo 2N/K
+// instead of 2N, where K is the packing factor of the LUT, and their error must be K times smaller than for Evaluate.
+// ct : a rlwe Ciphertext with coefficient encoded values at level 0
+// lutPolyWihtSlotIndex : a map with [slot_index] -> multi-value LUT of nbLUT functions
+// lutKey : lut.Key
+// Returns a map[slot_index] -> [LUT_0(ct[slot_index]), ..., LUT_{nbLUT-1}(ct[slot_index])]
+func (eval *Evaluator) EvaluateMultiValue(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[int]*ring.Poly, nbLUT int, key EvaluationKey) (res map[int][]*rlwe.Ciphertext) {
+
+	K := MultiValuePackingFactor(nbLUT)
+
+	if K > eval.paramsLUT.N() {
+		panic("cannot EvaluateMultiValue: the number of functions cannot be larger than N")
+	}
+
+	ringQ := eval.paramsLUT.RingQ()
+	twoN := 2 * ringQ.N
+
+	accs := eval.evaluate(ct, lutPolyWihtSlotIndex, K, key)
+
+	res = make(map[int][]*rlwe.Ciphertext, len(accs))
+
+	for index, acc := range accs {
+
+		res[index] = make([]*rlwe.Ciphertext, nbLUT)
+		res[index][0] = acc
+
+		// The output of the j-th function is on the j-th coefficient: LUT_j = acc * X^{-j} = acc * (X^{2N-j} - 1) + acc
+		for j := 1; j < nbLUT; j++ {
+			res[index][j] = acc.CopyNew()
+			for i := range acc.Value {
+				ringQ.MulCoeffsMontgomeryAndAddLvl(acc.Level(), acc.Value[i], eval.xPowMinusOne[twoN-j].Q, res[index][j].Value[i])
+			}
+		}
+	}
+
+	return
+}
+
+// evaluate evaluates the LUTs on the LWE samples switched modulo 2N/K and scaled by K, and returns the accumulators.
+func (eval *Evaluator) evaluate(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[int]*ring.Poly, K int, key EvaluationKey) (res map[int]*rlwe.Ciphertext) {
 
 	bRLWEMod2N := eval.poolMod2N[0]
 	aRLWEMod2N := eval.poolMod2N[1]
@@ -170,7 +239,7 @@ func (eval *Evaluator) Evaluate(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[in
 	ringQLWE.InvNTTLvl(ct.Level(), ct.Value[1], acc.Value[1])
 
 	// Switch modulus from Q to 2N
-	eval.ModSwitchRLWETo2NLvl(ct.Level(), acc.Value[1], acc.Value[1])
+	eval.modSwitchRLWETo2NLvl(ct.Level(), acc.Value[1], acc.Value[1], K)
 
 	// Conversion from Convolution(a, sk) to DotProd(a, sk) for LWE decryption.
 	// Copy coefficients multiplied by X^{N-1} in reverse order:
@@ -182,7 +251,7 @@ func (eval *Evaluator) Evaluate(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[in
 		tmp0[j] = -tmp1[ringQLWE.N-j] & mask
 	}
 
-	eval.ModSwitchRLWETo2NLvl(ct.Level(), acc.Value[0], bRLWEMod2N)
+	eval.modSwitchRLWETo2NLvl(ct.Level(), acc.Value[0], bRLWEMod2N, K)
 
 	levelQ := key.SkPos[0].LevelQ()
 	levelP := key.SkPos[0].LevelP()
@@ -227,13 +296,18 @@ func (eval *Evaluator) Evaluate(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[in
 
 // ModSwitchRLWETo2NLvl applies round(x * 2N / Q) to the coefficients of polQ and returns the result on pol2N.
 func (eval *Evaluator) ModSwitchRLWETo2NLvl(level int, polQ *ring.Poly, pol2N *ring.Poly) {
+	eval.modSwitchRLWETo2NLvl(level, polQ, pol2N, 1)
+}
+
+// modSwitchRLWETo2NLvl applies round(x * 2N / (Q * K)) * K to the coefficients of polQ and returns the result on pol2N.
+func (eval *Evaluator) modSwitchRLWETo2NLvl(level int, polQ *ring.Poly, pol2N *ring.Poly, K int) {
 	coeffsBigint := make([]*big.Int, len(polQ.Coeffs[0]))
 
 	ringQ := eval.paramsLWE.RingQ()
 
 	ringQ.PolyToBigintLvl(level, polQ, 1, coeffsBigint)
 
-	QBig := ring.NewUint(1)
+	QBig := ring.NewUint(uint64(K))
 	for i := 0; i < level+1; i++ {
 		QBig.Mul(QBig, ring.NewUint(ringQ.Modulus[i]))
 	}
@@ -244,6 +318,6 @@ func (eval *Evaluator) ModSwitchRLWETo2NLvl(level int, polQ *ring.Poly, pol2N *r
 	for i := 0; i < ringQ.N; i++ {
 		coeffsBigint[i].Mul(coeffsBigint[i], twoNBig)
 		ring.DivRound(coeffsBigint[i], QBig, coeffsBigint[i])
-		tmp[i] = coeffsBigint[i].Uint64() & (twoN - 1)
+		tmp[i] = (coeffsBigint[i].Uint64() * uint64(K)) & (twoN - 1)
 	}
 }
@@ -9,6 +9,13 @@ import (
 // Inputs to the LUT evaluation are assumed to have been normalized with the change of basis (2*x - a - b)/(b-a).
 // Interval [a, b] should take into account the "drift" of the value x, caused by the change of modulus from Q to 2N.
 func InitLUT(g func(x float64) (y float64), scale float64, ringQ *ring.Ring, a, b float64) (F *ring.Poly) {
+	F = initLUT(g, scale, ringQ, a, b)
+	ringQ.NTT(F, F)
+	return
+}
+
+// initLUT returns the LUT polynomial of InitLUT outside of the NTT domain.
+func initLUT(g func(x float64) (y float64), scale float64, ringQ *ring.Ring, a, b float64) (F *ring.Poly) {
 	F = ringQ.NewPoly()
 	Q := ringQ.Modulus
 
@@ -28,7 +35,76 @@ func InitLUT(g func(x float64) (y float64), scale float64, ringQ *ring.Ring, a,
 		}
 	}
 
+	return
+}
+
+// InitMultiValueLUT takes the functions gs and creates a single LUT polynomial packing the LUTs of all the functions in the
+// interval [a, b], to be evaluated with EvaluateMultiValue, which returns the outputs of all the functions with a single
+// blind rotation. The LUTs are interleaved with a packing factor K, the smallest power of two larger or equal to len(gs),
+// which must not be larger than N: the resolution of each LUT is reduced to 2K/N, instead of 2/N for InitLUT.
+// As for InitLUT, the inputs are assumed to have been normalized with the change of basis (2*x - a - b)/(b-a).
+func InitMultiValueLUT(gs []func(x float64) (y float64), scale float64, ringQ *ring.Ring, a, b float64) (F *ring.Poly) {
+
+	K := MultiValuePackingFactor(len(gs))
+
+	if K > ringQ.N {
+		panic("cannot InitMultiValueLUT: the number of functions cannot be larger than N")
+	}
+
+	lutPolys := make([]*ring.Poly, len(gs))
+	for j := range gs {
+		lutPolys[j] = initLUT(gs[j], scale, ringQ, a, b)
+	}
+
+	N := ringQ.N
+	twoN := 2 * N
+
+	F = ringQ.NewPoly()
+
+	for i, qi := range ringQ.Modulus {
+
+		// Constant coefficient of the j-th LUT polynomial times X^t, for 0 <= t < 2N.
+		lutTimesMonomial := func(j, t int) uint64 {
+			coeffs := lutPolys[j].Coeffs[i]
+			switch {
+			case t == 0:
+				return coeffs[0]
+			case t < N:
+				return (qi - coeffs[N-t]) % qi
+			case t == N:
+				return (qi - coeffs[0]) % qi
+			default:
+				return coeffs[twoN-t]
+			}
+		}
+
+		// The constant coefficient of F * X^t must be the one of the j-th LUT polynomial times X^{t+j},
+		// where t+j is the closest multiple of K larger or equal to t, such that, for an input
+		// phase K*m, the j-th coefficient of F * X^{K*m} is the output of the j-th LUT.
+		outputOf := func(t int) uint64 {
+			j := (K - t%K) % K
+			if j >= len(gs) {
+				return 0
+			}
+			return lutTimesMonomial(j, (t+j)%twoN)
+		}
+
+		// The constant coefficient of F * X^{N-k} is -F[k]
+		F.Coeffs[i][0] = outputOf(0)
+		for k := 1; k < N; k++ {
+			F.Coeffs[i][k] = (qi - outputOf(N-k)) % qi
+		}
+	}
+
 	ringQ.NTT(F, F)
 
 	return
 }
+
+// MultiValuePackingFactor returns the packing factor of InitMultiValueLUT for nbLUT functions, which is the smallest
+// power of two larger or equal to nbLUT.
+func MultiValuePackingFactor(nbLUT int) (K int) {
+	for K = 1; K < nbLUT; K <<= 1 {
+	}
+	return
+}
@@ -25,6 +25,7 @@ func testString(params rlwe.Parameters, opname string) string {
 func TestLUT(t *testing.T) {
 	for _, testSet := range []func(t *testing.T){
 		testLUT,
+		testMultiValueLUT,
 	} {
 		testSet(t)
 		runtime.GC()
@@ -148,3 +149,122 @@ func testLUT(t *testing.T) {
 		}
 	})
 }
+
+func testMultiValueLUT(t *testing.T) {
+
+	// RLWE parameters of the LUT, with an auxiliary modulus P to reduce the error of the repacking
+	// N=1024, Q=0x7fff801, P=0x4000026001
+	paramsLUT, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN: 10,
+		Q:    []uint64{0x7fff801},
+		P:    []uint64{0x4000026001},
+	})
+
+	assert.Nil(t, err)
+
+	// RLWE parameters of the samples
+	// N=512, Q=0x3001 -> 2^135
+	paramsLWE, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN: 9,
+		Q:    []uint64{0x3001},
+	})
+
+	assert.Nil(t, err)
+
+	t.Run(testString(paramsLUT, "MultiValueLUT/"), func(t *testing.T) {
+
+		scaleLWE := float64(paramsLWE.Q()[0]) / 4.0
+		scaleLUT := float64(paramsLUT.Q()[0]) / 4.0
+
+		slots := 16
+
+		// Functions evaluated with a single blind rotation per slot
+		functions := []func(x float64) float64{sign, math.Abs}
+
+		LUTPoly := InitMultiValueLUT(functions, scaleLUT, paramsLUT.RingQ(), -1, 1)
+
+		// Repacking with the largest gap, to minimize the number of key-switches
+		gap := paramsLUT.N() / slots
+
+		lutPolyMap := make(map[int]*ring.Poly)
+		repackIndex := make(map[int]int)
+		for i := 0; i < slots; i++ {
+			lutPolyMap[i] = LUTPoly
+			repackIndex[i] = i * gap
+		}
+
+		skLWE := rlwe.NewKeyGenerator(paramsLWE).GenSecretKey()
+		encryptorLWE := rlwe.NewEncryptor(paramsLWE, skLWE)
+
+		// Values in ]-1, 1[, away from the discontinuity of the anti-periodic extension of abs at -1 and 1
+		values := make([]float64, slots)
+		for i := 0; i < slots; i++ {
+			values[i] = -1 + float64(2*i+1)/float64(slots)
+		}
+
+		ptLWE := rlwe.NewPlaintext(paramsLWE, paramsLWE.MaxLevel())
+		for i := range values {
+			if values[i] < 0 {
+				ptLWE.Value.Coeffs[0][i] = paramsLWE.Q()[0] - uint64(-values[i]*scaleLWE)
+			} else {
+				ptLWE.Value.Coeffs[0][i] = uint64(values[i] * scaleLWE)
+			}
+		}
+
+		ctLWE := rlwe.NewCiphertextNTT(paramsLWE, 1, paramsLWE.MaxLevel())
+		encryptorLWE.Encrypt(ptLWE, ctLWE)
+
+		kgenLUT := rlwe.NewKeyGenerator(paramsLUT)
+		skLUT := kgenLUT.GenSecretKey()
+
+		// Rotation keys of the repacking
+		rtks := kgenLUT.GenRotationKeys(paramsLUT.GaloisElementsForMergeRLWE(), skLUT)
+
+		eval := NewEvaluator(paramsLUT, paramsLWE, rtks)
+
+		LUTKEY := GenEvaluationKey(paramsLUT, skLUT, paramsLWE, skLWE)
+
+		q := paramsLUT.Q()[0]
+		qHalf := q >> 1
+		decode := func(c uint64) float64 {
+			if c >= qHalf {
+				return -float64(q-c) / scaleLUT
+			}
+			return float64(c) / scaleLUT
+		}
+
+		decryptorLUT := rlwe.NewDecryptor(paramsLUT, skLUT)
+		ptLUT := rlwe.NewPlaintext(paramsLUT, paramsLUT.MaxLevel())
+
+		// One ciphertext per slot and function, with the output on the constant coefficient
+		ctsLUT := eval.EvaluateMultiValue(ctLWE, lutPolyMap, len(functions), LUTKEY)
+
+		for i := 0; i < slots; i++ {
+
+			assert.Len(t, ctsLUT[i], len(functions))
+
+			for j, f := range functions {
+				decryptorLUT.Decrypt(ctsLUT[i][j], ptLUT)
+				if ptLUT.Value.IsNTT {
+					paramsLUT.RingQ().InvNTT(ptLUT.Value, ptLUT.Value)
+				}
+				assert.InDelta(t, f(values[i]), decode(ptLUT.Value.Coeffs[0][0]), 0.125, "function %d on %f", j, values[i])
+			}
+		}
+
+		// One ciphertext per function, with the outputs repacked on the coefficients
+		ctsRepacked := eval.EvaluateMultiValueAndRepack(ctLWE, lutPolyMap, len(functions), repackIndex, LUTKEY)
+
+		assert.Len(t, ctsRepacked, len(functions))
+
+		for j, f := range functions {
+			decryptorLUT.Decrypt(ctsRepacked[j], ptLUT)
+			if ptLUT.Value.IsNTT {
+				paramsLUT.RingQ().InvNTT(ptLUT.Value, ptLUT.Value)
+			}
+			for i := 0; i < slots; i++ {
+				assert.InDelta(t, f(values[i]), decode(ptLUT.Value.Coeffs[0][i*gap]), 0.125, "function %d on %f", j, values[i])
+			}
+		}
+	})
+}
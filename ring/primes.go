@@ -2,6 +2,7 @@ package ring
 
 import (
 	"fmt"
+	"math"
 	"math/bits"
 )
 
@@ -10,6 +11,40 @@ func IsPrime(x uint64) bool {
 	return NewUint(x).ProbablyPrime(0)
 }
 
+// IsPrimePower returns the prime p and the exponent e >= 1 such that x = p^e, and false if x is not a prime power.
+func IsPrimePower(x uint64) (p uint64, e int, ok bool) {
+
+	if IsPrime(x) {
+		return x, 1, true
+	}
+
+	for e = 2; e < bits.Len64(x); e++ {
+
+		// Candidate e-th root, corrected for the floating point error.
+		root := uint64(math.Round(math.Pow(float64(x), 1/float64(e))))
+
+		for _, p = range []uint64{root - 1, root, root + 1} {
+
+			if p < 2 {
+				continue
+			}
+
+			pow, overflow := uint64(1), false
+			for i := 0; i < e && !overflow; i++ {
+				var hi uint64
+				hi, pow = bits.Mul64(pow, p)
+				overflow = hi != 0
+			}
+
+			if !overflow && pow == x && IsPrime(p) {
+				return p, e, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
 // GenerateNTTPrimes generates n NthRoot NTT friendly primes given logQ = size of the primes.
 // It will return all the appropriate primes, up to the number of n, with the
 // best available deviation from the base power of 2 for the given n.
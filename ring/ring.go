@@ -99,6 +99,7 @@ type Ring struct {
 
 // NewRing creates a new RNS Ring with degree N and coefficient moduli Moduli with Standard NTT. N must be a power of two larger than 8. Moduli should be
 // a non-empty []uint64 with distinct prime elements. All moduli must also be equal to 1 modulo 2*N.
+// Moduli can also be a single prime power p^e, with p equal to 1 modulo 2*N, in which case the NTT is carried out modulo p^e.
 // An error is returned with a nil *Ring in the case of non NTT-enabling parameters.
 func NewRing(N int, Moduli []uint64) (r *Ring, err error) {
 	return NewRingWithCustomNTT(N, Moduli, NumberTheoreticTransformerStandard{}, 2*N)
@@ -265,16 +266,28 @@ func (r *Ring) genNTTParams(NthRoot uint64) error {
 		panic("error : invalid r parameters (missing)")
 	}
 
-	// Checks if each qi is prime and equal to 1 mod NthRoot
+	// Checks if each qi is a power of a prime equal to 1 mod NthRoot, and computes the
+	// prime and Euler's totient of each qi, which are needed to find the roots and inverses.
+	primes := make([]uint64, len(r.Modulus))
+	totients := make([]uint64, len(r.Modulus))
 	for i, qi := range r.Modulus {
-		if !IsPrime(qi) {
-			return fmt.Errorf("invalid modulus (Modulus[%d] is not prime)", i)
+
+		p, e, ok := IsPrimePower(qi)
+		if !ok {
+			return fmt.Errorf("invalid modulus (Modulus[%d] is not prime or a prime power)", i)
 		}
 
-		if qi&(NthRoot-1) != 1 {
+		if p&(NthRoot-1) != 1 {
 			r.AllowsNTT = false
 			return fmt.Errorf("invalid modulus (Modulus[%d] != 1 mod NthRoot)", i)
 		}
+
+		primes[i] = p
+		totients[i] = qi / p * (p - 1)
+
+		if e > 1 && len(r.Modulus) > 1 {
+			return fmt.Errorf("invalid modulus (Modulus[%d] is a prime power, which is only supported for a single modulus)", i)
+		}
 	}
 
 	r.NthRoot = NthRoot
@@ -287,7 +300,7 @@ func (r *Ring) genNTTParams(NthRoot uint64) error {
 
 		for i := 0; i < j; i++ {
 
-			r.RescaleParams[j-1][i] = MForm(r.Modulus[i]-ModExp(r.Modulus[j], r.Modulus[i]-2, r.Modulus[i]), r.Modulus[i], r.BredParams[i])
+			r.RescaleParams[j-1][i] = MForm(r.Modulus[i]-ModExp(r.Modulus[j], totients[i]-1, r.Modulus[i]), r.Modulus[i], r.BredParams[i])
 		}
 	}
 
@@ -302,21 +315,24 @@ func (r *Ring) genNTTParams(NthRoot uint64) error {
 	for i, qi := range r.Modulus {
 
 		// 1.1 Computes N^(-1) mod Q in Montgomery form
-		r.NttNInv[i] = MForm(ModExp(NthRoot>>1, qi-2, qi), qi, r.BredParams[i])
+		r.NttNInv[i] = MForm(ModExp(NthRoot>>1, totients[i]-1, qi), qi, r.BredParams[i])
 
 		// 1.2 Computes Psi and PsiInv in Montgomery form
 		r.NttPsi[i] = make([]uint64, NthRoot>>1)
 		r.NttPsiInv[i] = make([]uint64, NthRoot>>1)
 
-		// Finds a 2N-th primitive Root
-		g := primitiveRoot(qi)
+		// Finds a 2N-th primitive Root mod p
+		p := primes[i]
+		g := primitiveRoot(p)
+		psi := ModExp(g, (p-1)/NthRoot, p)
 
-		power := (qi - 1) / NthRoot
-		powerInv := (qi - 1) - power
+		// Lifts it to a 2N-th primitive root mod qi = p^e: psi^(p^(e-1)) has the same
+		// residue mod p and its order divides p-1 (Teichmuller lift).
+		psi = ModExp(psi, qi/p, qi)
 
 		// Computes Psi and PsiInv in Montgomery form
-		PsiMont := MForm(ModExp(g, power, qi), qi, r.BredParams[i])
-		PsiInvMont := MForm(ModExp(g, powerInv, qi), qi, r.BredParams[i])
+		PsiMont := MForm(psi, qi, r.BredParams[i])
+		PsiInvMont := MForm(ModExp(psi, NthRoot-1, qi), qi, r.BredParams[i])
 
 		r.PsiMont[i] = PsiMont
 		r.PsiInvMont[i] = PsiInvMont
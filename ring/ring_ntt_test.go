@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cipherflow-fhe/lattigo/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var testVector = []struct {
@@ -118,3 +120,51 @@ func TestNTT(t *testing.T) {
 		})
 	}
 }
+
+func TestNTTPrimePower(t *testing.T) {
+
+	// 12289 = 1 mod 128
+	N := 64
+	q := uint64(12289 * 12289 * 12289)
+
+	ringQ, err := NewRing(N, []uint64{q})
+	require.NoError(t, err)
+
+	prng, err := utils.NewPRNG()
+	require.NoError(t, err)
+
+	sampler := NewUniformSampler(prng, ringQ)
+	p0, p1 := sampler.ReadNew(), sampler.ReadNew()
+
+	t.Run(fmt.Sprintf("N=%d/q=12289^3/InvNTT", N), func(t *testing.T) {
+		x := ringQ.NewPoly()
+		ringQ.NTT(p0, x)
+		ringQ.InvNTT(x, x)
+		require.True(t, ringQ.Equal(p0, x))
+	})
+
+	t.Run(fmt.Sprintf("N=%d/q=12289^3/MulCoeffs", N), func(t *testing.T) {
+
+		// Negacyclic convolution in the coefficient domain
+		want := make([]uint64, N)
+		bredParams := ringQ.BredParams[0]
+		for i := 0; i < N; i++ {
+			for j := 0; j < N; j++ {
+				c := BRed(p0.Coeffs[0][i], p1.Coeffs[0][j], q, bredParams)
+				if i+j < N {
+					want[i+j] = CRed(want[i+j]+c, q)
+				} else {
+					want[i+j-N] = CRed(want[i+j-N]+q-c, q)
+				}
+			}
+		}
+
+		x, y := ringQ.NewPoly(), ringQ.NewPoly()
+		ringQ.NTT(p0, x)
+		ringQ.NTT(p1, y)
+		ringQ.MulCoeffs(x, y, x)
+		ringQ.InvNTT(x, x)
+
+		require.Equal(t, want, x.Coeffs[0])
+	})
+}
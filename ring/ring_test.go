@@ -165,6 +165,14 @@ func testNewRing(t *testing.T) {
 		require.NotNil(t, r)
 		require.NoError(t, err)
 
+		r, err = NewRing(16, []uint64{97 * 97 * 97}) // Passing NTT-enabling prime power coeff modulus
+		require.NotNil(t, r)
+		require.NoError(t, err)
+
+		r, err = NewRing(16, []uint64{97 * 97, 193}) // Passing a prime power among several coeff moduli
+		require.NotNil(t, r)
+		require.Error(t, err)
+
 	})
 }
 
@@ -345,7 +345,7 @@ func (enc *pkEncryptor) encryptZeroNoP(ct *Ciphertext) {
 	}
 
 	c1.IsNTT = ct.Value[0].IsNTT
-	ct.Resize(levelQ, levelQ)
+	ct.Resize(ct.Degree(), levelQ)
 }
 
 // Encrypt encrypts the input plaintext using the stored secret-key and writes the result on ct.
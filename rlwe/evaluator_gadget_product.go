@@ -28,14 +28,17 @@ func (eval *Evaluator) GadgetProduct(levelQ int, cx *ring.Poly, gadgetCt GadgetC
 		eval.BasisExtender.ModDownQPtoQNTT(levelQ, levelP, p0QP.Q, p0QP.P, p0QP.Q)
 		eval.BasisExtender.ModDownQPtoQNTT(levelQ, levelP, p1QP.Q, p1QP.P, p1QP.Q)
 	} else if !cx.IsNTT {
-		eval.params.RingQ().InvNTTLazyLvl(levelQ, p0QP.Q, p0QP.Q)
-		eval.params.RingQ().InvNTTLazyLvl(levelQ, p1QP.Q, p1QP.Q)
-
 		if levelP != -1 {
+			eval.params.RingQ().InvNTTLazyLvl(levelQ, p0QP.Q, p0QP.Q)
+			eval.params.RingQ().InvNTTLazyLvl(levelQ, p1QP.Q, p1QP.Q)
 			eval.params.RingP().InvNTTLazyLvl(levelP, p0QP.P, p0QP.P)
 			eval.params.RingP().InvNTTLazyLvl(levelP, p1QP.P, p1QP.P)
 			eval.BasisExtender.ModDownQPtoQ(levelQ, levelP, p0QP.Q, p0QP.P, p0QP.Q)
 			eval.BasisExtender.ModDownQPtoQ(levelQ, levelP, p1QP.Q, p1QP.P, p1QP.Q)
+		} else {
+			// Without P there is no ModDown to reduce the output of the lazy InvNTT.
+			eval.params.RingQ().InvNTTLvl(levelQ, p0QP.Q, p0QP.Q)
+			eval.params.RingQ().InvNTTLvl(levelQ, p1QP.Q, p1QP.Q)
 		}
 	}
 }
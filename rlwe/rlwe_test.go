@@ -297,6 +297,22 @@ func testEncryptor(kgen KeyGenerator, t *testing.T) {
 		require.GreaterOrEqual(t, 9+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
 	})
 
+	t.Run(testString(params, "Encrypt/Pk/NoP/EncryptZero"), func(t *testing.T) {
+		paramsNoP := newTestParamsNoP(params, t)
+		ringQNoP := paramsNoP.RingQ()
+		kgenNoP := NewKeyGenerator(paramsNoP)
+		skNoP, pkNoP := kgenNoP.GenKeyPair()
+		encryptor := NewEncryptor(paramsNoP, pkNoP)
+		ciphertext := NewCiphertextNTT(paramsNoP, 1, paramsNoP.MaxLevel())
+		encryptor.EncryptZero(ciphertext)
+		require.Equal(t, 1, ciphertext.Degree())
+		require.Equal(t, paramsNoP.MaxLevel(), ciphertext.Level())
+		ringQNoP.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], skNoP.Value.Q, ciphertext.Value[0])
+		ringQNoP.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+		// Without P the error u*e + e0 + e1*s is not divided, and its norm grows with sqrt(N)
+		require.GreaterOrEqual(t, 5+paramsNoP.LogN()+(paramsNoP.LogN()+1)/2, log2OfInnerSum(ciphertext.Level(), ringQNoP, ciphertext.Value[0]))
+	})
+
 	t.Run(testString(params, "Encrypt/Pk/ShallowCopy"), func(t *testing.T) {
 		enc1 := NewEncryptor(params, pk)
 		enc2 := enc1.ShallowCopy()
@@ -441,6 +457,95 @@ func testKeySwitcher(kgen KeyGenerator, t *testing.T) {
 		ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
 		require.GreaterOrEqual(t, 11+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
 	})
+
+	t.Run(testString(params, "Relinearize/Degree3"), func(t *testing.T) {
+
+		sk := kgen.GenSecretKey()
+		eval := NewEvaluator(params, &EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 2)})
+
+		ringQ := params.RingQ()
+
+		levelQ := params.MaxLevel()
+
+		// Samples a degree-3 encryption of zero: c0 = -(c1*s + c2*s^2 + c3*s^3)
+		prng, _ := utils.NewPRNG()
+		sampler := ring.NewUniformSampler(prng, ringQ)
+		ciphertext := NewCiphertextNTT(params, 3, levelQ)
+		for i := 1; i < 4; i++ {
+			sampler.ReadLvl(levelQ, ciphertext.Value[i])
+		}
+
+		acc := ringQ.NewPolyLvl(levelQ)
+		ring.CopyValuesLvl(levelQ, ciphertext.Value[3], acc)
+		for i := 2; i > 0; i-- {
+			ringQ.MulCoeffsMontgomeryLvl(levelQ, acc, sk.Value.Q, acc)
+			ringQ.AddLvl(levelQ, acc, ciphertext.Value[i], acc)
+		}
+		ringQ.MulCoeffsMontgomeryLvl(levelQ, acc, sk.Value.Q, acc)
+		ringQ.NegLvl(levelQ, acc, ciphertext.Value[0])
+
+		ctOut := NewCiphertextNTT(params, 1, levelQ)
+		eval.Relinearize(ciphertext, ctOut)
+		require.Equal(t, 1, ctOut.Degree())
+
+		// Test that Dec(Relin(ct), sk) has a small norm
+		ringQ.MulCoeffsMontgomeryAndAddLvl(ctOut.Level(), ctOut.Value[1], sk.Value.Q, ctOut.Value[0])
+		ringQ.InvNTTLvl(ctOut.Level(), ctOut.Value[0], ctOut.Value[0])
+		require.GreaterOrEqual(t, 12+params.LogN(), log2OfInnerSum(ctOut.Level(), ringQ, ctOut.Value[0]))
+	})
+
+	t.Run(testString(params, "KeySwitch/NoP/InvNTT"), func(t *testing.T) {
+
+		paramsNoP := newTestParamsNoP(params, t)
+		ringQ := paramsNoP.RingQ()
+		kgenNoP := NewKeyGenerator(paramsNoP)
+		eval := NewEvaluator(paramsNoP, nil)
+
+		levelQ := paramsNoP.MaxLevel()
+
+		swk := kgenNoP.GenSwitchingKey(kgenNoP.GenSecretKey(), kgenNoP.GenSecretKey())
+
+		prng, _ := utils.NewPRNG()
+		cxNTT := ring.NewUniformSampler(prng, ringQ).ReadLvlNew(levelQ)
+		cxNTT.IsNTT = true
+		cx := ringQ.NewPolyLvl(levelQ)
+		ringQ.InvNTTLvl(levelQ, cxNTT, cx)
+
+		// The gadget product of cx outside of the NTT domain must match, coefficient-wise and
+		// fully reduced, the gadget product of cx in the NTT domain.
+		want0, want1 := ringQ.NewPolyLvl(levelQ), ringQ.NewPolyLvl(levelQ)
+		eval.GadgetProduct(levelQ, cxNTT, swk.GadgetCiphertext, want0, want1)
+		ringQ.InvNTTLvl(levelQ, want0, want0)
+		ringQ.InvNTTLvl(levelQ, want1, want1)
+
+		have0, have1 := ringQ.NewPolyLvl(levelQ), ringQ.NewPolyLvl(levelQ)
+		eval.GadgetProduct(levelQ, cx, swk.GadgetCiphertext, have0, have1)
+
+		for i := 0; i < levelQ+1; i++ {
+			for _, c := range [][]uint64{have0.Coeffs[i], have1.Coeffs[i]} {
+				for _, v := range c {
+					require.Less(t, v, ringQ.Modulus[i])
+				}
+			}
+		}
+
+		require.True(t, ringQ.EqualLvl(levelQ, want0, have0))
+		require.True(t, ringQ.EqualLvl(levelQ, want1, have1))
+	})
+}
+
+// newTestParamsNoP returns a set of parameters without auxiliary modulus P, with up
+// to the first three moduli of params, that relies on the power of two decomposition.
+func newTestParamsNoP(params Parameters, t *testing.T) Parameters {
+	paramsNoP, err := NewParametersFromLiteral(ParametersLiteral{
+		LogN:     params.LogN(),
+		Q:        params.Q()[:utils.MinInt(3, params.QCount())],
+		Pow2Base: 16,
+		Sigma:    params.Sigma(),
+		RingType: params.RingType(),
+	})
+	require.NoError(t, err)
+	return paramsNoP
 }
 
 func testKeySwitchDimension(kgen KeyGenerator, t *testing.T) {
@@ -0,0 +1,221 @@
+// Package schemeswitch implements the conversion of ciphertexts between the BFV and CKKS schemes under the same secret key.
+//
+// Both conversions move the plaintext between its coefficients and its slots with the homomorphic encoding of one scheme and
+// the half bootstrapping of the other, and change the interpretation of the ciphertext with a modulus switch at level zero:
+//
+//   - BFVToCKKS evaluates the BFV homomorphic encoding modulo t, which moves the slots of the two rows to the coefficients,
+//     rescales the ciphertext to the modulus Q0, where it is a CKKS ciphertext of the coefficients with scale Q0/t,
+//     and evaluates the CKKS half bootstrapping (ModUp, CoeffsToSlots and EvalMod), which moves the coefficients back to the slots.
+//   - CKKSToBFV evaluates the CKKS homomorphic encoding (SlotsToCoeffs), which moves the slots to the coefficients, sets the scale
+//     of the ciphertext to Q0/t at level zero, where it is a BFV ciphertext of the coefficients with an error, and evaluates the
+//     BFV half bootstrapping, which rounds the coefficients to integers with the digit removal and moves them to the slots.
+//
+// The conversion from BFV to CKKS is exact up to the precision of the CKKS bootstrapping, and the conversion from CKKS to BFV
+// rounds the values to the nearest integers modulo t. See Switcher for the requirements on the values and the precision loss.
+package schemeswitch
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	bfvbtp "github.com/cipherflow-fhe/lattigo/bfv/bootstrapping"
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	"github.com/cipherflow-fhe/lattigo/ckks/advanced"
+	ckksbtp "github.com/cipherflow-fhe/lattigo/ckks/bootstrapping"
+	"github.com/cipherflow-fhe/lattigo/ring"
+	"github.com/cipherflow-fhe/lattigo/rlwe"
+)
+
+// Parameters is a struct for the parameters of the scheme switching.
+type Parameters struct {
+	CKKSBootstrapping ckksbtp.Parameters // Parameters of the CKKS half bootstrapping of BFVToCKKS and of the SlotsToCoeffs of CKKSToBFV.
+	BFVBootstrapping  bfvbtp.Parameters  // Parameters of the BFV homomorphic encoding of BFVToCKKS and of the half bootstrapping of CKKSToBFV.
+}
+
+// Switcher is a struct to store the bootstrapping circuits of both schemes and the keys for the scheme switching.
+//
+// The CKKS and BFV parameters must share the same rlwe.Parameters, hence the same secret key, and the CKKS parameters must have
+// N/2 slots, so that the N/2 slots of each row of a BFV plaintext are mapped to the N/2 slots of one CKKS ciphertext. The plaintext
+// modulus t must be a power p^r of a prime p congruent to 1 mod 2N with p*t < 2^61, as required by the BFV bootstrapping.
+// The keys are the CKKS bootstrapping keys and the rotation keys of the BFV bootstrapping, see GenEvaluationKeys.
+//
+// BFVToCKKS requires the values v of the BFV plaintext, in the centered representation modulo t, to satisfy |v| <= t/MessageRatio,
+// where MessageRatio is the ratio of the EvalMod step of the CKKS bootstrapping, as the CKKS bootstrapping reduces the values
+// modulo Q0 only within this ratio. Its output has the error of the CKKS bootstrapping on the values v*MessageRatio/t, scaled
+// by t/MessageRatio. Unless ArcSineDeg is set, this error is dominated by the sine approximation of the EvalMod step, which gives
+// a relative error of about (2*pi*v/t)^2/6, e.g. 2^-13 for |v| = 256 and t = 65537.
+//
+// CKKSToBFV requires the values of the CKKS plaintext after the SlotsToCoeffs to be within about NoiseBound/(2p) of integers,
+// where NoiseBound is the noise bound of the BFV bootstrapping, since the error of the values is multiplied by p when the
+// ciphertext is switched to the plaintext modulus p*t, and returns their rounding to the nearest integers modulo t. This requires
+// log2(p) - log2(NoiseBound) + 1 bits of precision after the decimal point, e.g. 12 bits for p = 65537 and H = 192.
+// Values converted by BFVToCKKS meet this requirement if |v| is small enough, e.g. |v| <= 32 for t = 65537 and MessageRatio = 256.
+type Switcher struct {
+	ckksParams ckks.Parameters
+	bfvParams  bfv.Parameters
+
+	ckksBtp     *ckksbtp.Bootstrapper
+	stcMatrices advanced.EncodingMatrix // CKKS SlotsToCoeffs matrices without scaling
+
+	bfvBtp      *bfvbtp.Bootstrapper
+	bfvEval     bfv.Evaluator
+	bfvEncoding *bfvbtp.HomomorphicEncoding // BFV homomorphic encoding modulo t
+
+	p                  uint64 // Prime of the plaintext modulus t = p^r
+	q0OverMessageRatio float64
+}
+
+// NewSwitcher creates a new Switcher for the CKKS parameters ckksParams and the BFV parameters bfvParams,
+// which must share the same rlwe.Parameters.
+func NewSwitcher(ckksParams ckks.Parameters, bfvParams bfv.Parameters, swParams Parameters, evk ckksbtp.EvaluationKeys) (sw *Switcher, err error) {
+
+	if !ckksParams.Parameters.Equals(bfvParams.Parameters) {
+		return nil, fmt.Errorf("cannot NewSwitcher: ckksParams and bfvParams must share the same rlwe.Parameters")
+	}
+
+	if ckksParams.LogSlots() != ckksParams.MaxLogSlots() {
+		return nil, fmt.Errorf("cannot NewSwitcher: ckksParams.LogSlots() must be %d", ckksParams.MaxLogSlots())
+	}
+
+	sw = &Switcher{ckksParams: ckksParams, bfvParams: bfvParams}
+
+	var ok bool
+	if sw.p, _, ok = ring.IsPrimePower(bfvParams.T()); !ok {
+		return nil, fmt.Errorf("cannot NewSwitcher: the plaintext modulus must be a prime power")
+	}
+
+	if sw.ckksBtp, err = ckksbtp.NewBootstrapper(ckksParams, swParams.CKKSBootstrapping, evk); err != nil {
+		return nil, fmt.Errorf("cannot NewSwitcher: %w", err)
+	}
+
+	if sw.bfvBtp, err = bfvbtp.NewBootstrapper(bfvParams, swParams.BFVBootstrapping, evk.EvaluationKey); err != nil {
+		return nil, fmt.Errorf("cannot NewSwitcher: %w", err)
+	}
+
+	stcParams := swParams.CKKSBootstrapping.SlotsToCoeffsParameters
+	stcParams.LogN = ckksParams.LogN()
+	stcParams.LogSlots = ckksParams.LogSlots()
+	stcParams.Scaling = 1
+	sw.stcMatrices = advanced.NewHomomorphicEncodingMatrixFromLiteral(stcParams, ckks.NewEncoder(ckksParams))
+
+	sw.bfvEval = bfv.NewEvaluator(bfvParams, evk.EvaluationKey)
+	sw.bfvEncoding = bfvbtp.NewHomomorphicEncoding(bfvParams, swParams.BFVBootstrapping)
+
+	messageRatio := swParams.CKKSBootstrapping.EvalModParameters.MessageRatio
+	sw.q0OverMessageRatio = math.Exp2(math.Round(math.Log2(ckksParams.QiFloat64(0) / messageRatio)))
+
+	return
+}
+
+// GenEvaluationKeys generates the keys of the scheme switching, which contain the CKKS bootstrapping keys
+// and the rotation keys of the BFV bootstrapping.
+func GenEvaluationKeys(swParams Parameters, ckksParams ckks.Parameters, bfvParams bfv.Parameters, sk *rlwe.SecretKey) ckksbtp.EvaluationKeys {
+
+	rotations := swParams.CKKSBootstrapping.RotationsForBootstrapping(ckksParams)
+	rotations = append(rotations, swParams.BFVBootstrapping.RotationsForBootstrapping(bfvParams)...)
+
+	kgen := ckks.NewKeyGenerator(ckksParams)
+	swkDtS, swkStD := swParams.CKKSBootstrapping.GenEncapsulationSwitchingKeys(ckksParams, sk)
+
+	return ckksbtp.EvaluationKeys{
+		EvaluationKey: rlwe.EvaluationKey{
+			Rlk:  kgen.GenRelinearizationKey(sk, 1),
+			Rtks: kgen.GenRotationKeysForRotations(rotations, true, sk),
+		},
+		SwkDtS: swkDtS,
+		SwkStD: swkStD,
+	}
+}
+
+// BFVToCKKS converts a BFV ciphertext of degree one into two CKKS ciphertexts whose slots are the centered values of the first
+// and the second row of the BFV plaintext. The output ciphertexts are at the level LevelStart of the SlotsToCoeffs of the CKKS
+// bootstrapping, which is the input level of CKKSToBFV, and their scale is not a power of two: it is params.DefaultScale() times
+// Q0/(t * 2^round(log2(Q0/MessageRatio))), which can be set back to params.DefaultScale() with one level.
+func (sw *Switcher) BFVToCKKS(ctIn *bfv.Ciphertext) (ctRow0, ctRow1 *ckks.Ciphertext) {
+
+	if ctIn.Degree() != 1 {
+		panic("cannot BFVToCKKS: input ciphertext must be of degree 1")
+	}
+
+	// Moves the slots to the coefficients, row 0 to the first N/2 coefficients and row 1 to the last N/2 coefficients
+	ct := sw.bfvEncoding.SlotsToCoeffs(sw.bfvEval, ctIn)
+
+	// Q0/t * m + e at level 0
+	sw.bfvEval.RescaleTo(0, ct, ct)
+
+	// Interprets the ciphertext as a CKKS ciphertext at scale Q0/MessageRatio, so that the CKKS bootstrapping
+	// does not scale it up and its values m * Q0/(t * scale) stay within the range of the EvalMod step.
+	ctCKKS := &ckks.Ciphertext{Ciphertext: ct.Ciphertext, Scale: sw.q0OverMessageRatio}
+	ringQ := sw.ckksParams.RingQ()
+	for i := range ctCKKS.Value {
+		ringQ.NTTLvl(0, ctCKKS.Value[i], ctCKKS.Value[i])
+		ctCKKS.Value[i].IsNTT = true
+	}
+
+	ctRow0, ctRow1 = sw.ckksBtp.HalfBootstrapp(ctCKKS)
+
+	// Divides the values by Q0/(t * scale) for free
+	ratio := sw.ckksParams.QiFloat64(0) / (float64(sw.bfvParams.T()) * sw.q0OverMessageRatio)
+	ctRow0.Scale *= ratio
+	ctRow1.Scale *= ratio
+
+	return
+}
+
+// CKKSToBFV converts two CKKS ciphertexts of degree one and of the same scale, whose slots are the values of the first and
+// the second row, into a BFV ciphertext at MaxLevel whose rows are the values rounded to the nearest integers modulo t.
+// The input ciphertexts must be at least at the level LevelStart of the SlotsToCoeffs of the CKKS bootstrapping, whose output
+// must be at least at level 1.
+// Returns an error if the scale of the input ciphertexts is too large to be switched to Q0/t with the modulus Q1, that is, if
+// the rounding of the constant of the scale switching changes the values of magnitude t/2 by more than 1/(2p).
+func (sw *Switcher) CKKSToBFV(ctRow0, ctRow1 *ckks.Ciphertext) (ctOut *bfv.Ciphertext, err error) {
+
+	if ctRow0.Degree() != 1 || ctRow1.Degree() != 1 {
+		return nil, fmt.Errorf("cannot CKKSToBFV: input ciphertexts must be of degree 1")
+	}
+
+	if ctRow0.Scale != ctRow1.Scale {
+		return nil, fmt.Errorf("cannot CKKSToBFV: input ciphertexts must have the same scale")
+	}
+
+	// Moves the slots to the coefficients, row 0 to the real part and row 1 to the imaginary part of the slots,
+	// that is, to the first and to the last N/2 coefficients.
+	ct := sw.ckksBtp.SlotsToCoeffsNew(ctRow0, ctRow1, sw.stcMatrices)
+
+	if ct.Level() < 1 {
+		return nil, fmt.Errorf("cannot CKKSToBFV: SlotsToCoeffs must leave at least one level")
+	}
+
+	// Q0/t * m + e at level 0, by a multiplication with round(Q1 * Q0/(t * scale)) and a division by Q1,
+	// as the scale Q0/t is in general larger than the scale of the ciphertext.
+	sw.ckksBtp.DropLevel(ct, ct.Level()-1)
+	scale := sw.ckksParams.QiFloat64(0) / float64(sw.bfvParams.T())
+	q1 := sw.ckksParams.QiFloat64(1)
+
+	constant := q1 * scale / ct.Scale
+	rounded := math.Round(constant)
+
+	if rounded < 1 {
+		return nil, fmt.Errorf("cannot CKKSToBFV: scale 2^%.2f of the input is larger than Q1 * Q0/t", math.Log2(ct.Scale))
+	}
+
+	if relErr := math.Abs(rounded-constant) / constant; relErr*float64(sw.bfvParams.T())/2 > 1/(2*float64(sw.p)) {
+		return nil, fmt.Errorf("cannot CKKSToBFV: the scale switching constant %.2f of the input scale 2^%.2f cannot be rounded without changing the values", constant, math.Log2(ct.Scale))
+	}
+
+	sw.ckksBtp.MultByConst(ct, uint64(rounded), ct)
+	ct.Scale = scale * q1
+	if err = sw.ckksBtp.Rescale(ct, scale, ct); err != nil {
+		return nil, fmt.Errorf("cannot CKKSToBFV: %w", err)
+	}
+
+	ringQ := sw.bfvParams.RingQ()
+	for i := range ct.Value {
+		ringQ.InvNTTLvl(0, ct.Value[i], ct.Value[i])
+		ct.Value[i].IsNTT = false
+	}
+
+	// Rounds the coefficients and moves them to the slots
+	return sw.bfvBtp.HalfBootstrapp(&bfv.Ciphertext{Ciphertext: ct.Ciphertext}), nil
+}
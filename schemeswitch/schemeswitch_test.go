@@ -0,0 +1,134 @@
+package schemeswitch
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/cipherflow-fhe/lattigo/bfv"
+	bfvbtp "github.com/cipherflow-fhe/lattigo/bfv/bootstrapping"
+	"github.com/cipherflow-fhe/lattigo/ckks"
+	ckksbtp "github.com/cipherflow-fhe/lattigo/ckks/bootstrapping"
+	"github.com/cipherflow-fhe/lattigo/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemeSwitch(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping scheme switching tests for GOARCH=wasm")
+	}
+
+	paramSet := ckksbtp.DefaultParametersSparse[0]
+
+	// Insecure params for fast testing only
+	ckksParamsLit := paramSet.SchemeParams
+	ckksParamsLit.LogN = 13
+	ckksParamsLit.LogSlots = 12
+
+	ckksParams, err := ckks.NewParametersFromLiteral(ckksParamsLit)
+	require.NoError(t, err)
+
+	bfvParams, err := bfv.NewParameters(ckksParams.Parameters, 65537)
+	require.NoError(t, err)
+
+	swParams := Parameters{
+		CKKSBootstrapping: paramSet.BootstrappingParams,
+		BFVBootstrapping:  bfvbtp.DefaultParameters,
+	}
+
+	kgen := ckks.NewKeyGenerator(ckksParams)
+	sk := kgen.GenSecretKey()
+
+	sw, err := NewSwitcher(ckksParams, bfvParams, swParams, GenEvaluationKeys(swParams, ckksParams, bfvParams, sk))
+	require.NoError(t, err)
+
+	bfvEncoder := bfv.NewEncoder(bfvParams)
+	bfvEncryptor := bfv.NewEncryptor(bfvParams, sk)
+	bfvDecryptor := bfv.NewDecryptor(bfvParams, sk)
+
+	ckksEncoder := ckks.NewEncoder(ckksParams)
+	ckksEncryptor := ckks.NewEncryptor(ckksParams, sk)
+	ckksDecryptor := ckks.NewDecryptor(ckksParams, sk)
+
+	slots := bfvParams.N() >> 1
+	T := bfvParams.T()
+	messageRatio := swParams.CKKSBootstrapping.EvalModParameters.MessageRatio
+	bound := int64(float64(T) / messageRatio)
+
+	name := fmt.Sprintf("logN=%d/logQP=%d/T=%d", ckksParams.LogN(), ckksParams.LogQP(), T)
+
+	t.Run(name+"/BFVToCKKS", func(t *testing.T) {
+
+		values := make([]int64, bfvParams.N())
+		for i := range values {
+			values[i] = int64(utils.RandUint64()%uint64(2*bound+1)) - bound
+		}
+
+		ctIn := bfvEncryptor.EncryptNew(bfvEncoder.EncodeNew(values, bfvParams.MaxLevel()))
+
+		ctRow0, ctRow1 := sw.BFVToCKKS(ctIn)
+
+		require.Equal(t, ctRow0.Scale, ctRow1.Scale)
+
+		for k, ct := range []*ckks.Ciphertext{ctRow0, ctRow1} {
+			have := ckksEncoder.Decode(ckksDecryptor.DecryptNew(ct), ckksParams.LogSlots())
+			for i := 0; i < slots; i++ {
+				require.InDelta(t, float64(values[k*slots+i]), real(have[i]), 0.1)
+				require.InDelta(t, 0, imag(have[i]), 0.1)
+			}
+		}
+	})
+
+	t.Run(name+"/CKKSToBFV", func(t *testing.T) {
+
+		values := make([]uint64, bfvParams.N())
+		rows := [2][]complex128{make([]complex128, slots), make([]complex128, slots)}
+		for k := range rows {
+			for i := range rows[k] {
+				// Integers in [-T/2, T/2] with a small error
+				v := int64(utils.RandUint64()%T) - int64(T>>1)
+				rows[k][i] = complex(float64(v)+utils.RandFloat64(-1e-5, 1e-5), 0)
+				values[k*slots+i] = uint64(v+int64(T)) % T
+			}
+		}
+
+		ctRow0 := ckksEncryptor.EncryptNew(ckksEncoder.EncodeNew(rows[0], ckksParams.MaxLevel(), ckksParams.DefaultScale(), ckksParams.LogSlots()))
+		ctRow1 := ckksEncryptor.EncryptNew(ckksEncoder.EncodeNew(rows[1], ckksParams.MaxLevel(), ckksParams.DefaultScale(), ckksParams.LogSlots()))
+
+		ctOut, err := sw.CKKSToBFV(ctRow0, ctRow1)
+		require.NoError(t, err)
+
+		require.Equal(t, bfvParams.MaxLevel(), ctOut.Level())
+		require.Equal(t, values, bfvEncoder.DecodeUintNew(bfvDecryptor.DecryptNew(ctOut)))
+	})
+
+	t.Run(name+"/CKKSToBFV/Scale", func(t *testing.T) {
+
+		q1 := ckksParams.QiFloat64(1)
+		scale := ckksParams.QiFloat64(0) / float64(T)
+
+		// Scales for which the scale switching constant round(Q1 * Q0/(t * scale)) is zero or too coarse.
+		for _, ctScale := range []float64{4 * q1 * scale, q1 * scale / 1.5} {
+			ct := ckksEncryptor.EncryptNew(ckksEncoder.EncodeNew(make([]complex128, slots), ckksParams.MaxLevel(), ctScale, ckksParams.LogSlots()))
+			_, err := sw.CKKSToBFV(ct, ct.CopyNew())
+			require.Error(t, err)
+		}
+	})
+
+	t.Run(name+"/RoundTrip", func(t *testing.T) {
+
+		// Small values, so that the error of BFVToCKKS is within the rounding tolerance of CKKSToBFV
+		values := make([]int64, bfvParams.N())
+		for i := range values {
+			values[i] = int64(utils.RandUint64()%33) - 16
+		}
+
+		ctIn := bfvEncryptor.EncryptNew(bfvEncoder.EncodeNew(values, bfvParams.MaxLevel()))
+
+		ctOut, err := sw.CKKSToBFV(sw.BFVToCKKS(ctIn))
+		require.NoError(t, err)
+
+		require.Equal(t, values, bfvEncoder.DecodeIntNew(bfvDecryptor.DecryptNew(ctOut)))
+	})
+}